@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"employer/internal/handler"
+
+	"go.uber.org/zap"
+)
+
+// responseWriter запоминает код ответа и количество записанных байт
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush нужен потоковой выгрузке сотрудников
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// clientAddr IP клиента, определенный handler.ClientIPMiddleware, или адрес соединения
+func clientAddr(r *http.Request) string {
+	if ip := handler.ClientIPFromContext(r.Context()); ip != "" {
+		return ip
+	}
+	return r.RemoteAddr
+}
+
+// accessLogMiddleware логирует каждый запрос с кодом ответа и размером тела.
+// Оборачивает весь роутер, поэтому попадают и запросы к несуществующим маршрутам.
+// Статические файлы, пробы состояния и метрики не логируются.
+func accessLogMiddleware(logger *zap.Logger, basePath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, r)
+
+			if strings.HasPrefix(r.URL.Path, basePath+"/static/") || isProbePath(strings.TrimPrefix(r.URL.Path, basePath)) {
+				return
+			}
+
+			logger.Info("HTTP request",
+				zap.String("method", r.Method),
+				zap.String("url", r.URL.Path),
+				zap.String("remote_addr", clientAddr(r)),
+				zap.Int("status", rw.status),
+				zap.Int("bytes", rw.bytes),
+				zap.Duration("duration", time.Since(start)),
+				// идентификатор, под которым запрос записан в логах сервиса (пусто вне API)
+				zap.String("request_id", rw.Header().Get(handler.RequestIDHeader)),
+			)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// maxLoggedBodyBytes сколько байт тела запроса и ответа попадает в лог
+const maxLoggedBodyBytes = 16 << 10
+
+// redactedFields поля JSON, значения которых не попадают в лог (без учета регистра)
+var redactedFields = map[string]bool{
+	"phone":    true,
+	"password": true,
+	"token":    true,
+}
+
+// redactedValue подставляется вместо значений из redactedFields
+const redactedValue = "[REDACTED]"
+
+// redactJSON заменяет значения полей из redactedFields на любой глубине вложенности.
+// Порядок ключей в результате — по алфавиту
+func redactJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("после JSON значения есть лишние данные")
+	}
+	return json.Marshal(redactValue(value))
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if redactedFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(nested)
+			}
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = redactValue(nested)
+		}
+	}
+	return value
+}
+
+// cappedBuffer запоминает первые maxLoggedBodyBytes байт и считает общий размер
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	total int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if room := maxLoggedBodyBytes - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// describeBody тело для лога. Тексты сохраняются только для JSON и NDJSON, и то
+// после redactJSON: в остальных форматах (CSV, XML, multipart, XLSX) скрыть телефоны
+// нельзя, поэтому от них в лог попадают только тип и размер. Обрезанный или
+// некорректный JSON тоже не выводится
+func describeBody(contentType string, body *cappedBuffer) string {
+	if body == nil || body.total == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("<%s, %d bytes>", contentType, body.total)
+	if body.total > body.buf.Len() {
+		return summary
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-ndjson":
+		lines := strings.Split(strings.TrimRight(body.buf.String(), "\n"), "\n")
+		for i, line := range lines {
+			redacted, err := redactJSON([]byte(line))
+			if err != nil {
+				return summary
+			}
+			lines[i] = string(redacted)
+		}
+		return strings.Join(lines, "\n")
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		redacted, err := redactJSON(body.buf.Bytes())
+		if err != nil {
+			return summary
+		}
+		return string(redacted)
+	default:
+		return summary
+	}
+}
+
+// teeBody копирует прочитанное обработчиком тело запроса в буфер лога
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyLogWriter копирует начало ответа в буфер лога, не задерживая запись клиенту
+type bodyLogWriter struct {
+	http.ResponseWriter
+	body cappedBuffer
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+// Flush нужен потоковой выгрузке сотрудников
+func (w *bodyLogWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (w *bodyLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// bodyLogMiddleware логирует на уровне debug тела запросов и ответов API (LOG_HTTP_BODIES).
+// Запоминается только то, что обработчик сам прочитал и записал, поэтому повторное
+// чтение тела и потоковые ответы работают как без него
+func bodyLogMiddleware(logger *zap.Logger, basePath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, basePath+"/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var requestBody *cappedBuffer
+			if r.Body != nil && r.Body != http.NoBody {
+				requestBody = &cappedBuffer{}
+				r.Body = teeBody{Reader: io.TeeReader(r.Body, requestBody), Closer: r.Body}
+			}
+			rw := &bodyLogWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+
+			logger.Debug("HTTP body",
+				zap.String("method", r.Method),
+				zap.String("url", r.URL.Path),
+				zap.String("request_body", describeBody(r.Header.Get("Content-Type"), requestBody)),
+				zap.String("response_body", describeBody(rw.Header().Get("Content-Type"), &rw.body)),
+			)
+		})
+	}
+}
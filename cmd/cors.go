@@ -0,0 +1,72 @@
+package main
+
+import (
+	"employer/config"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// corsMiddleware добавляет CORS заголовки к запросам API. Разрешенные источники, время
+// кэширования предварительных запросов и доступные скриптам заголовки читаются из runtime
+// на каждом запросе, поэтому их можно менять без перезапуска. На предварительные запросы
+// OPTIONS отвечает роутер (handler.AutoMethods) с заголовком Allow
+func corsMiddleware(basePath string, runtime func() *config.RuntimeConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Применяем CORS только к API запросам
+			if strings.HasPrefix(r.URL.Path, basePath+"/api/") {
+				settings := runtime()
+				allowed := settings.CORSAllowedOrigins
+				if origin := allowedOrigin(allowed, r.Header.Get("Origin")); origin != "" {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Actor, Idempotency-Key, X-Request-ID")
+					// Max-Age нужен только ответу на предварительный запрос, Expose-Headers —
+					// только основному ответу
+					if isPreflight(r) {
+						if settings.CORSMaxAge > 0 {
+							w.Header().Set("Access-Control-Max-Age", strconv.Itoa(settings.CORSMaxAge))
+						}
+					} else if len(settings.CORSExposeHeaders) > 0 {
+						w.Header().Set("Access-Control-Expose-Headers", strings.Join(settings.CORSExposeHeaders, ", "))
+					}
+				}
+				// Ответ зависит от Origin, если разрешены не все источники
+				if !contains(allowed, "*") {
+					w.Header().Add("Vary", "Origin")
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isPreflight проверяет, что запрос — предварительный запрос CORS: OPTIONS
+// с заголовком Access-Control-Request-Method
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// allowedOrigin значение Access-Control-Allow-Origin для источника запроса:
+// "*", если разрешены любые, сам origin, если он в списке, иначе пусто
+func allowedOrigin(allowed []string, origin string) string {
+	if contains(allowed, "*") {
+		return "*"
+	}
+	if origin != "" && contains(allowed, origin) {
+		return origin
+	}
+	return ""
+}
+
+// contains проверяет наличие значения в списке
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"employer/internal/domain"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// eventPublisher получатель событий из БД (см. service.EventBroker)
+type eventPublisher interface {
+	Publish(event domain.EmployeeEvent)
+}
+
+// listenerPingInterval как часто проверять соединение LISTEN, если уведомлений нет
+const listenerPingInterval = 90 * time.Second
+
+// runEventListener слушает channel (EmployeeChangesChannel с префиксом DB_TABLE_PREFIX)
+// и публикует изменения сотрудников.
+// pq.Listener сам переподключается к БД; уведомления за время разрыва теряются,
+// поэтому после переподключения подписчикам отправляется resync. Завершается при отмене ctx.
+func runEventListener(ctx context.Context, dsn, channel string, publisher eventPublisher, zapLogger *zap.Logger) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+			zapLogger.Warn("соединение LISTEN потеряно", zap.Error(err))
+		case pq.ListenerEventReconnected:
+			zapLogger.Info("соединение LISTEN восстановлено")
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		zapLogger.Error("ошибка подписки на изменения сотрудников", zap.Error(err))
+		return
+	}
+	zapLogger.Info("запущена рассылка изменений сотрудников",
+		zap.String("channel", channel))
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zapLogger.Info("рассылка изменений сотрудников остановлена")
+			return
+		case notification := <-listener.Notify:
+			// nil приходит после переподключения
+			if notification == nil {
+				publisher.Publish(domain.EmployeeEvent{Op: domain.EventResync})
+				continue
+			}
+			event, err := parseEmployeeEvent(notification.Extra)
+			if err != nil {
+				zapLogger.Error("некорректное уведомление об изменении сотрудника",
+					zap.String("payload", notification.Extra),
+					zap.Error(err))
+				continue
+			}
+			publisher.Publish(event)
+		case <-ping.C:
+			go func() { _ = listener.Ping() }()
+		}
+	}
+}
+
+// parseEmployeeEvent разбирает уведомление триггера employees_notify
+func parseEmployeeEvent(payload string) (domain.EmployeeEvent, error) {
+	var event domain.EmployeeEvent
+	err := json.Unmarshal([]byte(payload), &event)
+	return event, err
+}
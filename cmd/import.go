@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"employer/internal/domain"
+	"employer/internal/repository"
+	"employer/internal/service"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// employeeImporter часть сервиса, необходимая команде import
+type employeeImporter interface {
+	ImportEmployees(ctx context.Context, reader service.EmployeeReader, opts service.ImportOptions) (*domain.ImportSummary, error)
+}
+
+// importCommand импортирует сотрудников из CSV файла без запуска HTTP сервера
+func importCommand(args []string, zapLogger *zap.Logger, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	configPath := configFlag(fs)
+	file := fs.String("file", "", "путь к CSV файлу (обязательно)")
+	mode := fs.String("mode", string(service.ImportModeReassign), "режим ID: reassign или overwrite")
+	strict := fs.Bool("strict", false, "остановиться на первой ошибочной строке")
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return configError(errors.New("флаг --file обязателен"))
+	}
+
+	cfg, zapLogger, err := setupCommand(*configPath, zapLogger)
+	if err != nil {
+		return err
+	}
+	defer syncLogger(zapLogger, stderr)
+
+	svcOpts, err := serviceOptions(cfg)
+	if err != nil {
+		return configError(err)
+	}
+
+	db, err := initDatabase(cfg, zapLogger)
+	if err != nil {
+		return databaseError(fmt.Errorf("инициализация БД: %w", err))
+	}
+	defer db.Close()
+
+	svc := service.NewEmployeeServiceWithOptions(repository.NewEmployeeRepository(db, zapLogger), zapLogger, svcOpts)
+	opts := service.ImportOptions{Mode: service.ImportMode(*mode), Strict: *strict}
+
+	if err := runImport(context.Background(), svc, *file, opts, stdout); err != nil {
+		return fmt.Errorf("импорт: %w", err)
+	}
+	return nil
+}
+
+// runImport импортирует CSV файл и печатает итог. Возвращает ошибку,
+// если хотя бы одна строка не импортирована.
+func runImport(ctx context.Context, importer employeeImporter, path string, opts service.ImportOptions, out io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("открытие файла: %w", err)
+	}
+	defer f.Close()
+
+	summary, err := importer.ImportEmployees(ctx, service.NewCSVReader(f), opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "импортировано: %d, с ошибками: %d\n", summary.Imported, summary.Failed)
+	for _, lineErr := range summary.Errors {
+		fmt.Fprintf(out, "  строка %d: %s\n", lineErr.Line, lineErr.Error)
+	}
+
+	if summary.Failed > 0 {
+		return errors.New("часть строк не импортирована")
+	}
+	return nil
+}
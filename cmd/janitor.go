@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"employer/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// softDeletePurger часть репозитория, необходимая для очистки
+type softDeletePurger interface {
+	PurgeSoftDeleted(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// newPurgedCounter счетчик окончательно удаленных очисткой сотрудников
+func newPurgedCounter() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "employer_purged_employees_total",
+		Help: "Количество сотрудников, окончательно удаленных очисткой",
+	})
+}
+
+// janitor окончательно удаляет записи, мягко удаленные раньше чем retention назад:
+// по таймеру (runJanitor) и по запросу POST /api/admin/purge
+type janitor struct {
+	purger    softDeletePurger
+	retention time.Duration
+	purged    prometheus.Counter
+	logger    *zap.Logger
+}
+
+func newJanitor(purger softDeletePurger, retention time.Duration, purged prometheus.Counter, zapLogger *zap.Logger) *janitor {
+	return &janitor{purger: purger, retention: retention, purged: purged, logger: zapLogger}
+}
+
+// Purge выполняет один проход очистки и логирует результат. Если очистку сейчас
+// выполняет другой экземпляр приложения — repository.ErrPurgeLocked
+func (j *janitor) Purge(ctx context.Context) (int64, error) {
+	olderThan := time.Now().Add(-j.retention)
+
+	purged, err := j.purger.PurgeSoftDeleted(ctx, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	j.purged.Add(float64(purged))
+
+	j.logger.Info("очистка удаленных записей выполнена",
+		zap.Int64("purged", purged),
+		zap.Time("older_than", olderThan))
+	return purged, nil
+}
+
+// runJanitor каждые interval выполняет очистку. Завершается при отмене ctx.
+func runJanitor(ctx context.Context, j *janitor, interval time.Duration) {
+	j.logger.Info("запущена очистка удаленных записей",
+		zap.Duration("retention", j.retention),
+		zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("очистка удаленных записей остановлена")
+			return
+		case <-ticker.C:
+			purgeSoftDeleted(ctx, j)
+		}
+	}
+}
+
+// purgeSoftDeleted выполняет один проход очистки по таймеру; ошибки только логируются
+func purgeSoftDeleted(ctx context.Context, j *janitor) {
+	_, err := j.Purge(ctx)
+	switch {
+	case err == nil, ctx.Err() != nil:
+	case errors.Is(err, repository.ErrPurgeLocked):
+		j.logger.Info("очистку выполняет другой экземпляр, проход пропущен")
+	default:
+		j.logger.Error("ошибка очистки удаленных записей", zap.Error(err))
+	}
+}
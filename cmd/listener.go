@@ -0,0 +1,76 @@
+package main
+
+import (
+	"employer/config"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+)
+
+// socketFileMode права на unix-сокет: доступ для владельца и группы (например nginx)
+const socketFileMode = 0o660
+
+// newListener создает слушатель сервера: unix-сокет, если задан LISTEN_SOCKET,
+// иначе TCP на HOST:PORT
+func newListener(cfg *config.Config) (net.Listener, error) {
+	if cfg.ListenSocket == "" {
+		ln, err := net.Listen("tcp", cfg.GetServerAddress())
+		if err != nil {
+			return nil, fmt.Errorf("прослушивание %s: %w", cfg.GetServerAddress(), err)
+		}
+		return ln, nil
+	}
+
+	// Файл мог остаться после аварийного завершения предыдущего процесса
+	if err := os.Remove(cfg.ListenSocket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("удаление старого сокета %s: %w", cfg.ListenSocket, err)
+	}
+
+	ln, err := net.Listen("unix", cfg.ListenSocket)
+	if err != nil {
+		return nil, fmt.Errorf("прослушивание сокета %s: %w", cfg.ListenSocket, err)
+	}
+	if err := os.Chmod(cfg.ListenSocket, socketFileMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("установка прав на сокет %s: %w", cfg.ListenSocket, err)
+	}
+	return ln, nil
+}
+
+// openListeners возвращает слушатель сервера и, если он нужен, слушатель перенаправлений
+// HTTP → HTTPS. Переданные прежним процессом слушатели (см. inheritListeners) используются
+// в том же порядке, недостающие создаются, лишние закрываются
+func openListeners(cfg *config.Config, inherited []net.Listener, withRedirect bool) (server, redirect net.Listener, err error) {
+	if len(inherited) > 0 {
+		server, inherited = inherited[0], inherited[1:]
+	} else if server, err = newListener(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case withRedirect && len(inherited) > 0:
+		redirect, inherited = inherited[0], inherited[1:]
+	case withRedirect:
+		if redirect, err = net.Listen("tcp", cfg.GetRedirectAddress()); err != nil {
+			server.Close()
+			return nil, nil, fmt.Errorf("прослушивание %s: %w", cfg.GetRedirectAddress(), err)
+		}
+	}
+	for _, ln := range inherited {
+		ln.Close()
+	}
+	return server, redirect, nil
+}
+
+// removeSocket удаляет файл unix-сокета после остановки сервера
+func removeSocket(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -1,275 +1,241 @@
 package main
 
 import (
-	"context"
 	"employer/config"
-	"employer/internal/handler"
 	"employer/internal/repository"
 	"employer/internal/service"
+	"employer/traits/build"
 	"employer/traits/database"
 	"employer/traits/logger"
-	"net/http"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
 	"strings"
 	"syscall"
-	"time"
 
-	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
-func main() {
-	// Инициализация логгера
-	zapLogger, err := logger.NewLogger()
-	if err != nil {
-		panic(err)
-	}
-	defer zapLogger.Sync()
+// Коды завершения процесса
+const (
+	exitOK = 0
+	// exitConfig некорректная конфигурация или аргументы командной строки
+	exitConfig = 1
+	// exitDatabase БД недоступна или не удалось создать таблицы
+	exitDatabase = 2
+	// exitServer ошибка работы сервера или выполнения команды
+	exitServer = 3
+)
 
-	// Загрузка конфигурации
-	cfg, err := config.NewConfig()
-	if err != nil {
-		zapLogger.Error("ошибка инициализации конфига", zap.Error(err))
-		return
-	}
+const usage = `Использование: employer <команда> [флаги]
 
-	// Валидация конфигурации
-	if err := cfg.ValidateConfig(); err != nil {
-		zapLogger.Error("некорректная конфигурация", zap.Error(err))
-		return
-	}
+Общие флаги команд:
+  -config <файл>        файл конфигурации YAML/JSON (или переменная CONFIG_FILE)
 
-	zapLogger.Info("запуск приложения Emplyee",
-		zap.String("environment", cfg.Environment),
-		zap.String("port", cfg.Port),
-		zap.String("db_name", cfg.DBName),
-	)
+Команды:
+  serve                 запуск HTTP сервера (по умолчанию)
+  migrate               создание таблиц и индексов
+  import --file <csv>   импорт сотрудников из CSV
+  stats                 вывод статистики по сотрудникам
+  seed [--count N] [--force]
+                        загрузка тестовых сотрудников для разработки
+  version               версия, коммит и дата сборки (или флаг --version)
 
-	// Инициализация базы данных
-	db, err := database.InitDatabase(cfg, zapLogger)
-	if err != nil {
-		zapLogger.Error("ошибка инициализации БД", zap.Error(err))
-		return
-	}
-	defer db.Close()
+Коды завершения: 0 — успех, 1 — ошибка конфигурации, 2 — ошибка БД, 3 — ошибка выполнения
+`
 
-	// Создание таблиц БД
-	if err := database.CreateTables(db, zapLogger); err != nil {
-		zapLogger.Error("ошибка создания таблиц", zap.Error(err))
+// initDatabase подключается к БД; переменная, чтобы тесты могли подменить подключение
+var initDatabase = database.InitDatabase
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
 		return
 	}
 
-	// Инициализация репозиториев
-	repos := repository.NewRepositories(db, zapLogger)
-
-	// Инициализация сервисов
-	services := service.NewServices(repos, zapLogger)
-
-	// Создание HTTP обработчиков
-	employeeHandler := handler.NewEmployeeHandler(services.Employee, zapLogger)
-
-	// Настройка маршрутизации
-	router := mux.NewRouter()
-
-	// CORS middleware для API запросов
-	corsMiddleware := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Применяем CORS только к API запросам
-			if strings.HasPrefix(r.URL.Path, "/api/") {
-				w.Header().Set("Access-Control-Allow-Origin", "*")
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-				if r.Method == "OPTIONS" {
-					w.WriteHeader(http.StatusOK)
-					return
-				}
-			}
+	fmt.Fprintf(os.Stderr, "employer: %v\n", err)
+	os.Exit(exitCode(err))
+}
 
-			next.ServeHTTP(w, r)
-		})
+// run создает логгер и выполняет команду из args. Ошибка несет код завершения (см. exitCode).
+func run(args []string, stdout, stderr io.Writer) error {
+	// Инициализация логгера
+	zapLogger, err := logger.NewLogger()
+	if err != nil {
+		return configError(fmt.Errorf("настройка логгера: %w", err))
 	}
+	defer syncLogger(zapLogger, stderr)
 
-	// Middleware для логирования запросов
-	loggingMiddleware := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			next.ServeHTTP(w, r)
+	return runCommand(args, zapLogger, stdout, stderr)
+}
 
-			// Логируем только важные запросы (не статические файлы)
-			if !strings.HasPrefix(r.URL.Path, "/static/") && r.URL.Path != "/health" {
-				zapLogger.Info("HTTP request",
-					zap.String("method", r.Method),
-					zap.String("url", r.URL.Path),
-					zap.String("remote_addr", getClientIP(r)),
-					zap.Duration("duration", time.Since(start)),
-				)
-			}
-		})
+// runCommand выбирает подкоманду по первому аргументу и выполняет ее
+func runCommand(args []string, zapLogger *zap.Logger, stdout, stderr io.Writer) error {
+	name := "serve"
+	switch {
+	case len(args) > 0 && (args[0] == "--version" || args[0] == "-version"):
+		name, args = "version", args[1:]
+	case len(args) > 0 && !strings.HasPrefix(args[0], "-"):
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "serve":
+		return serveCommand(args, zapLogger, stderr)
+	case "migrate":
+		return migrateCommand(args, zapLogger, stderr)
+	case "import":
+		return importCommand(args, zapLogger, stdout, stderr)
+	case "stats":
+		return statsCommand(args, zapLogger, stdout, stderr)
+	case "seed":
+		return seedCommand(args, zapLogger, stdout, stderr)
+	case "version":
+		info := build.Get()
+		fmt.Fprintf(stdout, "employer %s (commit %s, built %s, %s)\n",
+			info.Version, info.Commit, info.BuildDate, info.GoVersion)
+		return nil
+	case "help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return configError(fmt.Errorf("неизвестная команда %q", name))
 	}
+}
 
-	// Применение middleware
-	router.Use(corsMiddleware)
-	router.Use(loggingMiddleware)
-
-	// Регистрация маршрутов для API сотрудников
-	employeeHandler.RegisterRoutes(router)
+// exitError ошибка с кодом завершения процесса
+type exitError struct {
+	code int
+	err  error
+}
 
-	// Статические файлы (CSS, JS, изображения)
-	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
 
-	// Функция для обслуживания HTML страницы
-	serveEmployeePage := func(w http.ResponseWriter, r *http.Request) {
-		htmlPath := "./static/employee.html"
+func (e *exitError) Unwrap() error {
+	return e.err
+}
 
-		// Устанавливаем заголовки
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		w.Header().Set("Pragma", "no-cache")
-		w.Header().Set("Expires", "0")
+// configError помечает ошибку конфигурации или аргументов
+func configError(err error) error {
+	return &exitError{code: exitConfig, err: err}
+}
 
-		// Обслуживаем файл
-		http.ServeFile(w, r, htmlPath)
+// databaseError помечает ошибку подключения к БД или миграции
+func databaseError(err error) error {
+	return &exitError{code: exitDatabase, err: err}
+}
 
-		zapLogger.Info("employee page served",
-			zap.String("remote_addr", getClientIP(r)),
-			zap.String("path", r.URL.Path),
-		)
+// exitCode возвращает код завершения для ошибки run; неклассифицированные
+// ошибки считаются ошибками выполнения
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
 	}
-
-	// Маршруты для веб-интерфейса
-	router.HandleFunc("/", serveEmployeePage).Methods("GET")
-	router.HandleFunc("/employees", serveEmployeePage).Methods("GET")
-	router.HandleFunc("/employee", serveEmployeePage).Methods("GET")
-
-	// Health check endpoint
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"OK","service":"Employee Management"}`))
-	}).Methods("GET")
-
-	// Debug endpoint для проверки маршрутов
-	router.HandleFunc("/debug/routes", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		routes := []string{
-			"GET /",
-			"GET /employees",
-			"GET /employee",
-			"GET /health",
-			"GET /static/{file}",
-			"GET /api/employees",
-			"POST /api/employees",
-			"GET /api/employees/{id}",
-			"PUT /api/employees/{id}",
-			"DELETE /api/employees/{id}",
-		}
-
-		response := `{"available_routes":["` + strings.Join(routes, `","`) + `"]}`
-		w.Write([]byte(response))
-	}).Methods("GET")
-
-	// Создание HTTP сервера
-	srv := &http.Server{
-		Handler:      router,
-		Addr:         cfg.GetServerAddress(),
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
 	}
+	return exitServer
+}
 
-	// Настройка graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Канал для получения сигналов ОС
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
-
-	// Проверяем существование статических файлов при запуске
-	checkStaticFiles(zapLogger)
-
-	// Запуск сервера в отдельной горутине
-	go func() {
-		zapLogger.Info("🚀 Web App HTTP server started",
-			zap.String("local_address", cfg.GetServerAddress()),
-			zap.String("environment", cfg.Environment),
-		)
-		zapLogger.Info("📱 Employee Management Web Interface: https://meily.kz")
-		zapLogger.Info("🔧 API Endpoints: https://meily.kz/api/employees")
-		zapLogger.Info("🏥 Health Check: https://meily.kz/health")
-		zapLogger.Info("🐛 Debug Routes: https://meily.kz/debug/routes")
-		zapLogger.Info("📁 Static Files: https://meily.kz/static/")
+// syncLogger сбрасывает буферы логгера. Sync для stdout/stderr на Linux
+// возвращает EINVAL или ENOTTY, это не ошибка записи логов и не выводится.
+func syncLogger(zapLogger *zap.Logger, stderr io.Writer) {
+	if err := zapLogger.Sync(); err != nil && !isBenignSyncError(err) {
+		fmt.Fprintf(stderr, "employer: сброс логов: %v\n", err)
+	}
+}
 
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			zapLogger.Error("failed to start HTTP server", zap.Error(err))
-			cancel()
+// isBenignSyncError проверяет, что все ошибки Sync относятся к консольным дескрипторам
+func isBenignSyncError(err error) bool {
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range multi.Unwrap() {
+			if !isBenignSyncError(e) {
+				return false
+			}
 		}
-	}()
-
-	// Ожидание сигнала завершения
-	select {
-	case <-stop:
-		zapLogger.Info("🛑 shutdown signal received")
-	case <-ctx.Done():
-		zapLogger.Info("🛑 context cancelled")
+		return true
 	}
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
 
-	// Graceful shutdown сервера
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
-
-	zapLogger.Info("🔄 shutting down server...")
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		zapLogger.Error("❌ failed to shutdown server", zap.Error(err))
+// parseFlags разбирает флаги команды; ошибка разбора считается ошибкой аргументов
+func parseFlags(fs *flag.FlagSet, args []string, stderr io.Writer) error {
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return configError(err)
 	}
+	return nil
+}
 
-	zapLogger.Info("✅ Application stopped successfully")
+// configFlag добавляет в набор флагов путь к файлу конфигурации (по умолчанию CONFIG_FILE)
+func configFlag(fs *flag.FlagSet) *string {
+	return fs.String("config", os.Getenv("CONFIG_FILE"), "путь к файлу конфигурации YAML/JSON")
 }
 
-// checkStaticFiles проверяет существование необходимых статических файлов
-func checkStaticFiles(logger *zap.Logger) {
-	staticDir := "./static"
-	employeeHTML := "./static/employee.html"
+// setupCommand загружает конфигурацию и создает по ней логгер команды (уровень,
+// файл логов). При ошибке возвращает стартовый логгер и ошибку конфигурации.
+func setupCommand(path string, bootstrap *zap.Logger) (*config.Config, *zap.Logger, error) {
+	cfg, zapLogger, _, err := setupCommandWithLevel(path, bootstrap)
+	return cfg, zapLogger, err
+}
 
-	// Проверяем папку static
-	if _, err := os.Stat(employeeHTML); os.IsNotExist(err) {
-		logger.Warn("static directory does not exist, creating it", zap.String("path", staticDir))
-		if err := os.MkdirAll(staticDir, 0755); err != nil {
-			logger.Error("failed to create static directory", zap.Error(err))
-		}
+// setupCommandWithLevel как setupCommand, но возвращает и уровень логгера, чтобы менять
+// его без перезапуска
+func setupCommandWithLevel(path string, bootstrap *zap.Logger) (*config.Config, *zap.Logger, zap.AtomicLevel, error) {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, bootstrap, zap.AtomicLevel{}, configError(fmt.Errorf("некорректная конфигурация: %w", err))
 	}
 
-	// Проверяем employee.html
-	if _, err := os.Stat(employeeHTML); os.IsNotExist(err) {
-		logger.Warn("employee.html not found",
-			zap.String("expected_path", employeeHTML),
-			zap.String("solution", "Please create static/employee.html file"),
-		)
-	} else {
-		logger.Info("✅ employee.html found", zap.String("path", employeeHTML))
+	zapLogger, level, err := logger.NewLoggerWithLevel(cfg)
+	if err != nil {
+		return nil, bootstrap, zap.AtomicLevel{}, configError(fmt.Errorf("настройка логгера: %w", err))
 	}
+	return cfg, zapLogger, level, nil
 }
 
-// getClientIP получает реальный IP клиента с учетом прокси
-func getClientIP(r *http.Request) string {
-	// Проверяем заголовки прокси в порядке приоритета
-	if xForwardedFor := r.Header.Get("X-Forwarded-For"); xForwardedFor != "" {
-		// Берем первый IP из списка (клиентский)
-		ips := strings.Split(xForwardedFor, ",")
-		return strings.TrimSpace(ips[0])
+// loadConfig загружает и валидирует конфигурацию
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, err
 	}
-
-	if xRealIP := r.Header.Get("X-Real-IP"); xRealIP != "" {
-		return strings.TrimSpace(xRealIP)
+	if err := cfg.ValidateConfig(); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	if xClientIP := r.Header.Get("X-Client-IP"); xClientIP != "" {
-		return strings.TrimSpace(xClientIP)
-	}
+// sortCollation collation PostgreSQL для SORT_LOCALE из конфигурации
+func sortCollation(cfg *config.Config) (string, error) {
+	return repository.SortCollation(cfg.SortLocale)
+}
 
-	// Возвращаем RemoteAddr как последний вариант
-	return r.RemoteAddr
+// serviceOptions настройки сервисов из конфигурации
+func serviceOptions(cfg *config.Config) (service.Options, error) {
+	phoneValidator, err := service.NewPhoneValidator(cfg.PhoneRegion)
+	if err != nil {
+		return service.Options{}, err
+	}
+	return service.Options{
+		NormalizeCity:    cfg.NormalizeCity,
+		PhoneValidator:   phoneValidator,
+		StatsCacheTTL:    cfg.GetStatsCacheTTL(),
+		SearchCacheTTL:   cfg.GetSearchCacheTTL(),
+		IdempotencyTTL:   cfg.GetIdempotencyTTL(),
+		PageDefaultLimit: cfg.PageDefaultLimit,
+		PageMaxLimit:     cfg.PageMaxLimit,
+		SearchMaxResults: cfg.SearchMaxResults,
+		MaxEmployees:     cfg.MaxEmployees,
+		ListMaxRows:      cfg.ListMaxRows,
+	}, nil
 }
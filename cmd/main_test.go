@@ -0,0 +1,722 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"flag"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"employer/config"
+	"employer/internal/domain"
+	"employer/internal/handler"
+	"employer/internal/repository"
+	"employer/internal/service"
+	"employer/traits/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeImporter struct {
+	summary *domain.ImportSummary
+	err     error
+	names   []string
+}
+
+func (f *fakeImporter) ImportEmployees(ctx context.Context, reader service.EmployeeReader, opts service.ImportOptions) (*domain.ImportSummary, error) {
+	for {
+		_, e, err := reader.Next()
+		if err != nil {
+			break
+		}
+		f.names = append(f.names, e.Name)
+	}
+	return f.summary, f.err
+}
+
+type fakeStats struct {
+	stats *repository.EmployeeStats
+	err   error
+}
+
+func (f *fakeStats) GetEmployeeStats(ctx context.Context) (*repository.EmployeeStats, error) {
+	return f.stats, f.err
+}
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	return path
+}
+
+func TestRunCommand_Unknown(t *testing.T) {
+	var stderr bytes.Buffer
+	err := runCommand([]string{"bogus"}, zap.NewNop(), &bytes.Buffer{}, &stderr)
+	if code := exitCode(err); code != exitConfig {
+		t.Fatalf("expected exit code %d, got %d", exitConfig, code)
+	}
+	if !strings.Contains(err.Error(), "bogus") || !strings.Contains(stderr.String(), "Использование") {
+		t.Fatalf("expected usage error mentioning command, got %v / %q", err, stderr.String())
+	}
+}
+
+func TestRunCommand_Version(t *testing.T) {
+	for _, args := range [][]string{{"version"}, {"--version"}} {
+		var stdout bytes.Buffer
+		if err := runCommand(args, zap.NewNop(), &stdout, &bytes.Buffer{}); err != nil {
+			t.Fatalf("%v: unexpected error %v", args, err)
+		}
+		if !strings.HasPrefix(stdout.String(), "employer dev (commit unknown") {
+			t.Fatalf("%v: unexpected output %q", args, stdout.String())
+		}
+	}
+}
+
+func TestImportCommand_MissingFile(t *testing.T) {
+	err := runCommand([]string{"import"}, zap.NewNop(), &bytes.Buffer{}, &bytes.Buffer{})
+	if code := exitCode(err); code != exitConfig {
+		t.Fatalf("expected exit code %d without --file, got %d", exitConfig, code)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	path := writeTempFile(t, "config.yaml", "port: http\n")
+
+	err := run([]string{"migrate", "-config", path}, &bytes.Buffer{}, &bytes.Buffer{})
+	if code := exitCode(err); code != exitConfig {
+		t.Fatalf("expected exit code %d, got %d (%v)", exitConfig, code, err)
+	}
+}
+
+func TestRun_UnreachableDatabase(t *testing.T) {
+	original := initDatabase
+	defer func() { initDatabase = original }()
+	initDatabase = func(cfg database.Config, logger *zap.Logger) (*sql.DB, error) {
+		return nil, errors.New("dial tcp 127.0.0.1:5432: connection refused")
+	}
+
+	path := writeTempFile(t, "config.yaml", "db_password: secret\n")
+	for _, command := range []string{"serve", "migrate", "stats"} {
+		err := run([]string{command, "-config", path}, &bytes.Buffer{}, &bytes.Buffer{})
+		if code := exitCode(err); code != exitDatabase {
+			t.Fatalf("%s: expected exit code %d, got %d (%v)", command, exitDatabase, code, err)
+		}
+		if !strings.Contains(err.Error(), "connection refused") {
+			t.Fatalf("%s: expected DB error in message, got %v", command, err)
+		}
+	}
+}
+
+func TestRun_Help(t *testing.T) {
+	if err := run([]string{"migrate", "-h"}, &bytes.Buffer{}, &bytes.Buffer{}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+}
+
+func TestIsBenignSyncError(t *testing.T) {
+	stderrErr := &os.PathError{Op: "sync", Path: "/dev/stderr", Err: syscall.EINVAL}
+	if !isBenignSyncError(stderrErr) {
+		t.Fatalf("expected EINVAL on stderr to be benign")
+	}
+	if !isBenignSyncError(errors.Join(stderrErr, &os.PathError{Op: "sync", Path: "/dev/stdout", Err: syscall.ENOTTY})) {
+		t.Fatalf("expected joined console errors to be benign")
+	}
+	if isBenignSyncError(errors.Join(stderrErr, &os.PathError{Op: "sync", Path: "app.log", Err: syscall.EIO})) {
+		t.Fatalf("expected EIO on a log file to be reported")
+	}
+}
+
+func TestRunMigrate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS employees")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS employees_history")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_history_employee").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS idempotency_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS employee_merges").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employee_merges_target").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS employee_attendance").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employee_attendance_date").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS deleted_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ALTER TABLE employees ADD COLUMN IF NOT EXISTS status").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ALTER TABLE employees_history ADD COLUMN IF NOT EXISTS status").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS created_by").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS updated_by").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS version").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS birth_date").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS hire_date").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ADD COLUMN IF NOT EXISTS phone_digits").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE employees ALTER COLUMN id TYPE BIGINT")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_phone").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_city").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_name").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_deleted_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_phone_live ON employees\\(phone\\) WHERE deleted_at IS NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_phone_key").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_status").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_employees_created_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE EXTENSION IF NOT EXISTS pg_trgm").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("idx_employees_name_trgm").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("idx_employees_phone_trgm").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("idx_employees_phone_digits_trgm").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("idx_employees_city_trgm").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE OR REPLACE FUNCTION notify_employee_change").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DROP TRIGGER IF EXISTS employees_notify").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TRIGGER employees_notify").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS idx_employees_name_sort_ru_x_icu ON employees ((lower(name) COLLATE "ru-x-icu"))`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := runMigrate(db, zap.NewNop(), "ru-x-icu", ""); err != nil {
+		t.Fatalf("runMigrate: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRunImport_Success(t *testing.T) {
+	path := writeTempFile(t, "staff.csv", "name,phone,city\nAlice,+7701,Almaty\nBob,+7702,Astana\n")
+	importer := &fakeImporter{summary: &domain.ImportSummary{Imported: 2}}
+
+	var out bytes.Buffer
+	if err := runImport(context.Background(), importer, path, service.ImportOptions{}, &out); err != nil {
+		t.Fatalf("runImport: %v", err)
+	}
+	if strings.Join(importer.names, ",") != "Alice,Bob" {
+		t.Fatalf("unexpected imported names: %v", importer.names)
+	}
+	if !strings.Contains(out.String(), "импортировано: 2") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunImport_FailedLines(t *testing.T) {
+	path := writeTempFile(t, "staff.csv", "name,phone,city\n,+7701,Almaty\n")
+	importer := &fakeImporter{summary: &domain.ImportSummary{
+		Failed: 1,
+		Errors: []domain.ImportLineError{{Line: 2, Error: "имя обязательно"}},
+	}}
+
+	var out bytes.Buffer
+	if err := runImport(context.Background(), importer, path, service.ImportOptions{}, &out); err == nil {
+		t.Fatalf("expected error when lines fail")
+	}
+	if !strings.Contains(out.String(), "строка 2: имя обязательно") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunImport_MissingFile(t *testing.T) {
+	err := runImport(context.Background(), &fakeImporter{}, filepath.Join(t.TempDir(), "none.csv"), service.ImportOptions{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	provider := &fakeStats{stats: &repository.EmployeeStats{TotalCount: 12, CitiesCount: 3, MostCommonCity: "Almaty"}}
+
+	var out bytes.Buffer
+	if err := runStats(context.Background(), provider, &out); err != nil {
+		t.Fatalf("runStats: %v", err)
+	}
+	for _, want := range []string{"Всего сотрудников", "12", "Almaty"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunStats_Error(t *testing.T) {
+	if err := runStats(context.Background(), &fakeStats{err: errors.New("db down")}, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+// fakeSeedTarget сервис и репозиторий для runSeed: Truncate очищает счетчик сотрудников
+type fakeSeedTarget struct {
+	count     int64
+	created   int
+	truncated bool
+}
+
+func (f *fakeSeedTarget) CountEmployees(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	return f.count, nil
+}
+
+func (f *fakeSeedTarget) CreateEmployee(ctx context.Context, employee *domain.Employee) error {
+	f.created++
+	return nil
+}
+
+func (f *fakeSeedTarget) Truncate(ctx context.Context) error {
+	f.truncated = true
+	f.count = 0
+	return nil
+}
+
+func TestRunSeed(t *testing.T) {
+	target := &fakeSeedTarget{count: 5}
+
+	var out bytes.Buffer
+	if err := runSeed(context.Background(), target, target, 20, false, &out); err != nil {
+		t.Fatalf("runSeed: %v", err)
+	}
+	if target.created != 0 || !strings.Contains(out.String(), "пропущена") {
+		t.Fatalf("expected skip for non-empty table, created %d, output %q", target.created, out.String())
+	}
+
+	out.Reset()
+	if err := runSeed(context.Background(), target, target, 20, true, &out); err != nil {
+		t.Fatalf("runSeed --force: %v", err)
+	}
+	if !target.truncated || target.created != 20 || !strings.Contains(out.String(), "20") {
+		t.Fatalf("expected truncate and 20 created, got %+v, output %q", target, out.String())
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		port string
+		url  string
+		want string
+	}{
+		{"443", "http://example.com:8080/api/employees?q=1", "https://example.com/api/employees?q=1"},
+		{"8443", "http://example.com/health", "https://example.com:8443/health"},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		redirectToHTTPS(tt.port).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		if rr.Code != http.StatusMovedPermanently {
+			t.Fatalf("expected %d, got %d", http.StatusMovedPermanently, rr.Code)
+		}
+		if got := rr.Header().Get("Location"); got != tt.want {
+			t.Fatalf("expected Location %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestConfigureTLS_MissingCertIsStartupError(t *testing.T) {
+	cfg := &config.Config{
+		Port:        "8443",
+		TLSCertFile: filepath.Join(t.TempDir(), "missing.crt"),
+		TLSKeyFile:  filepath.Join(t.TempDir(), "missing.key"),
+	}
+	if _, err := configureTLS(cfg, &http.Server{}); err == nil {
+		t.Fatalf("expected error for missing certificate")
+	}
+}
+
+func TestNewTLSConfig_MinVersion(t *testing.T) {
+	if v := newTLSConfig().MinVersion; v != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2 minimum, got %x", v)
+	}
+}
+
+func TestNewListener_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "employer.sock")
+	// Старый файл сокета должен быть удален перед прослушиванием
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatalf("write stale socket: %v", err)
+	}
+
+	ln, err := newListener(&config.Config{ListenSocket: socket})
+	if err != nil {
+		t.Fatalf("newListener: %v", err)
+	}
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != socketFileMode {
+		t.Fatalf("expected mode %o, got %o", socketFileMode, perm)
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})}
+	go srv.Serve(ln)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://unix/health")
+	if err != nil {
+		t.Fatalf("request over socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := removeSocket(socket); err != nil {
+		t.Fatalf("removeSocket: %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Fatalf("expected socket file to be removed, got %v", err)
+	}
+}
+
+type fakePurger struct {
+	calls  chan time.Time
+	purged int64
+	err    error
+}
+
+func (f *fakePurger) PurgeSoftDeleted(ctx context.Context, olderThan time.Time) (int64, error) {
+	f.calls <- olderThan
+	return f.purged, f.err
+}
+
+func TestRunJanitor_PurgesOnTickAndStops(t *testing.T) {
+	purger := &fakePurger{calls: make(chan time.Time, 10), purged: 2}
+	purged := newPurgedCounter()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		runJanitor(ctx, newJanitor(purger, 90*24*time.Hour, purged, zap.NewNop()), 10*time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case olderThan := <-purger.calls:
+		if age := time.Since(olderThan); age < 89*24*time.Hour || age > 91*24*time.Hour {
+			t.Fatalf("unexpected cutoff age %v", age)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("janitor did not run")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("janitor did not stop after cancel")
+	}
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatalf("read counter: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestJanitorPurge_CountsAndSkipsWhenLocked(t *testing.T) {
+	purger := &fakePurger{calls: make(chan time.Time, 10), purged: 3}
+	purged := newPurgedCounter()
+	j := newJanitor(purger, time.Hour, purged, zap.NewNop())
+
+	if n, err := j.Purge(context.Background()); err != nil || n != 3 {
+		t.Fatalf("expected 3 purged, got %d, %v", n, err)
+	}
+	if got := counterValue(t, purged); got != 3 {
+		t.Fatalf("expected counter 3, got %v", got)
+	}
+
+	// блокировку держит другой экземпляр: проход пропускается без ошибки в логе
+	purger.err = repository.ErrPurgeLocked
+	core, logs := observer.New(zap.InfoLevel)
+	j.logger = zap.New(core)
+	purgeSoftDeleted(context.Background(), j)
+	if logs.FilterLevelExact(zap.ErrorLevel).Len() != 0 || logs.FilterMessageSnippet("другой экземпляр").Len() != 1 {
+		t.Fatalf("expected skipped pass, got logs %v", logs.All())
+	}
+	if got := counterValue(t, purged); got != 3 {
+		t.Fatalf("counter must not change on a skipped pass, got %v", got)
+	}
+}
+
+func TestAccessLogMiddleware_LogsStatusAndBytes(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/employees", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	})
+	srv := httptest.NewServer(accessLogMiddleware(zap.New(core), "")(router))
+	defer srv.Close()
+
+	for _, path := range []string{"/api/employees", "/api/missing", "/health"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	entries := logs.FilterMessage("HTTP request").All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 access log entries (probe skipped), got %d", len(entries))
+	}
+
+	ok := entries[0].ContextMap()
+	if ok["status"] != int64(http.StatusOK) || ok["bytes"] != int64(2) {
+		t.Fatalf("unexpected fields for 200: %v", ok)
+	}
+
+	notFound := entries[1].ContextMap()
+	if notFound["url"] != "/api/missing" || notFound["status"] != int64(http.StatusNotFound) {
+		t.Fatalf("unexpected fields for 404: %v", notFound)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"flat", `{"name":"Alice","phone":"+77010000000"}`, `{"name":"Alice","phone":"[REDACTED]"}`},
+		{"nested object", `{"user":{"Password":"secret","auth":{"token":123}},"city":"Almaty"}`,
+			`{"city":"Almaty","user":{"Password":"[REDACTED]","auth":{"token":"[REDACTED]"}}}`},
+		{"array", `[{"phone":"1","id":1},{"phones":["2"],"id":2}]`, `[{"id":1,"phone":"[REDACTED]"},{"id":2,"phones":["2"]}]`},
+		{"whole subtree", `{"token":{"access":"a","refresh":"b"}}`, `{"token":"[REDACTED]"}`},
+		{"scalar", `12345678901234567890`, `12345678901234567890`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redactJSON([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("redactJSON: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	for _, invalid := range []string{`{"phone":`, `{"a":1} {"b":2}`, ``} {
+		if _, err := redactJSON([]byte(invalid)); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestBodyLogMiddleware(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/employees", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// обработчик, который читает тело второй раз
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		again, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(again)
+	})
+	router.HandleFunc("/api/employees/import", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "text/csv")
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte("1,Alice,+77010000000\n"))
+			http.NewResponseController(w).Flush()
+		}
+	})
+	srv := httptest.NewServer(bodyLogMiddleware(zap.New(core), "")(router))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/employees", "application/json",
+		strings.NewReader(`{"name":"Alice","phone":"+77010000000"}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	echoed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(echoed), "+77010000000") {
+		t.Fatalf("handler must get the original body, got %s", echoed)
+	}
+
+	resp, err = http.Post(srv.URL+"/api/employees/import", "multipart/form-data; boundary=x", strings.NewReader("--x--"))
+	if err != nil {
+		t.Fatalf("POST import: %v", err)
+	}
+	streamed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Count(string(streamed), "\n") != 3 {
+		t.Fatalf("unexpected streamed response: %q", streamed)
+	}
+
+	entries := logs.FilterMessage("HTTP body").All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 body log entries, got %d", len(entries))
+	}
+
+	jsonEntry := entries[0].ContextMap()
+	wantJSON := `{"name":"Alice","phone":"[REDACTED]"}`
+	if jsonEntry["request_body"] != wantJSON || jsonEntry["response_body"] != wantJSON {
+		t.Fatalf("unexpected JSON bodies: %v", jsonEntry)
+	}
+
+	binary := entries[1].ContextMap()
+	if binary["request_body"] != "<multipart/form-data; boundary=x, 5 bytes>" || binary["response_body"] != "<text/csv, 63 bytes>" {
+		t.Fatalf("expected summaries, got %v", binary)
+	}
+}
+
+func TestCORSMiddleware_AllowedOrigins(t *testing.T) {
+	runtime := &config.RuntimeConfig{CORSAllowedOrigins: []string{"*"}}
+	router := mux.NewRouter()
+	router.Use(corsMiddleware("", func() *config.RuntimeConfig { return runtime }))
+	router.HandleFunc("/api/employees", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(origin string) http.Header {
+		req := httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+		req.Header.Set("Origin", origin)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Header()
+	}
+
+	if got := request("https://any.example.com").Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("expected *, got %q", got)
+	}
+
+	// список источников читается на каждом запросе
+	runtime = &config.RuntimeConfig{CORSAllowedOrigins: []string{"https://hr.example.com"}}
+	header := request("https://hr.example.com")
+	if header.Get("Access-Control-Allow-Origin") != "https://hr.example.com" || header.Get("Vary") != "Origin" {
+		t.Fatalf("expected allowed origin echoed with Vary, got %v", header)
+	}
+	if got := request("https://evil.example.com").Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no CORS header for unknown origin, got %q", got)
+	}
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	runtime := &config.RuntimeConfig{CORSAllowedOrigins: []string{"*"}}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/employees/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET", "PUT")
+	autoMethods, err := handler.AutoMethods(router)
+	if err != nil {
+		t.Fatalf("AutoMethods: %v", err)
+	}
+	cors := corsMiddleware("", func() *config.RuntimeConfig { return runtime })(autoMethods)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/employees/5", nil)
+	req.Header.Set("Origin", "https://hr.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	rr := httptest.NewRecorder()
+	cors.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+	if rr.Header().Get("Access-Control-Allow-Origin") != "*" || rr.Header().Get("Allow") != "GET, HEAD, PUT, OPTIONS" {
+		t.Fatalf("expected CORS and Allow headers, got %v", rr.Header())
+	}
+}
+
+func TestCORSMiddleware_MaxAgeAndExposeHeaders(t *testing.T) {
+	runtime := &config.RuntimeConfig{
+		CORSAllowedOrigins: []string{"https://hr.example.com"},
+		CORSMaxAge:         600,
+		CORSExposeHeaders:  []string{"X-Request-ID", "X-Total-Count"},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/employees", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+	autoMethods, err := handler.AutoMethods(router)
+	if err != nil {
+		t.Fatalf("AutoMethods: %v", err)
+	}
+	cors := corsMiddleware("", func() *config.RuntimeConfig { return runtime })(autoMethods)
+
+	request := func(method string, preflight bool) http.Header {
+		req := httptest.NewRequest(method, "/api/employees", nil)
+		req.Header.Set("Origin", "https://hr.example.com")
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		rr := httptest.NewRecorder()
+		cors.ServeHTTP(rr, req)
+		return rr.Header()
+	}
+
+	header := request(http.MethodOptions, true)
+	if header.Get("Access-Control-Max-Age") != "600" || header.Get("Access-Control-Expose-Headers") != "" {
+		t.Fatalf("preflight: expected Max-Age 600 without Expose-Headers, got %v", header)
+	}
+	header = request(http.MethodGet, false)
+	if header.Get("Access-Control-Expose-Headers") != "X-Request-ID, X-Total-Count" || header.Get("Access-Control-Max-Age") != "" {
+		t.Fatalf("actual: expected Expose-Headers without Max-Age, got %v", header)
+	}
+
+	// 0 и пустой список — заголовки не отправляются
+	runtime = &config.RuntimeConfig{CORSAllowedOrigins: []string{"*"}}
+	if header := request(http.MethodOptions, true); header.Get("Access-Control-Max-Age") != "" {
+		t.Fatalf("expected no Max-Age, got %v", header)
+	}
+	if header := request(http.MethodGet, false); header.Get("Access-Control-Expose-Headers") != "" {
+		t.Fatalf("expected no Expose-Headers, got %v", header)
+	}
+}
+
+func TestRuntimeSettings_ReloadSetsLogLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	path := writeTempFile(t, "config.yaml", "db_password: secret\nenvironment: production\n")
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	settings := newRuntimeSettings(cfg, level, zap.NewNop())
+
+	if err := os.WriteFile(path, []byte("db_password: secret\nenvironment: production\nlog_level: debug\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := settings.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if level.Level() != zap.DebugLevel {
+		t.Fatalf("expected debug level after reload, got %s", level.Level())
+	}
+
+	// некорректная конфигурация не меняет уровень
+	if err := os.WriteFile(path, []byte("db_password: secret\nlog_level: verbose\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := settings.Reload(); err == nil {
+		t.Fatalf("expected error for invalid log level")
+	}
+	if level.Level() != zap.DebugLevel || settings.Get().LogLevel != "debug" {
+		t.Fatalf("invalid reload must keep the previous settings, got %s", level.Level())
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"database/sql"
+	"employer/traits/database"
+	"flag"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// migrateCommand создает таблицы и индексы и завершается
+func migrateCommand(args []string, zapLogger *zap.Logger, stderr io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	configPath := configFlag(fs)
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+
+	cfg, zapLogger, err := setupCommand(*configPath, zapLogger)
+	if err != nil {
+		return err
+	}
+	defer syncLogger(zapLogger, stderr)
+
+	db, err := initDatabase(cfg, zapLogger)
+	if err != nil {
+		return databaseError(fmt.Errorf("инициализация БД: %w", err))
+	}
+	defer db.Close()
+
+	collation, err := sortCollation(cfg)
+	if err != nil {
+		return configError(err)
+	}
+
+	if err := runMigrate(db, zapLogger, collation, cfg.DBTablePrefix); err != nil {
+		return databaseError(fmt.Errorf("миграция: %w", err))
+	}
+	return nil
+}
+
+// runMigrate выполняет создание таблиц и индексов, включая индекс сортировки по collation;
+// имена объектов схемы начинаются с prefix (DB_TABLE_PREFIX)
+func runMigrate(db *sql.DB, zapLogger *zap.Logger, collation, prefix string) error {
+	if err := database.CreateTablesWithPrefix(db, zapLogger, prefix); err != nil {
+		return err
+	}
+	return database.CreateSortIndexWithPrefix(db, zapLogger, collation, prefix)
+}
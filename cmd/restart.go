@@ -0,0 +1,21 @@
+package main
+
+import "time"
+
+// Перезапуск без простоя (SIGHUP, только Unix): прежний процесс запускает новый экземпляр
+// бинарника с теми же аргументами и передает ему открытые слушатели как дескрипторы.
+// Новый процесс начинает принимать соединения на тех же сокетах и сообщает о готовности
+// через канал; только после этого прежний процесс перестает принимать соединения
+// и дожидается завершения начатых запросов.
+const (
+	// listenFDsEnv сколько слушателей передано новому процессу
+	listenFDsEnv = "EMPLOYER_LISTEN_FDS"
+	// readyFD дескриптор канала, в который новый процесс сообщает о готовности
+	readyFD = 3
+	// firstListenerFD дескриптор первого переданного слушателя
+	firstListenerFD = 4
+)
+
+// restartTimeout сколько ждать готовности нового процесса (миграции, подключение к БД);
+// если он не успел или завершился, прежний процесс продолжает работу
+const restartTimeout = time.Minute
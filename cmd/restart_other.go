@@ -0,0 +1,23 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// notifyRestart ничего не делает: перезапуск с передачей слушателей есть только на Unix
+func notifyRestart(ch chan<- os.Signal) {}
+
+// inheritListeners вне Unix слушатели не наследуются
+func inheritListeners() ([]net.Listener, func(), error) {
+	return nil, func() {}, nil
+}
+
+// startSuccessor вне Unix не поддерживается
+func startSuccessor(listeners []net.Listener, timeout time.Duration) error {
+	return errors.New("перезапуск с передачей слушателей поддерживается только на Unix")
+}
@@ -0,0 +1,139 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// successorCommand команда нового процесса: тот же бинарник с теми же аргументами;
+// переменная, чтобы тесты могли подменить процесс
+var successorCommand = func() (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("путь к исполняемому файлу: %w", err)
+	}
+	return exec.Command(exe, os.Args[1:]...), nil
+}
+
+// notifyRestart подписывает ch на сигнал перезапуска (SIGHUP)
+func notifyRestart(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGHUP)
+}
+
+// inheritListeners возвращает слушатели, переданные прежним процессом, и функцию, которая
+// сообщает ему о готовности. Если процесс запущен не перезапуском — nil и пустая функция
+func inheritListeners() ([]net.Listener, func(), error) {
+	raw := os.Getenv(listenFDsEnv)
+	if raw == "" {
+		return nil, func() {}, nil
+	}
+	// Дочерние процессы этого процесса дескрипторы уже не наследуют
+	os.Unsetenv(listenFDsEnv)
+
+	count, err := strconv.Atoi(raw)
+	if err != nil || count < 1 {
+		return nil, nil, fmt.Errorf("некорректное значение %s=%q", listenFDsEnv, raw)
+	}
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		file := os.NewFile(uintptr(firstListenerFD+i), "listener-"+strconv.Itoa(i))
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, nil, fmt.Errorf("унаследованный слушатель %d: %w", i, err)
+		}
+		listeners = append(listeners, ln)
+	}
+
+	ready := os.NewFile(readyFD, "ready")
+	return listeners, func() {
+		_, _ = ready.Write([]byte{1})
+		ready.Close()
+	}, nil
+}
+
+// startSuccessor запускает новый процесс, передает ему listeners и ждет его готовности
+// не дольше timeout. При ошибке новый процесс завершается, а прежний продолжает работу
+// со своими слушателями
+func startSuccessor(listeners []net.Listener, timeout time.Duration) error {
+	readyRead, readyWrite, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("канал готовности: %w", err)
+	}
+	defer readyRead.Close()
+
+	files := []*os.File{readyWrite}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, ln := range listeners {
+		fileListener, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("слушатель %s нельзя передать другому процессу", ln.Addr())
+		}
+		file, err := fileListener.File()
+		if err != nil {
+			return fmt.Errorf("дескриптор слушателя %s: %w", ln.Addr(), err)
+		}
+		files = append(files, file)
+	}
+
+	cmd, err := successorCommand()
+	if err != nil {
+		return err
+	}
+	cmd.Env = append(cmd.Environ(), listenFDsEnv+"="+strconv.Itoa(len(listeners)))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("запуск нового процесса: %w", err)
+	}
+	// Копии дескрипторов остались у нового процесса; без нашей копии записи в канал
+	// его завершение до готовности читается как EOF
+	for _, file := range files {
+		file.Close()
+	}
+	files = nil
+	go func() { _ = cmd.Wait() }()
+
+	ready := make(chan bool, 1)
+	go func() {
+		buf := make([]byte, 1)
+		n, _ := readyRead.Read(buf)
+		ready <- n == 1
+	}()
+
+	select {
+	case ok := <-ready:
+		if !ok {
+			_ = cmd.Process.Kill()
+			return errors.New("новый процесс завершился, не начав принимать соединения")
+		}
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("новый процесс не начал принимать соединения за %s", timeout)
+	}
+
+	// Сокет теперь общий: закрытие прежним процессом не должно удалять его файл
+	for _, ln := range listeners {
+		if unixListener, ok := ln.(*net.UnixListener); ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+	return nil
+}
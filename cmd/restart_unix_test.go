@@ -0,0 +1,95 @@
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// successorEnv запускает тестовый бинарник как новый процесс перезапуска (TestRestartSuccessor)
+const successorEnv = "EMPLOYER_TEST_SUCCESSOR"
+
+// TestRestartSuccessor не тест, а новый процесс для TestStartSuccessor: принимает
+// унаследованный слушатель, сообщает о готовности и отвечает на одно соединение
+func TestRestartSuccessor(t *testing.T) {
+	if os.Getenv(successorEnv) == "" {
+		t.Skip("запускается только из TestStartSuccessor")
+	}
+	listeners, notifyReady, err := inheritListeners()
+	if err != nil || len(listeners) != 1 {
+		t.Fatalf("inheritListeners: %v, %d listeners", err, len(listeners))
+	}
+	notifyReady()
+
+	conn, err := listeners[0].Accept()
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("successor\n"))
+}
+
+func useSuccessor(t *testing.T, env ...string) {
+	t.Helper()
+	prev := successorCommand
+	successorCommand = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestRestartSuccessor$")
+		cmd.Env = append(os.Environ(), env...)
+		return cmd, nil
+	}
+	t.Cleanup(func() { successorCommand = prev })
+}
+
+func TestStartSuccessor(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	useSuccessor(t, successorEnv+"=1")
+
+	if err := startSuccessor([]net.Listener{ln}, 10*time.Second); err != nil {
+		t.Fatalf("startSuccessor: %v", err)
+	}
+	// прежний процесс перестает принимать соединения, сокет остается открытым у нового
+	ln.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial after handoff: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "successor\n" {
+		t.Fatalf("expected reply from new process, got %q, %v", line, err)
+	}
+}
+
+func TestStartSuccessor_ExitsBeforeReady(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	// без successorEnv новый процесс пропускает тест и завершается, не сообщив о готовности
+	useSuccessor(t)
+
+	if err := startSuccessor([]net.Listener{ln}, 10*time.Second); err == nil {
+		t.Fatalf("expected error when new process exits before ready")
+	}
+}
+
+func TestInheritListeners_NotRestarted(t *testing.T) {
+	t.Setenv(listenFDsEnv, "")
+	listeners, notifyReady, err := inheritListeners()
+	if err != nil || listeners != nil {
+		t.Fatalf("expected no inherited listeners, got %v, %v", listeners, err)
+	}
+	notifyReady()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"employer/config"
+	"employer/traits/logger"
+
+	"go.uber.org/zap"
+)
+
+// runtimeSettings действующая конфигурация, меняющаяся без перезапуска. Уровень логов
+// применяется при перезагрузке, остальные параметры middleware читают через Get
+type runtimeSettings struct {
+	*config.Runtime
+	environment string
+	level       zap.AtomicLevel
+	logger      *zap.Logger
+}
+
+// newRuntimeSettings создает настройки с параметрами cfg; level — уровень логгера приложения
+func newRuntimeSettings(cfg *config.Config, level zap.AtomicLevel, zapLogger *zap.Logger) *runtimeSettings {
+	return &runtimeSettings{
+		Runtime:     config.NewRuntime(cfg),
+		environment: cfg.Environment,
+		level:       level,
+		logger:      zapLogger,
+	}
+}
+
+// Reload перечитывает конфигурацию и применяет уровень логов. Окружение берется из
+// конфигурации запуска: его смена требует перезапуска
+func (s *runtimeSettings) Reload() (*config.RuntimeConfig, error) {
+	runtime, err := s.Runtime.Reload()
+	if err != nil {
+		s.logger.Warn("конфигурация не перезагружена", zap.Error(err))
+		return nil, err
+	}
+
+	// Уровень проверен в ValidateConfig
+	level, _ := logger.EffectiveLevel(s.environment, runtime.LogLevel)
+	s.level.SetLevel(level)
+
+	s.logger.Info("конфигурация перезагружена",
+		zap.String("log_level", level.String()),
+		zap.Bool("log_http_bodies", runtime.LogHTTPBodies),
+		zap.Strings("cors_allowed_origins", runtime.CORSAllowedOrigins),
+		zap.Int("cors_max_age", runtime.CORSMaxAge),
+		zap.Strings("cors_expose_headers", runtime.CORSExposeHeaders),
+	)
+	return runtime, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"employer/internal/repository"
+	"employer/internal/seed"
+	"employer/internal/service"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// truncater часть репозитория, необходимая команде seed с --force
+type truncater interface {
+	Truncate(ctx context.Context) error
+}
+
+// seedCommand создает таблицы и загружает тестовых сотрудников для разработки
+func seedCommand(args []string, zapLogger *zap.Logger, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	configPath := configFlag(fs)
+	count := fs.Int("count", seed.DefaultCount, "количество сотрудников")
+	force := fs.Bool("force", false, "удалить всех сотрудников перед загрузкой")
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+	if *count < 1 {
+		fs.Usage()
+		return configError(errors.New("флаг --count должен быть положительным"))
+	}
+
+	cfg, zapLogger, err := setupCommand(*configPath, zapLogger)
+	if err != nil {
+		return err
+	}
+	defer syncLogger(zapLogger, stderr)
+
+	svcOpts, err := serviceOptions(cfg)
+	if err != nil {
+		return configError(err)
+	}
+	collation, err := sortCollation(cfg)
+	if err != nil {
+		return configError(err)
+	}
+
+	db, err := initDatabase(cfg, zapLogger)
+	if err != nil {
+		return databaseError(fmt.Errorf("инициализация БД: %w", err))
+	}
+	defer db.Close()
+
+	if err := runMigrate(db, zapLogger, collation, cfg.DBTablePrefix); err != nil {
+		return databaseError(fmt.Errorf("создание таблиц: %w", err))
+	}
+
+	repos := repository.NewRepositoriesWithOptions(db, zapLogger, repository.Options{
+		SortCollation: collation,
+		TablePrefix:   cfg.DBTablePrefix,
+	})
+	defer repos.Close()
+	services := service.NewServices(repos, zapLogger, svcOpts)
+
+	if err := runSeed(context.Background(), services.Employee, repos.Employee, *count, *force, stdout); err != nil {
+		return fmt.Errorf("загрузка тестовых данных: %w", err)
+	}
+	return nil
+}
+
+// runSeed загружает n тестовых сотрудников и печатает итог. С force таблицы сначала
+// очищаются, иначе непустая таблица остается как есть
+func runSeed(ctx context.Context, svc seed.Service, repo truncater, n int, force bool, out io.Writer) error {
+	if force {
+		if err := repo.Truncate(ctx); err != nil {
+			return err
+		}
+	}
+
+	loaded, err := seed.Load(ctx, svc, n)
+	if err != nil {
+		return err
+	}
+	if loaded == 0 {
+		fmt.Fprintln(out, "сотрудники уже есть, загрузка пропущена (--force очистит таблицу)")
+		return nil
+	}
+	fmt.Fprintf(out, "загружено сотрудников: %d\n", loaded)
+	return nil
+}
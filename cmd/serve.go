@@ -0,0 +1,528 @@
+package main
+
+import (
+	"context"
+	"employer/config"
+	"employer/internal/handler"
+	"employer/internal/lifecycle"
+	"employer/internal/repository"
+	"employer/internal/seed"
+	"employer/internal/service"
+	"employer/traits/build"
+	"employer/traits/database"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// serveCommand запускает HTTP сервер (команда по умолчанию)
+func serveCommand(args []string, zapLogger *zap.Logger, stderr io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configPath := configFlag(fs)
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+
+	cfg, zapLogger, level, err := setupCommandWithLevel(*configPath, zapLogger)
+	if err != nil {
+		return err
+	}
+	cfg.LogEffectiveConfig(zapLogger)
+
+	return runServe(cfg, zapLogger, level, stderr)
+}
+
+// shutdownTimeout общий срок остановки сервера и остальных компонентов
+const shutdownTimeout = 30 * time.Second
+
+// runServe поднимает БД, HTTP сервер и ждет сигнала остановки. Компоненты регистрируются
+// в lifecycle.Manager по мере создания и останавливаются в обратном порядке, в том числе
+// при ошибке запуска. level — уровень zapLogger, меняется при перезагрузке конфигурации
+func runServe(cfg *config.Config, zapLogger *zap.Logger, level zap.AtomicLevel, stderr io.Writer) (err error) {
+	components := lifecycle.New(zapLogger)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if stopErr := components.Stop(ctx); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}()
+	components.Append(lifecycle.Hook{
+		Name: "logger",
+		Stop: func(context.Context) error {
+			zapLogger.Info("✅ Application stopped")
+			syncLogger(zapLogger, stderr)
+			return nil
+		},
+	})
+
+	info := build.Get()
+	zapLogger.Info("запуск приложения Emplyee",
+		zap.String("version", info.Version),
+		zap.String("commit", info.Commit),
+		zap.String("build_date", info.BuildDate),
+		zap.String("go_version", info.GoVersion),
+		zap.String("environment", cfg.Environment),
+		zap.String("host", cfg.Host),
+		zap.String("port", cfg.Port),
+		zap.String("listen_socket", cfg.ListenSocket),
+		zap.String("db_name", cfg.DBName),
+		zap.String("api_base_path", cfg.APIBasePath),
+	)
+	for _, warning := range cfg.Deprecations() {
+		zapLogger.Warn(warning)
+	}
+
+	// Инициализация базы данных
+	db, err := initDatabase(cfg, zapLogger)
+	if err != nil {
+		return databaseError(fmt.Errorf("инициализация БД: %w", err))
+	}
+	components.Append(lifecycle.Hook{Name: "database", Stop: closeHook(db)})
+
+	// Реплика для чтения, если настроена
+	var replica repository.DBTX
+	if replicaCfg, ok := cfg.GetDBReplica(); ok {
+		replicaDB, err := initDatabase(replicaCfg, zapLogger)
+		if err != nil {
+			return databaseError(fmt.Errorf("инициализация реплики БД: %w", err))
+		}
+		components.Append(lifecycle.Hook{Name: "database replica", Stop: closeHook(replicaDB)})
+		replica = replicaDB
+	}
+
+	collation, err := sortCollation(cfg)
+	if err != nil {
+		return configError(err)
+	}
+
+	// Создание таблиц БД
+	if err := runMigrate(db, zapLogger, collation, cfg.DBTablePrefix); err != nil {
+		return databaseError(fmt.Errorf("создание таблиц: %w", err))
+	}
+
+	// Инициализация репозиториев с замером длительности запросов
+	queryDuration := repository.NewQueryDurationHistogram()
+	if err := prometheus.Register(queryDuration); err != nil {
+		return fmt.Errorf("регистрация метрик: %w", err)
+	}
+	repos := repository.NewRepositoriesWithOptions(db, zapLogger, repository.Options{
+		SortCollation: collation,
+		Replica:       replica,
+		TablePrefix:   cfg.DBTablePrefix,
+		Timing: &repository.TimingOptions{
+			SlowThreshold: cfg.GetSlowQueryThreshold(),
+			Histogram:     queryDuration,
+		},
+	})
+	// Подготовленные запросы закрываются раньше соединений с БД: хуки останавливаются в обратном порядке
+	components.Append(lifecycle.Hook{Name: "prepared statements", Stop: closeHook(repos)})
+
+	// Инициализация сервисов
+	opts, err := serviceOptions(cfg)
+	if err != nil {
+		return configError(err)
+	}
+	opts.SearchCacheRequests = service.NewSearchCacheCounter()
+	if err := prometheus.Register(opts.SearchCacheRequests); err != nil {
+		return fmt.Errorf("регистрация метрик: %w", err)
+	}
+	services := service.NewServices(repos, zapLogger, opts)
+
+	// Тестовые сотрудники для разработки (SEED_ON_START), только в пустую таблицу
+	if cfg.SeedOnStart {
+		loaded, err := seed.Load(context.Background(), services.Employee, seed.DefaultCount)
+		if err != nil {
+			return fmt.Errorf("загрузка тестовых данных: %w", err)
+		}
+		zapLogger.Info("загрузка тестовых данных при запуске", zap.Int("loaded", loaded))
+	}
+
+	// Параметры, меняющиеся без перезапуска (POST /api/admin/config/reload)
+	runtime := newRuntimeSettings(cfg, level, zapLogger)
+
+	// Создание HTTP обработчиков
+	employeeHandler := handler.NewEmployeeHandler(services.Employee, zapLogger)
+	employeeHandler.SetPageLimits(cfg.PageDefaultLimit, cfg.PageMaxLimit)
+	employeeHandler.SetSearchMaxResults(cfg.SearchMaxResults)
+	listRows := handler.NewListRowsHistogram()
+	if err := prometheus.Register(listRows); err != nil {
+		return fmt.Errorf("регистрация метрик: %w", err)
+	}
+	employeeHandler.SetListRowsHistogram(listRows)
+	employeeHandler.SetRequireContentType(cfg.RequireContentType)
+	employeeHandler.SetErrorDetails(cfg.ErrorDetails)
+	events := service.NewEventBroker()
+	employeeHandler.SetEvents(events)
+	healthHandler := handler.NewHealthHandler(db, zapLogger)
+
+	// Режим обслуживания: изменения отклоняются с 503, переключается через /api/admin/maintenance
+	maintenance, err := handler.NewMaintenance(cfg.MaintenanceFile, zapLogger)
+	if err != nil {
+		return err
+	}
+	healthHandler.SetMaintenance(maintenance)
+
+	// Настройка маршрутизации
+	router := mux.NewRouter()
+	basePath := cfg.APIBasePath
+
+	// Все маршруты монтируются под API_BASE_PATH (по умолчанию в корень)
+	app := handler.WithBasePath(router, basePath)
+
+	// Применение middleware; CORS и логирование запросов оборачивают весь роутер (см. ниже),
+	// чтобы работать и для запросов, не совпавших с маршрутом по методу (OPTIONS)
+	router.Use(maintenance.Middleware(basePath))
+
+	// Регистрация маршрутов для API сотрудников
+	employeeHandler.RegisterRoutes(app)
+
+	// Веб-интерфейс: встроенные статические файлы и страница или каталог STATIC_DIR
+	webHandler := handler.NewWebHandler(cfg.StaticDir, basePath, zapLogger)
+	webHandler.RegisterRoutes(app)
+
+	// Простой интерфейс без JavaScript: список, поиск, создание и удаление формами
+	plainPageSize := min(cfg.PageDefaultLimit, cfg.SearchMaxResults)
+	handler.NewPlainHandler(services.Employee, basePath, plainPageSize, zapLogger).RegisterRoutes(app)
+
+	// Запрос к самому префиксу (/hr) перенаправляем на страницу (/hr/)
+	if basePath != "" {
+		router.Handle(basePath, http.RedirectHandler(basePath+"/", http.StatusMovedPermanently)).Methods("GET")
+	}
+
+	// Health check, пробы Kubernetes (/health, /livez, /readyz) и сведения о сборке (/version)
+	healthHandler.RegisterRoutes(app)
+
+	// Метрики Prometheus
+	app.Handle("/metrics", promhttp.Handler()).Methods("GET")
+
+	// Очистка мягко удаленных записей: по таймеру (см. ниже) и вручную через /api/admin/purge
+	purgedCounter := newPurgedCounter()
+	if err := prometheus.Register(purgedCounter); err != nil {
+		return fmt.Errorf("регистрация метрик: %w", err)
+	}
+	softDeleteJanitor := newJanitor(repos.Employee, cfg.GetRetention(), purgedCounter, zapLogger)
+	var purger handler.Purger
+	if cfg.GetRetention() > 0 {
+		purger = softDeleteJanitor
+	}
+	// Служебные маршруты /api/admin доступны только с ADMIN_TOKEN
+	if cfg.AdminToken == "" {
+		zapLogger.Warn("ADMIN_TOKEN не задан, служебные маршруты /api/admin отключены")
+	}
+	adminHandler := handler.NewAdminHandler(purger, cfg.AdminToken, zapLogger)
+	adminHandler.SetConfig(runtime)
+	adminHandler.SetMaintenance(maintenance)
+	adminHandler.RegisterRoutes(app)
+
+	// Debug endpoint для проверки маршрутов
+	app.HandleFunc("/debug/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		routes := []string{
+			"GET /",
+			"GET /{path} (веб-интерфейс)",
+			"GET /health",
+			"GET /livez",
+			"GET /readyz",
+			"GET /version",
+			"GET /metrics",
+			"GET /static/{file}",
+			"GET /employees/plain",
+			"POST /employees/plain/create",
+			"POST /employees/plain/{id}/delete",
+			"GET /api/employees",
+			"HEAD /api/employees",
+			"POST /api/employees",
+			"DELETE /api/employees",
+			"PUT /api/employees/bulk",
+			"GET /api/employees/recent",
+			"GET /api/employees/anniversaries",
+			"GET /api/employees/count",
+			"GET /api/employees/stats",
+			"GET /api/employees/check-phone",
+			"GET /api/employees/duplicates",
+			"GET /api/employees/export",
+			"GET /api/employees/export.xlsx",
+			"GET /api/employees/events",
+			"POST /api/employees/import",
+			"POST /api/employees/validate",
+			"POST /api/employees/{id}/validate",
+			"GET /api/employees/{id}",
+			"GET /api/employees/{id}/history",
+			"POST /api/employees/{id}/activate",
+			"POST /api/employees/{id}/deactivate",
+			"POST /api/employees/{id}/merge",
+			"POST /api/employees/{id}/attendance",
+			"GET /api/employees/{id}/attendance",
+			"PUT /api/employees/{id}",
+			"DELETE /api/employees/{id}",
+			"GET /api/cities",
+			"GET /api/cities/{city}/employees",
+			"GET /api/attendance/summary",
+			"POST /api/admin/purge",
+			"GET /api/admin/config",
+			"POST /api/admin/config/reload",
+			"GET /api/admin/maintenance",
+			"POST /api/admin/maintenance",
+		}
+		for i, route := range routes {
+			method, path, _ := strings.Cut(route, " ")
+			routes[i] = method + " " + basePath + path
+		}
+
+		response := `{"available_routes":["` + strings.Join(routes, `","`) + `"]}`
+		w.Write([]byte(response))
+	}).Methods("GET")
+
+	// Неизвестные пути: 404 JSON для /api, страница веб-интерфейса для остальных GET
+	router.NotFoundHandler = http.HandlerFunc(webHandler.Fallback)
+
+	// OPTIONS и HEAD для всех маршрутов: Allow с зарегистрированными методами и GET без тела
+	autoMethods, err := handler.AutoMethods(router)
+	if err != nil {
+		return fmt.Errorf("обход маршрутов: %w", err)
+	}
+	routed := corsMiddleware(basePath, runtime.Get)(autoMethods)
+
+	// Тела запросов и ответов логируются только по LOG_HTTP_BODIES; флаг проверяется
+	// на каждом запросе, чтобы его можно было переключить перезагрузкой конфигурации
+	bodyLogged := bodyLogMiddleware(zapLogger, basePath)(routed)
+	appHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if runtime.Get().LogHTTPBodies {
+			bodyLogged.ServeHTTP(w, r)
+			return
+		}
+		routed.ServeHTTP(w, r)
+	})
+	if cfg.GetLogHTTPBodies() {
+		zapLogger.Info("логирование тел HTTP запросов включено (уровень debug)")
+	}
+
+	// Запросы сверх MAX_CONCURRENT_REQUESTS сразу получают 503; отклонения попадают в лог запросов
+	limited := handler.ConcurrencyLimit(cfg.MaxConcurrentRequests, basePath, zapLogger)(appHandler)
+
+	// Создание HTTP сервера; IP клиента определяется до всех middleware (TRUSTED_PROXIES)
+	srv := &http.Server{
+		Handler:      handler.ClientIPMiddleware(cfg.GetTrustedProxies())(accessLogMiddleware(zapLogger, basePath)(limited)),
+		Addr:         cfg.GetServerAddress(),
+		WriteTimeout: 15 * time.Second,
+		ReadTimeout:  15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// HTTPS и слушатель перенаправлений HTTP → HTTPS
+	var redirectSrv *http.Server
+	if cfg.TLSEnabled() {
+		redirectHandler, err := configureTLS(cfg, srv)
+		if err != nil {
+			return configError(err)
+		}
+		if cfg.HTTPRedirectPort != "" {
+			redirectSrv = &http.Server{
+				Handler:      redirectHandler,
+				Addr:         cfg.GetRedirectAddress(),
+				WriteTimeout: 15 * time.Second,
+				ReadTimeout:  15 * time.Second,
+				IdleTimeout:  60 * time.Second,
+			}
+		}
+	}
+
+	// Слушатели создаем заранее, чтобы занятый порт или сокет был ошибкой запуска.
+	// При перезапуске по SIGHUP они переданы прежним процессом
+	inherited, notifyReady, err := inheritListeners()
+	if err != nil {
+		return err
+	}
+	listener, redirectListener, err := openListeners(cfg, inherited, redirectSrv != nil)
+	if err != nil {
+		return err
+	}
+	listenAddress := listener.Addr().String()
+	listeners := []net.Listener{listener}
+	if redirectListener != nil {
+		listeners = append(listeners, redirectListener)
+	}
+
+	// Канал для получения сигналов ОС
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	restart := make(chan os.Signal, 1)
+	notifyRestart(restart)
+
+	// Проверяем наличие страницы веб-интерфейса при запуске
+	checkStaticFiles(webHandler, zapLogger)
+
+	// Фоновые задачи останавливаются после серверов, но до закрытия БД
+	if cfg.GetRetention() > 0 {
+		components.Append(lifecycle.Go("janitor", func(ctx context.Context) {
+			runJanitor(ctx, softDeleteJanitor, cfg.GetPurgeInterval())
+		}))
+	}
+	components.Append(lifecycle.Go("event listener", func(ctx context.Context) {
+		runEventListener(ctx, database.DSN(cfg), database.Prefixed(database.EmployeeChangesChannel, cfg.DBTablePrefix), events, zapLogger)
+	}))
+
+	// Файл unix-сокета удаляется после остановки серверов; после перезапуска
+	// сокет принадлежит новому процессу
+	handedOff := false
+	components.Append(lifecycle.Hook{
+		Name: "listen socket",
+		Stop: func(context.Context) error {
+			if handedOff {
+				return nil
+			}
+			return removeSocket(cfg.ListenSocket)
+		},
+	})
+
+	// Ошибки работы серверов; буфер на оба сервера, чтобы горутины не блокировались
+	serverErr := make(chan error, 2)
+
+	if redirectSrv != nil {
+		components.Append(lifecycle.Hook{
+			Name: "redirect server",
+			Start: func(context.Context) error {
+				zapLogger.Info("HTTP → HTTPS redirect server started",
+					zap.String("local_address", cfg.GetRedirectAddress()))
+				go func() {
+					if err := redirectSrv.Serve(redirectListener); err != nil && err != http.ErrServerClosed {
+						zapLogger.Error("failed to start redirect server", zap.Error(err))
+						serverErr <- fmt.Errorf("сервер перенаправления: %w", err)
+					}
+				}()
+				return nil
+			},
+			Stop: redirectSrv.Shutdown,
+		})
+	}
+
+	components.Append(lifecycle.Hook{
+		Name: "http server",
+		Start: func(context.Context) error {
+			zapLogger.Info("🚀 Web App HTTP server started",
+				zap.String("local_address", listenAddress),
+				zap.String("environment", cfg.Environment),
+				zap.Bool("tls", cfg.TLSEnabled()),
+			)
+			zapLogger.Info("📱 Employee Management Web Interface: https://meily.kz")
+			zapLogger.Info("🔧 API Endpoints: https://meily.kz/api/employees")
+			zapLogger.Info("🏥 Health Check: https://meily.kz/health")
+			zapLogger.Info("🐛 Debug Routes: https://meily.kz/debug/routes")
+			zapLogger.Info("📁 Static Files: https://meily.kz/static/")
+
+			go func() {
+				var err error
+				if cfg.TLSEnabled() {
+					// Сертификаты уже заданы в srv.TLSConfig
+					err = srv.ServeTLS(listener, "", "")
+				} else {
+					err = srv.Serve(listener)
+				}
+				if err != nil && err != http.ErrServerClosed {
+					zapLogger.Error("failed to start HTTP server", zap.Error(err))
+					serverErr <- fmt.Errorf("HTTP сервер: %w", err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			zapLogger.Info("🔄 shutting down server...")
+			return srv.Shutdown(ctx)
+		},
+	})
+
+	// Потоки SSE завершаются до остановки сервера, иначе Shutdown ждал бы их до таймаута
+	components.Append(lifecycle.Hook{
+		Name: "event broker",
+		Stop: func(context.Context) error {
+			events.Close()
+			return nil
+		},
+	})
+
+	// Таблицы созданы, серверы запущены — можно принимать трафик. При остановке готовность
+	// снимается первой, чтобы балансировщик перестал направлять трафик на останавливающийся под
+	components.Append(lifecycle.Hook{
+		Name: "readiness",
+		Start: func(context.Context) error {
+			healthHandler.SetReady(true)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			healthHandler.SetReady(false)
+			return nil
+		},
+	})
+
+	if err := components.Start(context.Background()); err != nil {
+		return err
+	}
+
+	// Если процесс запущен перезапуском, прежний может завершаться
+	notifyReady()
+
+	// Ожидание сигнала завершения, перезапуска или падения сервера
+	var runErr error
+wait:
+	for {
+		select {
+		case <-stop:
+			zapLogger.Info("🛑 shutdown signal received")
+		case runErr = <-serverErr:
+			zapLogger.Info("🛑 server failed, shutting down")
+		case <-restart:
+			zapLogger.Info("🔁 restart signal received, starting new process")
+			if err := startSuccessor(listeners, restartTimeout); err != nil {
+				zapLogger.Error("❌ restart failed, keep serving", zap.Error(err))
+				continue
+			}
+			handedOff = true
+			zapLogger.Info("новый процесс принимает соединения, прежний завершается")
+		}
+		break wait
+	}
+
+	return runErr
+}
+
+// closeHook останавливает компонент закрытием (например, пула соединений БД)
+func closeHook(c io.Closer) func(context.Context) error {
+	return func(context.Context) error {
+		return c.Close()
+	}
+}
+
+// checkStaticFiles проверяет, что страница веб-интерфейса есть среди встроенных
+// файлов или в каталоге STATIC_DIR
+func checkStaticFiles(web *handler.WebHandler, logger *zap.Logger) {
+	page, ok := web.Page()
+	if !ok {
+		logger.Warn("web page not found",
+			zap.String("source", web.Source()),
+			zap.String("expected_page", page),
+			zap.String("solution", "Please create index.html or employee.html in STATIC_DIR or unset STATIC_DIR to use the embedded files"),
+		)
+		return
+	}
+	logger.Info("✅ web page found", zap.String("source", web.Source()), zap.String("page", page))
+}
+
+// isProbePath проверяет, является ли путь пробой состояния или сбором метрик,
+// которые не нужно логировать
+func isProbePath(path string) bool {
+	return path == "/health" || path == "/livez" || path == "/readyz" || path == "/metrics"
+}
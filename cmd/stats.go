@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"employer/internal/repository"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+)
+
+// statsProvider часть репозитория, необходимая команде stats
+type statsProvider interface {
+	GetEmployeeStats(ctx context.Context) (*repository.EmployeeStats, error)
+}
+
+// statsCommand печатает статистику по сотрудникам
+func statsCommand(args []string, zapLogger *zap.Logger, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	configPath := configFlag(fs)
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+
+	cfg, zapLogger, err := setupCommand(*configPath, zapLogger)
+	if err != nil {
+		return err
+	}
+	defer syncLogger(zapLogger, stderr)
+
+	db, err := initDatabase(cfg, zapLogger)
+	if err != nil {
+		return databaseError(fmt.Errorf("инициализация БД: %w", err))
+	}
+	defer db.Close()
+
+	if err := runStats(context.Background(), repository.NewEmployeeRepository(db, zapLogger), stdout); err != nil {
+		return fmt.Errorf("получение статистики: %w", err)
+	}
+	return nil
+}
+
+// runStats выводит статистику в виде таблицы
+func runStats(ctx context.Context, provider statsProvider, out io.Writer) error {
+	stats, err := provider.GetEmployeeStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Всего сотрудников\t%d\n", stats.TotalCount)
+	fmt.Fprintf(tw, "Городов\t%d\n", stats.CitiesCount)
+	fmt.Fprintf(tw, "Самый частый город\t%s\n", stats.MostCommonCity)
+	return tw.Flush()
+}
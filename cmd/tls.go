@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/tls"
+	"employer/config"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newTLSConfig возвращает настройки TLS: минимум TLS 1.2 и только AEAD шифры с forward secrecy
+func newTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// configureTLS настраивает srv на HTTPS: загружает сертификат из файлов или
+// включает автоматическое получение через autocert. Ошибка загрузки сертификата
+// возвращается сразу, до старта сервера. Возвращает обработчик для HTTP слушателя
+// перенаправлений (с поддержкой ACME http-01 при autocert).
+func configureTLS(cfg *config.Config, srv *http.Server) (http.Handler, error) {
+	tlsConfig := newTLSConfig()
+	redirect := redirectToHTTPS(cfg.Port)
+
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("загрузка TLS сертификата: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		srv.TLSConfig = tlsConfig
+		return redirect, nil
+	}
+
+	if err := os.MkdirAll(cfg.TLSAutocertCacheDir, 0o700); err != nil {
+		return nil, fmt.Errorf("создание каталога кэша сертификатов: %w", err)
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+		Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+	}
+	tlsConfig.GetCertificate = manager.GetCertificate
+	tlsConfig.NextProtos = []string{"h2", "http/1.1", acme.ALPNProto}
+	srv.TLSConfig = tlsConfig
+
+	return manager.HTTPHandler(redirect), nil
+}
+
+// redirectToHTTPS перенаправляет запросы (301) на тот же адрес по HTTPS на порту httpsPort
+func redirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
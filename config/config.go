@@ -2,61 +2,667 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"employer/traits/database"
+
+	"gopkg.in/yaml.v2"
 )
 
 // Config структура конфигурации
 type Config struct {
 	// Database
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBSSLMode  string
+	DBHost     string `yaml:"db_host"`
+	DBPort     string `yaml:"db_port"`
+	DBUser     string `yaml:"db_user"`
+	DBPassword string `yaml:"db_password"`
+	DBName     string `yaml:"db_name"`
+	DBSSLMode  string `yaml:"db_sslmode"`
+	// DBSlowQueryMS порог медленного запроса в миллисекундах; 0 отключает лог
+	DBSlowQueryMS int `yaml:"db_slow_query_ms"`
+	// DBStatementTimeout наибольшее время одного запроса на стороне PostgreSQL (statement_timeout),
+	// формат time.ParseDuration; "0" — без ограничения
+	DBStatementTimeout string `yaml:"db_statement_timeout"`
+	// DBTablePrefix префикс имен таблиц, индексов и канала уведомлений ("tenant1_" -> tenant1_employees)
+	// для нескольких экземпляров в одной схеме; пусто — без префикса
+	DBTablePrefix string `yaml:"db_table_prefix"`
+	// DatabaseURL полная строка подключения postgres://...; если задана, заменяет DB_HOST,
+	// DB_PORT, DB_USER, DB_PASSWORD, DB_NAME и DB_SSLMODE (они заполняются из нее для логов и реплики)
+	DatabaseURL string `yaml:"database_url"`
+
+	// Реплика для чтения; без DBReplicaHost все запросы идут в основную БД.
+	// Незаданные параметры берутся у основной БД
+	DBReplicaHost     string `yaml:"db_replica_host"`
+	DBReplicaPort     string `yaml:"db_replica_port"`
+	DBReplicaUser     string `yaml:"db_replica_user"`
+	DBReplicaPassword string `yaml:"db_replica_password"`
+	DBReplicaName     string `yaml:"db_replica_name"`
+	DBReplicaSSLMode  string `yaml:"db_replica_sslmode"`
 
 	// Server
-	Port        string
-	Environment string
+	Host         string `yaml:"host"`
+	Port         string `yaml:"port"`
+	ListenSocket string `yaml:"listen_socket"`
+	Environment  string `yaml:"environment"`
+	APIBasePath  string `yaml:"api_base_path"`
+	// StaticDir каталог веб-интерфейса на диске (страница и файлы /static/) вместо
+	// встроенного в бинарный файл; пустой — встроенный
+	StaticDir string `yaml:"static_dir"`
+	// CORSAllowedOrigins источники, которым разрешены запросы к API из браузера; "*" — любые
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins"`
+	// CORSMaxAge сколько секунд браузер кэширует ответ на предварительный запрос
+	// (Access-Control-Max-Age); 0 — заголовок не отправляется
+	CORSMaxAge int `yaml:"cors_max_age"`
+	// CORSExposeHeaders заголовки ответа, которые может прочитать скрипт на странице
+	// (Access-Control-Expose-Headers), например X-Request-ID
+	CORSExposeHeaders []string `yaml:"cors_expose_headers"`
+	// RequireContentType отклонять тела запросов без заголовка Content-Type (415); тела
+	// с Content-Type, отличным от JSON и XML, отклоняются всегда
+	RequireContentType bool `yaml:"require_content_type"`
+	// ErrorDetails добавлять текст внутренней ошибки в ответы 500 (поле detail). Задается
+	// только переменной ERROR_DETAILS, по умолчанию включено везде, кроме production
+	ErrorDetails bool `yaml:"-"`
+	// MaintenanceFile файл состояния режима обслуживания, чтобы он пережил перезапуск;
+	// пусто — состояние только в памяти
+	MaintenanceFile string `yaml:"maintenance_file"`
+	// TrustedProxies адреса и диапазоны CIDR прокси, чьим заголовкам X-Forwarded-For и
+	// X-Real-IP можно верить при определении IP клиента; пусто — заголовки не учитываются
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// AdminToken токен доступа к служебным маршрутам /api/admin (заголовок
+	// Authorization: Bearer); пусто — служебные маршруты отключены
+	AdminToken string `yaml:"admin_token"`
+	// SeedOnStart загружать при запуске тестовых сотрудников, если таблица пуста
+	// (как команда seed); допускается только в ENVIRONMENT=development
+	SeedOnStart bool `yaml:"seed_on_start"`
+
+	// TLS
+	TLSCertFile         string   `yaml:"tls_cert_file"`
+	TLSKeyFile          string   `yaml:"tls_key_file"`
+	TLSAutocertDomains  []string `yaml:"tls_autocert_domains"`
+	TLSAutocertCacheDir string   `yaml:"tls_autocert_cache_dir"`
+	HTTPRedirectPort    string   `yaml:"http_redirect_port"`
+
+	// Data
+	NormalizeCity bool `yaml:"normalize_city"`
+	// PhoneRegion правила проверки телефонов: KZ, RU или INTL (E.164)
+	PhoneRegion string `yaml:"phone_region"`
+	// SortLocale правила сортировки имен в списках: und (ICU), ru, kk или C (побайтово)
+	SortLocale string `yaml:"sort_locale"`
+	// PurgeRetention срок хранения мягко удаленных записей, формат time.ParseDuration
+	// ("720h"); "0" отключает очистку
+	PurgeRetention string `yaml:"purge_retention"`
+	// RetentionDays устаревший синоним PurgeRetention в днях; учитывается, только если
+	// PurgeRetention не задан
+	RetentionDays int `yaml:"retention_days"`
+	// PurgeInterval период запуска очистки, формат time.ParseDuration ("24h")
+	PurgeInterval string `yaml:"purge_interval"`
+	// StatsCacheTTL сколько отдавать статистику из кэша, формат time.ParseDuration; "0" — без кэша
+	StatsCacheTTL string `yaml:"stats_cache_ttl"`
+	// SearchCacheTTL сколько отдавать результаты поиска из кэша, формат time.ParseDuration; "0" — без кэша
+	SearchCacheTTL string `yaml:"search_cache_ttl"`
+	// IdempotencyTTL сколько действует ключ Idempotency-Key, формат time.ParseDuration
+	IdempotencyTTL string `yaml:"idempotency_ttl"`
+	// PageDefaultLimit размер страницы списка сотрудников без limit в запросе
+	PageDefaultLimit int `yaml:"page_default_limit"`
+	// PageMaxLimit наибольший размер страницы; больший limit уменьшается до него
+	PageMaxLimit int `yaml:"page_max_limit"`
+	// SearchMaxResults наибольшее количество результатов поиска за запрос
+	SearchMaxResults int `yaml:"search_max_results"`
+	// MaxEmployees наибольшее количество сотрудников (квота тарифа); 0 — без ограничения
+	MaxEmployees int `yaml:"max_employees"`
+	// ListMaxRows наибольшее количество сотрудников в списке без limit и offset
+	ListMaxRows int `yaml:"list_max_rows"`
+	// MaxConcurrentRequests наибольшее количество одновременно обрабатываемых запросов;
+	// сверх него — 503. 0 — без ограничения
+	MaxConcurrentRequests int `yaml:"max_concurrent_requests"`
+
+	// Logging
+	LogLevel      string `yaml:"log_level"`
+	LogFile       string `yaml:"log_file"`
+	LogMaxSizeMB  int    `yaml:"log_max_size_mb"`
+	LogMaxBackups int    `yaml:"log_max_backups"`
+	LogMaxAgeDays int    `yaml:"log_max_age_days"`
+	// LogHTTPBodies логировать тела запросов и ответов API на уровне debug
+	LogHTTPBodies bool `yaml:"log_http_bodies"`
+
+	// path файл, из которого загружена конфигурация; перечитывается при перезагрузке
+	path string
+	// dbPasswordSource откуда получен пароль БД, для диагностики
+	dbPasswordSource string
+	// deprecated предупреждения об устаревших параметрах, заданных в конфигурации
+	deprecated []string
+	// portExplicit порт задан явно, а не взят по умолчанию
+	portExplicit bool
 }
 
-// NewConfig создает новую конфигурацию
+// Допустимые значения sslmode для Postgres
+var validSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Допустимые уровни логирования
+var validLogLevels = []string{"debug", "info", "warn", "error"}
+
+// Допустимые регионы проверки телефонов
+var validPhoneRegions = []string{"KZ", "RU", "INTL"}
+
+// Допустимые локали сортировки имен
+var validSortLocales = []string{"und", "ru", "kk", "C"}
+
+// Допустимые окружения
+var validEnvironments = []string{"development", "staging", "production"}
+
+// defaultCORSExposeHeaders заголовки ответов API, которые по умолчанию доступны скриптам:
+// идентификатор запроса, общее количество и ссылки пагинации, версия записи
+const defaultCORSExposeHeaders = "X-Request-ID,X-Total-Count,Link,ETag"
+
+// Очистка мягко удаленных записей по умолчанию: хранить 30 дней, проверять раз в сутки
+const (
+	DefaultPurgeRetention = "720h"
+	DefaultPurgeInterval  = "24h"
+)
+
+// MinAdminTokenLength наименьшая длина ADMIN_TOKEN, чтобы токен нельзя было подобрать
+const MinAdminTokenLength = 16
+
+// NewConfig создает новую конфигурацию из файла CONFIG_FILE (если задан) и переменных окружения
 func NewConfig() (*Config, error) {
-	return &Config{
+	return LoadConfig(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadConfig загружает конфигурацию: значения из файла path (YAML или JSON,
+// необязателен), поверх них переменные окружения, затем значения по умолчанию
+func LoadConfig(path string) (*Config, error) {
+	// Значения, у которых умолчание отличается от нулевого, если их нет в файле
+	// RetentionDays -1 — устаревший ключ retention_days в файле не задан
+	file := &Config{NormalizeCity: true, RetentionDays: -1, DBSlowQueryMS: 200}
+	if path != "" {
+		if err := readConfigFile(path, file); err != nil {
+			return nil, err
+		}
+	}
+
+	dbPassword, dbPasswordSource, err := getEnvOrFile("DB_PASSWORD", withDefault(file.DBPassword, "postgres123"))
+	if err != nil {
+		return nil, err
+	}
+	if dbPasswordSource == sourceDefault && file.DBPassword != "" {
+		dbPasswordSource = "файл конфигурации " + path
+	}
+
+	databaseURL, _, err := getEnvOrFile("DATABASE_URL", file.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	logMaxSizeMB, err := getEnvInt("LOG_MAX_SIZE_MB", withDefaultInt(file.LogMaxSizeMB, 100))
+	if err != nil {
+		return nil, err
+	}
+	logMaxBackups, err := getEnvInt("LOG_MAX_BACKUPS", withDefaultInt(file.LogMaxBackups, 5))
+	if err != nil {
+		return nil, err
+	}
+	logMaxAgeDays, err := getEnvInt("LOG_MAX_AGE_DAYS", withDefaultInt(file.LogMaxAgeDays, 30))
+	if err != nil {
+		return nil, err
+	}
+
+	logHTTPBodies, err := getEnvBool("LOG_HTTP_BODIES", file.LogHTTPBodies)
+	if err != nil {
+		return nil, err
+	}
+
+	corsMaxAge, err := getEnvInt("CORS_MAX_AGE", withDefaultInt(file.CORSMaxAge, 600))
+	if err != nil {
+		return nil, err
+	}
+
+	requireContentType, err := getEnvBool("REQUIRE_CONTENT_TYPE", file.RequireContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	environment := getEnv("ENVIRONMENT", withDefault(file.Environment, "development"))
+	errorDetails, err := getEnvBool("ERROR_DETAILS", environment != "production")
+	if err != nil {
+		return nil, err
+	}
+
+	seedOnStart, err := getEnvBool("SEED_ON_START", file.SeedOnStart)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeCity, err := getEnvBool("NORMALIZE_CITY", file.NormalizeCity)
+	if err != nil {
+		return nil, err
+	}
+	// RETENTION_DAYS устарел: без PURGE_RETENTION его значение переводится в длительность
+	var deprecated []string
+	purgeRetention := getEnv("PURGE_RETENTION", file.PurgeRetention)
+	retentionDays := 0
+	if os.Getenv("RETENTION_DAYS") != "" || file.RetentionDays != -1 {
+		if retentionDays, err = getEnvInt("RETENTION_DAYS", file.RetentionDays); err != nil {
+			return nil, err
+		}
+		deprecated = append(deprecated, "RETENTION_DAYS устарел, используйте PURGE_RETENTION")
+		if purgeRetention == "" {
+			purgeRetention = (time.Duration(retentionDays) * 24 * time.Hour).String()
+		}
+	}
+	pageDefaultLimit, err := getEnvInt("PAGE_DEFAULT_LIMIT", withDefaultInt(file.PageDefaultLimit, 20))
+	if err != nil {
+		return nil, err
+	}
+	pageMaxLimit, err := getEnvInt("PAGE_MAX_LIMIT", withDefaultInt(file.PageMaxLimit, 100))
+	if err != nil {
+		return nil, err
+	}
+	searchMaxResults, err := getEnvInt("SEARCH_MAX_RESULTS", withDefaultInt(file.SearchMaxResults, 100))
+	if err != nil {
+		return nil, err
+	}
+	maxEmployees, err := getEnvInt("MAX_EMPLOYEES", file.MaxEmployees)
+	if err != nil {
+		return nil, err
+	}
+	listMaxRows, err := getEnvInt("LIST_MAX_ROWS", withDefaultInt(file.ListMaxRows, 10000))
+	if err != nil {
+		return nil, err
+	}
+	maxConcurrentRequests, err := getEnvInt("MAX_CONCURRENT_REQUESTS", file.MaxConcurrentRequests)
+	if err != nil {
+		return nil, err
+	}
+	dbSlowQueryMS, err := getEnvInt("DB_SLOW_QUERY_MS", file.DBSlowQueryMS)
+	if err != nil {
+		return nil, err
+	}
+	dbReplicaPassword, _, err := getEnvOrFile("DB_REPLICA_PASSWORD", file.DBReplicaPassword)
+	if err != nil {
+		return nil, err
+	}
+	adminToken, _, err := getEnvOrFile("ADMIN_TOKEN", file.AdminToken)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
 		// Database
-		DBHost:     getEnv("DB_HOST", "127.0.0.1"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "postgres123"),
-		DBName:     getEnv("DB_NAME", "employee"),
-		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+		DBHost:     getEnv("DB_HOST", withDefault(file.DBHost, "127.0.0.1")),
+		DBPort:     getEnv("DB_PORT", withDefault(file.DBPort, "5432")),
+		DBUser:     getEnv("DB_USER", withDefault(file.DBUser, "postgres")),
+		DBPassword: dbPassword,
+		DBName:     getEnv("DB_NAME", withDefault(file.DBName, "employee")),
+		DBSSLMode:  getEnv("DB_SSLMODE", withDefault(file.DBSSLMode, "disable")),
+
+		DBSlowQueryMS:      dbSlowQueryMS,
+		DBStatementTimeout: getEnv("DB_STATEMENT_TIMEOUT", withDefault(file.DBStatementTimeout, "0")),
+		DBTablePrefix:      getEnv("DB_TABLE_PREFIX", file.DBTablePrefix),
+		DatabaseURL:        databaseURL,
+
+		DBReplicaHost:     getEnv("DB_REPLICA_HOST", file.DBReplicaHost),
+		DBReplicaPort:     getEnv("DB_REPLICA_PORT", file.DBReplicaPort),
+		DBReplicaUser:     getEnv("DB_REPLICA_USER", file.DBReplicaUser),
+		DBReplicaPassword: dbReplicaPassword,
+		DBReplicaName:     getEnv("DB_REPLICA_NAME", file.DBReplicaName),
+		DBReplicaSSLMode:  getEnv("DB_REPLICA_SSLMODE", file.DBReplicaSSLMode),
 
 		// Server
-		Port:        getEnv("PORT", "8081"),
-		Environment: getEnv("ENVIRONMENT", "development"),
-	}, nil
+		Host:         getEnv("HOST", file.Host),
+		Port:         getEnv("PORT", withDefault(file.Port, "8081")),
+		ListenSocket: getEnv("LISTEN_SOCKET", file.ListenSocket),
+		Environment:  environment,
+		APIBasePath:  normalizeBasePath(getEnv("API_BASE_PATH", file.APIBasePath)),
+		StaticDir:    getEnv("STATIC_DIR", file.StaticDir),
+
+		CORSAllowedOrigins: splitList(getEnv("CORS_ALLOWED_ORIGINS", withDefault(strings.Join(file.CORSAllowedOrigins, ","), "*"))),
+		CORSMaxAge:         corsMaxAge,
+		CORSExposeHeaders:  splitList(getEnv("CORS_EXPOSE_HEADERS", withDefault(strings.Join(file.CORSExposeHeaders, ","), defaultCORSExposeHeaders))),
+		RequireContentType: requireContentType,
+		ErrorDetails:       errorDetails,
+		MaintenanceFile:    getEnv("MAINTENANCE_FILE", file.MaintenanceFile),
+		TrustedProxies:     splitList(getEnv("TRUSTED_PROXIES", strings.Join(file.TrustedProxies, ","))),
+		AdminToken:         adminToken,
+		SeedOnStart:        seedOnStart,
+
+		// TLS
+		TLSCertFile:         getEnv("TLS_CERT_FILE", file.TLSCertFile),
+		TLSKeyFile:          getEnv("TLS_KEY_FILE", file.TLSKeyFile),
+		TLSAutocertDomains:  splitList(getEnv("TLS_AUTOCERT_DOMAINS", strings.Join(file.TLSAutocertDomains, ","))),
+		TLSAutocertCacheDir: getEnv("TLS_AUTOCERT_CACHE_DIR", withDefault(file.TLSAutocertCacheDir, "./certs")),
+		HTTPRedirectPort:    getEnv("HTTP_REDIRECT_PORT", file.HTTPRedirectPort),
+
+		// Data
+		NormalizeCity:    normalizeCity,
+		PhoneRegion:      strings.ToUpper(getEnv("PHONE_REGION", withDefault(file.PhoneRegion, "KZ"))),
+		SortLocale:       getEnv("SORT_LOCALE", withDefault(file.SortLocale, "und")),
+		PurgeRetention:   withDefault(purgeRetention, DefaultPurgeRetention),
+		RetentionDays:    retentionDays,
+		PurgeInterval:    getEnv("PURGE_INTERVAL", withDefault(file.PurgeInterval, DefaultPurgeInterval)),
+		StatsCacheTTL:    getEnv("STATS_CACHE_TTL", withDefault(file.StatsCacheTTL, "30s")),
+		SearchCacheTTL:   getEnv("SEARCH_CACHE_TTL", withDefault(file.SearchCacheTTL, "5s")),
+		IdempotencyTTL:   getEnv("IDEMPOTENCY_TTL", withDefault(file.IdempotencyTTL, "24h")),
+		PageDefaultLimit: pageDefaultLimit,
+		PageMaxLimit:     pageMaxLimit,
+		SearchMaxResults: searchMaxResults,
+		MaxEmployees:     maxEmployees,
+		ListMaxRows:      listMaxRows,
+
+		MaxConcurrentRequests: maxConcurrentRequests,
+
+		// Logging
+		LogLevel:      getEnv("LOG_LEVEL", file.LogLevel),
+		LogFile:       getEnv("LOG_FILE", file.LogFile),
+		LogMaxSizeMB:  logMaxSizeMB,
+		LogMaxBackups: logMaxBackups,
+		LogMaxAgeDays: logMaxAgeDays,
+		LogHTTPBodies: logHTTPBodies,
+
+		path:             path,
+		dbPasswordSource: dbPasswordSource,
+		deprecated:       deprecated,
+		portExplicit:     os.Getenv("PORT") != "" || file.Port != "",
+	}
+
+	// DATABASE_URL имеет приоритет над отдельными DB_*
+	if cfg.DatabaseURL != "" {
+		params, err := database.ParseURL(cfg.DatabaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("DATABASE_URL: %w", err)
+		}
+		cfg.DBHost, cfg.DBPort, cfg.DBUser = params.Host, params.Port, params.User
+		cfg.DBPassword, cfg.DBName, cfg.DBSSLMode = params.Password, params.Name, params.SSLMode
+		cfg.dbPasswordSource = "DATABASE_URL"
+	}
+
+	return cfg, nil
 }
 
 // ValidateConfig проверяет корректность конфигурации
 func (c *Config) ValidateConfig() error {
 	if c.DBPassword == "" {
+		if c.dbPasswordSource != "" {
+			return fmt.Errorf("DB_PASSWORD обязателен (источник: %s)", c.dbPasswordSource)
+		}
 		return fmt.Errorf("DB_PASSWORD обязателен")
 	}
+
+	port, err := strconv.Atoi(c.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("PORT должен быть числом от 1 до 65535, получено %q", c.Port)
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, err := parseTrustedProxy(proxy); err != nil {
+			return fmt.Errorf("TRUSTED_PROXIES: %q не является IP адресом или диапазоном CIDR", proxy)
+		}
+	}
+
+	if c.CORSMaxAge < 0 {
+		return fmt.Errorf("CORS_MAX_AGE не может быть отрицательным, получено %d", c.CORSMaxAge)
+	}
+	for _, header := range c.CORSExposeHeaders {
+		if !isHeaderName(header) {
+			return fmt.Errorf("CORS_EXPOSE_HEADERS: %q не является именем заголовка", header)
+		}
+	}
+
+	if c.AdminToken != "" && len(c.AdminToken) < MinAdminTokenLength {
+		return fmt.Errorf("ADMIN_TOKEN должен быть не короче %d символов", MinAdminTokenLength)
+	}
+
+	if c.ListenSocket != "" && (c.Host != "" || c.portExplicit) {
+		return fmt.Errorf("LISTEN_SOCKET нельзя задавать вместе с HOST или PORT")
+	}
+
+	if err := database.ValidateTablePrefix(c.DBTablePrefix); err != nil {
+		return fmt.Errorf("DB_TABLE_PREFIX: %w", err)
+	}
+
+	if !contains(validSSLModes, c.DBSSLMode) {
+		return fmt.Errorf("DB_SSLMODE должен быть одним из %s, получено %q",
+			strings.Join(validSSLModes, ", "), c.DBSSLMode)
+	}
+
+	if c.DBReplicaSSLMode != "" && !contains(validSSLModes, c.DBReplicaSSLMode) {
+		return fmt.Errorf("DB_REPLICA_SSLMODE должен быть одним из %s, получено %q",
+			strings.Join(validSSLModes, ", "), c.DBReplicaSSLMode)
+	}
+
+	if !contains(validEnvironments, c.Environment) {
+		return fmt.Errorf("ENVIRONMENT должен быть одним из %s, получено %q",
+			strings.Join(validEnvironments, ", "), c.Environment)
+	}
+	if c.SeedOnStart && c.Environment != "development" {
+		return fmt.Errorf("SEED_ON_START допускается только в ENVIRONMENT=development, получено %q", c.Environment)
+	}
+
+	if c.DBSlowQueryMS < 0 {
+		return fmt.Errorf("DB_SLOW_QUERY_MS не может быть отрицательным, получено %d", c.DBSlowQueryMS)
+	}
+	// пустое значение (Config без LoadConfig) — без ограничения, как "0"
+	if timeout, err := time.ParseDuration(withDefault(c.DBStatementTimeout, "0")); err != nil || timeout < 0 {
+		return fmt.Errorf("DB_STATEMENT_TIMEOUT должен быть неотрицательной длительностью (например 30s), получено %q", c.DBStatementTimeout)
+	}
+
+	if !contains(validPhoneRegions, c.PhoneRegion) {
+		return fmt.Errorf("PHONE_REGION должен быть одним из %s, получено %q",
+			strings.Join(validPhoneRegions, ", "), c.PhoneRegion)
+	}
+
+	if !contains(validSortLocales, c.SortLocale) {
+		return fmt.Errorf("SORT_LOCALE должен быть одним из %s, получено %q",
+			strings.Join(validSortLocales, ", "), c.SortLocale)
+	}
+
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("RETENTION_DAYS не может быть отрицательным, получено %d", c.RetentionDays)
+	}
+	if retention, err := time.ParseDuration(c.PurgeRetention); err != nil || retention < 0 {
+		return fmt.Errorf("PURGE_RETENTION должен быть неотрицательной длительностью (например 720h), получено %q", c.PurgeRetention)
+	}
+	if interval, err := time.ParseDuration(c.PurgeInterval); err != nil || interval <= 0 {
+		return fmt.Errorf("PURGE_INTERVAL должен быть положительной длительностью (например 24h), получено %q", c.PurgeInterval)
+	}
+	if ttl, err := time.ParseDuration(c.StatsCacheTTL); err != nil || ttl < 0 {
+		return fmt.Errorf("STATS_CACHE_TTL должен быть неотрицательной длительностью (например 30s), получено %q", c.StatsCacheTTL)
+	}
+	if ttl, err := time.ParseDuration(c.SearchCacheTTL); err != nil || ttl < 0 {
+		return fmt.Errorf("SEARCH_CACHE_TTL должен быть неотрицательной длительностью (например 5s), получено %q", c.SearchCacheTTL)
+	}
+	if ttl, err := time.ParseDuration(c.IdempotencyTTL); err != nil || ttl <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_TTL должен быть положительной длительностью (например 24h), получено %q", c.IdempotencyTTL)
+	}
+	if c.PageDefaultLimit < 1 {
+		return fmt.Errorf("PAGE_DEFAULT_LIMIT должен быть положительным, получено %d", c.PageDefaultLimit)
+	}
+	if c.PageMaxLimit < c.PageDefaultLimit {
+		return fmt.Errorf("PAGE_MAX_LIMIT (%d) не может быть меньше PAGE_DEFAULT_LIMIT (%d)", c.PageMaxLimit, c.PageDefaultLimit)
+	}
+	if c.SearchMaxResults < 1 {
+		return fmt.Errorf("SEARCH_MAX_RESULTS должен быть положительным, получено %d", c.SearchMaxResults)
+	}
+	if c.MaxEmployees < 0 {
+		return fmt.Errorf("MAX_EMPLOYEES должен быть неотрицательным (0 — без ограничения), получено %d", c.MaxEmployees)
+	}
+	if c.ListMaxRows < 1 {
+		return fmt.Errorf("LIST_MAX_ROWS должен быть положительным, получено %d", c.ListMaxRows)
+	}
+	if c.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("MAX_CONCURRENT_REQUESTS должен быть неотрицательным (0 — без ограничения), получено %d", c.MaxConcurrentRequests)
+	}
+
+	if c.LogLevel != "" && !contains(validLogLevels, c.LogLevel) {
+		return fmt.Errorf("LOG_LEVEL должен быть одним из %s, получено %q",
+			strings.Join(validLogLevels, ", "), c.LogLevel)
+	}
+	if c.LogMaxSizeMB < 1 {
+		return fmt.Errorf("LOG_MAX_SIZE_MB должен быть положительным, получено %d", c.LogMaxSizeMB)
+	}
+	if c.LogMaxBackups < 0 || c.LogMaxAgeDays < 0 {
+		return fmt.Errorf("LOG_MAX_BACKUPS и LOG_MAX_AGE_DAYS не могут быть отрицательными")
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE и TLS_KEY_FILE задаются только вместе")
+	}
+	if c.TLSCertFile != "" && len(c.TLSAutocertDomains) > 0 {
+		return fmt.Errorf("TLS_CERT_FILE и TLS_AUTOCERT_DOMAINS взаимоисключающие")
+	}
+	if c.HTTPRedirectPort != "" {
+		if !c.TLSEnabled() {
+			return fmt.Errorf("HTTP_REDIRECT_PORT требует включенного TLS")
+		}
+		if port, err := strconv.Atoi(c.HTTPRedirectPort); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("HTTP_REDIRECT_PORT должен быть числом от 1 до 65535, получено %q", c.HTTPRedirectPort)
+		}
+	}
+
 	return nil
 }
 
-// GetServerAddress возвращает адрес сервера
+// TLSEnabled сообщает, должен ли сервер обслуживать HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || len(c.TLSAutocertDomains) > 0
+}
+
+// GetSlowQueryThreshold возвращает порог медленного запроса к БД
+func (c *Config) GetSlowQueryThreshold() time.Duration {
+	return time.Duration(c.DBSlowQueryMS) * time.Millisecond
+}
+
+// GetDBStatementTimeout возвращает ограничение времени запроса к БД; значение проверено в ValidateConfig
+func (c *Config) GetDBStatementTimeout() time.Duration {
+	timeout, _ := time.ParseDuration(c.DBStatementTimeout)
+	return timeout
+}
+
+// GetTrustedProxies возвращает диапазоны доверенных прокси; значения проверены в ValidateConfig
+func (c *Config) GetTrustedProxies() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, proxy := range c.TrustedProxies {
+		if prefix, err := parseTrustedProxy(proxy); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// parseTrustedProxy разбирает диапазон CIDR или отдельный адрес (как /32 или /128)
+func parseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// GetRetention возвращает срок хранения мягко удаленных записей; 0 — очистка отключена.
+// Значение проверено в ValidateConfig
+func (c *Config) GetRetention() time.Duration {
+	retention, _ := time.ParseDuration(c.PurgeRetention)
+	return retention
+}
+
+// Deprecations возвращает предупреждения об устаревших параметрах, заданных в конфигурации
+func (c *Config) Deprecations() []string {
+	return c.deprecated
+}
+
+// GetPurgeInterval возвращает период очистки; значение проверено в ValidateConfig
+func (c *Config) GetPurgeInterval() time.Duration {
+	interval, _ := time.ParseDuration(c.PurgeInterval)
+	return interval
+}
+
+// GetIdempotencyTTL возвращает срок действия ключа Idempotency-Key; значение проверено в ValidateConfig
+func (c *Config) GetIdempotencyTTL() time.Duration {
+	ttl, _ := time.ParseDuration(c.IdempotencyTTL)
+	return ttl
+}
+
+// GetStatsCacheTTL возвращает время жизни кэша статистики; значение проверено в ValidateConfig
+func (c *Config) GetStatsCacheTTL() time.Duration {
+	ttl, _ := time.ParseDuration(c.StatsCacheTTL)
+	return ttl
+}
+
+// GetSearchCacheTTL возвращает время жизни кэша поиска; значение проверено в ValidateConfig
+func (c *Config) GetSearchCacheTTL() time.Duration {
+	ttl, _ := time.ParseDuration(c.SearchCacheTTL)
+	return ttl
+}
+
+// GetRedirectAddress возвращает адрес HTTP слушателя, перенаправляющего на HTTPS
+func (c *Config) GetRedirectAddress() string {
+	return net.JoinHostPort(c.Host, c.HTTPRedirectPort)
+}
+
+// GetServerAddress возвращает адрес сервера; пустой HOST означает все интерфейсы
 func (c *Config) GetServerAddress() string {
-	return ":" + c.Port
+	return net.JoinHostPort(c.Host, c.Port)
 }
 
 // Database interface methods
-func (c *Config) GetDBHost() string     { return c.DBHost }
-func (c *Config) GetDBPort() string     { return c.DBPort }
-func (c *Config) GetDBUser() string     { return c.DBUser }
-func (c *Config) GetDBPassword() string { return c.DBPassword }
-func (c *Config) GetDBName() string     { return c.DBName }
-func (c *Config) GetDBSSLMode() string  { return c.DBSSLMode }
+func (c *Config) GetDBHost() string      { return c.DBHost }
+func (c *Config) GetDBPort() string      { return c.DBPort }
+func (c *Config) GetDBUser() string      { return c.DBUser }
+func (c *Config) GetDBPassword() string  { return c.DBPassword }
+func (c *Config) GetDBName() string      { return c.DBName }
+func (c *Config) GetDBSSLMode() string   { return c.DBSSLMode }
+func (c *Config) GetDatabaseURL() string { return c.DatabaseURL }
+
+// DBReplica параметры подключения к реплике; реализует тот же интерфейс, что и Config
+type DBReplica struct {
+	Host, Port, User, Password, Name, SSLMode string
+	// StatementTimeout ограничение времени запроса, как у основной БД
+	StatementTimeout time.Duration
+}
+
+func (r *DBReplica) GetDBHost() string     { return r.Host }
+func (r *DBReplica) GetDBPort() string     { return r.Port }
+func (r *DBReplica) GetDBUser() string     { return r.User }
+func (r *DBReplica) GetDBPassword() string { return r.Password }
+func (r *DBReplica) GetDBName() string     { return r.Name }
+func (r *DBReplica) GetDBSSLMode() string  { return r.SSLMode }
+
+func (r *DBReplica) GetDBStatementTimeout() time.Duration { return r.StatementTimeout }
+
+// GetDBReplica возвращает параметры реплики для чтения с подставленными значениями
+// основной БД; false, если реплика не настроена
+func (c *Config) GetDBReplica() (*DBReplica, bool) {
+	if c.DBReplicaHost == "" {
+		return nil, false
+	}
+	return &DBReplica{
+		Host:     c.DBReplicaHost,
+		Port:     withDefault(c.DBReplicaPort, c.DBPort),
+		User:     withDefault(c.DBReplicaUser, c.DBUser),
+		Password: withDefault(c.DBReplicaPassword, c.DBPassword),
+		Name:     withDefault(c.DBReplicaName, c.DBName),
+		SSLMode:  withDefault(c.DBReplicaSSLMode, c.DBSSLMode),
+
+		StatementTimeout: c.GetDBStatementTimeout(),
+	}, true
+}
+
+// Logger interface methods
+func (c *Config) GetEnvironment() string { return c.Environment }
+func (c *Config) GetLogLevel() string    { return c.LogLevel }
+func (c *Config) GetLogFile() string     { return c.LogFile }
+func (c *Config) GetLogMaxSizeMB() int   { return c.LogMaxSizeMB }
+func (c *Config) GetLogMaxBackups() int  { return c.LogMaxBackups }
+func (c *Config) GetLogMaxAgeDays() int  { return c.LogMaxAgeDays }
+func (c *Config) GetLogHTTPBodies() bool { return c.LogHTTPBodies }
 
 // getEnv получает переменную окружения с значением по умолчанию
 func getEnv(key, defaultValue string) string {
@@ -65,3 +671,131 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt получает целочисленную переменную окружения с значением по умолчанию
+func getEnvInt(key string, defaultValue int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s должен быть целым числом, получено %q", key, raw)
+	}
+	return value, nil
+}
+
+// getEnvBool получает логическую переменную окружения с значением по умолчанию
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s должен быть true или false, получено %q", key, raw)
+	}
+	return value, nil
+}
+
+// sourceDefault источник значения по умолчанию
+const sourceDefault = "значение по умолчанию"
+
+// getEnvOrFile получает значение с приоритетом: переменная key, затем содержимое
+// файла из переменной key_FILE (секреты Docker/Kubernetes), затем defaultValue.
+// Вторым значением возвращает источник. Отсутствующий или пустой файл — ошибка.
+func getEnvOrFile(key, defaultValue string) (string, string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, "переменная " + key, nil
+	}
+
+	fileKey := key + "_FILE"
+	path := os.Getenv(fileKey)
+	if path == "" {
+		return defaultValue, sourceDefault, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: чтение файла %s: %w", fileKey, path, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", "", fmt.Errorf("%s: файл %s пуст", fileKey, path)
+	}
+	return value, fmt.Sprintf("файл %s (%s)", path, fileKey), nil
+}
+
+// readConfigFile читает YAML или JSON файл конфигурации. Неизвестные ключи считаются ошибкой.
+func readConfigFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("чтение файла конфигурации: %w", err)
+	}
+
+	// JSON является подмножеством YAML, поэтому оба формата разбираются одним парсером
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("разбор файла конфигурации %s: %w", path, err)
+	}
+	return nil
+}
+
+// withDefault возвращает value или defaultValue, если value пусто
+func withDefault(value, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// withDefaultInt возвращает value или defaultValue, если value не задано
+func withDefaultInt(value, defaultValue int) int {
+	if value != 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// splitList разбирает список через запятую, пропуская пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// contains проверяет наличие значения в списке
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isHeaderName проверяет, что name — имя HTTP заголовка: латинские буквы, цифры и дефисы
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeBasePath приводит префикс к виду "/hr": с ведущим и без завершающего слеша.
+// Пустой префикс и "/" означают монтирование в корень.
+func normalizeBasePath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
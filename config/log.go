@@ -0,0 +1,92 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// maskedValue пишется в лог вместо заданного секрета; незаданный секрет остается пустым,
+// чтобы было видно, что он не подхвачен
+const maskedValue = "***"
+
+// secretMarkers части имен полей с секретами, которые нельзя писать в лог
+var secretMarkers = []string{"password", "secret", "token"}
+
+// LogEffectiveConfig пишет в лог (info) действующие параметры после применения файла,
+// переменных окружения и значений по умолчанию. Поля называются ключами YAML; пароли
+// и другие секреты заменяются маской, в DATABASE_URL скрывается пароль
+func (c *Config) LogEffectiveConfig(logger *zap.Logger) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	fields := make([]zap.Field, 0, t.NumField()+2)
+	fields = append(fields,
+		zap.String("config_file", c.path),
+		zap.String("db_password_source", c.dbPasswordSource),
+	)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := configKey(field)
+		value := v.Field(i).Interface()
+
+		switch s, _ := value.(string); {
+		case isSecret(name):
+			if s != "" {
+				value = maskedValue
+			}
+		case name == "database_url" && s != "":
+			value = redactURL(s)
+		}
+		fields = append(fields, zap.Any(name, value))
+	}
+
+	logger.Info("действующая конфигурация", fields...)
+}
+
+// configKey имя параметра в логе: ключ YAML, а для полей без него (ErrorDetails) —
+// имя поля в snake_case
+func configKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name != "" && name != "-" {
+		return name
+	}
+
+	var b strings.Builder
+	for i, r := range field.Name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isSecret содержит ли параметр секрет
+func isSecret(name string) bool {
+	for _, marker := range secretMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL скрывает пароль в строке подключения (заменяется на xxxxx); нераспознанная
+// строка скрывается целиком
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return maskedValue
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"sync/atomic"
+)
+
+// RuntimeConfig параметры, которые меняются без перезапуска (POST /api/admin/config/reload).
+// Остальные параметры Config (БД, адреса, TLS) применяются только при запуске
+type RuntimeConfig struct {
+	LogLevel           string   `json:"log_level"`
+	LogHTTPBodies      bool     `json:"log_http_bodies"`
+	CORSAllowedOrigins []string `json:"cors_allowed_origins"`
+	CORSMaxAge         int      `json:"cors_max_age"`
+	CORSExposeHeaders  []string `json:"cors_expose_headers"`
+}
+
+// Runtime возвращает параметры конфигурации, меняющиеся без перезапуска
+func (c *Config) Runtime() *RuntimeConfig {
+	return &RuntimeConfig{
+		LogLevel:           c.LogLevel,
+		LogHTTPBodies:      c.LogHTTPBodies,
+		CORSAllowedOrigins: c.CORSAllowedOrigins,
+		CORSMaxAge:         c.CORSMaxAge,
+		CORSExposeHeaders:  c.CORSExposeHeaders,
+	}
+}
+
+// Runtime действующая RuntimeConfig. Middleware читают ее на каждом запросе через Get,
+// Reload заменяет значение целиком, поэтому запрос видит либо старую, либо новую версию
+type Runtime struct {
+	path    string
+	current atomic.Pointer[RuntimeConfig]
+}
+
+// NewRuntime создает Runtime с параметрами cfg; Reload перечитывает тот же файл, что и cfg
+func NewRuntime(cfg *Config) *Runtime {
+	r := &Runtime{path: cfg.path}
+	r.current.Store(cfg.Runtime())
+	return r
+}
+
+// Get возвращает действующие параметры; результат нельзя изменять
+func (r *Runtime) Get() *RuntimeConfig {
+	return r.current.Load()
+}
+
+// Reload перечитывает файл конфигурации и переменные окружения. Если новая конфигурация
+// некорректна, остаются прежние параметры, а ошибка описывает причину
+func (r *Runtime) Reload() (*RuntimeConfig, error) {
+	cfg, err := LoadConfig(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateConfig(); err != nil {
+		return nil, err
+	}
+
+	runtime := cfg.Runtime()
+	r.current.Store(runtime)
+	return runtime, nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Отметки посещаемости сотрудника за день
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceSick    = "sick"
+)
+
+// AttendanceStatuses допустимые отметки посещаемости
+var AttendanceStatuses = []string{AttendancePresent, AttendanceAbsent, AttendanceSick}
+
+// ValidAttendanceStatus проверяет, что status — одна из AttendanceStatuses
+func ValidAttendanceStatus(status string) bool {
+	for _, s := range AttendanceStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Attendance отметка посещаемости сотрудника; у сотрудника одна отметка на дату
+type Attendance struct {
+	EmployeeID int64
+	// Date день отметки (полночь UTC)
+	Date      time.Time
+	Status    string
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CheckInRequest запрос отметки за сегодня (POST /api/employees/{id}/attendance).
+// Без status сотрудник отмечается присутствующим
+type CheckInRequest struct {
+	XMLName xml.Name `json:"-" xml:"check_in"`
+	Status  string   `json:"status" xml:"status"`
+	Note    string   `json:"note" xml:"note"`
+}
+
+// AttendanceResponse отметка посещаемости в ответе API
+type AttendanceResponse struct {
+	XMLName    xml.Name  `json:"-" xml:"attendance"`
+	EmployeeID int64     `json:"employee_id" xml:"employee_id"`
+	Date       string    `json:"date" xml:"date"`
+	Status     string    `json:"status" xml:"status"`
+	Note       string    `json:"note,omitempty" xml:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// AttendanceSummary количество отметок каждого вида за день
+type AttendanceSummary struct {
+	Date    time.Time
+	Present int64
+	Absent  int64
+	Sick    int64
+}
+
+// Total количество отмеченных сотрудников за день
+func (s *AttendanceSummary) Total() int64 {
+	return s.Present + s.Absent + s.Sick
+}
+
+// AttendanceSummaryResponse сводка посещаемости за день (GET /api/attendance/summary)
+type AttendanceSummaryResponse struct {
+	XMLName xml.Name `json:"-" xml:"attendance_summary"`
+	Date    string   `json:"date" xml:"date"`
+	Present int64    `json:"present" xml:"present"`
+	Absent  int64    `json:"absent" xml:"absent"`
+	Sick    int64    `json:"sick" xml:"sick"`
+	Total   int64    `json:"total" xml:"total"`
+}
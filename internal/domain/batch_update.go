@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"encoding/json"
+	"encoding/xml"
+)
+
+// BatchUpdateItem сотрудник в пакетном обновлении: ID и новые имя, телефон и город
+type BatchUpdateItem struct {
+	ID    int64  `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Phone string `json:"phone" xml:"phone"`
+	City  string `json:"city" xml:"city"`
+}
+
+// BatchUpdateRequest тело пакетного обновления (PUT /api/employees/bulk): в JSON —
+// массив сотрудников, в XML — <employees><employee>...</employee></employees>
+type BatchUpdateRequest struct {
+	XMLName   xml.Name          `json:"-" xml:"employees"`
+	Employees []BatchUpdateItem `xml:"employee"`
+}
+
+// UnmarshalJSON разбирает массив сотрудников
+func (r *BatchUpdateRequest) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Employees)
+}
+
+// Результат сотрудника в пакетном обновлении
+const (
+	BatchUpdated         = "updated"
+	BatchNotFound        = "not_found"
+	BatchValidationError = "validation_error"
+	// BatchSkipped корректный сотрудник не обновлен: пакет отменен из-за ошибок (strict)
+	BatchSkipped = "skipped"
+)
+
+// BatchUpdateResult результат обновления одного сотрудника; Errors — только при validation_error
+type BatchUpdateResult struct {
+	ID     int64        `json:"id" xml:"id"`
+	Status string       `json:"status" xml:"status"`
+	Errors []FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// BatchUpdateResponse отчет пакетного обновления; Results в порядке запроса.
+// Aborted — при strict пакет с ошибками отменен целиком
+type BatchUpdateResponse struct {
+	XMLName  xml.Name            `json:"-" xml:"batch_update"`
+	Updated  int                 `json:"updated" xml:"updated"`
+	NotFound int                 `json:"not_found" xml:"not_found"`
+	Failed   int                 `json:"failed" xml:"failed"`
+	Aborted  bool                `json:"aborted,omitempty" xml:"aborted,omitempty"`
+	Results  []BatchUpdateResult `json:"results" xml:"results>result"`
+}
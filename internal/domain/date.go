@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// DateLayout формат дат без времени в API: дата рождения, дата приема на работу
+const DateLayout = "2006-01-02"
+
+// ParseDate разбирает дату в формате "YYYY-MM-DD" (полночь UTC). Пустая строка —
+// дата не указана, nil без ошибки. Несуществующая дата ("2023-02-29") — ошибка
+func ParseDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// FormatDate форматирует дату как "YYYY-MM-DD"; nil — пустая строка
+func FormatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(DateLayout)
+}
+
+// Виды годовщин сотрудника
+const (
+	AnniversaryBirthday = "birthday"
+	AnniversaryHire     = "hire"
+)
+
+// Anniversary ближайшая годовщина сотрудника: день рождения или годовщина приема на работу
+type Anniversary struct {
+	Employee *Employee
+	Kind     string
+	// Date день годовщины; у родившихся 29 февраля в невисокосный год — 28 февраля
+	Date time.Time
+	// Years сколько лет исполняется или сколько лет стажа в этот день
+	Years int
+}
+
+// AnniversaryResponse годовщина сотрудника (GET /api/employees/anniversaries)
+type AnniversaryResponse struct {
+	XMLName  xml.Name         `json:"-" xml:"anniversary"`
+	Employee EmployeeResponse `json:"employee" xml:"employee"`
+	Kind     string           `json:"kind" xml:"kind"`
+	Date     string           `json:"date" xml:"date"`
+	Years    int              `json:"years" xml:"years"`
+}
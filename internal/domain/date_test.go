@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string // пусто — nil
+		wantErr bool
+	}{
+		{in: ""},
+		{in: "1990-01-05", want: "1990-01-05"},
+		// 29 февраля допустимо только в високосный год
+		{in: "2000-02-29", want: "2000-02-29"},
+		{in: "2024-02-29", want: "2024-02-29"},
+		{in: "2023-02-29", wantErr: true},
+		{in: "1900-02-29", wantErr: true},
+		{in: "1990-04-31", wantErr: true},
+		{in: "1990-13-01", wantErr: true},
+		{in: "1990-1-5", wantErr: true},
+		{in: "05.01.1990", wantErr: true},
+		{in: "1990-01-05T00:00:00Z", wantErr: true},
+		{in: " 1990-01-05", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDate(%q): expected error, got %v", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", tt.in, err)
+			continue
+		}
+		if FormatDate(got) != tt.want {
+			t.Errorf("ParseDate(%q) = %q, want %q", tt.in, FormatDate(got), tt.want)
+		}
+		if got != nil && (got.Location() != time.UTC || got.Hour() != 0) {
+			t.Errorf("ParseDate(%q) = %v, want midnight UTC", tt.in, got)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	if got := FormatDate(nil); got != "" {
+		t.Fatalf("FormatDate(nil) = %q, want empty", got)
+	}
+
+	// время и часовой пояс отбрасываются: дата берется в поясе значения
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	date := time.Date(2000, 2, 29, 23, 30, 0, 0, almaty)
+	if got := FormatDate(&date); got != "2000-02-29" {
+		t.Fatalf("FormatDate = %q, want 2000-02-29", got)
+	}
+}
+
+func TestEmployeeDiff_Dates(t *testing.T) {
+	birth, _ := ParseDate("2000-02-29")
+	before := &Employee{Name: "Alice"}
+	after := &Employee{Name: "Alice", BirthDate: birth}
+
+	got := before.Diff(after)
+	if len(got) != 1 || got["birth_date"] != (FieldChange{Old: "", New: "2000-02-29"}) {
+		t.Fatalf("unexpected diff: %v", got)
+	}
+	if before.Equal(after) {
+		t.Fatal("employees with different birth dates must not be equal")
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MergeRequest запрос слияния дубликата (POST /api/employees/{id}/merge): сотрудник
+// SourceID объединяется с сотрудником из пути и удаляется
+type MergeRequest struct {
+	XMLName  xml.Name `json:"-" xml:"merge"`
+	SourceID int64    `json:"source_id" xml:"source_id"`
+	// FillMissing переносит из источника поля, не заполненные у сотрудника из пути
+	FillMissing bool `json:"fill_missing" xml:"fill_missing"`
+}
+
+// MergeResult итог слияния: сотрудник после слияния, удаленный источник и
+// перенесенные из него поля (имена полей в JSON)
+type MergeResult struct {
+	Employee     *Employee
+	SourceID     int64
+	CopiedFields []string
+}
+
+// MergeResponse ответ на слияние
+type MergeResponse struct {
+	XMLName      xml.Name          `json:"-" xml:"merge"`
+	Employee     *EmployeeResponse `json:"employee" xml:"employee"`
+	SourceID     int64             `json:"source_id" xml:"source_id"`
+	CopiedFields []string          `json:"copied_fields" xml:"copied_fields>field"`
+}
+
+// EmployeeMerge запись журнала слияний: источник в том виде, в котором он был удален,
+// сотрудник, в которого он влит, и кто выполнил слияние
+type EmployeeMerge struct {
+	SourceID     int64
+	TargetID     int64
+	Source       *Employee
+	CopiedFields []string
+	MergedBy     string
+	MergedAt     time.Time
+}
+
+// Причины, по которым пара сотрудников считается возможными дубликатами
+const (
+	// DuplicatePhonePrefix телефоны совпадают без последней цифры
+	DuplicatePhonePrefix = "phone_prefix"
+	// DuplicateNameCity совпадают имя и город без учета регистра
+	DuplicateNameCity = "name_city"
+)
+
+// DuplicatePair пара возможных дубликатов; First старше (меньший ID)
+type DuplicatePair struct {
+	First   *Employee
+	Second  *Employee
+	Reasons []string
+}
+
+// DuplicatePairResponse пара возможных дубликатов (GET /api/employees/duplicates)
+type DuplicatePairResponse struct {
+	XMLName   xml.Name            `json:"-" xml:"pair"`
+	Employees []*EmployeeResponse `json:"employees" xml:"employees>employee"`
+	Reasons   []string            `json:"reasons" xml:"reasons>reason"`
+}
@@ -1,33 +1,364 @@
 package domain
 
+import (
+	"encoding/xml"
+	"time"
+)
+
 // Employee модель сотрудника
 type Employee struct {
-	ID    int    `json:"id" db:"id"`
-	Name  string `json:"name" db:"name"`
-	Phone string `json:"phone" db:"phone"`
-	City  string `json:"city" db:"city"`
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Phone     string    `json:"phone" db:"phone"`
+	City      string    `json:"city" db:"city"`
+	Status    string    `json:"status,omitempty" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// CreatedBy и UpdatedBy — кто создал и кто последним изменил запись
+	CreatedBy string `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy string `json:"updated_by,omitempty" db:"updated_by"`
+	// Version версия записи, растет при каждом изменении. При обновлении — ожидаемая
+	// версия; 0 — без проверки
+	Version int `json:"version,omitempty" db:"version"`
+	// BirthDate и HireDate даты рождения и приема на работу; nil — не указаны
+	BirthDate *time.Time `json:"birth_date,omitempty" db:"birth_date"`
+	HireDate  *time.Time `json:"hire_date,omitempty" db:"hire_date"`
+}
+
+// FieldChange изменение поля сотрудника: прежнее и новое значение
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// employeeFields поля данных сотрудника, которые сравнивают Equal и Diff; ID, время
+// и авторы изменений к данным не относятся
+func (e *Employee) employeeFields() [][2]string {
+	if e == nil {
+		e = &Employee{}
+	}
+	return [][2]string{
+		{"name", e.Name},
+		{"phone", e.Phone},
+		{"city", e.City},
+		{"status", e.Status},
+		{"birth_date", FormatDate(e.BirthDate)},
+		{"hire_date", FormatDate(e.HireDate)},
+	}
+}
+
+// Equal сообщает, совпадают ли данные сотрудников: имя, телефон, город, статус и даты.
+// ID, время и авторы изменений не сравниваются; nil равен только nil
+func (e *Employee) Equal(other *Employee) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	return len(e.Diff(other)) == 0
+}
+
+// Diff возвращает изменившиеся при переходе от e к other поля (ключи — имена полей в JSON:
+// name, phone, city, status, birth_date, hire_date). nil считается сотрудником с пустыми полями, поэтому
+// Diff от nil перечисляет все заполненные поля нового сотрудника
+func (e *Employee) Diff(other *Employee) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	before, after := e.employeeFields(), other.employeeFields()
+	for i := range before {
+		if before[i][1] != after[i][1] {
+			changes[before[i][0]] = FieldChange{Old: before[i][1], New: after[i][1]}
+		}
+	}
+	return changes
+}
+
+// Статусы сотрудника. Уволенных не удаляют, а переводят в inactive
+const (
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+	StatusOnLeave  = "on_leave"
+)
+
+// EmployeeStatuses допустимые статусы сотрудника
+var EmployeeStatuses = []string{StatusActive, StatusInactive, StatusOnLeave}
+
+// ValidStatus проверяет, что status — один из EmployeeStatuses
+func ValidStatus(status string) bool {
+	for _, s := range EmployeeStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// EmployeeVersion версия записи сотрудника, действовавшая в [ValidFrom, ValidTo).
+// У текущей версии ValidTo пуст
+type EmployeeVersion struct {
+	XMLName    xml.Name   `json:"-" xml:"version"`
+	EmployeeID int64      `json:"employee_id" xml:"employee_id"`
+	Name       string     `json:"name" xml:"name"`
+	Phone      string     `json:"phone" xml:"phone"`
+	City       string     `json:"city" xml:"city"`
+	Status     string     `json:"status" xml:"status"`
+	ValidFrom  time.Time  `json:"valid_from" xml:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to,omitempty" xml:"valid_to,omitempty"`
 }
 
+// EmployeeEvent изменение сотрудника для подписчиков GET /api/employees/events
+type EmployeeEvent struct {
+	Op string `json:"op"`
+	ID int64  `json:"id,omitempty"`
+}
+
+// Виды EmployeeEvent. Мягкое удаление приходит как delete; resync — события могли
+// потеряться (переподключение к БД), клиенту нужно перечитать данные
+const (
+	EventInsert = "insert"
+	EventUpdate = "update"
+	EventDelete = "delete"
+	EventResync = "resync"
+)
+
 // DTOs для API
 type CreateEmployeeRequest struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-	City  string `json:"city"`
+	XMLName xml.Name `json:"-" xml:"employee"`
+	Name    string   `json:"name" xml:"name"`
+	Phone   string   `json:"phone" xml:"phone"`
+	City    string   `json:"city" xml:"city"`
+	// Status по умолчанию active
+	Status string `json:"status,omitempty" xml:"status,omitempty"`
+	// BirthDate и HireDate в формате YYYY-MM-DD, необязательны
+	BirthDate string `json:"birth_date,omitempty" xml:"birth_date,omitempty"`
+	HireDate  string `json:"hire_date,omitempty" xml:"hire_date,omitempty"`
 }
 
 type UpdateEmployeeRequest struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-	City  string `json:"city"`
+	XMLName xml.Name `json:"-" xml:"employee"`
+	Name    string   `json:"name" xml:"name"`
+	Phone   string   `json:"phone" xml:"phone"`
+	City    string   `json:"city" xml:"city"`
+	// Status пустой — статус не меняется
+	Status string `json:"status,omitempty" xml:"status,omitempty"`
+	// BirthDate и HireDate в формате YYYY-MM-DD; пустые — даты не меняются
+	BirthDate string `json:"birth_date,omitempty" xml:"birth_date,omitempty"`
+	HireDate  string `json:"hire_date,omitempty" xml:"hire_date,omitempty"`
+	// Version версия, которую клиент прочитал; если запись с тех пор изменилась —
+	// 409 CONFLICT. 0 — без проверки (можно передать и заголовком If-Match)
+	Version int `json:"version,omitempty" xml:"version,omitempty"`
+}
+
+// BatchDeleteRequest запрос пакетного удаления; при DryRun ничего не удаляется
+type BatchDeleteRequest struct {
+	XMLName xml.Name `json:"-" xml:"batch_delete"`
+	IDs     []int64  `json:"ids" xml:"ids>id"`
+	DryRun  bool     `json:"dry_run" xml:"dry_run"`
+}
+
+// BatchDeleteResponse отчет пакетного удаления; при DryRun Deleted — кого бы удалили
+type BatchDeleteResponse struct {
+	XMLName  xml.Name `json:"-" xml:"batch_delete"`
+	Deleted  []int64  `json:"deleted" xml:"deleted>id"`
+	NotFound []int64  `json:"not_found" xml:"not_found>id"`
+	DryRun   bool     `json:"dry_run" xml:"dry_run"`
 }
 
 type EmployeeResponse struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-	City  string `json:"city"`
+	XMLName   xml.Name `json:"-" xml:"employee"`
+	ID        int64    `json:"id" xml:"id"`
+	Name      string   `json:"name" xml:"name"`
+	Phone     string   `json:"phone" xml:"phone"`
+	City      string   `json:"city" xml:"city"`
+	Status    string   `json:"status,omitempty" xml:"status,omitempty"`
+	CreatedBy string   `json:"created_by,omitempty" xml:"created_by,omitempty"`
+	UpdatedBy string   `json:"updated_by,omitempty" xml:"updated_by,omitempty"`
+	Version   int      `json:"version,omitempty" xml:"version,omitempty"`
+	BirthDate string   `json:"birth_date,omitempty" xml:"birth_date,omitempty"`
+	HireDate  string   `json:"hire_date,omitempty" xml:"hire_date,omitempty"`
 }
 
+// SearchResultResponse результат поиска с местом совпадения для подсветки
+// (/api/employees/search?highlight=true). MatchStart и MatchEnd — позиции в символах
+// значения поля MatchedField, MatchEnd не включается
+type SearchResultResponse struct {
+	XMLName      xml.Name         `json:"-" xml:"result"`
+	Employee     EmployeeResponse `json:"employee" xml:"employee"`
+	MatchedField string           `json:"matched_field" xml:"matched_field"`
+	MatchStart   int              `json:"match_start" xml:"match_start"`
+	MatchEnd     int              `json:"match_end" xml:"match_end"`
+}
+
+// CityCount город и количество его сотрудников (GET /api/cities). City — самое частое
+// написание названия среди сотрудников города
+type CityCount struct {
+	XMLName       xml.Name `json:"-" xml:"city"`
+	City          string   `json:"city" xml:"name"`
+	EmployeeCount int      `json:"employee_count" xml:"employee_count"`
+}
+
+// EmployeeDetailsResponse сотрудник со связанными данными, запрошенными через ?expand=;
+// не запрошенные данные в ответ не попадают
+type EmployeeDetailsResponse struct {
+	XMLName xml.Name `json:"-" xml:"employee"`
+	*EmployeeResponse
+	History []*EmployeeVersion `json:"history,omitempty" xml:"history>version,omitempty"`
+}
+
+// ListResponse конверт для списков: {"data": [...], "meta": {...}}; в XML элементы
+// списка идут прямо в <response> перед <meta>
+type ListResponse struct {
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Data    interface{} `json:"data" xml:"data"`
+	Meta    ListMeta    `json:"meta" xml:"meta"`
+}
+
+// ListMeta метаданные списка
+type ListMeta struct {
+	Count int `json:"count" xml:"count"`
+	// Total, Limit и Offset заполняются только при постраничной выдаче
+	Total  int64 `json:"total,omitempty" xml:"total,omitempty"`
+	Limit  int   `json:"limit,omitempty" xml:"limit,omitempty"`
+	Offset int   `json:"offset,omitempty" xml:"offset,omitempty"`
+}
+
+// Коды ошибок API: клиенты различают ошибки по code, а не по тексту message
+const (
+	CodeValidation   = "VALIDATION_ERROR"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeInternal     = "INTERNAL"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeRateLimited  = "RATE_LIMITED"
+	// CodeNotAcceptable ответ нельзя отдать ни в одном формате из заголовка Accept
+	CodeNotAcceptable = "NOT_ACCEPTABLE"
+	// CodeUnsupportedMediaType тело запроса не в JSON и не в XML
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	// CodeMaintenance включен режим обслуживания: изменения временно недоступны
+	CodeMaintenance = "MAINTENANCE"
+	// CodeQuotaExceeded создание сотрудников превысило бы квоту тарифа (MAX_EMPLOYEES)
+	CodeQuotaExceeded = "QUOTA_EXCEEDED"
+	// CodePreconditionRequired изменение без ожидаемой версии записи (поле version или If-Match)
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	// CodeOverloaded сервер обрабатывает MAX_CONCURRENT_REQUESTS запросов и новые не принимает
+	CodeOverloaded = "OVERLOADED"
+)
+
+// ErrorResponse ответ с ошибкой. Details зависит от кода: []FieldError для
+// VALIDATION_ERROR, ConflictDetails для CONFLICT. Error и Errors дублируют
+// Message и ошибки полей для клиентов, написанных до появления кодов
 type ErrorResponse struct {
-	Error string `json:"error"`
+	XMLName xml.Name     `json:"-" xml:"error"`
+	Code    string       `json:"code" xml:"code"`
+	Message string       `json:"message" xml:"message"`
+	Details interface{}  `json:"details,omitempty" xml:"details,omitempty"`
+	Error   string       `json:"error" xml:"error"`
+	Errors  []FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+	// Detail текст внутренней ошибки; только для INTERNAL и только вне production
+	Detail string `json:"detail,omitempty" xml:"detail,omitempty"`
+}
+
+// ConflictDetails подробности CONFLICT: поле конфликтует с существующим сотрудником.
+// При конфликте версий (Field "version") Version — текущая версия сотрудника EmployeeID
+type ConflictDetails struct {
+	Field      string `json:"field" xml:"field"`
+	EmployeeID int64  `json:"employee_id,omitempty" xml:"employee_id,omitempty"`
+	Version    int    `json:"version,omitempty" xml:"version,omitempty"`
+}
+
+// QuotaDetails подробности QUOTA_EXCEEDED: квота, текущее количество сотрудников
+// и сколько пытались добавить
+type QuotaDetails struct {
+	Limit     int   `json:"limit" xml:"limit"`
+	Count     int64 `json:"count" xml:"count"`
+	Requested int   `json:"requested" xml:"requested"`
+}
+
+// FieldError ошибка валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+	// Key и Args ключ сообщения для перевода; в ответ не попадают
+	Key  string        `json:"-"`
+	Args []interface{} `json:"-"`
+}
+
+// ValidationResponse результат проверки данных сотрудника без сохранения
+type ValidationResponse struct {
+	XMLName xml.Name     `json:"-" xml:"validation"`
+	Valid   bool         `json:"valid" xml:"valid"`
+	Errors  []FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// PhoneCheckResponse результат проверки занятости телефона
+type PhoneCheckResponse struct {
+	XMLName  xml.Name         `json:"-" xml:"phone_check"`
+	Exists   bool             `json:"exists" xml:"exists"`
+	Employee *EmployeeSummary `json:"employee,omitempty" xml:"employee,omitempty"`
+}
+
+// EmployeeSummary краткие данные сотрудника без контактов; имя замаскировано
+type EmployeeSummary struct {
+	ID   int64  `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+// CountResponse количество сотрудников
+type CountResponse struct {
+	XMLName xml.Name `json:"-" xml:"employees"`
+	Count   int64    `json:"count" xml:"count"`
+}
+
+// EmployeeFilter параметры фильтрации списков, поиска, подсчета и выгрузки сотрудников;
+// пустое поле не фильтрует
+type EmployeeFilter struct {
+	City   string
+	Status string
+	// Query поисковый запрос: каждое слово должно найтись в имени, телефоне или городе
+	Query string
+	// CreatedFrom и CreatedTo границы даты создания, включительно
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Sort поле сортировки списков и поиска (EmployeeSorts); пустое — по имени,
+	// а в поиске по релевантности. Подсчет и выгрузка его не учитывают
+	Sort string
+	// Desc сортировка по убыванию
+	Desc bool
+}
+
+// Поля сортировки сотрудников (?sort=)
+const (
+	SortName      = "name"
+	SortID        = "id"
+	SortCity      = "city"
+	SortCreatedAt = "created_at"
+)
+
+// EmployeeSorts допустимые поля сортировки
+var EmployeeSorts = []string{SortName, SortID, SortCity, SortCreatedAt}
+
+// ImportSummary итог импорта сотрудников
+type ImportSummary struct {
+	XMLName  xml.Name          `json:"-" xml:"import"`
+	Imported int               `json:"imported" xml:"imported"`
+	Failed   int               `json:"failed" xml:"failed"`
+	Aborted  bool              `json:"aborted,omitempty" xml:"aborted,omitempty"`
+	Errors   []ImportLineError `json:"errors" xml:"errors>error"`
+	// DryRun импорт только проверен: Imported — сколько строк было бы импортировано
+	DryRun bool `json:"dry_run,omitempty" xml:"dry_run,omitempty"`
+	// Rows строки файла в том виде, в каком были бы сохранены; только при DryRun
+	Rows []ImportRow `json:"rows,omitempty" xml:"rows>row"`
+}
+
+// ImportRow строка отчета dry run: нормализованные значения записи и ошибка, если есть
+type ImportRow struct {
+	Line  int    `json:"line" xml:"line"`
+	ID    int64  `json:"id,omitempty" xml:"id,omitempty"`
+	Name  string `json:"name" xml:"name"`
+	Phone string `json:"phone" xml:"phone"`
+	City  string `json:"city" xml:"city"`
+	Error string `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// ImportLineError ошибка импорта конкретной строки
+type ImportLineError struct {
+	Line  int    `json:"line" xml:"line"`
+	Error string `json:"error" xml:"error"`
 }
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEmployeeDiff(t *testing.T) {
+	base := &Employee{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty", Status: StatusActive,
+		UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), UpdatedBy: "hr"}
+
+	tests := []struct {
+		name   string
+		modify func(e *Employee)
+		want   map[string]FieldChange
+	}{
+		{
+			name: "identical data",
+			// ID, время и автор изменения не считаются изменением данных
+			modify: func(e *Employee) { e.ID, e.UpdatedAt, e.UpdatedBy = 2, time.Now(), "admin" },
+			want:   map[string]FieldChange{},
+		},
+		{
+			name:   "single field",
+			modify: func(e *Employee) { e.City = "Astana" },
+			want:   map[string]FieldChange{"city": {Old: "Almaty", New: "Astana"}},
+		},
+		{
+			name: "multiple fields",
+			modify: func(e *Employee) {
+				e.Name, e.Phone, e.Status = "Alice Smith", "+77010000002", StatusOnLeave
+			},
+			want: map[string]FieldChange{
+				"name":   {Old: "Alice", New: "Alice Smith"},
+				"phone":  {Old: "+77010000001", New: "+77010000002"},
+				"status": {Old: StatusActive, New: StatusOnLeave},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := *base
+			tt.modify(&other)
+
+			if got := base.Diff(&other); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff = %v, want %v", got, tt.want)
+			}
+			if equal := base.Equal(&other); equal != (len(tt.want) == 0) {
+				t.Fatalf("Equal = %v with diff %v", equal, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmployeeDiff_Nil(t *testing.T) {
+	created := &Employee{Name: "Bob", Phone: "+77010000003", City: "Almaty"}
+
+	want := map[string]FieldChange{
+		"name":  {New: "Bob"},
+		"phone": {New: "+77010000003"},
+		"city":  {New: "Almaty"},
+	}
+	var none *Employee
+	if got := none.Diff(created); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff from nil = %v, want %v", got, want)
+	}
+	if created.Equal(nil) || none.Equal(created) || !none.Equal(nil) {
+		t.Fatalf("nil must be equal only to nil")
+	}
+}
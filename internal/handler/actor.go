@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"employer/internal/i18n"
+	"employer/internal/service"
+)
+
+// ActorHeader заголовок с именем пользователя, от которого выполняется запрос.
+// Имя записывается в created_by и updated_by; без заголовка автор — service.SystemActor
+const ActorHeader = "X-Actor"
+
+// actorMiddleware кладет автора изменений из X-Actor в контекст запроса.
+// Слишком длинное имя или имя с управляющими символами — 400
+func (h *EmployeeHandler) actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := strings.TrimSpace(r.Header.Get(ActorHeader))
+		if actor == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if utf8.RuneCountInString(actor) > service.MaxActorLength || strings.IndexFunc(actor, unicode.IsControl) >= 0 {
+			h.writeError(w, r, badRequest(ActorHeader, i18n.RequestActor, service.MaxActorLength))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(service.WithActor(r.Context(), actor)))
+	})
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"employer/config"
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Purger окончательная очистка мягко удаленных сотрудников
+type Purger interface {
+	Purge(ctx context.Context) (int64, error)
+}
+
+// ConfigReloader действующая конфигурация, меняющаяся без перезапуска
+type ConfigReloader interface {
+	Get() *config.RuntimeConfig
+	Reload() (*config.RuntimeConfig, error)
+}
+
+// AdminHandler обработчик служебных операций /api/admin
+type AdminHandler struct {
+	purger      Purger
+	token       string
+	config      ConfigReloader
+	maintenance *Maintenance
+	logger      *zap.Logger
+}
+
+// MaintenanceRequest тело POST /api/admin/maintenance
+type MaintenanceRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Message string `json:"message"`
+}
+
+// PurgeResponse результат ручной очистки
+type PurgeResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+// NewAdminHandler создает обработчик служебных операций, доступных с заголовком
+// Authorization: Bearer token. Без token маршруты /api/admin не регистрируются вовсе,
+// без purger (очистка отключена) не регистрируется /api/admin/purge
+func NewAdminHandler(purger Purger, token string, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		purger: purger,
+		token:  token,
+		logger: logger,
+	}
+}
+
+// SetConfig включает просмотр и перезагрузку конфигурации (/api/admin/config)
+func (h *AdminHandler) SetConfig(config ConfigReloader) {
+	h.config = config
+}
+
+// SetMaintenance включает управление режимом обслуживания (/api/admin/maintenance)
+func (h *AdminHandler) SetMaintenance(maintenance *Maintenance) {
+	h.maintenance = maintenance
+}
+
+// Purge запускает очистку мягко удаленных сотрудников, не дожидаясь таймера.
+// Если очистку сейчас выполняет другой экземпляр — 409 CONFLICT
+// POST /api/admin/purge
+func (h *AdminHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	locale := i18n.FromContext(r.Context())
+
+	purged, err := h.purger.Purge(r.Context())
+	if errors.Is(err, repository.ErrPurgeLocked) {
+		w.Header().Set("Content-Language", locale)
+		h.writeJSONResponse(w, http.StatusConflict,
+			newErrorResponse(domain.CodeConflict, i18n.T(locale, i18n.ConflictPurgeInProgress), nil))
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Language", locale)
+		status, resp := errorResponse(locale, err)
+		h.logger.Error("ошибка ручной очистки", zap.Error(err))
+		h.writeJSONResponse(w, status, resp)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, &PurgeResponse{Purged: purged})
+}
+
+// Config возвращает действующие параметры, меняющиеся без перезапуска. Секретов
+// (паролей БД) среди них нет
+// GET /api/admin/config
+func (h *AdminHandler) Config(w http.ResponseWriter, r *http.Request) {
+	h.writeJSONResponse(w, http.StatusOK, h.config.Get())
+}
+
+// ReloadConfig перечитывает файл конфигурации и переменные окружения и применяет
+// параметры, меняющиеся без перезапуска. Некорректная конфигурация не применяется — 400
+// POST /api/admin/config/reload
+func (h *AdminHandler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	runtime, err := h.config.Reload()
+	if err != nil {
+		locale := i18n.FromContext(r.Context())
+		w.Header().Set("Content-Language", locale)
+		fields := []domain.FieldError{{Field: "config", Message: err.Error()}}
+		h.writeJSONResponse(w, http.StatusBadRequest, validationResponse(i18n.T(locale, i18n.ConfigInvalid), fields))
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, runtime)
+}
+
+// MaintenanceStatus возвращает состояние режима обслуживания
+// GET /api/admin/maintenance
+func (h *AdminHandler) MaintenanceStatus(w http.ResponseWriter, r *http.Request) {
+	state := h.maintenance.State()
+	h.writeJSONResponse(w, http.StatusOK, &state)
+}
+
+// ToggleMaintenance включает или выключает режим обслуживания:
+// {"enabled": true, "message": "..."}; message необязателен
+// POST /api/admin/maintenance
+func (h *AdminHandler) ToggleMaintenance(w http.ResponseWriter, r *http.Request) {
+	locale := i18n.FromContext(r.Context())
+
+	var req MaintenanceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	switch {
+	case err != nil:
+		err = jsonBodyError(err)
+	case req.Enabled == nil:
+		err = badRequest("enabled", i18n.RequestMaintenanceEnabled)
+	case utf8.RuneCountInString(req.Message) > MaxMaintenanceMessageLength:
+		err = badRequest("message", i18n.RequestMaintenanceMessage, MaxMaintenanceMessageLength)
+	}
+	if err != nil {
+		w.Header().Set("Content-Language", locale)
+		status, resp := errorResponse(locale, err)
+		h.writeJSONResponse(w, status, resp)
+		return
+	}
+
+	state, err := h.maintenance.Set(*req.Enabled, strings.TrimSpace(req.Message))
+	if err != nil {
+		w.Header().Set("Content-Language", locale)
+		status, resp := errorResponse(locale, err)
+		h.logger.Error("ошибка изменения режима обслуживания", zap.Error(err))
+		h.writeJSONResponse(w, status, resp)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, &state)
+}
+
+// Authorize пропускает запрос только с токеном администратора в заголовке
+// Authorization: Bearer; иначе 401 UNAUTHORIZED
+func (h *AdminHandler) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		// сравнение за постоянное время, чтобы токен нельзя было подобрать по времени ответа
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(h.token)) != 1 {
+			locale := i18n.FromContext(r.Context())
+			h.logger.Warn("отклонен запрос без токена администратора",
+				zap.String("method", r.Method), zap.String("path", r.URL.Path), zap.String("client_ip", clientIP(r)))
+			w.Header().Set("Content-Language", locale)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			h.writeJSONResponse(w, http.StatusUnauthorized,
+				newErrorResponse(domain.CodeUnauthorized, i18n.T(locale, i18n.AdminUnauthorized), nil))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RegisterRoutes регистрирует маршруты /api/admin; без токена администратора
+// служебные операции недоступны
+func (h *AdminHandler) RegisterRoutes(router *mux.Router) {
+	if h.token == "" {
+		return
+	}
+	admin := router.PathPrefix("/api/admin").Subrouter()
+	admin.Use(i18n.Middleware, h.Authorize)
+	if h.purger != nil {
+		admin.HandleFunc("/purge", h.Purge).Methods("POST")
+	}
+	if h.config != nil {
+		admin.HandleFunc("/config", h.Config).Methods("GET")
+		admin.HandleFunc("/config/reload", h.ReloadConfig).Methods("POST")
+	}
+	if h.maintenance != nil {
+		admin.HandleFunc("/maintenance", h.MaintenanceStatus).Methods("GET")
+		admin.HandleFunc("/maintenance", h.ToggleMaintenance).Methods("POST")
+	}
+}
+
+func (h *AdminHandler) writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
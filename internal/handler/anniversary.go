@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/service"
+)
+
+// GetUpcomingAnniversaries возвращает дни рождения и годовщины приема на работу
+// в ближайшие days дней (по умолчанию 14), ближайшие первыми. Для каждой годовщины —
+// сотрудник, вид (birthday или hire), дата и сколько лет исполняется
+// GET /api/employees/anniversaries?days=14
+func (h *EmployeeHandler) GetUpcomingAnniversaries(w http.ResponseWriter, r *http.Request) {
+	days := service.DefaultAnniversaryDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		var err error
+		if days, err = strconv.Atoi(raw); err != nil {
+			h.writeError(w, r, badRequest("days", i18n.AnniversaryDaysRange, service.MaxAnniversaryDays))
+			return
+		}
+	}
+
+	anniversaries, err := h.service.GetUpcomingAnniversaries(r.Context(), days)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	items := make([]*domain.AnniversaryResponse, len(anniversaries))
+	for i, anniversary := range anniversaries {
+		items[i] = &domain.AnniversaryResponse{
+			Employee: *toEmployeeResponse(anniversary.Employee),
+			Kind:     anniversary.Kind,
+			Date:     domain.FormatDate(&anniversary.Date),
+			Years:    anniversary.Years,
+		}
+	}
+
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: items,
+			Meta: domain.ListMeta{Count: len(items)},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, items)
+}
+
+// parseEmployeeDates разбирает даты рождения и приема из запроса в employee;
+// пустая строка — дата не указана. Ошибка формата — VALIDATION_ERROR поля
+func parseEmployeeDates(employee *domain.Employee, birthDate, hireDate string) error {
+	return employeeDates(employee, birthDate, hireDate).OrNil()
+}
+
+// employeeDates как parseEmployeeDates, но возвращает все ошибки полей
+func employeeDates(employee *domain.Employee, birthDate, hireDate string) *service.ValidationErrors {
+	errs := &service.ValidationErrors{}
+	var err error
+	if employee.BirthDate, err = domain.ParseDate(birthDate); err != nil {
+		errs.Add("birth_date", i18n.DateFormat, "birth_date")
+	}
+	if employee.HireDate, err = domain.ParseDate(hireDate); err != nil {
+		errs.Add("hire_date", i18n.DateFormat, "hire_date")
+	}
+	return errs
+}
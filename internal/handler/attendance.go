@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+)
+
+// CheckIn отмечает сотрудника за сегодня: present, absent или sick (по умолчанию present)
+// с необязательным примечанием. Повторная отметка за день заменяет прежнюю.
+// Отсутствующий сотрудник — 404
+// POST /api/employees/{id}/attendance {"status": "sick", "note": "больничный"}
+func (h *EmployeeHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	var req domain.CheckInRequest
+	// Без тела сотрудник отмечается присутствующим
+	if r.ContentLength != 0 {
+		if err := h.decodeBody(r, &req); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+
+	attendance, err := h.service.CheckIn(r.Context(), id, req.Status, req.Note)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeResponse(w, r, http.StatusOK, toAttendanceResponse(attendance))
+}
+
+// GetEmployeeAttendance получает отметки сотрудника за даты from..to включительно
+// по возрастанию даты; без to — по сегодня, без from — последние 30 дней.
+// Период не длиннее 366 дней
+// GET /api/employees/{id}/attendance?from=2026-10-01&to=2026-10-31
+func (h *EmployeeHandler) GetEmployeeAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	from, err := parseDateParam(r, "from")
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	to, err := parseDateParam(r, "to")
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	records, err := h.service.GetEmployeeAttendance(r.Context(), id, from, to)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	items := make([]*domain.AttendanceResponse, len(records))
+	for i, attendance := range records {
+		items[i] = toAttendanceResponse(attendance)
+	}
+
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: items,
+			Meta: domain.ListMeta{Count: len(items)},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, items)
+}
+
+// GetAttendanceSummary количество отметок present, absent и sick за день (по умолчанию
+// сегодня). Сотрудники без отметки не учитываются
+// GET /api/attendance/summary?date=2026-10-05
+func (h *EmployeeHandler) GetAttendanceSummary(w http.ResponseWriter, r *http.Request) {
+	date, err := parseDateParam(r, "date")
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	summary, err := h.service.GetAttendanceSummary(r.Context(), date)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeResponse(w, r, http.StatusOK, &domain.AttendanceSummaryResponse{
+		Date:    domain.FormatDate(&summary.Date),
+		Present: summary.Present,
+		Absent:  summary.Absent,
+		Sick:    summary.Sick,
+		Total:   summary.Total(),
+	})
+}
+
+// parseDateParam разбирает дату YYYY-MM-DD из параметра name; без параметра — нулевое время
+func parseDateParam(r *http.Request, name string) (time.Time, error) {
+	date, err := domain.ParseDate(r.URL.Query().Get(name))
+	if err != nil {
+		return time.Time{}, badRequest(name, i18n.DateFormat, name)
+	}
+	if date == nil {
+		return time.Time{}, nil
+	}
+	return *date, nil
+}
+
+// toAttendanceResponse преобразует отметку посещаемости в DTO ответа
+func toAttendanceResponse(attendance *domain.Attendance) *domain.AttendanceResponse {
+	return &domain.AttendanceResponse{
+		EmployeeID: attendance.EmployeeID,
+		Date:       domain.FormatDate(&attendance.Date),
+		Status:     attendance.Status,
+		Note:       attendance.Note,
+		CreatedAt:  attendance.CreatedAt,
+		UpdatedAt:  attendance.UpdatedAt,
+	}
+}
@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+)
+
+func TestCheckIn(t *testing.T) {
+	var gotID int64
+	var gotStatus, gotNote string
+	date := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	svc := &mockService{
+		CheckInFn: func(ctx context.Context, id int64, status, note string) (*domain.Attendance, error) {
+			if id == 99 {
+				return nil, &repository.NotFoundError{Entity: "employee", ID: id}
+			}
+			gotID, gotStatus, gotNote = id, status, note
+			return &domain.Attendance{EmployeeID: id, Date: date, Status: domain.AttendanceSick, Note: note}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees/3/attendance", bytes.NewBufferString(`{"status": "sick", "note": "больничный"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if gotID != 3 || gotStatus != "sick" || gotNote != "больничный" {
+		t.Fatalf("unexpected check-in id=%d status=%q note=%q", gotID, gotStatus, gotNote)
+	}
+	var resp domain.AttendanceResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.EmployeeID != 3 || resp.Date != "2026-10-05" || resp.Status != domain.AttendanceSick {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// без тела статус выбирает сервис
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees/3/attendance", nil))
+	if rr.Code != http.StatusOK || gotStatus != "" {
+		t.Fatalf("expected 200 with empty status, got %d (%q): %s", rr.Code, gotStatus, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees/99/attendance", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetEmployeeAttendance(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	called := false
+	svc := &mockService{
+		AttendanceFn: func(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+			called = true
+			gotFrom, gotTo = from, to
+			return []*domain.Attendance{
+				{EmployeeID: id, Date: from, Status: domain.AttendancePresent},
+				{EmployeeID: id, Date: to, Status: domain.AttendanceAbsent, Note: "без предупреждения"},
+			}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/3/attendance?from=2026-10-01&to=2026-10-31", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if domain.FormatDate(&gotFrom) != "2026-10-01" || domain.FormatDate(&gotTo) != "2026-10-31" {
+		t.Fatalf("unexpected range %v..%v", gotFrom, gotTo)
+	}
+	var items []domain.AttendanceResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &items); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(items) != 2 || items[1].Date != "2026-10-31" || items[1].Note != "без предупреждения" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+
+	// без границ их выбирает сервис
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/3/attendance", nil))
+	if rr.Code != http.StatusOK || !gotFrom.IsZero() || !gotTo.IsZero() {
+		t.Fatalf("expected zero bounds, got %d %v..%v", rr.Code, gotFrom, gotTo)
+	}
+
+	for _, query := range []string{"from=2026-13-01", "to=yesterday"} {
+		called = false
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/3/attendance?"+query, nil))
+		if rr.Code != http.StatusBadRequest || called {
+			t.Fatalf("%s: expected 400 without service call, got %d: %s", query, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestGetAttendanceSummary(t *testing.T) {
+	var gotDate time.Time
+	svc := &mockService{
+		AttendanceSummaryFn: func(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+			gotDate = date
+			return &domain.AttendanceSummary{Date: date, Present: 12, Absent: 3, Sick: 1}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/attendance/summary?date=2026-10-05", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if domain.FormatDate(&gotDate) != "2026-10-05" {
+		t.Fatalf("unexpected date %v", gotDate)
+	}
+	var resp domain.AttendanceSummaryResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Date != "2026-10-05" || resp.Present != 12 || resp.Absent != 3 || resp.Sick != 1 || resp.Total != 16 {
+		t.Fatalf("unexpected summary %+v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/attendance/summary?date=05.10.2026", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
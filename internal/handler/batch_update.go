@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+)
+
+// UpdateEmployees обновляет имя, телефон и город нескольких сотрудников одной транзакцией
+// и сообщает результат по каждому: updated, not_found или validation_error с ошибками полей.
+// Ненайденные и ошибочные сотрудники пропускаются; со strict=true любая ошибка отменяет
+// пакет целиком (422 с тем же отчетом, корректные сотрудники — skipped)
+// PUT /api/employees/bulk?strict=true [{"id": 1, "name": "...", "phone": "...", "city": "..."}]
+func (h *EmployeeHandler) UpdateEmployees(w http.ResponseWriter, r *http.Request) {
+	strict, ok := parseBoolParam(r.URL.Query().Get("strict"))
+	if !ok {
+		h.writeError(w, r, badRequest("strict", i18n.RequestStrict))
+		return
+	}
+
+	var req domain.BatchUpdateRequest
+	if err := h.decodeBody(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	result, err := h.service.UpdateEmployees(r.Context(), req.Employees, strict)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	locale := i18n.FromContext(r.Context())
+	for i := range result.Results {
+		if len(result.Results[i].Errors) > 0 {
+			result.Results[i].Errors = localizeFields(locale, result.Results[i].Errors)
+		}
+	}
+
+	status := http.StatusOK
+	if result.Aborted {
+		status = http.StatusUnprocessableEntity
+	}
+	h.writeResponse(w, r, status, result)
+}
@@ -0,0 +1,132 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+)
+
+func TestUpdateEmployees_Bulk(t *testing.T) {
+	var gotItems []domain.BatchUpdateItem
+	var gotStrict bool
+	svc := &mockService{
+		BatchUpdateFn: func(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error) {
+			gotItems, gotStrict = items, strict
+			return &domain.BatchUpdateResponse{Updated: 1, NotFound: 1, Failed: 1, Results: []domain.BatchUpdateResult{
+				{ID: 1, Status: domain.BatchUpdated},
+				{ID: 99, Status: domain.BatchNotFound},
+				{ID: 2, Status: domain.BatchValidationError, Errors: []domain.FieldError{{Field: "name", Key: i18n.NameRequired}}},
+			}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `[{"id": 1, "name": "Alice", "phone": "+77010000001", "city": "Almaty"},
+		{"id": 99, "name": "Bob", "phone": "+77010000002", "city": "Astana"},
+		{"id": 2, "name": "", "phone": "+77010000003", "city": "Almaty"}]`
+	req := httptest.NewRequest(http.MethodPut, "/api/employees/bulk", bytes.NewBufferString(body))
+	req.Header.Set("Accept-Language", "en")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(gotItems) != 3 || gotItems[1].ID != 99 || gotItems[0].Phone != "+77010000001" || gotStrict {
+		t.Fatalf("unexpected call: items %+v, strict %v", gotItems, gotStrict)
+	}
+	var resp domain.BatchUpdateResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Updated != 1 || resp.NotFound != 1 || len(resp.Results) != 3 || resp.Results[1].Status != domain.BatchNotFound {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+	if msg := resp.Results[2].Errors[0].Message; msg != i18n.T("en", i18n.NameRequired) {
+		t.Fatalf("expected localized field error, got %q", msg)
+	}
+}
+
+func TestUpdateEmployees_BulkStrictAborted(t *testing.T) {
+	svc := &mockService{
+		BatchUpdateFn: func(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error) {
+			if !strict {
+				t.Errorf("expected strict")
+			}
+			return &domain.BatchUpdateResponse{NotFound: 1, Aborted: true, Results: []domain.BatchUpdateResult{
+				{ID: 1, Status: domain.BatchSkipped},
+				{ID: 99, Status: domain.BatchNotFound},
+			}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `[{"id": 1, "name": "Alice", "phone": "+77010000001", "city": "Almaty"},
+		{"id": 99, "name": "Bob", "phone": "+77010000002", "city": "Astana"}]`
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/employees/bulk?strict=true", bytes.NewBufferString(body)))
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp domain.BatchUpdateResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Aborted || resp.Results[0].Status != domain.BatchSkipped {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+}
+
+func TestUpdateEmployees_BulkXML(t *testing.T) {
+	var gotItems []domain.BatchUpdateItem
+	svc := &mockService{
+		BatchUpdateFn: func(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error) {
+			gotItems = items
+			return &domain.BatchUpdateResponse{Updated: 1, Results: []domain.BatchUpdateResult{{ID: 1, Status: domain.BatchUpdated}}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `<employees><employee><id>1</id><name>Alice</name><phone>+77010000001</phone><city>Almaty</city></employee></employees>`
+	req := httptest.NewRequest(http.MethodPut, "/api/employees/bulk", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Accept", "application/xml")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(gotItems) != 1 || gotItems[0].ID != 1 || gotItems[0].City != "Almaty" {
+		t.Fatalf("unexpected items: %+v", gotItems)
+	}
+	var resp domain.BatchUpdateResponse
+	if err := xml.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.Updated != 1 || resp.Results[0].Status != domain.BatchUpdated {
+		t.Fatalf("unexpected XML response (%v): %s", err, rr.Body.String())
+	}
+}
+
+func TestUpdateEmployees_BulkBadRequest(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	for _, tt := range []struct{ path, body string }{
+		{"/api/employees/bulk?strict=maybe", `[]`},
+		{"/api/employees/bulk", `{"id": 1}`},
+		{"/api/employees/bulk", `[{"id": "one"}]`},
+	} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, tt.path, bytes.NewBufferString(tt.body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s %s: expected 400, got %d", tt.path, tt.body, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeValidation)
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"employer/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// GetCityCounts возвращает города с количеством сотрудников по убыванию количества;
+// без сотрудников — пустой список
+// GET /api/cities
+func (h *EmployeeHandler) GetCityCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.service.GetCityCounts(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: counts,
+			Meta: domain.ListMeta{Count: len(counts)},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, counts)
+}
+
+// GetCityEmployees отдает страницу сотрудников города с заголовками Link и X-Total-Count;
+// город сравнивается без учета регистра
+// GET /api/cities/{city}/employees?limit=20&offset=0
+func (h *EmployeeHandler) GetCityEmployees(w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	params, err := ParseListParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	limit, offset := params.pageLimit(h.pageDefaultLimit), params.Offset
+	if limit > h.pageMaxLimit {
+		limit = h.pageMaxLimit
+	}
+
+	employees, total, err := h.service.GetCityEmployees(r.Context(), mux.Vars(r)["city"], limit, offset)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writePageResponse(w, r, toEmployeeResponses(employees), fields, total, limit, offset)
+}
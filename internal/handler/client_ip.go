@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// ClientIPMiddleware определяет IP клиента (см. ClientIP) и кладет его в контекст запроса
+// для обработчиков, лога запросов и ограничения частоты запросов
+func ClientIPMiddleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := ClientIP(r, trusted)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// ClientIPFromContext возвращает IP клиента, определенный ClientIPMiddleware, или пустую строку
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// ClientIP определяет IP клиента. Заголовки прокси учитываются, только если соединение пришло
+// с адреса из trusted: X-Forwarded-For просматривается справа налево, доверенные прокси
+// пропускаются, и клиентом считается первый адрес вне trusted. Если все адреса цепочки доверенные —
+// самый левый. Без X-Forwarded-For берется X-Real-IP. В остальных случаях — адрес соединения
+func ClientIP(r *http.Request, trusted []netip.Prefix) string {
+	remote, ok := parseIP(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !isTrusted(remote, trusted) {
+		return remote.String()
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		// client — ближайший проверенный адрес цепочки: сначала адрес соединения
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip, ok := parseIP(strings.TrimSpace(hops[i]))
+			if !ok {
+				// цепочку дальше подделать мог кто угодно
+				break
+			}
+			client = ip
+			if !isTrusted(ip, trusted) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if ip, ok := parseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ok {
+		return ip.String()
+	}
+	return remote.String()
+}
+
+// clientIP IP клиента из контекста или, без ClientIPMiddleware, адрес соединения
+func clientIP(r *http.Request) string {
+	if ip := ClientIPFromContext(r.Context()); ip != "" {
+		return ip
+	}
+	return r.RemoteAddr
+}
+
+// parseIP разбирает адрес с портом или без ("10.0.0.1:5000", "[::1]:80", "::1");
+// IPv4 в виде IPv6 (::ffff:10.0.0.1) приводится к IPv4, зона отбрасывается
+func parseIP(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap().WithZone(""), true
+}
+
+// isTrusted проверяет, что ip входит в один из диапазонов trusted
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"employer/internal/handler"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name     string
+		remote   string
+		forwards []string
+		realIP   string
+		want     string
+	}{
+		{"no proxy", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"untrusted peer spoofs XFF", "203.0.113.7:5000", []string{"1.1.1.1"}, "", "203.0.113.7"},
+		{"untrusted peer spoofs X-Real-IP", "203.0.113.7:5000", nil, "1.1.1.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:443", []string{"198.51.100.4"}, "", "198.51.100.4"},
+		{"chained trusted proxies", "10.0.0.2:443", []string{"198.51.100.4, 10.1.1.1, 10.2.2.2"}, "", "198.51.100.4"},
+		{"client prepends fake hops", "10.0.0.2:443", []string{"1.1.1.1, 2.2.2.2, 198.51.100.4, 10.1.1.1"}, "", "198.51.100.4"},
+		{"several XFF headers", "10.0.0.2:443", []string{"1.1.1.1", "198.51.100.4"}, "", "198.51.100.4"},
+		{"all hops trusted", "10.0.0.2:443", []string{"10.3.3.3, 10.1.1.1"}, "", "10.3.3.3"},
+		{"garbage in chain", "10.0.0.2:443", []string{"1.1.1.1, not-an-ip, 10.1.1.1"}, "", "10.1.1.1"},
+		{"hop with port", "10.0.0.2:443", []string{"198.51.100.4:61000"}, "", "198.51.100.4"},
+		{"X-Real-IP from trusted proxy", "10.0.0.2:443", nil, "198.51.100.4", "198.51.100.4"},
+		{"invalid X-Real-IP", "10.0.0.2:443", nil, "unknown", "10.0.0.2"},
+		{"IPv6 peer", "[2001:db8::1]:5000", []string{"1.1.1.1"}, "", "2001:db8::1"},
+		{"trusted IPv6 proxy", "[fd00::5]:443", []string{"2001:db8::9, fd00::7"}, "", "2001:db8::9"},
+		{"IPv6 hop in brackets", "[fd00::5]:443", []string{"[2001:db8::9]:1234"}, "", "2001:db8::9"},
+		{"IPv4-mapped proxy", "[::ffff:10.0.0.2]:443", []string{"198.51.100.4"}, "", "198.51.100.4"},
+		{"unix socket", "@", []string{"1.1.1.1"}, "", "@"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+		r.RemoteAddr = tt.remote
+		for _, value := range tt.forwards {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if tt.realIP != "" {
+			r.Header.Set("X-Real-IP", tt.realIP)
+		}
+
+		if got := handler.ClientIP(r, trusted); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestClientIPMiddleware(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = handler.ClientIPFromContext(r.Context())
+	})
+	trusted := []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.4")
+	handler.ClientIPMiddleware(trusted)(next).ServeHTTP(httptest.NewRecorder(), r)
+	if got != "198.51.100.4" {
+		t.Fatalf("expected client IP in context, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// OverloadRetryAfter через сколько клиенту повторить запрос, отклоненный из-за
+// превышения MAX_CONCURRENT_REQUESTS (заголовок Retry-After)
+const OverloadRetryAfter = time.Second
+
+// ConcurrencyLimit ограничивает число одновременно обрабатываемых запросов значением limit.
+// Запрос сверх лимита не ждет в очереди, а сразу получает 503 OVERLOADED с заголовком
+// Retry-After. Пробы состояния, метрики и поток событий /api/employees/events не
+// учитываются: пробы должны отвечать и под нагрузкой, а поток событий занимал бы место
+// все время подписки. limit 0 отключает ограничение
+func ConcurrencyLimit(limit int, basePath string, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		slots := make(chan struct{}, limit)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isUnlimitedPath(r.URL.Path, basePath) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				next.ServeHTTP(w, r)
+				return
+			default:
+			}
+
+			logger.Warn("запрос отклонен: превышен лимит одновременных запросов",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("limit", limit),
+			)
+			locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+			w.Header().Add("Vary", "Accept-Language")
+			w.Header().Set("Content-Language", locale)
+			w.Header().Set("Retry-After", strconv.Itoa(int(OverloadRetryAfter.Seconds())))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if err := json.NewEncoder(w).Encode(newErrorResponse(domain.CodeOverloaded, i18n.T(locale, i18n.Overloaded), nil)); err != nil {
+				logger.Error("failed to encode response", zap.Error(err))
+			}
+		})
+	}
+}
+
+// isUnlimitedPath не учитывается ли путь в ограничении одновременных запросов
+func isUnlimitedPath(path, basePath string) bool {
+	switch path {
+	case basePath + "/health", basePath + "/livez", basePath + "/readyz", basePath + "/metrics",
+		basePath + "/api/employees/events":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/handler"
+
+	"go.uber.org/zap"
+)
+
+func TestConcurrencyLimit_ShedsOverLimit(t *testing.T) {
+	const limit, total = 2, 6
+	entered := make(chan struct{}, total)
+	release := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	h := handler.ConcurrencyLimit(limit, "", zap.NewNop())(slow)
+
+	results := make(chan *httptest.ResponseRecorder, total)
+	for i := 0; i < total; i++ {
+		go func() {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees", nil))
+			results <- rr
+		}()
+	}
+
+	// пока limit запросов заняты, остальные отклоняются сразу, не дожидаясь освобождения
+	for i := 0; i < total-limit; i++ {
+		rr := <-results
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", rr.Code)
+		}
+		if rr.Header().Get("Retry-After") != "1" {
+			t.Fatalf("expected Retry-After 1, got %q", rr.Header().Get("Retry-After"))
+		}
+		assertErrorCode(t, rr, domain.CodeOverloaded)
+	}
+	for i := 0; i < limit; i++ {
+		<-entered
+	}
+
+	// пробы состояния не учитываются в лимите
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected /health to bypass the limit, got %d", rr.Code)
+	}
+
+	close(release)
+	for i := 0; i < limit; i++ {
+		if rr := <-results; rr.Code != http.StatusOK {
+			t.Fatalf("expected 200 for admitted request, got %d", rr.Code)
+		}
+	}
+
+	// освободившиеся места снова доступны
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 after release, got %d", rr.Code)
+	}
+}
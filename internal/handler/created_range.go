@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"employer/internal/i18n"
+)
+
+// getEmployeesCreatedBetween отдает сотрудников, созданных в интервале
+// ?created_from=...&created_to=..., по возрастанию даты создания. Границы включаются
+func (h *EmployeeHandler) getEmployeesCreatedBetween(w http.ResponseWriter, r *http.Request, fields []employeeField) {
+	query := r.URL.Query()
+	for _, param := range []string{"ids", "limit", "offset", "city", "status", "sort", "order"} {
+		if query.Has(param) {
+			h.writeError(w, r, badRequest("created_from", i18n.RequestCreatedCombined))
+			return
+		}
+	}
+	if query.Get("created_from") == "" || query.Get("created_to") == "" {
+		h.writeError(w, r, badRequest("created_from", i18n.RequestCreatedBounds))
+		return
+	}
+
+	from, err := parseCreatedBound(query.Get("created_from"), false)
+	if err != nil {
+		h.writeError(w, r, badRequest("created_from", i18n.RequestCreatedDate, "created_from"))
+		return
+	}
+	to, err := parseCreatedBound(query.Get("created_to"), true)
+	if err != nil {
+		h.writeError(w, r, badRequest("created_to", i18n.RequestCreatedDate, "created_to"))
+		return
+	}
+	if from.After(to) {
+		h.writeError(w, r, badRequest("created_from", i18n.RequestCreatedOrder))
+		return
+	}
+
+	employees, err := h.service.GetEmployeesCreatedBetween(r.Context(), from, to)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeListResponse(w, r, toEmployeeResponses(employees), fields)
+}
+
+// parseCreatedBound разбирает границу интервала создания: дату YYYY-MM-DD или момент в RFC 3339.
+// Дата означает сутки по UTC: для верхней границы (end) берется их последняя микросекунда,
+// чтобы сотрудники, созданные в этот день, попали в выборку
+func parseCreatedBound(raw string, end bool) (time.Time, error) {
+	if t, err := time.Parse(asOfDateLayout, raw); err == nil {
+		if end {
+			return t.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
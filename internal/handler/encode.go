@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// responseBuffers буферы, в которые ответ кодируется целиком перед отправкой;
+// переиспользуются между запросами
+var responseBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer буфер больше этого размера после ответа не возвращается в пул,
+// чтобы редкий большой ответ не удерживал память
+const maxPooledBuffer = 1 << 20
+
+// writeEncoded кодирует data функцией encode в буфер и только после этого пишет статус,
+// Content-Type, Content-Length и тело. Если кодирование не удалось, клиент получает
+// 500 INTERNAL в JSON вместо обрезанного ответа со статусом status
+func (h *EmployeeHandler) writeEncoded(w http.ResponseWriter, status int, contentType string, data interface{}, encode func(io.Writer, interface{}) error) {
+	buf := responseBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			responseBuffers.Put(buf)
+		}
+	}()
+
+	if err := encode(buf, data); err != nil {
+		h.logger.Error("ошибка кодирования ответа", zap.Error(err),
+			zap.String("payload_type", fmt.Sprintf("%T", data)), zap.Int("status", status))
+
+		// язык ответа с ошибкой уже выбран, если ошибку кодировали; иначе — по умолчанию
+		locale := w.Header().Get("Content-Language")
+		if locale == "" {
+			locale = i18n.Default
+		}
+		// заголовки успешного ответа к ошибке не относятся
+		w.Header().Del("ETag")
+		w.Header().Del("Last-Modified")
+
+		buf.Reset()
+		status, contentType = http.StatusInternalServerError, "application/json"
+		_ = encodeJSON(buf, newErrorResponse(domain.CodeInternal, i18n.T(locale, i18n.Internal), nil))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		h.logger.Warn("ошибка отправки ответа", zap.Error(err))
+	}
+}
+
+// encodeJSON кодирует data в JSON
+func encodeJSON(w io.Writer, data interface{}) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// encodeXML кодирует data в XML с заголовком <?xml ...?>
+func encodeXML(w io.Writer, data interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"employer/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+func TestWriteJSONResponse_EncodeFailure(t *testing.T) {
+	h := NewEmployeeHandler(nil, zap.NewNop())
+
+	for _, data := range []interface{}{math.Inf(1), map[string]interface{}{"ch": make(chan int)}} {
+		rr := httptest.NewRecorder()
+		rr.Header().Set("ETag", `"1"`)
+		h.writeJSONResponse(rr, http.StatusOK, data)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("%T: expected 500, got %d", data, rr.Code)
+		}
+		if rr.Header().Get("ETag") != "" {
+			t.Fatalf("%T: expected ETag to be dropped", data)
+		}
+		var resp domain.ErrorResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%T: expected JSON error body, got %q", data, rr.Body.String())
+		}
+		if resp.Code != domain.CodeInternal {
+			t.Fatalf("%T: expected code %s, got %s", data, domain.CodeInternal, resp.Code)
+		}
+	}
+}
+
+func TestWriteResponse_ContentLength(t *testing.T) {
+	h := NewEmployeeHandler(nil, zap.NewNop())
+	employee := &domain.EmployeeResponse{ID: 1, Name: "John", Phone: "+77011234567", City: "Almaty"}
+
+	for format, accept := range map[string]string{formatJSON: "application/json", formatXML: "application/xml"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/employees/1", nil)
+		r = r.WithContext(context.WithValue(r.Context(), formatKey{}, format))
+		rr := httptest.NewRecorder()
+		h.writeResponse(rr, r, http.StatusOK, employee)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", accept, rr.Code)
+		}
+		if !strings.HasPrefix(rr.Header().Get("Content-Type"), accept) {
+			t.Fatalf("%s: got Content-Type %q", accept, rr.Header().Get("Content-Type"))
+		}
+		if got := rr.Header().Get("Content-Length"); got != strconv.Itoa(rr.Body.Len()) {
+			t.Fatalf("%s: Content-Length %s, body %d bytes", accept, got, rr.Body.Len())
+		}
+	}
+}
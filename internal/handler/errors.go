@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+	"employer/internal/service"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// writeError отвечает ошибкой err; статус и код ответа определяются типом ошибки,
+// сообщение переводится на язык запроса (см. i18n.Middleware).
+// Ошибки VALIDATION_ERROR делятся по статусу: 400 — запрос не удалось разобрать
+// (некорректный JSON, параметры query и пути, см. badRequest), 422 — запрос разобран,
+// но данные не прошли проверку сервиса (service.ValidationError и ValidationErrors).
+// Неизвестные ошибки отдаются как INTERNAL и логируются; текст ошибки попадает
+// в ответ, только если включен SetErrorDetails
+func (h *EmployeeHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	locale := i18n.FromContext(r.Context())
+	w.Header().Set("Content-Language", locale)
+	status, resp := errorResponse(locale, err)
+	if status == http.StatusInternalServerError {
+		h.logger.Error("внутренняя ошибка сервера", zap.Error(err))
+		if h.errorDetails {
+			resp.Detail = err.Error()
+		}
+	}
+	h.writeResponse(w, r, status, resp)
+}
+
+// SetErrorDetails включает текст внутренней ошибки в ответах 500 (поле detail);
+// в production должно быть выключено
+func (h *EmployeeHandler) SetErrorDetails(enabled bool) {
+	h.errorDetails = enabled
+}
+
+// errorResponse сопоставляет ошибке HTTP статус и тело ответа на языке locale
+func errorResponse(locale string, err error) (int, *domain.ErrorResponse) {
+	var (
+		request     *requestError
+		validation  *service.ValidationError
+		validations *service.ValidationErrors
+		conflict    *service.ConflictError
+		quota       *service.QuotaError
+		exists      *repository.AlreadyExistsError
+		notFound    *repository.NotFoundError
+		mediaType   *mediaTypeError
+		noVersion   *versionRequiredError
+	)
+
+	switch {
+	case errors.As(err, &request):
+		message := localize(locale, request.Key, request.Message, request.Args)
+		fields := []domain.FieldError{{Field: request.Field, Message: message}}
+		return http.StatusBadRequest, validationResponse(message, fields)
+	case errors.As(err, &validation):
+		message := localize(locale, validation.Key, validation.Message, validation.Args)
+		fields := []domain.FieldError{{Field: validation.Field, Message: message}}
+		return http.StatusUnprocessableEntity, validationResponse(message, fields)
+	case errors.As(err, &validations):
+		fields := localizeFields(locale, validations.FieldErrors())
+		messages := make([]string, len(fields))
+		for i, field := range fields {
+			messages[i] = field.Message
+		}
+		return http.StatusUnprocessableEntity, validationResponse(strings.Join(messages, "; "), fields)
+	case errors.As(err, &conflict):
+		return http.StatusConflict, newErrorResponse(domain.CodeConflict, localize(locale, conflict.Key, conflict.Message, nil),
+			&domain.ConflictDetails{Field: conflict.Field, EmployeeID: conflict.EmployeeID, Version: conflict.Version})
+	case errors.As(err, &quota):
+		return http.StatusForbidden, newErrorResponse(domain.CodeQuotaExceeded, i18n.T(locale, i18n.QuotaExceeded, quota.Limit),
+			&domain.QuotaDetails{Limit: quota.Limit, Count: quota.Count, Requested: quota.Requested})
+	case errors.As(err, &exists):
+		return http.StatusConflict, newErrorResponse(domain.CodeConflict, i18n.T(locale, i18n.ConflictDuplicate),
+			&domain.ConflictDetails{Field: exists.Field})
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, newErrorResponse(domain.CodeNotFound, i18n.T(locale, notFound.MessageKey()), nil)
+	case errors.As(err, &mediaType):
+		return http.StatusUnsupportedMediaType, newErrorResponse(domain.CodeUnsupportedMediaType,
+			i18n.T(locale, i18n.RequestContentType, mediaType.ContentType), nil)
+	case errors.As(err, &noVersion):
+		return http.StatusPreconditionRequired, newErrorResponse(domain.CodePreconditionRequired,
+			i18n.T(locale, i18n.RequestVersionRequired), nil)
+	default:
+		return http.StatusInternalServerError, newErrorResponse(domain.CodeInternal, i18n.T(locale, i18n.Internal), nil)
+	}
+}
+
+// localize переводит сообщение по ключу key; без ключа возвращает message как есть
+func localize(locale, key, message string, args []interface{}) string {
+	if key == "" {
+		return message
+	}
+	return i18n.T(locale, key, args...)
+}
+
+// localizeFields переводит сообщения ошибок полей на язык locale
+func localizeFields(locale string, fields []domain.FieldError) []domain.FieldError {
+	localized := make([]domain.FieldError, len(fields))
+	for i, field := range fields {
+		localized[i] = field
+		localized[i].Message = localize(locale, field.Key, field.Message, field.Args)
+	}
+	return localized
+}
+
+func newErrorResponse(code, message string, details interface{}) *domain.ErrorResponse {
+	return &domain.ErrorResponse{Code: code, Message: message, Details: details, Error: message}
+}
+
+func validationResponse(message string, fields []domain.FieldError) *domain.ErrorResponse {
+	resp := newErrorResponse(domain.CodeValidation, message, fields)
+	resp.Errors = fields
+	return resp
+}
+
+// versionRequiredError обновление без ожидаемой версии сотрудника; отдается как 428
+type versionRequiredError struct{}
+
+func (e *versionRequiredError) Error() string {
+	return "не передана ожидаемая версия сотрудника (поле version или заголовок If-Match)"
+}
+
+// maxID наибольший ID сотрудника. Колонка id имеет тип BIGSERIAL, но ID ограничены
+// 2^53-1, чтобы оставаться точными в JSON для клиентов на JavaScript
+const maxID = 1<<53 - 1
+
+// parseIDParam читает ID сотрудника из пути и проверяет, что он может существовать:
+// 0, отрицательные, слишком большие и не помещающиеся в int64 значения отклоняются
+// с 400 до обращения к БД
+func parseIDParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id < 1 || id > maxID {
+		return 0, badRequest("id", i18n.RequestID, maxID)
+	}
+	return id, nil
+}
+
+// requestError ошибка разбора запроса: некорректное тело или параметр. Отдается как
+// VALIDATION_ERROR со статусом 400; errors.As находит в ней service.ValidationError
+type requestError struct {
+	*service.ValidationError
+}
+
+func (e *requestError) Unwrap() error {
+	return e.ValidationError
+}
+
+// badRequest ошибка некорректного параметра запроса; отдается как VALIDATION_ERROR с 400
+func badRequest(field, key string, args ...interface{}) error {
+	return &requestError{service.NewValidationError(field, key, args...)}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"employer/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// sseHeartbeat период комментариев-пустышек, чтобы прокси не закрывали тихий поток
+const sseHeartbeat = 30 * time.Second
+
+// EventSource источник событий об изменениях сотрудников (см. service.EventBroker)
+type EventSource interface {
+	Subscribe() (<-chan domain.EmployeeEvent, func())
+}
+
+// SetEvents подключает источник событий; без него /api/employees/events не регистрируется
+func (h *EmployeeHandler) SetEvents(events EventSource) {
+	h.events = events
+}
+
+// StreamEvents отдает изменения сотрудников потоком Server-Sent Events, пока клиент
+// не отключится или источник не закроется при остановке сервера
+// GET /api/employees/events
+func (h *EmployeeHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	// Поток живет дольше WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		h.logger.Error("поток событий не поддерживается", zap.Error(err))
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE записывает событие в формате SSE: "event: <op>\ndata: <json>\n\n"
+func writeSSE(w io.Writer, event domain.EmployeeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Op, data)
+	return err
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+)
+
+// expandHistory встраивает в сотрудника все его версии (см. GetEmployeeHistory)
+const expandHistory = "history"
+
+// employeeExpansions связанные данные, которые GET /api/employees/{id} встраивает по ?expand=
+var employeeExpansions = map[string]bool{
+	expandHistory: true,
+}
+
+// parseExpand разбирает ?expand=history,...: значения через запятую, можно несколько
+// параметров. Неизвестное значение — VALIDATION_ERROR со списком поддерживаемых
+func parseExpand(r *http.Request) (map[string]bool, error) {
+	expand := map[string]bool{}
+	for _, raw := range r.URL.Query()["expand"] {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !employeeExpansions[name] {
+				return nil, badRequest("expand", i18n.RequestExpand, supportedExpansions())
+			}
+			expand[name] = true
+		}
+	}
+	return expand, nil
+}
+
+// supportedExpansions список значений expand через запятую для сообщения об ошибке
+func supportedExpansions() string {
+	names := make([]string, 0, len(employeeExpansions))
+	for name := range employeeExpansions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// expandEmployee загружает запрошенные связанные данные сотрудника
+func (h *EmployeeHandler) expandEmployee(r *http.Request, employee *domain.EmployeeResponse, expand map[string]bool) (*domain.EmployeeDetailsResponse, error) {
+	details := &domain.EmployeeDetailsResponse{EmployeeResponse: employee}
+	if expand[expandHistory] {
+		history, err := h.service.GetEmployeeHistory(r.Context(), employee.ID)
+		if err != nil {
+			return nil, err
+		}
+		details.History = history
+	}
+	return details, nil
+}
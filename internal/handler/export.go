@@ -0,0 +1,379 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/service"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	exportFormatCSV   = "csv"
+	exportFormatXLSX  = "xlsx"
+	exportFormatJSONL = "jsonl"
+
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	xlsxSheetName   = "Сотрудники"
+	xlsxMaxColWidth = 60
+	// xlsxTextNumFmt встроенный формат Excel "@" (текст): телефон не превращается
+	// в число и сохраняет ведущие + и нули при редактировании
+	xlsxTextNumFmt = 49
+)
+
+// exportHeader заголовок таблицы выгрузки
+var exportHeader = []string{"ID", "Имя", "Телефон", "Город"}
+
+// ExportEmployees выгружает сотрудников в CSV, XLSX или JSON Lines. Фильтры q, city и status
+// разбираются так же, как у поиска и списка (ParseListParams), поэтому выгружаются ровно
+// найденные сотрудники. Некорректный фильтр — 400 до записи первой строки
+// GET /api/employees/export?format=csv|xlsx|jsonl&q=&city=&status=
+func (h *EmployeeHandler) ExportEmployees(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = exportFormatCSV
+	}
+
+	filter, ok := h.exportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	switch format {
+	case exportFormatCSV:
+		h.exportCSV(w, r, filter)
+	case exportFormatXLSX:
+		h.exportXLSX(w, r, filter)
+	case exportFormatJSONL:
+		h.exportJSONL(w, r, filter)
+	default:
+		h.writeError(w, r, badRequest("format", i18n.RequestExportFormat))
+	}
+}
+
+// ExportEmployeesXLSX выгружает сотрудников в XLSX
+// GET /api/employees/export.xlsx?q=&city=&status=
+func (h *EmployeeHandler) ExportEmployeesXLSX(w http.ResponseWriter, r *http.Request) {
+	if filter, ok := h.exportFilter(w, r); ok {
+		h.exportXLSX(w, r, filter)
+	}
+}
+
+// exportFilter фильтр выгрузки из query; при ошибке пишет ответ и возвращает false
+func (h *EmployeeHandler) exportFilter(w http.ResponseWriter, r *http.Request) (domain.EmployeeFilter, bool) {
+	params, err := ParseListParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return domain.EmployeeFilter{}, false
+	}
+	return params.SearchFilter(), true
+}
+
+// exportCSV потоково пишет сотрудников в CSV. Заголовки ответа отправляются с первой
+// строкой: ошибка до нее (например, некорректный фильтр) отдается обычным ответом
+func (h *EmployeeHandler) exportCSV(w http.ResponseWriter, r *http.Request, filter domain.EmployeeFilter) {
+	cw := csv.NewWriter(w)
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", exportDisposition(exportFormatCSV, filter))
+		return cw.Write(exportHeader)
+	}
+
+	err := h.service.ExportEmployees(r.Context(), filter, func(e *domain.Employee) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return cw.Write(exportRow(e))
+	})
+	if err != nil && !started {
+		h.writeError(w, r, err)
+		return
+	}
+	if !started {
+		err = start()
+	}
+	cw.Flush()
+
+	// Заголовки уже отправлены, поэтому ошибку можно только залогировать
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		h.logger.Error("ошибка выгрузки сотрудников в CSV", zap.Error(err))
+	}
+}
+
+// exportJSONL потоково пишет сотрудников без потерь (с id и временными метками),
+// по одному JSON-объекту на строку. Как и CSV, заголовки отправляются с первой строкой
+func (h *EmployeeHandler) exportJSONL(w http.ResponseWriter, r *http.Request, filter domain.EmployeeFilter) {
+	enc := json.NewEncoder(w)
+	started := false
+	start := func() {
+		started = true
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", exportDisposition(exportFormatJSONL, filter))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err := h.service.ExportEmployees(r.Context(), filter, func(e *domain.Employee) error {
+		if !started {
+			start()
+		}
+		return enc.Encode(e)
+	})
+	if err != nil && !started {
+		h.writeError(w, r, err)
+		return
+	}
+	if !started {
+		start()
+	}
+	if err != nil {
+		h.logger.Error("ошибка выгрузки сотрудников в JSON Lines", zap.Error(err))
+	}
+}
+
+// exportXLSX формирует XLSX через потоковый writer excelize, чтобы память
+// не росла вместе с количеством строк. Ширина колонок должна быть задана до
+// записи первой строки, поэтому выборка читается дважды: сначала для расчета
+// ширины, затем для записи.
+func (h *EmployeeHandler) exportXLSX(w http.ResponseWriter, r *http.Request, filter domain.EmployeeFilter) {
+	widths := make([]int, len(exportHeader))
+	for i, title := range exportHeader {
+		widths[i] = utf8.RuneCountInString(title)
+	}
+
+	err := h.service.ExportEmployees(r.Context(), filter, func(e *domain.Employee) error {
+		for i, value := range exportRow(e) {
+			if n := utf8.RuneCountInString(value); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := h.writeXLSXSheet(r, f, filter, widths); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", xlsxContentType)
+	w.Header().Set("Content-Disposition", exportDisposition(exportFormatXLSX, filter))
+	if _, err := f.WriteTo(w); err != nil {
+		h.logger.Error("ошибка отправки XLSX", zap.Error(err))
+	}
+}
+
+// writeXLSXSheet заполняет лист книги заголовком и строками сотрудников
+func (h *EmployeeHandler) writeXLSXSheet(r *http.Request, f *excelize.File, filter domain.EmployeeFilter, widths []int) error {
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSheetName); err != nil {
+		return fmt.Errorf("переименование листа: %w", err)
+	}
+
+	sw, err := f.NewStreamWriter(xlsxSheetName)
+	if err != nil {
+		return fmt.Errorf("создание потокового writer: %w", err)
+	}
+
+	for i, width := range widths {
+		if width+2 > xlsxMaxColWidth {
+			width = xlsxMaxColWidth - 2
+		}
+		if err := sw.SetColWidth(i+1, i+1, float64(width+2)); err != nil {
+			return fmt.Errorf("установка ширины колонки: %w", err)
+		}
+	}
+
+	boldStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("создание стиля заголовка: %w", err)
+	}
+
+	textStyle, err := f.NewStyle(&excelize.Style{NumFmt: xlsxTextNumFmt})
+	if err != nil {
+		return fmt.Errorf("создание текстового стиля: %w", err)
+	}
+
+	header := make([]interface{}, len(exportHeader))
+	for i, title := range exportHeader {
+		header[i] = excelize.Cell{StyleID: boldStyle, Value: title}
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return fmt.Errorf("запись заголовка: %w", err)
+	}
+
+	rowNum := 2
+	err = h.service.ExportEmployees(r.Context(), filter, func(e *domain.Employee) error {
+		cell, err := excelize.CoordinatesToCellName(1, rowNum)
+		if err != nil {
+			return err
+		}
+		rowNum++
+		return sw.SetRow(cell, []interface{}{
+			e.ID,
+			e.Name,
+			excelize.Cell{StyleID: textStyle, Value: e.Phone},
+			e.City,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("запись строк: %w", err)
+	}
+
+	return sw.Flush()
+}
+
+// ImportEmployees загружает сотрудников из JSON Lines или CSV. mapping — JSON
+// соответствие заголовков CSV полям ({"ФИО":"name"}), dry_run=true только проверяет файл
+// POST /api/employees/import?format=jsonl|csv&mode=reassign|overwrite&strict=true&dry_run=true&mapping={...}
+func (h *EmployeeHandler) ImportEmployees(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	if format == "" {
+		format = exportFormatJSONL
+	}
+	if format != exportFormatJSONL && format != exportFormatCSV {
+		h.writeError(w, r, badRequest("format", i18n.RequestImportFormat))
+		return
+	}
+
+	strict, ok := parseBoolParam(query.Get("strict"))
+	if !ok {
+		h.writeError(w, r, badRequest("strict", i18n.RequestStrict))
+		return
+	}
+	dryRun, ok := parseBoolParam(query.Get("dry_run"))
+	if !ok {
+		h.writeError(w, r, badRequest("dry_run", i18n.RequestDryRun))
+		return
+	}
+
+	var mapping map[string]string
+	if raw := query.Get("mapping"); raw != "" {
+		if format != exportFormatCSV || json.Unmarshal([]byte(raw), &mapping) != nil {
+			h.writeError(w, r, badRequest("mapping", i18n.RequestMapping))
+			return
+		}
+	}
+
+	opts := service.ImportOptions{
+		Mode:   service.ImportMode(strings.ToLower(query.Get("mode"))),
+		Strict: strict,
+		DryRun: dryRun,
+	}
+
+	reader := service.NewJSONLReader(r.Body)
+	if format == exportFormatCSV {
+		var err error
+		if reader, err = service.NewMappedCSVReader(r.Body, mapping); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+
+	summary, err := h.service.ImportEmployees(r.Context(), reader, opts)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if summary.Aborted {
+		status = http.StatusUnprocessableEntity
+	}
+	h.writeResponse(w, r, status, summary)
+}
+
+// parseBoolParam разбирает необязательный логический параметр запроса; пустой — false
+func parseBoolParam(raw string) (value, ok bool) {
+	if raw == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(raw)
+	return value, err == nil
+}
+
+// exportRow возвращает значения строки выгрузки в порядке exportHeader
+func exportRow(e *domain.Employee) []string {
+	return []string{strconv.FormatInt(e.ID, 10), e.Name, e.Phone, e.City}
+}
+
+// exportDisposition формирует Content-Disposition с фильтром и датой в имени файла:
+// employees_almaty_active_2024-06-01.csv (город, статус, строка поиска). Имя с не-ASCII
+// символами передается в filename* (RFC 6266), а в filename — без частей фильтра
+func exportDisposition(format string, filter domain.EmployeeFilter) string {
+	date := time.Now().Format("2006-01-02")
+	parts := []string{"employees"}
+	for _, value := range []string{filter.City, filter.Status, filter.Query} {
+		if slug := exportSlug(value); slug != "" {
+			parts = append(parts, slug)
+		}
+	}
+	name := strings.Join(append(parts, date), "_") + "." + format
+	if isASCII(name) {
+		return fmt.Sprintf(`attachment; filename="%s"`, name)
+	}
+	return fmt.Sprintf(`attachment; filename="employees_%s.%s"; filename*=UTF-8''%s`, date, format, url.PathEscape(name))
+}
+
+// exportSlugMaxLen наибольшая длина части имени файла в символах
+const exportSlugMaxLen = 40
+
+// exportSlug часть имени файла из значения фильтра: буквы и цифры в нижнем регистре,
+// остальные символы подряд заменяются одним "-"
+func exportSlug(value string) string {
+	var b strings.Builder
+	dash := false
+	n := 0
+	for _, r := range strings.ToLower(value) {
+		if n == exportSlugMaxLen {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+				n++
+			}
+			b.WriteRune(r)
+			n++
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// isASCII состоит ли s только из ASCII символов
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+)
+
+// employeeField поле EmployeeResponse, которое можно запросить через ?fields=
+type employeeField struct {
+	name  string
+	value func(e *domain.EmployeeResponse) interface{}
+}
+
+// employeeFieldRegistry поля для ?fields= в порядке вывода; имена совпадают с JSON
+var employeeFieldRegistry = []employeeField{
+	{"id", func(e *domain.EmployeeResponse) interface{} { return e.ID }},
+	{"name", func(e *domain.EmployeeResponse) interface{} { return e.Name }},
+	{"phone", func(e *domain.EmployeeResponse) interface{} { return e.Phone }},
+	{"city", func(e *domain.EmployeeResponse) interface{} { return e.City }},
+	{"status", func(e *domain.EmployeeResponse) interface{} { return e.Status }},
+	{"created_by", func(e *domain.EmployeeResponse) interface{} { return e.CreatedBy }},
+	{"updated_by", func(e *domain.EmployeeResponse) interface{} { return e.UpdatedBy }},
+	{"birth_date", func(e *domain.EmployeeResponse) interface{} { return e.BirthDate }},
+	{"hire_date", func(e *domain.EmployeeResponse) interface{} { return e.HireDate }},
+}
+
+// parseFields разбирает ?fields=id,name. Без параметра возвращает nil — отдаются все поля.
+// Поля выводятся в порядке employeeFieldRegistry; неизвестное поле — VALIDATION_ERROR
+func parseFields(r *http.Request) ([]employeeField, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	requested := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if !knownEmployeeField(name) {
+			return nil, badRequest("fields", i18n.RequestFields, supportedFields())
+		}
+		requested[name] = true
+	}
+
+	fields := make([]employeeField, 0, len(requested))
+	for _, field := range employeeFieldRegistry {
+		if requested[field.name] {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+func knownEmployeeField(name string) bool {
+	for _, field := range employeeFieldRegistry {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// supportedFields список полей через запятую для сообщения об ошибке
+func supportedFields() string {
+	names := make([]string, len(employeeFieldRegistry))
+	for i, field := range employeeFieldRegistry {
+		names[i] = field.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// projectedEmployee сотрудник только с запрошенными полями; кодируется в JSON и XML
+type projectedEmployee struct {
+	employee *domain.EmployeeResponse
+	fields   []employeeField
+}
+
+// projectEmployees оставляет в списке только поля fields; без fields список не меняется
+func projectEmployees(items []*domain.EmployeeResponse, fields []employeeField) interface{} {
+	if fields == nil {
+		return items
+	}
+	projected := make([]*projectedEmployee, len(items))
+	for i, item := range items {
+		projected[i] = &projectedEmployee{employee: item, fields: fields}
+	}
+	return projected
+}
+
+func (p *projectedEmployee) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range p.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		value, err := json.Marshal(field.value(p.employee))
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`"` + field.name + `":`)
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p *projectedEmployee) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "employee"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, field := range p.fields {
+		if err := e.EncodeElement(field.value(p.employee), xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
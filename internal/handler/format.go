@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Форматы ответов API сотрудников
+const (
+	formatJSON = "json"
+	formatXML  = "xml"
+)
+
+// mediaFormats форматы ответа по типам из заголовка Accept; JSON — формат по умолчанию
+var mediaFormats = map[string]string{
+	"*/*":              formatJSON,
+	"application/*":    formatJSON,
+	"application/json": formatJSON,
+	"application/xml":  formatXML,
+	"text/xml":         formatXML,
+}
+
+// ownFormatRoutes имена маршрутов со своим форматом ответа, для которых Accept не
+// проверяется: выгрузки (CSV, NDJSON и XLSX по параметру format) и поток событий SSE
+var ownFormatRoutes = map[string]bool{
+	"export":      true,
+	"export.xlsx": true,
+	"events":      true,
+}
+
+type formatKey struct{}
+
+// formatMiddleware выбирает формат ответа по заголовку Accept и кладет его в контекст.
+// Если ни один тип из Accept не поддерживается — 406 с ошибкой в JSON
+func (h *EmployeeHandler) formatMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && ownFormatRoutes[route.GetName()] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept")
+		format, ok := negotiateFormat(r.Header.Get("Accept"))
+		if !ok {
+			locale := i18n.FromContext(r.Context())
+			w.Header().Set("Content-Language", locale)
+			h.writeJSONResponse(w, http.StatusNotAcceptable,
+				newErrorResponse(domain.CodeNotAcceptable, i18n.T(locale, i18n.RequestAccept), nil))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), formatKey{}, format)))
+	})
+}
+
+// negotiateFormat выбирает формат с наибольшим q среди поддерживаемых типов Accept;
+// при равных q побеждает указанный раньше. Пустой Accept — JSON
+func negotiateFormat(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, true
+	}
+
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if format, ok := mediaFormats[mediaType]; ok && q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best, best != ""
+}
+
+// responseFormat формат ответа, выбранный formatMiddleware; без него — JSON
+func responseFormat(r *http.Request) string {
+	if format, ok := r.Context().Value(formatKey{}).(string); ok {
+		return format
+	}
+	return formatJSON
+}
+
+// xmlList корневой элемент для списка в XML
+type xmlList struct {
+	XMLName xml.Name
+	Items   interface{}
+}
+
+// xmlListName имя корневого элемента XML для списков; для остальных значений пусто
+func xmlListName(data interface{}) string {
+	switch data.(type) {
+	case []*domain.EmployeeResponse, []*projectedEmployee:
+		return "employees"
+	case []*domain.EmployeeVersion:
+		return "versions"
+	case []*domain.SearchResultResponse:
+		return "results"
+	case []*domain.CityCount:
+		return "cities"
+	default:
+		return ""
+	}
+}
+
+// writeResponse отдает data в формате из заголовка Accept (см. formatMiddleware).
+// В XML список оборачивается в корневой элемент: <employees><employee>...</employee></employees>
+func (h *EmployeeHandler) writeResponse(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	if responseFormat(r) != formatXML {
+		h.writeJSONResponse(w, status, data)
+		return
+	}
+
+	if name := xmlListName(data); name != "" {
+		data = &xmlList{XMLName: xml.Name{Local: name}, Items: data}
+	}
+
+	h.writeEncoded(w, status, "application/xml", data, encodeXML)
+}
+
+// SetRequireContentType включает отказ (415) для тел запросов без заголовка Content-Type;
+// по умолчанию такое тело разбирается как JSON
+func (h *EmployeeHandler) SetRequireContentType(require bool) {
+	h.requireContentType = require
+}
+
+// bodyMediaTypes типы тела запроса, которые умеет разбирать decodeBody
+var bodyMediaTypes = map[string]string{
+	"application/json": formatJSON,
+	"application/xml":  formatXML,
+	"text/xml":         formatXML,
+}
+
+// mediaTypeError тело запроса в неподдерживаемом формате; отдается как 415
+type mediaTypeError struct {
+	ContentType string
+}
+
+func (e *mediaTypeError) Error() string {
+	return fmt.Sprintf("неподдерживаемый Content-Type %q", e.ContentType)
+}
+
+// bodyFormat формат тела запроса по Content-Type (параметры вроде charset допустимы).
+// Без Content-Type тело считается JSON, если не включен SetRequireContentType
+func (h *EmployeeHandler) bodyFormat(r *http.Request) (string, error) {
+	contentType := r.Header.Get("Content-Type")
+	if strings.TrimSpace(contentType) == "" && !h.requireContentType {
+		return formatJSON, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", &mediaTypeError{ContentType: contentType}
+	}
+	format, ok := bodyMediaTypes[mediaType]
+	if !ok {
+		return "", &mediaTypeError{ContentType: contentType}
+	}
+	return format, nil
+}
+
+// decodeBody разбирает тело запроса в v: XML при Content-Type application/xml или
+// text/xml, JSON при application/json или без Content-Type. Другой Content-Type —
+// *mediaTypeError, ошибка разбора — VALIDATION_ERROR поля body
+func (h *EmployeeHandler) decodeBody(r *http.Request, v interface{}) error {
+	format, err := h.bodyFormat(r)
+	if err != nil {
+		return err
+	}
+	if format == formatXML {
+		if err := xml.NewDecoder(r.Body).Decode(v); err != nil {
+			h.logger.Error("ошибка декодирования запроса", zap.Error(err))
+			return badRequest("body", i18n.RequestBodyXML)
+		}
+		return nil
+	}
+
+	return h.decodeJSON(r, v)
+}
+
+// decodeJSON разбирает JSON тело запроса в v. Пустое тело, синтаксическая ошибка
+// и поле не того типа отдаются отдельными сообщениями: с позицией ошибки или
+// с именем поля и ожидаемым типом. null оставляет поле пустым, как и его отсутствие
+func (h *EmployeeHandler) decodeJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+	h.logger.Error("ошибка декодирования запроса", zap.Error(err))
+	return jsonBodyError(err)
+}
+
+// jsonBodyError переводит ошибку разбора JSON тела в VALIDATION_ERROR
+func jsonBodyError(err error) error {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return badRequest("body", i18n.RequestBodyEmpty)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return badRequest(field, i18n.RequestBodyType, field, jsonTypeName(typeErr.Type))
+	case errors.As(err, &syntaxErr):
+		return badRequest("body", i18n.RequestBodySyntax, syntaxErr.Offset)
+	default:
+		return badRequest("body", i18n.RequestBody)
+	}
+}
+
+// jsonTypeName название типа JSON, в который декодируется значение типа t
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return t.String()
+	}
+}
@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"mime"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"employer/internal/domain"
+	"employer/internal/i18n"
 	"employer/internal/service"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -18,275 +23,799 @@ import (
 type EmployeeHandler struct {
 	service service.EmployeeService
 	logger  *zap.Logger
+	// events источник для /api/employees/events; nil — поток событий отключен
+	events EventSource
+	// размер страницы списка без limit и наибольший размер (см. SetPageLimits)
+	pageDefaultLimit int
+	pageMaxLimit     int
+	// searchMaxResults размер страницы поиска без limit (см. SetSearchMaxResults)
+	searchMaxResults int
+	// requireContentType тело без Content-Type отклоняется (см. SetRequireContentType)
+	requireContentType bool
+	// errorDetails текст внутренней ошибки отдается в ответе (см. SetErrorDetails)
+	errorDetails bool
+	// listRows гистограмма количества сотрудников в ответах списков (см. SetListRowsHistogram)
+	listRows *prometheus.HistogramVec
 }
 
 // NewEmployeeHandler создает новый обработчик для сотрудников
 func NewEmployeeHandler(service service.EmployeeService, logger *zap.Logger) *EmployeeHandler {
-	return &EmployeeHandler{
+	h := &EmployeeHandler{
 		service: service,
 		logger:  logger,
 	}
+	h.SetPageLimits(defaultPageLimit, maxPageLimit)
+	h.SetSearchMaxResults(maxSearchResults)
+	return h
 }
 
-// CreateEmployee создает нового сотрудника
+// CreateEmployee создает нового сотрудника. С заголовком Idempotency-Key повтор
+// запроса возвращает ранее созданного сотрудника вместо новой записи
 // POST /api/employees
 func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 	var req domain.CreateEmployeeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("ошибка декодирования запроса", zap.Error(err))
-		h.writeErrorResponse(w, http.StatusBadRequest, "некорректный JSON")
+	if err := h.decodeBody(r, &req); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	employee := &domain.Employee{
-		Name:  req.Name,
-		Phone: req.Phone,
-		City:  req.City,
+		Name:   req.Name,
+		Phone:  req.Phone,
+		City:   req.City,
+		Status: req.Status,
 	}
-
-	if err := h.service.CreateEmployee(r.Context(), employee); err != nil {
-		if _, ok := err.(*service.ValidationError); ok {
-			h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		h.logger.Error("ошибка создания сотрудника", zap.Error(err))
-		h.writeErrorResponse(w, http.StatusInternalServerError, "внутренняя ошибка сервера")
+	if err := parseEmployeeDates(employee, req.BirthDate, req.HireDate); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	response := &domain.EmployeeResponse{
-		ID:    employee.ID,
-		Name:  employee.Name,
-		Phone: employee.Phone,
-		City:  employee.City,
+	var err error
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		err = h.createEmployeeOnce(w, r, key, &req, employee)
+	} else {
+		err = h.service.CreateEmployee(r.Context(), employee)
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
-	h.writeJSONResponse(w, http.StatusCreated, response)
+	response := toEmployeeResponse(employee)
+
+	h.writeResponse(w, r, http.StatusCreated, response)
 }
 
-// GetEmployee получает сотрудника по ID
-// GET /api/employees/{id}
+// GetEmployee получает сотрудника по ID; с as_of — в том виде, в котором он был на эту дату.
+// С expand в ответ встраиваются связанные данные (см. employeeExpansions); такой ответ
+// отдается без ETag. Без expand поддерживаются If-None-Match и If-Modified-Since (по updated_at)
+// GET /api/employees/{id}?as_of=2024-01-01&expand=history
 func (h *EmployeeHandler) GetEmployee(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := parseIDParam(r)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "некорректный ID")
+		h.writeError(w, r, err)
+		return
+	}
+	expand, err := parseExpand(r)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	employee, err := h.service.GetEmployee(r.Context(), id)
+	var employee *domain.Employee
+	if raw := r.URL.Query().Get("as_of"); raw != "" {
+		asOf, parseErr := parseAsOf(raw)
+		if parseErr != nil {
+			h.writeError(w, r, badRequest("as_of", i18n.RequestAsOf))
+			return
+		}
+		employee, err = h.service.GetEmployeeAsOf(r.Context(), id, asOf)
+	} else {
+		employee, err = h.service.GetEmployee(r.Context(), id)
+	}
 	if err != nil {
-		if h.isNotFoundError(err) {
-			h.writeErrorResponse(w, http.StatusNotFound, "сотрудник не найден")
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := toEmployeeResponse(employee)
+
+	if len(expand) > 0 {
+		details, err := h.expandEmployee(r, response, expand)
+		if err != nil {
+			h.writeError(w, r, err)
 			return
 		}
-		h.logger.Error("ошибка получения сотрудника", zap.Error(err), zap.Int("id", id))
-		h.writeErrorResponse(w, http.StatusInternalServerError, "внутренняя ошибка сервера")
+		h.writeResponse(w, r, http.StatusOK, details)
 		return
 	}
 
-	response := &domain.EmployeeResponse{
-		ID:    employee.ID,
-		Name:  employee.Name,
-		Phone: employee.Phone,
-		City:  employee.City,
+	etag := employeeETag(response)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private")
+	if !employee.UpdatedAt.IsZero() {
+		w.Header().Set("Last-Modified", employee.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+	if etagMatches(r.Header.Get("If-None-Match"), etag) || notModifiedSince(r, employee.UpdatedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	h.writeResponse(w, r, http.StatusOK, response)
 }
 
-// SearchEmployees поиск сотрудников по имени, телефону или городу
-// GET /api/employees/search?q=search_term
+// SearchEmployees поиск сотрудников по имени, телефону или городу. Общее количество
+// совпадений возвращается в X-Total-Count (и meta.total в конверте), а X-Results-Truncated
+// сообщает, что совпадений больше, чем вошло в ответ, и запрос стоит уточнить.
+// С highlight=true каждый результат — SearchResultResponse с местом совпадения для подсветки.
+// С sort результаты упорядочиваются по полю вместо релевантности.
+// GET /api/employees/search?q=search_term&status=active&sort=city&order=desc&limit=100&offset=0&fields=id,name
 func (h *EmployeeHandler) SearchEmployees(w http.ResponseWriter, r *http.Request) {
-	searchQuery := r.URL.Query().Get("q")
+	params, err := ParseListParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	searchQuery := params.Query
 	if searchQuery == "" {
-		h.writeErrorResponse(w, http.StatusBadRequest, "параметр поиска 'q' обязателен")
+		h.writeError(w, r, badRequest("q", i18n.RequestQuery))
 		return
 	}
+	fields, err := parseFields(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	highlight := false
+	if raw := r.URL.Query().Get("highlight"); raw != "" {
+		if highlight, err = strconv.ParseBool(raw); err != nil {
+			h.writeError(w, r, badRequest("highlight", i18n.RequestHighlight))
+			return
+		}
+	}
+	if highlight && fields != nil {
+		h.writeError(w, r, badRequest("highlight", i18n.RequestHighlightFields))
+		return
+	}
+
+	limit, offset := params.pageLimit(h.searchMaxResults), params.Offset
 
 	// Логирование поискового запроса
 	h.logger.Info("получен запрос на поиск сотрудников", 
 		zap.String("search_query", searchQuery),
-		zap.String("remote_addr", r.RemoteAddr))
+		zap.String("remote_addr", clientIP(r)))
+
+	employees, total, err := h.service.SearchEmployees(r.Context(), searchQuery, params.Filter(), limit, offset)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
 
-	employees, err := h.service.SearchEmployees(r.Context(), searchQuery)
+	h.logger.Info("поиск сотрудников выполнен успешно", 
+		zap.String("search_query", searchQuery),
+		zap.Int("results_count", len(employees)),
+		zap.Int64("total", total))
+
+	truncated := int64(offset+len(employees)) < total
+	w.Header().Set(ResultsTruncatedHeader, strconv.FormatBool(truncated))
+	if highlight {
+		h.writePage(w, r, toSearchResults(employees, searchQuery), len(employees), total, limit, offset)
+		return
+	}
+	h.writePageResponse(w, r, toEmployeeResponses(employees), fields, total, limit, offset)
+}
+
+
+// GetAllEmployees получает всех сотрудников или, если заданы limit/offset, страницу.
+// Фильтры city и status сужают выборку, sort и order задают порядок (по умолчанию по имени).
+// С fields в ответе остаются только перечисленные поля.
+// С ids возвращаются только сотрудники с этими ID в порядке запроса; ненайденные пропускаются.
+// GET /api/employees?status=active&sort=created_at&order=desc&limit=20&offset=40&fields=id,name
+// С created_from и created_to возвращаются сотрудники, созданные в этом интервале, по дате создания.
+// С stream=true весь список пишется JSON-массивом по мере чтения из БД, без LIST_MAX_ROWS.
+// GET /api/employees?ids=1,2,3
+// GET /api/employees?stream=true&status=active
+// GET /api/employees?created_from=2024-01-01&created_to=2024-02-01
+func (h *EmployeeHandler) GetAllEmployees(w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r)
 	if err != nil {
-		// Проверка на ошибку валидации
-		if validationErr, ok := err.(*service.ValidationError); ok {
-			h.writeErrorResponse(w, http.StatusBadRequest, validationErr.Error())
+		h.writeError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Has("created_from") || r.URL.Query().Has("created_to") {
+		h.getEmployeesCreatedBetween(w, r, fields)
+		return
+	}
+	if r.URL.Query().Has("ids") {
+		h.getEmployeesByIDs(w, r, fields)
+		return
+	}
+	params, err := ParseListParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	stream, err := wantsStream(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if stream {
+		if params.Paged || wantsEnvelope(r) || responseFormat(r) != formatJSON {
+			h.writeError(w, r, badRequest("stream", i18n.RequestStreamCombined))
 			return
 		}
-		
-		h.logger.Error("ошибка поиска сотрудников", 
-			zap.Error(err), 
-			zap.String("search_query", searchQuery))
-		h.writeErrorResponse(w, http.StatusInternalServerError, "внутренняя ошибка сервера")
+		h.streamEmployees(w, r, params.Filter(), fields)
+		return
+	}
+	if params.Paged {
+		h.getEmployeesPage(w, r, params, fields)
 		return
 	}
 
-	// Формирование ответа
-	response := make([]*domain.EmployeeResponse, len(employees))
-	for i, emp := range employees {
-		response[i] = &domain.EmployeeResponse{
-			ID:    emp.ID,
-			Name:  emp.Name,
-			Phone: emp.Phone,
-			City:  emp.City,
+	employees, err := h.service.GetAllEmployees(r.Context(), params.Filter())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeListResponse(w, r, toEmployeeResponses(employees), fields)
+}
+
+// getEmployeesByIDs отдает сотрудников по списку ?ids=1,2,3
+func (h *EmployeeHandler) getEmployeesByIDs(w http.ResponseWriter, r *http.Request, fields []employeeField) {
+	query := r.URL.Query()
+	for _, param := range []string{"limit", "offset", "city", "status", "sort", "order"} {
+		if query.Has(param) {
+			h.writeError(w, r, badRequest("ids", i18n.RequestIDsCombined))
+			return
 		}
 	}
 
-	h.logger.Info("поиск сотрудников выполнен успешно", 
-		zap.String("search_query", searchQuery),
-		zap.Int("results_count", len(employees)))
+	ids, err := parseIDList(query.Get("ids"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	employees, err := h.service.GetEmployeesByIDs(r.Context(), ids)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeListResponse(w, r, toEmployeeResponses(employees), fields)
+}
+
+// parseIDList разбирает список ID через запятую; пустой список проверяет сервис
+func parseIDList(raw string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, badRequest("ids", i18n.RequestIDs)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
 }
 
+// CheckPhone проверяет, занят ли телефон другим сотрудником. Возвращает только ID
+// и замаскированное имя найденного сотрудника.
+// GET /api/employees/check-phone?phone=%2B77010000000&exclude_id=5
+func (h *EmployeeHandler) CheckPhone(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var excludeID int64
+	if raw := query.Get("exclude_id"); raw != "" {
+		var err error
+		if excludeID, err = strconv.ParseInt(raw, 10, 64); err != nil || excludeID < 0 {
+			h.writeError(w, r, badRequest("exclude_id", i18n.RequestExcludeID))
+			return
+		}
+	}
 
-// GetAllEmployees получает всех сотрудников
-// GET /api/employees
-func (h *EmployeeHandler) GetAllEmployees(w http.ResponseWriter, r *http.Request) {
-	employees, err := h.service.GetAllEmployees(r.Context())
+	employee, err := h.service.CheckPhone(r.Context(), query.Get("phone"), excludeID)
 	if err != nil {
-		h.logger.Error("ошибка получения списка сотрудников", zap.Error(err))
-		h.writeErrorResponse(w, http.StatusInternalServerError, "внутренняя ошибка сервера")
+		h.writeError(w, r, err)
 		return
 	}
 
-	response := make([]*domain.EmployeeResponse, len(employees))
-	for i, emp := range employees {
-		response[i] = &domain.EmployeeResponse{
-			ID:    emp.ID,
-			Name:  emp.Name,
-			Phone: emp.Phone,
-			City:  emp.City,
+	resp := domain.PhoneCheckResponse{}
+	if employee != nil {
+		resp.Exists = true
+		resp.Employee = &domain.EmployeeSummary{ID: employee.ID, Name: maskName(employee.Name)}
+	}
+	h.writeResponse(w, r, http.StatusOK, resp)
+}
+
+// CountEmployees возвращает количество сотрудников
+// GET /api/employees/count?city=Almaty
+func (h *EmployeeHandler) CountEmployees(w http.ResponseWriter, r *http.Request) {
+	count, ok := h.countEmployees(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeResponse(w, r, http.StatusOK, domain.CountResponse{Count: count})
+}
+
+// GetEmployeeStats возвращает статистику сотрудников; значение может отставать
+// от данных на время жизни кэша (STATS_CACHE_TTL)
+// GET /api/employees/stats
+func (h *EmployeeHandler) GetEmployeeStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetEmployeeStats(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeResponse(w, r, http.StatusOK, stats)
+}
+
+// HeadEmployees возвращает количество сотрудников в заголовке X-Total-Count без тела
+// HEAD /api/employees?city=Almaty
+func (h *EmployeeHandler) HeadEmployees(w http.ResponseWriter, r *http.Request) {
+	count, ok := h.countEmployees(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(count, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// countEmployees считает сотрудников по фильтру из query; при ошибке пишет ответ и возвращает false
+func (h *EmployeeHandler) countEmployees(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	params, err := ParseListParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return 0, false
+	}
+	count, err := h.service.CountEmployees(r.Context(), params.Filter())
+	if err != nil {
+		h.writeError(w, r, err)
+		return 0, false
+	}
+	return count, true
+}
+
+// GetRecentEmployees получает последних добавленных сотрудников, новые первыми
+// GET /api/employees/recent?limit=10
+func (h *EmployeeHandler) GetRecentEmployees(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			h.writeError(w, r, badRequest("limit", i18n.RequestLimit))
+			return
 		}
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	employees, err := h.service.GetRecentEmployees(r.Context(), limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeListResponse(w, r, toEmployeeResponses(employees), nil)
 }
 
-// UpdateEmployee обновляет сотрудника
+// UpdateEmployee обновляет сотрудника. Ожидаемая версия обязательна: поле version или
+// заголовок If-Match (версия или ETag); без нее — 428, если сотрудник с тех пор
+// изменен — 409 с текущей версией
 // PUT /api/employees/{id}
 func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := parseIDParam(r)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "некорректный ID")
+		h.writeError(w, r, err)
 		return
 	}
 
 	var req domain.UpdateEmployeeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("ошибка декодирования запроса", zap.Error(err))
-		h.writeErrorResponse(w, http.StatusBadRequest, "некорректный JSON")
+	if err := h.decodeBody(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	version, err := h.expectedVersion(r, id, req.Version)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	employee := &domain.Employee{
-		ID:    id,
-		Name:  req.Name,
-		Phone: req.Phone,
-		City:  req.City,
+		ID:      id,
+		Name:    req.Name,
+		Phone:   req.Phone,
+		City:    req.City,
+		Status:  req.Status,
+		Version: version,
+	}
+	if err := parseEmployeeDates(employee, req.BirthDate, req.HireDate); err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
 	if err := h.service.UpdateEmployee(r.Context(), employee); err != nil {
-		if _, ok := err.(*service.ValidationError); ok {
-			h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		if h.isNotFoundError(err) {
-			h.writeErrorResponse(w, http.StatusNotFound, "сотрудник не найден")
-			return
-		}
-		h.logger.Error("ошибка обновления сотрудника", zap.Error(err), zap.Int("id", id))
-		h.writeErrorResponse(w, http.StatusInternalServerError, "внутренняя ошибка сервера")
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := toEmployeeResponse(employee)
+
+	h.writeResponse(w, r, http.StatusOK, response)
+}
+
+// ActivateEmployee делает сотрудника активным. Уже активный сотрудник не меняется
+// POST /api/employees/{id}/activate
+func (h *EmployeeHandler) ActivateEmployee(w http.ResponseWriter, r *http.Request) {
+	h.setEmployeeStatus(w, r, domain.StatusActive)
+}
+
+// DeactivateEmployee делает сотрудника неактивным. Уже неактивный сотрудник не меняется
+// POST /api/employees/{id}/deactivate
+func (h *EmployeeHandler) DeactivateEmployee(w http.ResponseWriter, r *http.Request) {
+	h.setEmployeeStatus(w, r, domain.StatusInactive)
+}
+
+// setEmployeeStatus переводит сотрудника в статус и отдает его текущее состояние
+func (h *EmployeeHandler) setEmployeeStatus(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	response := &domain.EmployeeResponse{
-		ID:    employee.ID,
-		Name:  employee.Name,
-		Phone: employee.Phone,
-		City:  employee.City,
+	employee, err := h.service.SetEmployeeStatus(r.Context(), id, status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	h.writeResponse(w, r, http.StatusOK, toEmployeeResponses([]*domain.Employee{employee})[0])
 }
 
-// DeleteEmployee удаляет сотрудника
-// DELETE /api/employees/{id}
+// DeleteEmployee удаляет сотрудника; отсутствующий — 404. С if_exists=true удаление
+// идемпотентно: 204 и для отсутствующего сотрудника
+// DELETE /api/employees/{id}?if_exists=true
 func (h *EmployeeHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := parseIDParam(r)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "некорректный ID")
+		h.writeError(w, r, err)
+		return
+	}
+
+	ifExists, ok := parseBoolParam(r.URL.Query().Get("if_exists"))
+	if !ok {
+		h.writeError(w, r, badRequest("if_exists", i18n.RequestIfExists))
+		return
+	}
+	if ifExists {
+		h.deleteEmployeeIfExists(w, r, id)
 		return
 	}
 
 	if err := h.service.DeleteEmployee(r.Context(), id); err != nil {
-		if h.isNotFoundError(err) {
-			h.writeErrorResponse(w, http.StatusNotFound, "сотрудник не найден")
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteEmployeeIfExists идемпотентное удаление: 204 и для уже отсутствующего сотрудника,
+// поэтому клиент может безопасно повторить запрос
+func (h *EmployeeHandler) deleteEmployeeIfExists(w http.ResponseWriter, r *http.Request, id int64) {
+	existed, err := h.service.DeleteEmployeeIfExists(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if !existed {
+		h.logger.Info("сотрудник для удаления уже отсутствует", zap.Int64("id", id))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteEmployees удаляет сотрудников по списку ID одной транзакцией;
+// с "dry_run": true только возвращает отчет
+// DELETE /api/employees {"ids": [1, 2, 3], "dry_run": false}
+func (h *EmployeeHandler) DeleteEmployees(w http.ResponseWriter, r *http.Request) {
+	var req domain.BatchDeleteRequest
+	if err := h.decodeBody(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	result, err := h.service.DeleteEmployees(r.Context(), req.IDs, req.DryRun)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeResponse(w, r, http.StatusOK, result)
+}
+
+// ValidateEmployee проверяет данные сотрудника без сохранения в БД.
+// Для /{id}/validate телефон самого сотрудника не считается занятым.
+// POST /api/employees/validate
+// POST /api/employees/{id}/validate
+func (h *EmployeeHandler) ValidateEmployee(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateEmployeeRequest
+	if err := h.decodeBody(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	employee := &domain.Employee{
+		Name:   req.Name,
+		Phone:  req.Phone,
+		City:   req.City,
+		Status: req.Status,
+	}
+	// ошибки формата дат отдаются вместе с остальными ошибками полей
+	dateErrs := employeeDates(employee, req.BirthDate, req.HireDate)
+	if _, ok := mux.Vars(r)["id"]; ok {
+		id, err := parseIDParam(r)
+		if err != nil {
+			h.writeError(w, r, err)
 			return
 		}
-		h.logger.Error("ошибка удаления сотрудника", zap.Error(err), zap.Int("id", id))
-		h.writeErrorResponse(w, http.StatusInternalServerError, "внутренняя ошибка сервера")
+		employee.ID = id
+	}
+
+	fieldErrors, err := h.service.ValidateEmployee(r.Context(), employee)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
+	fieldErrors = append(fieldErrors, dateErrs.FieldErrors()...)
 
-	w.WriteHeader(http.StatusNoContent)
+	if len(fieldErrors) > 0 {
+		fieldErrors = localizeFields(i18n.FromContext(r.Context()), fieldErrors)
+		h.writeResponse(w, r, http.StatusUnprocessableEntity, &domain.ValidationResponse{Valid: false, Errors: fieldErrors})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, &domain.ValidationResponse{Valid: true})
 }
 
 // RegisterRoutes регистрирует маршруты для API сотрудников
 func (h *EmployeeHandler) RegisterRoutes(router *mux.Router) {
 	api := router.PathPrefix("/api/employees").Subrouter()
+	// Язык сообщений об ошибках выбирается по Accept-Language
+	api.Use(h.requestIDMiddleware)
+	api.Use(i18n.Middleware)
+	api.Use(h.actorMiddleware)
+	api.Use(h.formatMiddleware)
 
 	api.HandleFunc("/search", h.SearchEmployees).Methods("GET")
+	api.HandleFunc("/recent", h.GetRecentEmployees).Methods("GET")
+	api.HandleFunc("/anniversaries", h.GetUpcomingAnniversaries).Methods("GET")
+	api.HandleFunc("/count", h.CountEmployees).Methods("GET")
+	api.HandleFunc("/stats", h.GetEmployeeStats).Methods("GET")
+	api.HandleFunc("/check-phone", h.CheckPhone).Methods("GET")
+	api.HandleFunc("/duplicates", h.GetDuplicates).Methods("GET")
+	api.HandleFunc("/export", h.ExportEmployees).Methods("GET").Name("export")
+	api.HandleFunc("/export.xlsx", h.ExportEmployeesXLSX).Methods("GET").Name("export.xlsx")
+	if h.events != nil {
+		api.HandleFunc("/events", h.StreamEvents).Methods("GET").Name("events")
+	}
+	api.HandleFunc("/import", h.ImportEmployees).Methods("POST")
+	api.HandleFunc("/validate", h.ValidateEmployee).Methods("POST")
+	api.HandleFunc("/{id:[0-9]+}/validate", h.ValidateEmployee).Methods("POST")
 	api.HandleFunc("", h.CreateEmployee).Methods("POST")
 	api.HandleFunc("", h.GetAllEmployees).Methods("GET")
+	api.HandleFunc("", h.HeadEmployees).Methods("HEAD")
+	api.HandleFunc("", h.DeleteEmployees).Methods("DELETE")
+	api.HandleFunc("/bulk", h.UpdateEmployees).Methods("PUT")
 	api.HandleFunc("/{id:[0-9]+}", h.GetEmployee).Methods("GET")
+	api.HandleFunc("/{id:[0-9]+}/history", h.GetEmployeeHistory).Methods("GET")
+	api.HandleFunc("/{id:[0-9]+}/activate", h.ActivateEmployee).Methods("POST")
+	api.HandleFunc("/{id:[0-9]+}/deactivate", h.DeactivateEmployee).Methods("POST")
+	api.HandleFunc("/{id:[0-9]+}/merge", h.MergeEmployee).Methods("POST")
+	api.HandleFunc("/{id:[0-9]+}/attendance", h.CheckIn).Methods("POST")
+	api.HandleFunc("/{id:[0-9]+}/attendance", h.GetEmployeeAttendance).Methods("GET")
 	api.HandleFunc("/{id:[0-9]+}", h.UpdateEmployee).Methods("PUT")
 	api.HandleFunc("/{id:[0-9]+}", h.DeleteEmployee).Methods("DELETE")
+
+	cities := router.PathPrefix("/api/cities").Subrouter()
+	cities.Use(h.requestIDMiddleware)
+	cities.Use(i18n.Middleware)
+	cities.Use(h.formatMiddleware)
+	cities.HandleFunc("", h.GetCityCounts).Methods("GET")
+	cities.HandleFunc("/{city}/employees", h.GetCityEmployees).Methods("GET")
+
+	attendance := router.PathPrefix("/api/attendance").Subrouter()
+	attendance.Use(h.requestIDMiddleware)
+	attendance.Use(i18n.Middleware)
+	attendance.Use(h.formatMiddleware)
+	attendance.HandleFunc("/summary", h.GetAttendanceSummary).Methods("GET")
 }
 
-// ServeEmployeePage обслуживает страницу управления сотрудниками
-// GET /
-// GET /employees
-func (h *EmployeeHandler) ServeEmployeePage(w http.ResponseWriter, r *http.Request) {
-	// Путь к статическому файлу
-	staticPath := filepath.Join("static", "employee.html")
+// WithBasePath возвращает роутер, смонтированный под префиксом basePath (например "/hr"),
+// или сам router, если префикс пуст
+func WithBasePath(router *mux.Router, basePath string) *mux.Router {
+	basePath = strings.TrimRight(basePath, "/")
+	if basePath == "" {
+		return router
+	}
+	return router.PathPrefix(basePath).Subrouter()
+}
 
-	// Устанавливаем заголовки
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Header().Set("Pragma", "no-cache")
-	w.Header().Set("Expires", "0")
+// Вспомогательные методы
 
-	// Обслуживаем файл
-	http.ServeFile(w, r, staticPath)
+// toSearchResults результаты поиска с местом совпадения searchQuery (см. service.MatchSearch)
+func toSearchResults(employees []*domain.Employee, searchQuery string) []*domain.SearchResultResponse {
+	results := make([]*domain.SearchResultResponse, len(employees))
+	for i, employee := range toEmployeeResponses(employees) {
+		match := service.MatchSearch(employees[i], searchQuery)
+		results[i] = &domain.SearchResultResponse{
+			Employee:     *employee,
+			MatchedField: match.Field,
+			MatchStart:   match.Start,
+			MatchEnd:     match.End,
+		}
+	}
+	return results
+}
 
-	h.logger.Info("employee page served",
-		zap.String("remote_addr", r.RemoteAddr),
-		zap.String("user_agent", r.UserAgent()),
-	)
+// toEmployeeResponses преобразует сотрудников в DTO ответа
+func toEmployeeResponses(employees []*domain.Employee) []*domain.EmployeeResponse {
+	response := make([]*domain.EmployeeResponse, len(employees))
+	for i, emp := range employees {
+		response[i] = toEmployeeResponse(emp)
+	}
+	return response
 }
 
-// Вспомогательные методы
-func (h *EmployeeHandler) writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		h.logger.Error("failed to encode response", zap.Error(err))
+func toEmployeeResponse(emp *domain.Employee) *domain.EmployeeResponse {
+	return &domain.EmployeeResponse{
+		ID:        emp.ID,
+		Name:      emp.Name,
+		Phone:     emp.Phone,
+		City:      emp.City,
+		Status:    emp.Status,
+		CreatedBy: emp.CreatedBy,
+		UpdatedBy: emp.UpdatedBy,
+		Version:   emp.Version,
+		BirthDate: domain.FormatDate(emp.BirthDate),
+		HireDate:  domain.FormatDate(emp.HireDate),
+	}
+}
+
+// maskName оставляет первую букву каждого слова имени: "Иван Петров" -> "И*** П*****"
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
+
+// employeeETag вычисляет слабый ETag по всему ответу сотрудника в JSON (статус, версия,
+// авторы и даты тоже): любое изменение ответа меняет ETag
+func employeeETag(e *domain.EmployeeResponse) string {
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(`W/"%x"`, sum[:16])
+}
+
+// expectedVersion ожидаемая версия сотрудника id при обновлении: поле version тела, а без
+// него заголовок If-Match с номером версии ("3" или W/"3") или ETag из ответа GET. ETag
+// сравнивается с текущим состоянием сотрудника: совпал — ожидается его текущая версия,
+// нет — 409 с текущей версией. Без версии (и с If-Match: *) — 428: обновление вслепую
+// затерло бы чужие изменения
+func (h *EmployeeHandler) expectedVersion(r *http.Request, id int64, bodyVersion int) (int, error) {
+	if bodyVersion < 0 {
+		return 0, badRequest("version", i18n.RequestIfMatch)
+	}
+	if bodyVersion > 0 {
+		return bodyVersion, nil
+	}
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, &versionRequiredError{}
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		tag := strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if version, err := strconv.Atoi(strings.Trim(tag, `"`)); err == nil && version >= 1 {
+			return version, nil
+		}
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			return 0, badRequest("If-Match", i18n.RequestIfMatch)
+		}
+	}
+
+	employee, err := h.service.GetEmployee(r.Context(), id)
+	if err != nil {
+		return 0, err
+	}
+	if etagMatches(ifMatch, employeeETag(toEmployeeResponse(employee))) {
+		return employee.Version, nil
+	}
+	return 0, &service.ConflictError{
+		Field:      "version",
+		Message:    i18n.T(i18n.Default, i18n.ConflictVersion),
+		Key:        i18n.ConflictVersion,
+		EmployeeID: id,
+		Version:    employee.Version,
 	}
 }
 
-func (h *EmployeeHandler) writeErrorResponse(w http.ResponseWriter, status int, message string) {
-	h.writeJSONResponse(w, status, &domain.ErrorResponse{Error: message})
+// etagMatches проверяет заголовок If-None-Match: список ETag через запятую или "*".
+// Для GET используется слабое сравнение, поэтому префикс W/ не учитывается.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
 
-func (h *EmployeeHandler) isNotFoundError(err error) bool {
-	errMsg := strings.ToLower(err.Error())
-	return strings.Contains(errMsg, "не найден") ||
-		strings.Contains(errMsg, "not found") ||
-		strings.Contains(errMsg, "notfound")
+// notModifiedSince проверяет If-Modified-Since: ресурс, измененный не позже указанного
+// времени (с точностью до секунды, как в заголовке), не изменился. If-None-Match важнее
+// (RFC 7232), некорректная дата игнорируется
+func notModifiedSince(r *http.Request, modified time.Time) bool {
+	if modified.IsZero() || r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// writeListResponse отдает список как массив или, если клиент запросил конверт,
+// как {"data": [...], "meta": {"count": N}}; формат — по заголовку Accept.
+// С fields у сотрудников остаются только эти поля (см. parseFields)
+func (h *EmployeeHandler) writeListResponse(w http.ResponseWriter, r *http.Request, items []*domain.EmployeeResponse, fields []employeeField) {
+	h.observeRows(r, len(items))
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: projectEmployees(items, fields),
+			Meta: domain.ListMeta{Count: len(items)},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, projectEmployees(items, fields))
+}
+
+// wantsEnvelope определяет, запрошен ли конверт: параметром ?envelope=true
+// или параметром типа в заголовке Accept: application/json; envelope=true
+func wantsEnvelope(r *http.Request) bool {
+	if raw := r.URL.Query().Get("envelope"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		return err == nil && enabled
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if enabled, err := strconv.ParseBool(params["envelope"]); err == nil && enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONResponse отдает data в JSON (см. writeEncoded)
+func (h *EmployeeHandler) writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
+	h.writeEncoded(w, status, "application/json", data, encodeJSON)
 }
@@ -3,24 +3,75 @@ package handler_test
 import (
 	"bytes"
 	"context"
+	"employer/config"
 	"employer/internal/domain"
 	"employer/internal/handler"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+	"employer/internal/service"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 )
 
 type mockService struct {
-	CreateFn func(ctx context.Context, e *domain.Employee) error
-	GetFn    func(ctx context.Context, id int) (*domain.Employee, error)
-	GetAllFn func(ctx context.Context) ([]*domain.Employee, error)
-	UpdateFn func(ctx context.Context, e *domain.Employee) error
-	DeleteFn func(ctx context.Context, id int) error
-	SearchFn func(ctx context.Context, query string) ([]*domain.Employee, error) // Added
+	CreateFn   func(ctx context.Context, e *domain.Employee) error
+	GetFn      func(ctx context.Context, id int64) (*domain.Employee, error)
+	GetAllFn   func(ctx context.Context) ([]*domain.Employee, error)
+	RecentFn   func(ctx context.Context, limit int) ([]*domain.Employee, error)
+	PageFn     func(ctx context.Context, limit, offset int) ([]*domain.Employee, int64, error)
+	CountFn    func(ctx context.Context, filter domain.EmployeeFilter) (int64, error)
+	UpdateFn   func(ctx context.Context, e *domain.Employee) error
+	DeleteFn   func(ctx context.Context, id int64) error
+	SearchFn   func(ctx context.Context, query string) ([]*domain.Employee, error) // Added
+	ExportFn   func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	ImportFn   func(ctx context.Context, reader service.EmployeeReader, opts service.ImportOptions) (*domain.ImportSummary, error)
+	ValidateFn func(ctx context.Context, e *domain.Employee) ([]domain.FieldError, error)
+	CheckFn    func(ctx context.Context, phone string, excludeID int64) (*domain.Employee, error)
+
+	// SearchPageFn, если задан, заменяет SearchFn и возвращает страницу с общим количеством
+	SearchPageFn func(ctx context.Context, query string, limit, offset int) ([]*domain.Employee, int64, error)
+
+	AsOfFn    func(ctx context.Context, id int64, at time.Time) (*domain.Employee, error)
+	HistoryFn func(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error)
+	StatsFn   func(ctx context.Context) (*repository.EmployeeStats, error)
+
+	CityCountsFn    func(ctx context.Context) ([]*domain.CityCount, error)
+	AnniversariesFn func(ctx context.Context, withinDays int) ([]*domain.Anniversary, error)
+	CityEmployeesFn func(ctx context.Context, city string, limit, offset int) ([]*domain.Employee, int64, error)
+
+	BatchDeleteFn    func(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error)
+	DeleteIfExistsFn func(ctx context.Context, id int64) (bool, error)
+	BatchUpdateFn    func(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error)
+	ByIDsFn          func(ctx context.Context, ids []int64) ([]*domain.Employee, error)
+	CreatedFn        func(ctx context.Context, from, to time.Time) ([]*domain.Employee, error)
+	StreamAllFn      func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	StatusFn         func(ctx context.Context, id int64, status string) (*domain.Employee, error)
+	IdempotentFn     func(ctx context.Context, key, requestHash string, e *domain.Employee) (bool, error)
+
+	MergeFn      func(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error)
+	DuplicatesFn func(ctx context.Context, limit int) ([]*domain.DuplicatePair, error)
+
+	CheckInFn           func(ctx context.Context, id int64, status, note string) (*domain.Attendance, error)
+	AttendanceFn        func(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error)
+	AttendanceSummaryFn func(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error)
+
+	// lastFilter фильтр последнего вызова GetAllEmployees, GetEmployeesPage или SearchEmployees
+	lastFilter domain.EmployeeFilter
 }
 
 func (m *mockService) CreateEmployee(ctx context.Context, e *domain.Employee) error {
@@ -30,20 +81,153 @@ func (m *mockService) CreateEmployee(ctx context.Context, e *domain.Employee) er
 	return nil
 }
 
-func (m *mockService) GetEmployee(ctx context.Context, id int) (*domain.Employee, error) {
+func (m *mockService) CreateEmployeeIdempotent(ctx context.Context, key, requestHash string, e *domain.Employee) (bool, error) {
+	if m.IdempotentFn != nil {
+		return m.IdempotentFn(ctx, key, requestHash, e)
+	}
+	return false, m.CreateEmployee(ctx, e)
+}
+
+func (m *mockService) GetEmployee(ctx context.Context, id int64) (*domain.Employee, error) {
 	if m.GetFn != nil {
 		return m.GetFn(ctx, id)
 	}
 	return nil, nil
 }
 
-func (m *mockService) GetAllEmployees(ctx context.Context) ([]*domain.Employee, error) {
+func (m *mockService) GetEmployeeAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+	if m.AsOfFn != nil {
+		return m.AsOfFn(ctx, id, at)
+	}
+	return nil, nil
+}
+
+func (m *mockService) GetEmployeeHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+	if m.HistoryFn != nil {
+		return m.HistoryFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) GetEmployeeStats(ctx context.Context) (*repository.EmployeeStats, error) {
+	if m.StatsFn != nil {
+		return m.StatsFn(ctx)
+	}
+	return &repository.EmployeeStats{}, nil
+}
+
+func (m *mockService) DeleteEmployees(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error) {
+	if m.BatchDeleteFn != nil {
+		return m.BatchDeleteFn(ctx, ids, dryRun)
+	}
+	return &domain.BatchDeleteResponse{Deleted: []int64{}, NotFound: []int64{}, DryRun: dryRun}, nil
+}
+
+func (m *mockService) UpdateEmployees(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error) {
+	if m.BatchUpdateFn != nil {
+		return m.BatchUpdateFn(ctx, items, strict)
+	}
+	return &domain.BatchUpdateResponse{Results: []domain.BatchUpdateResult{}}, nil
+}
+
+func (m *mockService) MergeEmployees(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error) {
+	if m.MergeFn != nil {
+		return m.MergeFn(ctx, targetID, sourceID, fillMissing)
+	}
+	return &domain.MergeResult{Employee: &domain.Employee{ID: targetID}, SourceID: sourceID, CopiedFields: []string{}}, nil
+}
+
+func (m *mockService) FindDuplicates(ctx context.Context, limit int) ([]*domain.DuplicatePair, error) {
+	if m.DuplicatesFn != nil {
+		return m.DuplicatesFn(ctx, limit)
+	}
+	return []*domain.DuplicatePair{}, nil
+}
+
+func (m *mockService) CheckIn(ctx context.Context, id int64, status, note string) (*domain.Attendance, error) {
+	if m.CheckInFn != nil {
+		return m.CheckInFn(ctx, id, status, note)
+	}
+	return &domain.Attendance{EmployeeID: id, Status: status, Note: note}, nil
+}
+
+func (m *mockService) GetEmployeeAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+	if m.AttendanceFn != nil {
+		return m.AttendanceFn(ctx, id, from, to)
+	}
+	return []*domain.Attendance{}, nil
+}
+
+func (m *mockService) GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+	if m.AttendanceSummaryFn != nil {
+		return m.AttendanceSummaryFn(ctx, date)
+	}
+	return &domain.AttendanceSummary{Date: date}, nil
+}
+
+func (m *mockService) GetEmployeesByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+	if m.ByIDsFn != nil {
+		return m.ByIDsFn(ctx, ids)
+	}
+	return []*domain.Employee{}, nil
+}
+
+func (m *mockService) StreamAllEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	m.lastFilter = filter
+	if m.StreamAllFn != nil {
+		return m.StreamAllFn(ctx, filter, fn)
+	}
+	return nil
+}
+
+func (m *mockService) GetEmployeesCreatedBetween(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+	if m.CreatedFn != nil {
+		return m.CreatedFn(ctx, from, to)
+	}
+	return []*domain.Employee{}, nil
+}
+
+func (m *mockService) GetAllEmployees(ctx context.Context, filter domain.EmployeeFilter) ([]*domain.Employee, error) {
+	m.lastFilter = filter
 	if m.GetAllFn != nil {
 		return m.GetAllFn(ctx)
 	}
 	return nil, nil
 }
 
+func (m *mockService) GetEmployeesPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	m.lastFilter = filter
+	if m.PageFn != nil {
+		return m.PageFn(ctx, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *mockService) GetRecentEmployees(ctx context.Context, limit int) ([]*domain.Employee, error) {
+	if m.RecentFn != nil {
+		return m.RecentFn(ctx, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockService) CountEmployees(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	if m.CountFn != nil {
+		return m.CountFn(ctx, filter)
+	}
+	return 0, nil
+}
+
+func (m *mockService) CheckPhone(ctx context.Context, phone string, excludeID int64) (*domain.Employee, error) {
+	if m.CheckFn != nil {
+		return m.CheckFn(ctx, phone, excludeID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) WithTx(ctx context.Context, fn func(svc service.EmployeeService) error) error {
+	return fn(m)
+}
+
 func (m *mockService) UpdateEmployee(ctx context.Context, e *domain.Employee) error {
 	if m.UpdateFn != nil {
 		return m.UpdateFn(ctx, e)
@@ -51,19 +235,84 @@ func (m *mockService) UpdateEmployee(ctx context.Context, e *domain.Employee) er
 	return nil
 }
 
-func (m *mockService) DeleteEmployee(ctx context.Context, id int) error {
+func (m *mockService) SetEmployeeStatus(ctx context.Context, id int64, status string) (*domain.Employee, error) {
+	if m.StatusFn != nil {
+		return m.StatusFn(ctx, id, status)
+	}
+	return &domain.Employee{ID: id, Status: status}, nil
+}
+
+func (m *mockService) DeleteEmployee(ctx context.Context, id int64) error {
 	if m.DeleteFn != nil {
 		return m.DeleteFn(ctx, id)
 	}
 	return nil
 }
 
+func (m *mockService) DeleteEmployeeIfExists(ctx context.Context, id int64) (bool, error) {
+	if m.DeleteIfExistsFn != nil {
+		return m.DeleteIfExistsFn(ctx, id)
+	}
+	return true, nil
+}
+
 // Added SearchEmployees method
-func (m *mockService) SearchEmployees(ctx context.Context, query string) ([]*domain.Employee, error) {
+func (m *mockService) SearchEmployees(ctx context.Context, query string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	m.lastFilter = filter
+	if m.SearchPageFn != nil {
+		return m.SearchPageFn(ctx, query, limit, offset)
+	}
 	if m.SearchFn != nil {
-		return m.SearchFn(ctx, query)
+		employees, err := m.SearchFn(ctx, query)
+		return employees, int64(len(employees)), err
 	}
-	return []*domain.Employee{}, nil
+	return []*domain.Employee{}, 0, nil
+}
+
+func (m *mockService) ExportEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	if m.ExportFn != nil {
+		return m.ExportFn(ctx, filter, fn)
+	}
+	return nil
+}
+
+func (m *mockService) ImportEmployees(ctx context.Context, reader service.EmployeeReader, opts service.ImportOptions) (*domain.ImportSummary, error) {
+	if m.ImportFn != nil {
+		return m.ImportFn(ctx, reader, opts)
+	}
+	return &domain.ImportSummary{}, nil
+}
+
+func (m *mockService) GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error) {
+	return nil, nil
+}
+
+func (m *mockService) GetCityCounts(ctx context.Context) ([]*domain.CityCount, error) {
+	if m.CityCountsFn != nil {
+		return m.CityCountsFn(ctx)
+	}
+	return []*domain.CityCount{}, nil
+}
+
+func (m *mockService) GetUpcomingAnniversaries(ctx context.Context, withinDays int) ([]*domain.Anniversary, error) {
+	if m.AnniversariesFn != nil {
+		return m.AnniversariesFn(ctx, withinDays)
+	}
+	return nil, nil
+}
+
+func (m *mockService) GetCityEmployees(ctx context.Context, city string, limit, offset int) ([]*domain.Employee, int64, error) {
+	if m.CityEmployeesFn != nil {
+		return m.CityEmployeesFn(ctx, city, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *mockService) ValidateEmployee(ctx context.Context, e *domain.Employee) ([]domain.FieldError, error) {
+	if m.ValidateFn != nil {
+		return m.ValidateFn(ctx, e)
+	}
+	return nil, nil
 }
 
 func newRouter(svc *mockService) *mux.Router {
@@ -74,6 +323,18 @@ func newRouter(svc *mockService) *mux.Router {
 	return r
 }
 
+// assertErrorCode проверяет машиночитаемый код в теле ответа с ошибкой
+func assertErrorCode(t *testing.T, rr *httptest.ResponseRecorder, want string) {
+	t.Helper()
+	var resp domain.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error response: %v (%s)", err, rr.Body.String())
+	}
+	if resp.Code != want || resp.Message == "" {
+		t.Fatalf("expected code %s with message, got %+v", want, resp)
+	}
+}
+
 // --- existing tests ---
 
 func TestCreateEmployee_Success(t *testing.T) {
@@ -106,7 +367,7 @@ func TestCreateEmployee_Success(t *testing.T) {
 
 func TestGetEmployee_Success(t *testing.T) {
 	svc := &mockService{
-		GetFn: func(ctx context.Context, id int) (*domain.Employee, error) {
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
 			return &domain.Employee{ID: id, Name: "Bob", Phone: "123", City: "Astana"}, nil
 		},
 	}
@@ -161,7 +422,7 @@ func TestUpdateEmployee_Success(t *testing.T) {
 	}
 	r := newRouter(svc)
 
-	body := `{"name":"Neo","phone":"777","city":"Matrix"}`
+	body := `{"name":"Neo","phone":"777","city":"Matrix","version":1}`
 	req := httptest.NewRequest(http.MethodPut, "/api/employees/10", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -181,7 +442,7 @@ func TestUpdateEmployee_Success(t *testing.T) {
 
 func TestDeleteEmployee_Success(t *testing.T) {
 	svc := &mockService{
-		DeleteFn: func(ctx context.Context, id int) error { return nil },
+		DeleteFn: func(ctx context.Context, id int64) error { return nil },
 	}
 	r := newRouter(svc)
 
@@ -194,6 +455,53 @@ func TestDeleteEmployee_Success(t *testing.T) {
 	}
 }
 
+func TestDeleteEmployee_IfExists(t *testing.T) {
+	for _, existed := range []bool{true, false} {
+		var gotID int64
+		svc := &mockService{
+			DeleteFn: func(ctx context.Context, id int64) error {
+				t.Fatal("if_exists=true must not use strict DeleteEmployee")
+				return nil
+			},
+			DeleteIfExistsFn: func(ctx context.Context, id int64) (bool, error) {
+				gotID = id
+				return existed, nil
+			},
+		}
+		r := newRouter(svc)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/employees/12?if_exists=true", nil))
+		if rr.Code != http.StatusNoContent || gotID != 12 {
+			t.Fatalf("existed=%v: expected %d for id 12, got %d for id %d", existed, http.StatusNoContent, rr.Code, gotID)
+		}
+	}
+}
+
+func TestDeleteEmployee_StrictNotFound(t *testing.T) {
+	svc := &mockService{
+		DeleteFn: func(ctx context.Context, id int64) error {
+			return &repository.NotFoundError{Entity: "employee", ID: id}
+		},
+	}
+	r := newRouter(svc)
+
+	for _, query := range []string{"", "?if_exists=false"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/employees/12"+query, nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("%q: expected %d, got %d", query, http.StatusNotFound, rr.Code)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/employees/12?if_exists=maybe", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for invalid if_exists, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
 // --- new search tests ---
 
 func TestSearchEmployees_Success(t *testing.T) {
@@ -291,8 +599,8 @@ func TestSearchEmployees_EmptyQuery(t *testing.T) {
 		t.Fatalf("decode error: %v", err)
 	}
 
-	if errResp.Error == "" {
-		t.Fatalf("expected error message, got empty string")
+	if errResp.Code != domain.CodeValidation || errResp.Message == "" || errResp.Error == "" {
+		t.Fatalf("expected VALIDATION_ERROR with message, got %+v", errResp)
 	}
 }
 
@@ -330,7 +638,7 @@ func BenchmarkSearchEmployees(b *testing.B) {
 			results := make([]*domain.Employee, 50)
 			for i := range results {
 				results[i] = &domain.Employee{
-					ID:    i + 1,
+					ID:    int64(i + 1),
 					Name:  "Employee " + string(rune(i)),
 					Phone: "+77777777777",
 					City:  "Almaty",
@@ -352,3 +660,2825 @@ func BenchmarkSearchEmployees(b *testing.B) {
 		}
 	}
 }
+
+// discardResponseWriter ResponseWriter без сохранения тела: в бенчмарке считаются
+// только аллокации обработчика, а не буфера httptest.ResponseRecorder
+type discardResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardResponseWriter) WriteHeader(status int)      { w.status = status }
+
+func BenchmarkGetAllEmployees(b *testing.B) {
+	employees := make([]*domain.Employee, 1000)
+	for i := range employees {
+		employees[i] = &domain.Employee{
+			ID:     int64(i + 1),
+			Name:   fmt.Sprintf("Employee %d", i+1),
+			Phone:  fmt.Sprintf("+7701%07d", i+1),
+			City:   "Almaty",
+			Status: domain.StatusActive,
+		}
+	}
+	svc := &mockService{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			return employees, nil
+		},
+	}
+	r := newRouter(svc)
+	req := httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := &discardResponseWriter{header: http.Header{}}
+		r.ServeHTTP(w, req)
+		if w.status != http.StatusOK {
+			b.Fatalf("unexpected status code: %d", w.status)
+		}
+	}
+}
+
+// --- export tests ---
+
+func TestExportEmployees_XLSX(t *testing.T) {
+	employees := []*domain.Employee{
+		{ID: 1, Name: "Алия Нурланова", Phone: "+77010000001", City: "Almaty"},
+		{ID: 2, Name: "Bob", Phone: "+77010000002", City: "Almaty"},
+	}
+	var gotCity string
+	svc := &mockService{
+		ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			gotCity = filter.City
+			for _, e := range employees {
+				if err := fn(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/export?format=xlsx&city=Almaty", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, "employees_") || !strings.HasSuffix(cd, `.xlsx"`) {
+		t.Fatalf("unexpected content disposition: %s", cd)
+	}
+	if gotCity != "Almaty" {
+		t.Fatalf("expected city filter Almaty, got %q", gotCity)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(rr.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		t.Fatalf("get rows: %v", err)
+	}
+	want := [][]string{
+		{"ID", "Имя", "Телефон", "Город"},
+		{"1", "Алия Нурланова", "+77010000001", "Almaty"},
+		{"2", "Bob", "+77010000002", "Almaty"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Fatalf("row %d: expected %v, got %v", i, want[i], rows[i])
+		}
+	}
+}
+
+func TestExportEmployeesXLSX_Route(t *testing.T) {
+	var gotCity string
+	svc := &mockService{
+		ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			gotCity = filter.City
+			return fn(&domain.Employee{ID: 1, Name: "Алия", Phone: "+77010000001", City: "Almaty"})
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/export.xlsx?city=Almaty", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment") {
+		t.Fatalf("expected attachment disposition, got %s", cd)
+	}
+	if gotCity != "Almaty" {
+		t.Fatalf("expected city filter Almaty, got %q", gotCity)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(rr.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		t.Fatalf("get rows: %v", err)
+	}
+	if len(rows) != 2 || strings.Join(rows[0], "|") != "ID|Имя|Телефон|Город" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+
+	styleID, err := f.GetCellStyle(sheet, "C2")
+	if err != nil {
+		t.Fatalf("get cell style: %v", err)
+	}
+	style, err := f.GetStyle(styleID)
+	if err != nil {
+		t.Fatalf("get style: %v", err)
+	}
+	if style.NumFmt != 49 {
+		t.Fatalf("expected text number format for phone, got %d", style.NumFmt)
+	}
+}
+
+func TestExportEmployees_CSV(t *testing.T) {
+	svc := &mockService{
+		ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			return fn(&domain.Employee{ID: 3, Name: "Neo", Phone: "777", City: "Matrix"})
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/export", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	want := "ID,Имя,Телефон,Город\n3,Neo,777,Matrix\n"
+	if rr.Body.String() != want {
+		t.Fatalf("unexpected csv:\n%s", rr.Body.String())
+	}
+}
+
+func TestExportEmployees_UnknownFormat(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/export?format=pdf", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestExportEmployees_SearchFilter(t *testing.T) {
+	var got domain.EmployeeFilter
+	svc := &mockService{
+		ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			got = filter
+			return fn(&domain.Employee{ID: 3, Name: "Nurlan", Phone: "777", City: "Almaty"})
+		},
+	}
+	r := newRouter(svc)
+
+	for _, path := range []string{"/api/employees/export", "/api/employees/export.xlsx"} {
+		got = domain.EmployeeFilter{}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path+"?q=nurlan&city=Almaty&status=active", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected %d, got %d: %s", path, http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if got.Query != "nurlan" || got.City != "Almaty" || got.Status != "active" {
+			t.Fatalf("%s: filter did not reach service: %+v", path, got)
+		}
+		if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, `filename="employees_almaty_active_nurlan_`+time.Now().Format("2006-01-02")) {
+			t.Fatalf("%s: expected filter in file name, got %s", path, cd)
+		}
+	}
+
+	// не-ASCII части имени передаются в filename*
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/export?city="+url.QueryEscape("Усть-Каменогорск"), nil))
+	cd := rr.Header().Get("Content-Disposition")
+	if !strings.Contains(cd, `filename="employees_`) || !strings.Contains(cd, "filename*=UTF-8''employees_"+url.PathEscape("усть-каменогорск")) {
+		t.Fatalf("expected UTF-8 file name, got %s", cd)
+	}
+}
+
+func TestExportEmployees_InvalidFilter(t *testing.T) {
+	for _, format := range []string{"csv", "jsonl", "xlsx"} {
+		called := false
+		svc := &mockService{
+			ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+				called = true
+				// как сервис: фильтр проверяется до чтения строк, с тем же ответом, что у поиска
+				return service.NewValidationError("search_query", i18n.SearchTooShort)
+			},
+		}
+		r := newRouter(svc)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/export?format="+format+"&q=a", nil))
+		if !called || rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s: expected %d, got %d: %s", format, http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+		}
+		assertErrorCode(t, rr, domain.CodeValidation)
+		if cd := rr.Header().Get("Content-Disposition"); cd != "" || strings.Contains(rr.Body.String(), "Телефон") {
+			t.Fatalf("%s: rows written before error: %q %s", format, cd, rr.Body.String())
+		}
+
+		// некорректный параметр отклоняется до обращения к сервису
+		called = false
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/export?format="+format+"&order=sideways", nil))
+		if called || rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected %d without export, got %d (called %v)", format, http.StatusBadRequest, rr.Code, called)
+		}
+	}
+}
+
+// --- base path tests ---
+
+func TestRegisterRoutes_WithBasePath(t *testing.T) {
+	svc := &mockService{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			return []*domain.Employee{{ID: 1, Name: "A", Phone: "1", City: "X"}}, nil
+		},
+	}
+	h := handler.NewEmployeeHandler(svc, zap.NewNop())
+	r := mux.NewRouter()
+	h.RegisterRoutes(handler.WithBasePath(r, "/hr/"))
+
+	req := httptest.NewRequest(http.MethodGet, "/hr/api/employees", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d for prefixed path, got %d", http.StatusOK, rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected %d for unprefixed path, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestExportEmployees_JSONL(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := &mockService{
+		ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			return fn(&domain.Employee{ID: 5, Name: "Neo", Phone: "777", City: "Matrix", CreatedAt: created, UpdatedAt: created})
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/export?format=jsonl", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	want := `{"id":5,"name":"Neo","phone":"777","city":"Matrix","created_at":"2024-01-02T03:04:05Z","updated_at":"2024-01-02T03:04:05Z"}` + "\n"
+	if rr.Body.String() != want {
+		t.Fatalf("unexpected jsonl:\n%s", rr.Body.String())
+	}
+}
+
+func TestImportEmployees_Options(t *testing.T) {
+	var gotOpts service.ImportOptions
+	svc := &mockService{
+		ImportFn: func(ctx context.Context, reader service.EmployeeReader, opts service.ImportOptions) (*domain.ImportSummary, error) {
+			gotOpts = opts
+			return &domain.ImportSummary{
+				Imported: 1,
+				Failed:   1,
+				Aborted:  true,
+				Errors:   []domain.ImportLineError{{Line: 2, Error: "некорректный JSON"}},
+			}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/employees/import?format=jsonl&mode=overwrite&strict=true", strings.NewReader("{}\n"))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d for aborted import, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	if gotOpts.Mode != service.ImportModeOverwrite || !gotOpts.Strict {
+		t.Fatalf("unexpected options: %+v", gotOpts)
+	}
+	var summary domain.ImportSummary
+	if err := json.Unmarshal(rr.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Line != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestImportEmployees_DryRunCSVMapping(t *testing.T) {
+	var gotOpts service.ImportOptions
+	var gotNames []string
+	svc := &mockService{
+		ImportFn: func(ctx context.Context, reader service.EmployeeReader, opts service.ImportOptions) (*domain.ImportSummary, error) {
+			gotOpts = opts
+			for {
+				_, e, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				gotNames = append(gotNames, e.Name)
+			}
+			return &domain.ImportSummary{Imported: len(gotNames), DryRun: true, Errors: []domain.ImportLineError{}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	mapping := url.QueryEscape(`{"ФИО":"name","Телефон":"phone","Город":"city"}`)
+	body := "ФИО,Телефон,Город\nАлия,+77010000001,Almaty\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/employees/import?format=csv&dry_run=true&mapping="+mapping, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !gotOpts.DryRun || strings.Join(gotNames, ",") != "Алия" {
+		t.Fatalf("unexpected options %+v or records %v", gotOpts, gotNames)
+	}
+
+	for _, target := range []string{
+		"/api/employees/import?format=jsonl&mapping=" + mapping,
+		"/api/employees/import?format=csv&mapping=not-json",
+		"/api/employees/import?format=csv&dry_run=maybe",
+	} {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, rr.Code)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/employees/import?format=csv&mapping="+url.QueryEscape(`{"ФИО":"fio"}`), strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for unknown mapping field, got %d", rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestCreateEmployee_QuotaExceeded(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &service.QuotaError{Limit: 5, Count: 5, Requested: 1}
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/employees", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d", http.StatusForbidden, rr.Code)
+	}
+	var resp struct {
+		Code    string              `json:"code"`
+		Details domain.QuotaDetails `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != domain.CodeQuotaExceeded || resp.Details.Limit != 5 || resp.Details.Count != 5 || resp.Details.Requested != 1 {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+}
+
+// --- envelope tests ---
+
+func newListService() *mockService {
+	employees := []*domain.Employee{
+		{ID: 1, Name: "John Doe", Phone: "1", City: "X"},
+		{ID: 2, Name: "John Smith", Phone: "2", City: "Y"},
+	}
+	return &mockService{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) { return employees, nil },
+		SearchFn: func(ctx context.Context, query string) ([]*domain.Employee, error) { return employees, nil },
+	}
+}
+
+func TestGetAllEmployees_Envelope(t *testing.T) {
+	r := newRouter(newListService())
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{"query param", "/api/employees?envelope=true", ""},
+		{"accept header", "/api/employees", "application/json; envelope=true"},
+		{"search", "/api/employees/search?q=john&envelope=1", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+			}
+			var resp struct {
+				Data []domain.EmployeeResponse `json:"data"`
+				Meta domain.ListMeta           `json:"meta"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode envelope: %v (%s)", err, rr.Body.String())
+			}
+			if len(resp.Data) != 2 || resp.Meta.Count != 2 || resp.Data[1].Name != "John Smith" {
+				t.Fatalf("unexpected envelope: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestGetAllEmployees_BareArrayByDefault(t *testing.T) {
+	r := newRouter(newListService())
+
+	for _, path := range []string{"/api/employees", "/api/employees?envelope=false", "/api/employees/search?q=john"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var list []domain.EmployeeResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+			t.Fatalf("%s: expected bare array: %v (%s)", path, err, rr.Body.String())
+		}
+		if len(list) != 2 {
+			t.Fatalf("%s: expected 2 items, got %d", path, len(list))
+		}
+	}
+}
+
+func TestValidateEmployee_Valid(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("validate must not create employee")
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/employees/validate", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if got := strings.TrimSpace(rr.Body.String()); got != `{"valid":true}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestValidateEmployee_FieldErrors(t *testing.T) {
+	var gotID int64
+	svc := &mockService{
+		ValidateFn: func(ctx context.Context, e *domain.Employee) ([]domain.FieldError, error) {
+			gotID = e.ID
+			return []domain.FieldError{
+				{Field: "name", Message: "имя обязательно"},
+				{Field: "city", Message: "город обязателен"},
+			}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/employees/7/validate", bytes.NewBufferString(`{"phone":"+7701"}`))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	if gotID != 7 {
+		t.Fatalf("expected id 7 passed to service, got %d", gotID)
+	}
+	var resp domain.ValidationResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Valid || len(resp.Errors) != 2 || resp.Errors[1].Field != "city" {
+		t.Fatalf("unexpected resp: %+v", resp)
+	}
+}
+
+func TestCreateEmployee_ValidationErrors(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			errs := &service.ValidationErrors{}
+			errs.Add("name", i18n.NameRequired)
+			errs.Add("phone", i18n.PhoneRequired)
+			errs.Add("city", i18n.CityRequired)
+			return errs
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/employees", bytes.NewBufferString(`{}`))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	var resp domain.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != domain.CodeValidation || len(resp.Errors) != 3 || resp.Errors[2].Field != "city" || resp.Error == "" {
+		t.Fatalf("unexpected resp: %+v", resp)
+	}
+}
+
+func TestCreateEmployee_ValidationErrorsLocalized(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			errs := &service.ValidationErrors{}
+			errs.Add("name", i18n.NameRequired)
+			errs.Add("phone", i18n.PhoneRequired)
+			return errs
+		},
+	}
+	r := newRouter(svc)
+
+	tests := []struct {
+		acceptLanguage string
+		wantLocale     string
+		wantName       string
+		wantMessage    string
+	}{
+		{"", "ru", "имя обязательно", "имя обязательно; телефон обязателен"},
+		{"en-US,en;q=0.9", "en", "name is required", "name is required; phone is required"},
+		{"de, kk;q=0.8, ru;q=0.5", "kk", "аты міндетті", "аты міндетті; телефон міндетті"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantLocale, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/employees", bytes.NewBufferString(`{}`))
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+			}
+			if got := rr.Header().Get("Content-Language"); got != tt.wantLocale {
+				t.Fatalf("expected Content-Language %s, got %q", tt.wantLocale, got)
+			}
+			var resp domain.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Message != tt.wantMessage || resp.Errors[0].Field != "name" || resp.Errors[0].Message != tt.wantName {
+				t.Fatalf("unexpected resp: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestGetEmployee_NotFoundLocalized(t *testing.T) {
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return nil, &repository.NotFoundError{Entity: "employee", ID: id}
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/5", nil)
+	req.Header.Set("Accept-Language", "en")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, rr.Code)
+	}
+	var resp domain.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != domain.CodeNotFound || resp.Message != "employee not found" {
+		t.Fatalf("unexpected resp: %+v", resp)
+	}
+}
+
+func TestParseID_OutOfRangeLocalized(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/99999999999999999999", nil)
+	req.Header.Set("Accept-Language", "en")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var resp domain.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want := "invalid ID: expected an integer from 1 to 9007199254740991"; resp.Message != want {
+		t.Fatalf("expected message %q, got %q", want, resp.Message)
+	}
+}
+
+func TestGetEmployee_ETagNotModified(t *testing.T) {
+	employee := &domain.Employee{ID: 3, Name: "Bob", Phone: "123", City: "Astana"}
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			e := *employee
+			return &e, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/3", nil))
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected 200 with weak ETag, got %d %q", rr.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("expected %d, got %d", http.StatusNotModified, rr.Code)
+	}
+	if rr.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rr.Body.String())
+	}
+	if rr.Header().Get("ETag") != etag {
+		t.Fatalf("expected ETag on 304 response")
+	}
+
+	// после изменения любого поля ответа старый ETag больше не совпадает
+	birth := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	changes := map[string]func(e *domain.Employee){
+		"city":       func(e *domain.Employee) { e.City = "Almaty" },
+		"status":     func(e *domain.Employee) { e.Status = domain.StatusInactive },
+		"version":    func(e *domain.Employee) { e.Version = 2 },
+		"updated_by": func(e *domain.Employee) { e.UpdatedBy = "hr" },
+		"birth_date": func(e *domain.Employee) { e.BirthDate = &birth },
+	}
+	original := *employee
+	for name, change := range changes {
+		*employee = original
+		change(employee)
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected %d after change, got %d", name, http.StatusOK, rr.Code)
+		}
+	}
+}
+
+func TestGetEmployee_IfModifiedSince(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 10, 30, 15, 500_000_000, time.UTC)
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return &domain.Employee{ID: 3, Name: "Bob", Phone: "123", City: "Astana", UpdatedAt: updatedAt}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"equal", "Fri, 01 Mar 2024 10:30:15 GMT", http.StatusNotModified},
+		{"newer", "Fri, 01 Mar 2024 11:00:00 GMT", http.StatusNotModified},
+		{"older", "Fri, 01 Mar 2024 10:30:14 GMT", http.StatusOK},
+		{"malformed", "вчера", http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/employees/3", nil)
+			req.Header.Set("If-Modified-Since", tc.header)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rr.Code)
+			}
+			if got := rr.Header().Get("Last-Modified"); got != "Fri, 01 Mar 2024 10:30:15 GMT" {
+				t.Fatalf("unexpected Last-Modified %q", got)
+			}
+			if got := rr.Header().Get("Cache-Control"); got != "private" {
+				t.Fatalf("expected Cache-Control private, got %q", got)
+			}
+			if tc.want == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Fatalf("expected empty body on 304, got %q", rr.Body.String())
+			}
+			if tc.want == http.StatusOK && !strings.Contains(rr.Body.String(), `"name":"Bob"`) {
+				t.Fatalf("expected full response, got %q", rr.Body.String())
+			}
+		})
+	}
+
+	// If-None-Match важнее If-Modified-Since
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3", nil)
+	req.Header.Set("If-None-Match", `"other"`)
+	req.Header.Set("If-Modified-Since", "Fri, 01 Mar 2024 11:00:00 GMT")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected If-None-Match to take precedence, got %d", rr.Code)
+	}
+}
+
+func TestGetRecentEmployees(t *testing.T) {
+	var gotLimit int
+	svc := &mockService{
+		RecentFn: func(ctx context.Context, limit int) ([]*domain.Employee, error) {
+			gotLimit = limit
+			return []*domain.Employee{
+				{ID: 9, Name: "Newest", Phone: "+77010000009", City: "Almaty"},
+				{ID: 8, Name: "Older", Phone: "+77010000008", City: "Astana"},
+			}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/recent?limit=5", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if gotLimit != 5 {
+		t.Fatalf("expected limit 5, got %d", gotLimit)
+	}
+	var resp []domain.EmployeeResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp) != 2 || resp[0].ID != 9 {
+		t.Fatalf("expected newest first, got %+v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/recent?limit=abc", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for invalid limit, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestCountEmployees(t *testing.T) {
+	var gotCity string
+	svc := &mockService{
+		CountFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			gotCity = filter.City
+			return 42, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/count?city=Almaty", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if gotCity != "Almaty" {
+		t.Fatalf("expected city filter Almaty, got %q", gotCity)
+	}
+	var resp domain.CountResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 42 {
+		t.Fatalf("expected count 42, got %d", resp.Count)
+	}
+}
+
+func TestHeadEmployees(t *testing.T) {
+	svc := &mockService{
+		CountFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			return 7, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodHead, "/api/employees", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("X-Total-Count"); got != "7" {
+		t.Fatalf("expected X-Total-Count 7, got %q", got)
+	}
+	if rr.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rr.Body.String())
+	}
+}
+
+func TestCheckPhone(t *testing.T) {
+	var gotPhone string
+	var gotExclude int64
+	svc := &mockService{
+		CheckFn: func(ctx context.Context, phone string, excludeID int64) (*domain.Employee, error) {
+			gotPhone, gotExclude = phone, excludeID
+			return &domain.Employee{ID: 3, Name: "Иван Петров", Phone: phone, City: "Almaty"}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/check-phone?phone=%2B77010000000&exclude_id=5", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if gotPhone != "+77010000000" || gotExclude != 5 {
+		t.Fatalf("unexpected args: phone=%q exclude=%d", gotPhone, gotExclude)
+	}
+	if strings.Contains(rr.Body.String(), "Almaty") || strings.Contains(rr.Body.String(), "77010000000") {
+		t.Fatalf("response leaks employee details: %s", rr.Body.String())
+	}
+	var resp domain.PhoneCheckResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Exists || resp.Employee == nil || resp.Employee.ID != 3 || resp.Employee.Name != "И*** П*****" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestCheckPhone_NotExists(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/check-phone?phone=%2B77010000000", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := strings.TrimSpace(rr.Body.String()); got != `{"exists":false}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestGetAllEmployees_PaginationLinks(t *testing.T) {
+	var gotLimit, gotOffset int
+	svc := &mockService{
+		PageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*domain.Employee{{ID: 21, Name: "A", Phone: "+77010000021", City: "Almaty"}}, 95, nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "http://hr.example.com/api/employees?limit=20&offset=40", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if gotLimit != 20 || gotOffset != 40 {
+		t.Fatalf("unexpected page: limit=%d offset=%d", gotLimit, gotOffset)
+	}
+
+	link := rr.Header().Get("Link")
+	for _, want := range []string{
+		`<http://hr.example.com/api/employees?limit=20&offset=60>; rel="next"`,
+		`<http://hr.example.com/api/employees?limit=20&offset=20>; rel="prev"`,
+		`<http://hr.example.com/api/employees?limit=20&offset=0>; rel="first"`,
+		`<http://hr.example.com/api/employees?limit=20&offset=80>; rel="last"`,
+	} {
+		if !strings.Contains(link, want) {
+			t.Fatalf("Link header %q does not contain %q", link, want)
+		}
+	}
+	if got := rr.Header().Get("X-Total-Count"); got != "95" {
+		t.Fatalf("expected X-Total-Count 95, got %q", got)
+	}
+}
+
+func TestGetAllEmployees_LimitClampedToMax(t *testing.T) {
+	var gotLimit int
+	svc := &mockService{
+		PageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit = limit
+			return nil, 500, nil
+		},
+	}
+	h := handler.NewEmployeeHandler(svc, zap.NewNop())
+	h.SetPageLimits(50, 200)
+	r := mux.NewRouter()
+	h.RegisterRoutes(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?limit=1000&envelope=true", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected limit above max to be clamped, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if gotLimit != 200 {
+		t.Fatalf("expected limit clamped to 200, got %d", gotLimit)
+	}
+	var resp domain.ListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.Meta.Limit != 200 {
+		t.Fatalf("expected meta.limit 200, got %s (%v)", rr.Body.String(), err)
+	}
+	if link := rr.Header().Get("Link"); !strings.Contains(link, "limit=200&offset=200") {
+		t.Fatalf("expected links with clamped limit, got %q", link)
+	}
+
+	// без limit — значение по умолчанию из настроек
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?offset=0", nil))
+	if gotLimit != 50 {
+		t.Fatalf("expected default limit 50, got %d", gotLimit)
+	}
+}
+
+func TestGetAllEmployees_LastPageHasNoNext(t *testing.T) {
+	svc := &mockService{
+		PageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, int64, error) {
+			return nil, 95, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?limit=20&offset=80", nil))
+
+	if link := rr.Header().Get("Link"); strings.Contains(link, `rel="next"`) {
+		t.Fatalf("unexpected next link on last page: %q", link)
+	}
+}
+
+func TestCreateEmployee_Conflict(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &service.ConflictError{Field: "phone", Message: "сотрудник с таким телефоном уже существует", EmployeeID: 7}
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees", bytes.NewBufferString(body)))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d", http.StatusConflict, rr.Code)
+	}
+	var resp struct {
+		Code    string                 `json:"code"`
+		Details domain.ConflictDetails `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != domain.CodeConflict || resp.Details.Field != "phone" || resp.Details.EmployeeID != 7 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestCreateEmployee_AlreadyExists(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			return fmt.Errorf("создание сотрудника: %w",
+				&repository.AlreadyExistsError{Entity: "employee", Field: "phone", Value: e.Phone})
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees", bytes.NewBufferString(body)))
+
+	// конфликт с существующими данными — 409, а не ошибка валидации 400
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d", http.StatusConflict, rr.Code)
+	}
+	var resp struct {
+		Code    string                 `json:"code"`
+		Details domain.ConflictDetails `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != domain.CodeConflict || resp.Details.Field != "phone" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestUpdateEmployee_Conflict(t *testing.T) {
+	svc := &mockService{
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &service.ConflictError{Field: "phone", Message: "сотрудник с таким телефоном уже существует"}
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty","version":2}`
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/employees/5", bytes.NewBufferString(body)))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d", http.StatusConflict, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeConflict)
+}
+
+func TestSearchEmployees_PaginationTotal(t *testing.T) {
+	var gotLimit, gotOffset int
+	svc := &mockService{
+		SearchPageFn: func(ctx context.Context, query string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*domain.Employee{{ID: 11, Name: "John", Phone: "+77010000011", City: "Almaty"}}, 31, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/search?q=john&limit=10&offset=10&envelope=true", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if gotLimit != 10 || gotOffset != 10 {
+		t.Fatalf("unexpected page: limit=%d offset=%d", gotLimit, gotOffset)
+	}
+	if got := rr.Header().Get("X-Total-Count"); got != "31" {
+		t.Fatalf("expected X-Total-Count 31, got %q", got)
+	}
+	var resp struct {
+		Meta domain.ListMeta `json:"meta"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Meta.Total != 31 || resp.Meta.Count != 1 || resp.Meta.Limit != 10 || resp.Meta.Offset != 10 {
+		t.Fatalf("unexpected meta: %+v", resp.Meta)
+	}
+}
+
+func TestSearchEmployees_ResultsTruncated(t *testing.T) {
+	var gotLimit int
+	var total int64
+	svc := &mockService{
+		SearchPageFn: func(ctx context.Context, query string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit = limit
+			employees := make([]*domain.Employee, 0, limit)
+			for i := offset; i < offset+limit && int64(i) < total; i++ {
+				employees = append(employees, &domain.Employee{ID: int64(i + 1), Name: "John", Phone: "+77010000000", City: "Almaty"})
+			}
+			return employees, total, nil
+		},
+	}
+	h := handler.NewEmployeeHandler(svc, zap.NewNop())
+	h.SetSearchMaxResults(3)
+	r := mux.NewRouter()
+	h.RegisterRoutes(r)
+
+	tests := []struct {
+		name   string
+		total  int64
+		url    string
+		want   string
+		wantIn int
+	}{
+		{"all matches fit", 3, "/api/employees/search?q=john", "false", 3},
+		{"one more match", 4, "/api/employees/search?q=john", "true", 3},
+		{"last page", 4, "/api/employees/search?q=john&offset=3", "false", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total = tt.total
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+			if gotLimit != 3 {
+				t.Fatalf("expected SEARCH_MAX_RESULTS as default limit, got %d", gotLimit)
+			}
+			if got := rr.Header().Get(handler.ResultsTruncatedHeader); got != tt.want {
+				t.Fatalf("expected %s %q, got %q", handler.ResultsTruncatedHeader, tt.want, got)
+			}
+			var items []domain.EmployeeResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &items); err != nil || len(items) != tt.wantIn {
+				t.Fatalf("expected %d results, got %s (%v)", tt.wantIn, rr.Body.String(), err)
+			}
+		})
+	}
+}
+
+func TestSearchEmployees_Highlight(t *testing.T) {
+	svc := &mockService{
+		SearchPageFn: func(ctx context.Context, query string, limit, offset int) ([]*domain.Employee, int64, error) {
+			return []*domain.Employee{{ID: 3, Name: "Ерлан Әбенов", Phone: "+77010000003", City: "Шымкент"}}, 1, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/search?q="+url.QueryEscape("әбен")+"&highlight=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var results []domain.SearchResultResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &results); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rr.Body.String())
+	}
+	want := domain.SearchResultResponse{MatchedField: "name", MatchStart: 6, MatchEnd: 10}
+	if len(results) != 1 || results[0].Employee.ID != 3 || results[0].MatchedField != want.MatchedField ||
+		results[0].MatchStart != want.MatchStart || results[0].MatchEnd != want.MatchEnd {
+		t.Fatalf("unexpected results: %s", rr.Body.String())
+	}
+
+	// без highlight ответ — прежний плоский массив сотрудников
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/search?q=john", nil))
+	if !strings.HasPrefix(rr.Body.String(), `[{"id":3,`) {
+		t.Fatalf("expected flat employee array, got %s", rr.Body.String())
+	}
+
+	for _, query := range []string{"highlight=maybe", "highlight=true&fields=id,name"} {
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/search?q=john&"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeValidation)
+	}
+}
+
+func TestGetEmployee_AsOf(t *testing.T) {
+	var gotAt time.Time
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			t.Fatal("GetEmployee must not be called when as_of is set")
+			return nil, nil
+		},
+		AsOfFn: func(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+			gotAt = at
+			return &domain.Employee{ID: id, Name: "Old Bob", Phone: "123", City: "Astana"}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	tests := []struct {
+		asOf string
+		want time.Time
+	}{
+		{asOf: "2024-01-01", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{asOf: "2024-01-01T12:30:00Z", want: time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/7?as_of="+tt.asOf, nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("as_of=%s: expected %d, got %d: %s", tt.asOf, http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if !gotAt.Equal(tt.want) {
+			t.Fatalf("as_of=%s: expected %v, got %v", tt.asOf, tt.want, gotAt)
+		}
+		var resp domain.EmployeeResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.Name != "Old Bob" {
+			t.Fatalf("unexpected resp: %+v", resp)
+		}
+	}
+}
+
+func TestGetEmployee_AsOfInvalid(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/7?as_of=01.01.2024", nil))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestGetEmployeeHistory(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	svc := &mockService{
+		HistoryFn: func(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+			return []*domain.EmployeeVersion{
+				{EmployeeID: id, Name: "Old Bob", Phone: "123", City: "Astana", ValidFrom: from, ValidTo: &to},
+				{EmployeeID: id, Name: "Bob", Phone: "123", City: "Almaty", ValidFrom: to},
+			}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/7/history", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp []domain.EmployeeVersion
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp) != 2 || resp[0].Name != "Old Bob" || resp[0].ValidTo == nil || !resp[0].ValidTo.Equal(to) {
+		t.Fatalf("unexpected history: %+v", resp)
+	}
+	if resp[1].ValidTo != nil {
+		t.Fatalf("current version must not have valid_to: %+v", resp[1])
+	}
+}
+
+func TestGetEmployeeStats(t *testing.T) {
+	svc := &mockService{
+		StatsFn: func(ctx context.Context) (*repository.EmployeeStats, error) {
+			return &repository.EmployeeStats{TotalCount: 12, CitiesCount: 3, MostCommonCity: "Almaty"}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/stats", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp repository.EmployeeStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.TotalCount != 12 || resp.MostCommonCity != "Almaty" {
+		t.Fatalf("unexpected stats: %+v", resp)
+	}
+}
+
+func TestDeleteEmployees_Batch(t *testing.T) {
+	var gotIDs []int64
+	var gotDryRun bool
+	svc := &mockService{
+		BatchDeleteFn: func(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error) {
+			gotIDs, gotDryRun = ids, dryRun
+			return &domain.BatchDeleteResponse{Deleted: []int64{1}, NotFound: []int64{2}, DryRun: dryRun}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := strings.NewReader(`{"ids":[1,2],"dry_run":true}`)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/employees", body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if len(gotIDs) != 2 || !gotDryRun {
+		t.Fatalf("unexpected call: ids=%v dryRun=%v", gotIDs, gotDryRun)
+	}
+	var resp domain.BatchDeleteResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Deleted) != 1 || resp.Deleted[0] != 1 || len(resp.NotFound) != 1 || resp.NotFound[0] != 2 || !resp.DryRun {
+		t.Fatalf("unexpected resp: %+v", resp)
+	}
+}
+
+func TestDeleteEmployees_BatchValidation(t *testing.T) {
+	svc := &mockService{
+		BatchDeleteFn: func(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error) {
+			return nil, &service.ValidationError{Field: "ids", Message: "список ID не может быть пустым"}
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/employees", strings.NewReader(`{"ids":[]}`)))
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestGetAllEmployees_ByIDs(t *testing.T) {
+	var gotIDs []int64
+	svc := &mockService{
+		ByIDsFn: func(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+			gotIDs = ids
+			return []*domain.Employee{{ID: 3, Name: "Asel"}, {ID: 1, Name: "John"}}, nil
+		},
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			t.Fatal("ids must not fall back to the full list")
+			return nil, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?ids=3,%201,2&fields=id", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if fmt.Sprint(gotIDs) != "[3 1 2]" {
+		t.Fatalf("unexpected ids: %v", gotIDs)
+	}
+	want := `[{"id":3},{"id":1}]`
+	if strings.TrimSpace(rr.Body.String()) != want {
+		t.Fatalf("expected %s, got %s", want, rr.Body.String())
+	}
+}
+
+func TestGetAllEmployees_ByIDsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"not a number", "ids=1,abc"},
+		{"with limit", "ids=1,2&limit=10"},
+		{"with city", "ids=1&city=Almaty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				ByIDsFn: func(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+					t.Fatal("service must not be called for invalid ids")
+					return nil, nil
+				},
+			}
+			r := newRouter(svc)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?"+tt.query, nil))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			assertErrorCode(t, rr, domain.CodeValidation)
+		})
+	}
+}
+
+func TestGetCityCounts(t *testing.T) {
+	svc := &mockService{
+		CityCountsFn: func(ctx context.Context) ([]*domain.CityCount, error) {
+			return []*domain.CityCount{{City: "Almaty", EmployeeCount: 7}, {City: "Astana", EmployeeCount: 2}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/cities", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	want := `[{"city":"Almaty","employee_count":7},{"city":"Astana","employee_count":2}]`
+	if strings.TrimSpace(rr.Body.String()) != want {
+		t.Fatalf("expected %s, got %s", want, rr.Body.String())
+	}
+}
+
+func TestGetCityCounts_Empty(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/cities", nil))
+
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Fatalf("expected 200 with empty array, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetUpcomingAnniversaries(t *testing.T) {
+	var gotDays int
+	birth := time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)
+	svc := &mockService{
+		AnniversariesFn: func(ctx context.Context, withinDays int) ([]*domain.Anniversary, error) {
+			gotDays = withinDays
+			return []*domain.Anniversary{{
+				Employee: &domain.Employee{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty", BirthDate: &birth},
+				Kind:     domain.AnniversaryBirthday,
+				Date:     time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+				Years:    25,
+			}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/anniversaries", nil))
+	if rr.Code != http.StatusOK || gotDays != service.DefaultAnniversaryDays {
+		t.Fatalf("expected 200 with default days, got %d (days %d)", rr.Code, gotDays)
+	}
+	want := `[{"employee":{"id":1,"name":"Alice","phone":"+77010000001","city":"Almaty","birth_date":"2000-02-29"},"kind":"birthday","date":"2025-02-28","years":25}]`
+	if strings.TrimSpace(rr.Body.String()) != want {
+		t.Fatalf("expected %s, got %s", want, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/anniversaries?days=30", nil))
+	if rr.Code != http.StatusOK || gotDays != 30 {
+		t.Fatalf("expected days 30, got %d (%d)", gotDays, rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/anniversaries?days=soon", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for non-numeric days, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestCreateEmployee_Dates(t *testing.T) {
+	var got *domain.Employee
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			got = e
+			e.ID = 7
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty","birth_date":"2000-02-29","hire_date":"2020-03-01"}`
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if domain.FormatDate(got.BirthDate) != "2000-02-29" || domain.FormatDate(got.HireDate) != "2020-03-01" {
+		t.Fatalf("unexpected dates passed to service: %v %v", got.BirthDate, got.HireDate)
+	}
+	if !strings.Contains(rr.Body.String(), `"birth_date":"2000-02-29","hire_date":"2020-03-01"`) {
+		t.Fatalf("expected dates in response, got %s", rr.Body.String())
+	}
+
+	// несуществующая дата и неверный формат — ошибки обоих полей
+	body = `{"name":"Alice","phone":"+77010000000","city":"Almaty","birth_date":"2023-02-29","hire_date":"01.03.2020"}`
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body)))
+	if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), `"field":"birth_date"`) || !strings.Contains(rr.Body.String(), `"field":"hire_date"`) {
+		t.Fatalf("expected 422 with both date fields, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetCityEmployees(t *testing.T) {
+	var gotCity string
+	var gotLimit, gotOffset int
+	svc := &mockService{
+		CityEmployeesFn: func(ctx context.Context, city string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotCity, gotLimit, gotOffset = city, limit, offset
+			return []*domain.Employee{{ID: 1, Name: "Alice", City: "Almaty"}}, 3, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/cities/Alma%20Ata/employees?limit=1&offset=1", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if gotCity != "Alma Ata" || gotLimit != 1 || gotOffset != 1 {
+		t.Fatalf("unexpected call: city=%q limit=%d offset=%d", gotCity, gotLimit, gotOffset)
+	}
+	if rr.Header().Get("X-Total-Count") != "3" || !strings.Contains(rr.Header().Get("Link"), `rel="next"`) {
+		t.Fatalf("expected pagination headers, got %v", rr.Header())
+	}
+}
+
+func TestGetCityEmployees_ValidationError(t *testing.T) {
+	svc := &mockService{
+		CityEmployeesFn: func(ctx context.Context, city string, limit, offset int) ([]*domain.Employee, int64, error) {
+			return nil, 0, service.NewValidationError("city", i18n.CityTooLong, service.MaxCityLength)
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/cities/x/employees", nil))
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestDecodeBody_UnsupportedContentType(t *testing.T) {
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("service must not be called for unsupported Content-Type")
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodPost, "/api/employees", body},
+		{http.MethodPut, "/api/employees/1", body},
+		{http.MethodDelete, "/api/employees", `{"ids":[1]}`},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "text/plain")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, http.StatusUnsupportedMediaType, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeUnsupportedMediaType)
+		if !strings.Contains(rr.Body.String(), "text/plain") {
+			t.Fatalf("expected Content-Type in message, got %s", rr.Body.String())
+		}
+	}
+}
+
+func TestWriteError_InternalDetail(t *testing.T) {
+	tests := []struct {
+		name       string
+		details    bool
+		wantDetail string
+	}{
+		{"development", true, "получение сотрудника: connection refused"},
+		{"production", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+					return nil, errors.New("получение сотрудника: connection refused")
+				},
+			}
+			h := handler.NewEmployeeHandler(svc, zap.NewNop())
+			h.SetErrorDetails(tt.details)
+			r := mux.NewRouter()
+			h.RegisterRoutes(r)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/1", nil))
+
+			if rr.Code != http.StatusInternalServerError {
+				t.Fatalf("expected %d, got %d", http.StatusInternalServerError, rr.Code)
+			}
+			var resp map[string]interface{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp["code"] != domain.CodeInternal {
+				t.Fatalf("unexpected code: %v", resp["code"])
+			}
+			detail, ok := resp["detail"]
+			if tt.wantDetail == "" {
+				if ok {
+					t.Fatalf("detail must be hidden, got %v", detail)
+				}
+				return
+			}
+			if detail != tt.wantDetail {
+				t.Fatalf("expected detail %q, got %v", tt.wantDetail, detail)
+			}
+		})
+	}
+}
+
+func TestDecodeBody_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		require     bool
+		wantStatus  int
+	}{
+		{"json with charset", "application/json; charset=utf-8", false, http.StatusCreated},
+		{"missing allowed by default", "", false, http.StatusCreated},
+		{"missing when required", "", true, http.StatusUnsupportedMediaType},
+		{"form", "application/x-www-form-urlencoded", false, http.StatusUnsupportedMediaType},
+		{"malformed", "application/", false, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewEmployeeHandler(&mockService{}, zap.NewNop())
+			h.SetRequireContentType(tt.require)
+			r := mux.NewRouter()
+			h.RegisterRoutes(r)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/employees",
+				strings.NewReader(`{"name":"Alice","phone":"+77010000000","city":"Almaty"}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateEmployee_ExpectedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		ifMatch string
+		want    int
+	}{
+		{"body field", `{"name":"Alice","phone":"+77010000000","city":"Almaty","version":3}`, "", 3},
+		{"If-Match", `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`, `"4"`, 4},
+		{"weak If-Match", `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`, `W/"5"`, 5},
+		{"body wins over If-Match", `{"name":"Alice","phone":"+77010000000","city":"Almaty","version":3}`, `"4"`, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := -1
+			svc := &mockService{
+				UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+					got = e.Version
+					e.Version++
+					return nil
+				},
+			}
+			r := newRouter(svc)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/employees/7", strings.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+			if got != tt.want {
+				t.Fatalf("expected version %d, got %d", tt.want, got)
+			}
+			var resp domain.EmployeeResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.Version != tt.want+1 {
+				t.Fatalf("expected new version %d in response, got %s", tt.want+1, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateEmployee_InvalidIfMatch(t *testing.T) {
+	svc := &mockService{
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("service must not be called for invalid If-Match")
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/employees/7", strings.NewReader(`{"name":"Alice","phone":"+77010000000","city":"Almaty"}`))
+	req.Header.Set("If-Match", `abc`)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestUpdateEmployee_VersionRequired(t *testing.T) {
+	svc := &mockService{
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("service must not be called without the expected version")
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	// без version и If-Match, как и с If-Match: *, обновление затерло бы чужие изменения
+	for _, ifMatch := range []string{"", "*"} {
+		req := httptest.NewRequest(http.MethodPut, "/api/employees/7", strings.NewReader(`{"name":"Alice","phone":"+77010000000","city":"Almaty"}`))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusPreconditionRequired {
+			t.Fatalf("If-Match %q: expected %d, got %d", ifMatch, http.StatusPreconditionRequired, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodePreconditionRequired)
+	}
+}
+
+func TestUpdateEmployee_IfMatchETag(t *testing.T) {
+	stored := &domain.Employee{ID: 7, Name: "Alice", Phone: "+77010000000", City: "Almaty", Status: domain.StatusActive, Version: 4}
+	got := -1
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			current := *stored
+			return &current, nil
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			got = e.Version
+			e.Version++
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/7", nil))
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", rr.Code, etag)
+	}
+
+	put := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/employees/7", strings.NewReader(`{"name":"Alice B","phone":"+77010000000","city":"Almaty"}`))
+		req.Header.Set("If-Match", etag)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// ETag из ответа GET — обновление в версии, которую видел клиент
+	if rr := put(); rr.Code != http.StatusOK || got != 4 {
+		t.Fatalf("expected 200 with expected version 4, got %d (version %d): %s", rr.Code, got, rr.Body.String())
+	}
+
+	// сотрудник изменен после GET — 409 с текущей версией
+	stored.City, stored.Version = "Astana", 5
+	got = -1
+	rr = put()
+	if rr.Code != http.StatusConflict || got != -1 {
+		t.Fatalf("expected %d without calling the service, got %d", http.StatusConflict, rr.Code)
+	}
+	var resp struct {
+		Details domain.ConflictDetails `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.Details.Field != "version" || resp.Details.Version != 5 {
+		t.Fatalf("expected current version in details, got %s", rr.Body.String())
+	}
+}
+
+func TestUpdateEmployee_VersionConflict(t *testing.T) {
+	svc := &mockService{
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &service.ConflictError{Field: "version", Message: "сотрудник изменен", Key: i18n.ConflictVersion, EmployeeID: e.ID, Version: 8}
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/employees/7",
+		strings.NewReader(`{"name":"Alice","phone":"+77010000000","city":"Almaty","version":3}`)))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d", http.StatusConflict, rr.Code)
+	}
+	var resp struct {
+		Code    string                 `json:"code"`
+		Details domain.ConflictDetails `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != domain.CodeConflict || resp.Details.Field != "version" || resp.Details.EmployeeID != 7 || resp.Details.Version != 8 {
+		t.Fatalf("unexpected conflict response: %s", rr.Body.String())
+	}
+}
+
+func TestErrorResponses_Codes(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"validation", &service.ValidationError{Field: "name", Message: "имя обязательно"}, http.StatusUnprocessableEntity, domain.CodeValidation},
+		{"not found", &repository.NotFoundError{Entity: "employee", ID: 7}, http.StatusNotFound, domain.CodeNotFound},
+		{"wrapped not found", fmt.Errorf("получение: %w", &repository.NotFoundError{Entity: "employee", ID: 7}), http.StatusNotFound, domain.CodeNotFound},
+		{"twice wrapped not found", fmt.Errorf("получение сотрудника 7: %w", fmt.Errorf("получение: %w", &repository.NotFoundError{Entity: "employee", ID: 7})), http.StatusNotFound, domain.CodeNotFound},
+		{"wrapped validation", fmt.Errorf("импорт: %w", &service.ValidationErrors{Errors: []service.ValidationError{{Field: "name", Message: "имя обязательно"}}}), http.StatusUnprocessableEntity, domain.CodeValidation},
+		{"conflict", &service.ConflictError{Field: "phone", Message: "телефон занят", EmployeeID: 3}, http.StatusConflict, domain.CodeConflict},
+		{"already exists", &repository.AlreadyExistsError{Entity: "employee", Field: "phone", Value: "+77010000001"}, http.StatusConflict, domain.CodeConflict},
+		{"wrapped already exists", fmt.Errorf("создание: %w", &repository.AlreadyExistsError{Entity: "employee", Field: "phone"}), http.StatusConflict, domain.CodeConflict},
+		{"internal", errors.New("connection reset"), http.StatusInternalServerError, domain.CodeInternal},
+		// текст ошибки больше не влияет на код ответа
+		{"internal mentioning not found", errors.New("таблица не найдена"), http.StatusInternalServerError, domain.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+					return nil, tt.err
+				},
+			}
+			r := newRouter(svc)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/7", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rr.Code)
+			}
+			assertErrorCode(t, rr, tt.wantCode)
+		})
+	}
+}
+
+func TestErrorResponses_InternalHidesDetails(t *testing.T) {
+	svc := &mockService{
+		DeleteFn: func(ctx context.Context, id int64) error {
+			return errors.New("pq: password authentication failed")
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/employees/7", nil))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeInternal)
+	if strings.Contains(rr.Body.String(), "password") {
+		t.Fatalf("internal error details leaked: %s", rr.Body.String())
+	}
+}
+
+func TestUpdateEmployee_InvalidJSONCode(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/employees/7", strings.NewReader(`{"name":`)))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestParseID_OutOfRange(t *testing.T) {
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			t.Fatalf("service must not be called for id %d", id)
+			return nil, nil
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("service must not be called for id %d", e.ID)
+			return nil
+		},
+		DeleteFn: func(ctx context.Context, id int64) error {
+			t.Fatalf("service must not be called for id %d", id)
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000000","city":"Almaty"}`
+	for _, id := range []string{"0", "9007199254740992", "99999999999999999999999"} {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			t.Run(method+" "+id, func(t *testing.T) {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest(method, "/api/employees/"+id, strings.NewReader(body)))
+
+				if rr.Code != http.StatusBadRequest {
+					t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+				}
+				assertErrorCode(t, rr, domain.CodeValidation)
+			})
+		}
+	}
+}
+
+func TestParseID_MaxAccepted(t *testing.T) {
+	var gotID int64
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			gotID = id
+			return &domain.Employee{ID: id}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/9007199254740991", nil))
+
+	if rr.Code != http.StatusOK || gotID != 9007199254740991 {
+		t.Fatalf("expected max id 2^53-1 to be accepted, got %d (id %d)", rr.Code, gotID)
+	}
+}
+
+func TestEmployeeStatusEndpoints(t *testing.T) {
+	var gotID int64
+	var gotStatus string
+	svc := &mockService{
+		StatusFn: func(ctx context.Context, id int64, status string) (*domain.Employee, error) {
+			gotID, gotStatus = id, status
+			return &domain.Employee{ID: id, Name: "Alice", Status: status}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	tests := []struct {
+		path       string
+		wantStatus string
+	}{
+		{"/api/employees/4/deactivate", domain.StatusInactive},
+		{"/api/employees/4/activate", domain.StatusActive},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected %d, got %d", tt.path, http.StatusOK, rr.Code)
+		}
+		var resp domain.EmployeeResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if gotID != 4 || gotStatus != tt.wantStatus || resp.Status != tt.wantStatus {
+			t.Fatalf("%s: expected status %q for id 4, got %q (id %d), response %q", tt.path, tt.wantStatus, gotStatus, gotID, resp.Status)
+		}
+	}
+}
+
+func TestGetAllEmployees_StatusFilter(t *testing.T) {
+	svc := &mockService{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			return []*domain.Employee{{ID: 1, Name: "Alice", Status: domain.StatusOnLeave}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?status=on_leave&city=Almaty", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if svc.lastFilter.Status != domain.StatusOnLeave || svc.lastFilter.City != "Almaty" {
+		t.Fatalf("unexpected filter: %+v", svc.lastFilter)
+	}
+	if !strings.Contains(rr.Body.String(), `"status":"on_leave"`) {
+		t.Fatalf("expected status in response, got %s", rr.Body.String())
+	}
+
+	svc.SearchFn = func(ctx context.Context, query string) ([]*domain.Employee, error) {
+		return nil, service.NewValidationError("status", i18n.StatusInvalid, "active, inactive, on_leave")
+	}
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/search?q=al&status=fired", nil))
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	if svc.lastFilter.Status != "fired" {
+		t.Fatalf("expected status filter passed to search, got %+v", svc.lastFilter)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestActorHeader(t *testing.T) {
+	var gotActor string
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			gotActor = service.ActorFromContext(ctx)
+			e.ID = 1
+			e.CreatedBy, e.UpdatedBy = gotActor, gotActor
+			return nil
+		},
+	}
+	r := newRouter(svc)
+
+	body := `{"name":"Alice","phone":"+77010000001","city":"Almaty"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body))
+	req.Header.Set("X-Actor", " alice ")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, rr.Code)
+	}
+	var resp domain.EmployeeResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if gotActor != "alice" || resp.CreatedBy != "alice" || resp.UpdatedBy != "alice" {
+		t.Fatalf("expected actor alice, got %q (response %+v)", gotActor, resp)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body)))
+	if gotActor != service.SystemActor {
+		t.Fatalf("expected %q without header, got %q", service.SystemActor, gotActor)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body))
+	req.Header.Set("X-Actor", strings.Repeat("a", service.MaxActorLength+1))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for long actor, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	var gotID string
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			gotID = service.RequestIDFromContext(ctx)
+			return &domain.Employee{ID: id}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/1", nil)
+	req.Header.Set(handler.RequestIDHeader, "abc-123")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if gotID != "abc-123" || rr.Header().Get(handler.RequestIDHeader) != "abc-123" {
+		t.Fatalf("expected request id abc-123, got %q (header %q)", gotID, rr.Header().Get(handler.RequestIDHeader))
+	}
+
+	// без заголовка и с некорректным значением идентификатор создается заново
+	for _, header := range []string{"", "bad id", strings.Repeat("x", 200)} {
+		req = httptest.NewRequest(http.MethodGet, "/api/employees/1", nil)
+		if header != "" {
+			req.Header.Set(handler.RequestIDHeader, header)
+		}
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if len(gotID) != 32 || rr.Header().Get(handler.RequestIDHeader) != gotID {
+			t.Fatalf("%q: expected generated request id, got %q (header %q)", header, gotID, rr.Header().Get(handler.RequestIDHeader))
+		}
+	}
+}
+
+func TestCreateEmployee_IdempotencyKeyReplay(t *testing.T) {
+	hashes := map[string]string{}
+	var nextID int64
+	svc := &mockService{
+		IdempotentFn: func(ctx context.Context, key, requestHash string, e *domain.Employee) (bool, error) {
+			if hash, ok := hashes[key]; ok {
+				if hash != requestHash {
+					return false, &service.ConflictError{Field: "Idempotency-Key", Message: "conflict", Key: i18n.ConflictIdempotencyKey}
+				}
+				e.ID = nextID
+				return true, nil
+			}
+			hashes[key] = requestHash
+			nextID++
+			e.ID = nextID
+			return false, nil
+		},
+	}
+	r := newRouter(svc)
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := send("key-1", `{"name":"Alice","phone":"+77010000001","city":"Almaty"}`)
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first: expected 201 without replay header, got %d %q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+
+	// тот же запрос с другим форматированием JSON считается повтором
+	retry := send("key-1", `{ "city": "Almaty", "name": "Alice", "phone": "+77010000001" }`)
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry: expected replayed 201, got %d %q", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if first.Body.String() != retry.Body.String() {
+		t.Fatalf("expected the original response, got %s vs %s", retry.Body.String(), first.Body.String())
+	}
+
+	other := send("key-1", `{"name":"Bob","phone":"+77010000002","city":"Almaty"}`)
+	if other.Code != http.StatusConflict {
+		t.Fatalf("expected %d for another body, got %d", http.StatusConflict, other.Code)
+	}
+	assertErrorCode(t, other, domain.CodeConflict)
+
+	long := send(strings.Repeat("k", service.MaxIdempotencyKeyLength+1), `{"name":"Alice","phone":"+77010000001","city":"Almaty"}`)
+	if long.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for a long key, got %d", http.StatusBadRequest, long.Code)
+	}
+}
+
+func TestEmployee_XMLRoundTrip(t *testing.T) {
+	stored := map[int64]*domain.Employee{}
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			e.ID = 42
+			copied := *e
+			stored[e.ID] = &copied
+			return nil
+		},
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			if e, ok := stored[id]; ok {
+				return e, nil
+			}
+			return nil, &repository.NotFoundError{Entity: "employee", ID: id}
+		},
+	}
+	r := newRouter(svc)
+
+	body := `<employee><name>Alice</name><phone>+77010000000</phone><city>Almaty</city></employee>`
+	req := httptest.NewRequest(http.MethodPost, "/api/employees", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Accept", "application/xml")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: expected %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Fatalf("create: expected application/xml, got %q", ct)
+	}
+	var created domain.EmployeeResponse
+	if err := xml.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("create: decode: %v: %s", err, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/42", nil)
+	req.Header.Set("Accept", "application/xml")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("get: expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var got domain.EmployeeResponse
+	if err := xml.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("get: decode: %v: %s", err, rr.Body.String())
+	}
+	if got != created || got.ID != 42 || got.Name != "Alice" || got.Phone != "+77010000000" || got.City != "Almaty" {
+		t.Fatalf("round trip mismatch: created %+v, got %+v", created, got)
+	}
+
+	// ошибки отдаются в запрошенном формате
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/43", nil)
+	req.Header.Set("Accept", "application/xml")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var errResp domain.ErrorResponse
+	if err := xml.Unmarshal(rr.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("error: decode: %v: %s", err, rr.Body.String())
+	}
+	if rr.Code != http.StatusNotFound || errResp.Code != domain.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND in XML, got %d %+v", rr.Code, errResp)
+	}
+}
+
+func TestGetAllEmployees_XMLList(t *testing.T) {
+	r := newRouter(newListService())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+	req.Header.Set("Accept", "application/json;q=0.5, application/xml")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var list struct {
+		XMLName   xml.Name                  `xml:"employees"`
+		Employees []domain.EmployeeResponse `xml:"employee"`
+	}
+	if err := xml.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v: %s", err, rr.Body.String())
+	}
+	if len(list.Employees) != 2 || list.Employees[1].Name != "John Smith" {
+		t.Fatalf("unexpected list: %s", rr.Body.String())
+	}
+}
+
+func TestGetEmployeeStats_XML(t *testing.T) {
+	svc := &mockService{
+		StatsFn: func(ctx context.Context) (*repository.EmployeeStats, error) {
+			return &repository.EmployeeStats{TotalCount: 3, ByStatus: map[string]int{"inactive": 1, "active": 2}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/stats", nil)
+	req.Header.Set("Accept", "text/xml")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	want := `<stats><total_count>3</total_count><cities_count>0</cities_count><most_common_city></most_common_city>` +
+		`<by_status><status name="active">2</status><status name="inactive">1</status></by_status></stats>`
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), want) {
+		t.Fatalf("unexpected stats: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	r := newRouter(newListService())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees", nil)
+	req.Header.Set("Accept", "text/csv, application/xml;q=0")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected %d, got %d", http.StatusNotAcceptable, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON error, got %q", ct)
+	}
+	assertErrorCode(t, rr, domain.CodeNotAcceptable)
+
+	// выгрузка выбирает формат по параметру format и Accept не проверяет
+	svc := &mockService{
+		ExportFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			return nil
+		},
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/export?format=csv", nil)
+	req.Header.Set("Accept", "text/csv")
+	rr = httptest.NewRecorder()
+	newRouter(svc).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("export: expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+func TestDecodeJSON_Errors(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantField string
+		wantMsg   string
+	}{
+		{"type mismatch", http.MethodPost, "/api/employees", `{"name": 123}`, "name", "invalid field name: expected string"},
+		{"nested type mismatch", http.MethodDelete, "/api/employees", `{"ids": [1, "2"]}`, "ids.1", "invalid field ids.1: expected number"},
+		{"not an object", http.MethodPut, "/api/employees/1", `["Alice"]`, "body", "invalid field body: expected object"},
+		{"syntax error", http.MethodPost, "/api/employees", `{"name" "Alice"}`, "body", "invalid JSON: syntax error at offset 9"},
+		{"empty body", http.MethodPut, "/api/employees/1", ``, "body", "empty request body"},
+		{"truncated", http.MethodPost, "/api/employees", `{"name": "Al`, "body", "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Accept-Language", "en")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+			var resp domain.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Code != domain.CodeValidation || len(resp.Errors) != 1 ||
+				resp.Errors[0].Field != tt.wantField || resp.Errors[0].Message != tt.wantMsg {
+				t.Fatalf("unexpected resp: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestGetEmployee_Expand(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	historyCalls := 0
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return &domain.Employee{ID: id, Name: "Bob", Phone: "123", City: "Astana"}, nil
+		},
+		HistoryFn: func(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+			historyCalls++
+			return []*domain.EmployeeVersion{{EmployeeID: id, Name: "Bob", Phone: "123", City: "Astana", ValidFrom: from}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		var fields map[string]json.RawMessage
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &fields); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rr, fields
+	}
+
+	rr, lean := get("/api/employees/7")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if _, ok := lean["history"]; ok || historyCalls != 0 {
+		t.Fatalf("history must not be loaded without expand: %s", rr.Body.String())
+	}
+
+	rr, expanded := get("/api/employees/7?expand=history")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var history []domain.EmployeeVersion
+	if err := json.Unmarshal(expanded["history"], &history); err != nil || len(history) != 1 || history[0].EmployeeID != 7 {
+		t.Fatalf("expected embedded history, got %s", rr.Body.String())
+	}
+	if string(expanded["name"]) != `"Bob"` || rr.Header().Get("ETag") != "" {
+		t.Fatalf("expected employee fields without ETag, got %s (ETag %q)", rr.Body.String(), rr.Header().Get("ETag"))
+	}
+
+	rr, _ = get("/api/employees/7?expand=history,department")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for unknown expand, got %d", http.StatusBadRequest, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
+
+func TestFieldSelection(t *testing.T) {
+	svc := newListService()
+	svc.PageFn = func(ctx context.Context, limit, offset int) ([]*domain.Employee, int64, error) {
+		return []*domain.Employee{{ID: 2, Name: "John Smith", Phone: "2", City: "Y"}}, 5, nil
+	}
+	r := newRouter(svc)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"list", "/api/employees?fields=name,id", `[{"id":1,"name":"John Doe"},{"id":2,"name":"John Smith"}]`},
+		{"search", "/api/employees/search?q=john&fields=city", `[{"city":"X"},{"city":"Y"}]`},
+		{"page in envelope", "/api/employees?limit=1&offset=1&envelope=true&fields=id",
+			`{"data":[{"id":2}],"meta":{"count":1,"total":5,"limit":1,"offset":1}}`},
+		{"all fields by default", "/api/employees/search?q=john",
+			`[{"id":1,"name":"John Doe","phone":"1","city":"X"},{"id":2,"name":"John Smith","phone":"2","city":"Y"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+			if got := strings.TrimSpace(rr.Body.String()); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees?fields=id,name", nil)
+	req.Header.Set("Accept", "application/xml")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if want := `<employees><employee><id>1</id><name>John Doe</name></employee>`; !strings.Contains(rr.Body.String(), want) {
+		t.Fatalf("expected projected XML, got %s", rr.Body.String())
+	}
+
+	for _, path := range []string{"/api/employees?fields=id,salary", "/api/employees/search?q=john&fields=id,", "/api/employees?limit=1&fields=Name"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected %d, got %d", path, http.StatusBadRequest, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeValidation)
+	}
+}
+
+func TestStreamEvents_SSEFraming(t *testing.T) {
+	broker := service.NewEventBroker()
+	h := handler.NewEmployeeHandler(&mockService{}, zap.NewNop())
+	h.SetEvents(broker)
+	router := mux.NewRouter()
+	h.RegisterRoutes(router)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/employees/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected 200 text/event-stream, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// заголовки получены — подписка уже есть
+	broker.Publish(domain.EmployeeEvent{Op: domain.EventUpdate, ID: 7})
+	broker.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	want := "event: update\ndata: {\"op\":\"update\",\"id\":7}\n\n"
+	if string(body) != want {
+		t.Fatalf("expected %q, got %q", want, body)
+	}
+}
+
+func TestStreamEvents_DisabledWithoutSource(t *testing.T) {
+	r := newRouter(&mockService{})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/events", nil))
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected %d without an event source, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+// testAdminToken токен администратора обработчиков /api/admin в тестах
+const testAdminToken = "test-admin-token-0123456789"
+
+// newAdminRequest запрос к /api/admin с токеном администратора
+func newAdminRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	return req
+}
+
+type fakePurger struct {
+	purged int64
+	err    error
+}
+
+func (f *fakePurger) Purge(ctx context.Context) (int64, error) {
+	return f.purged, f.err
+}
+
+func TestAdminPurge(t *testing.T) {
+	purger := &fakePurger{purged: 4}
+	r := mux.NewRouter()
+	handler.NewAdminHandler(purger, testAdminToken, zap.NewNop()).RegisterRoutes(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/purge", nil))
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != `{"purged":4}` {
+		t.Fatalf("unexpected response: %d %s", rr.Code, rr.Body.String())
+	}
+
+	purger.err = repository.ErrPurgeLocked
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/purge", nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected %d while another instance purges, got %d", http.StatusConflict, rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeConflict)
+}
+
+func TestAdminAuthorize(t *testing.T) {
+	purger := &fakePurger{purged: 1}
+	r := mux.NewRouter()
+	handler.NewAdminHandler(purger, testAdminToken, zap.NewNop()).RegisterRoutes(r)
+
+	for name, header := range map[string]string{
+		"no header":    "",
+		"wrong token":  "Bearer wrong-token",
+		"basic scheme": "Basic " + testAdminToken,
+		"token prefix": "Bearer " + testAdminToken[:10],
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/purge", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if rr.Code != http.StatusUnauthorized {
+				t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rr.Code)
+			}
+			assertErrorCode(t, rr, domain.CodeUnauthorized)
+			if rr.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("expected WWW-Authenticate header")
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/purge", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d with the admin token, got %d", http.StatusOK, rr.Code)
+	}
+
+	// без токена администратора служебные маршруты не регистрируются
+	r = mux.NewRouter()
+	handler.NewAdminHandler(purger, "", zap.NewNop()).RegisterRoutes(r)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/purge", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected %d without ADMIN_TOKEN, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+type fakeConfig struct {
+	current *config.RuntimeConfig
+	err     error
+}
+
+func (f *fakeConfig) Get() *config.RuntimeConfig { return f.current }
+
+func (f *fakeConfig) Reload() (*config.RuntimeConfig, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.current = &config.RuntimeConfig{LogLevel: "debug", CORSAllowedOrigins: []string{"*"}}
+	return f.current, nil
+}
+
+func TestAdminConfig(t *testing.T) {
+	cfg := &fakeConfig{current: &config.RuntimeConfig{
+		LogLevel:           "info",
+		CORSAllowedOrigins: []string{"https://hr.example.com"},
+		CORSMaxAge:         600,
+		CORSExposeHeaders:  []string{"X-Request-ID"},
+	}}
+	admin := handler.NewAdminHandler(nil, testAdminToken, zap.NewNop())
+	admin.SetConfig(cfg)
+	r := mux.NewRouter()
+	admin.RegisterRoutes(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodGet, "/api/admin/config", nil))
+	want := `{"log_level":"info","log_http_bodies":false,"cors_allowed_origins":["https://hr.example.com"],` +
+		`"cors_max_age":600,"cors_expose_headers":["X-Request-ID"]}`
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != want {
+		t.Fatalf("unexpected response: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/config/reload", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"log_level":"debug"`) {
+		t.Fatalf("unexpected reload response: %d %s", rr.Code, rr.Body.String())
+	}
+
+	// некорректная конфигурация — 400 с причиной в деталях
+	cfg.err = errors.New(`LOG_LEVEL должен быть одним из debug, info, warn, error, получено "verbose"`)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/config/reload", nil))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "verbose") {
+		t.Fatalf("unexpected reload response: %d %s", rr.Code, rr.Body.String())
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+
+	// без очистки маршрут purge не регистрируется
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/purge", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected %d without a purger, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestAdminMaintenance(t *testing.T) {
+	maintenance, err := handler.NewMaintenance("", zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMaintenance: %v", err)
+	}
+	admin := handler.NewAdminHandler(nil, testAdminToken, zap.NewNop())
+	admin.SetMaintenance(maintenance)
+	r := mux.NewRouter()
+	r.Use(maintenance.Middleware(""))
+	admin.RegisterRoutes(r)
+	r.HandleFunc("/api/employees", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("GET", "POST", "PUT", "PATCH", "DELETE")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, newAdminRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	if rr := do(http.MethodGet, "/api/admin/maintenance", ""); strings.TrimSpace(rr.Body.String()) != `{"enabled":false}` {
+		t.Fatalf("unexpected state: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodPost, "/api/employees", `{}`); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected %d with maintenance off, got %d", http.StatusNoContent, rr.Code)
+	}
+
+	rr := do(http.MethodPost, "/api/admin/maintenance", `{"enabled":true,"message":"Миграция до 22:00"}`)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"enabled":true`) || !strings.Contains(rr.Body.String(), `"since"`) {
+		t.Fatalf("unexpected toggle response: %d %s", rr.Code, rr.Body.String())
+	}
+
+	// изменения отклоняются, чтение работает
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		rr := do(method, "/api/employees", `{}`)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected %d, got %d", method, http.StatusServiceUnavailable, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeMaintenance)
+		if rr.Header().Get("Retry-After") != "60" || !strings.Contains(rr.Body.String(), "Миграция до 22:00") {
+			t.Fatalf("%s: unexpected response: %v %s", method, rr.Header(), rr.Body.String())
+		}
+	}
+	if rr := do(http.MethodGet, "/api/employees", ""); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected GET %d during maintenance, got %d", http.StatusNoContent, rr.Code)
+	}
+
+	// некорректное тело не меняет режим
+	if rr := do(http.MethodPost, "/api/admin/maintenance", `{"message":"x"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d without enabled, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := do(http.MethodPost, "/api/admin/maintenance", `{"enabled":false,"message":"`+strings.Repeat("я", handler.MaxMaintenanceMessageLength+1)+`"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for a long message, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if !maintenance.Enabled() {
+		t.Fatal("invalid requests must not change the maintenance mode")
+	}
+
+	// без своего сообщения — перевод по Accept-Language
+	if _, err := maintenance.Set(true, ""); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/api/employees", nil)
+	req.Header.Set("Accept-Language", "en")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if want := i18n.T("en", i18n.Maintenance); !strings.Contains(rr.Body.String(), want) || rr.Header().Get("Content-Language") != "en" {
+		t.Fatalf("expected %q, got %s", want, rr.Body.String())
+	}
+
+	if rr := do(http.MethodPost, "/api/admin/maintenance", `{"enabled":false}`); rr.Code != http.StatusOK {
+		t.Fatalf("unexpected toggle response: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodPut, "/api/employees", `{}`); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected %d after maintenance, got %d", http.StatusNoContent, rr.Code)
+	}
+}
+
+func TestMaintenance_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	m, err := handler.NewMaintenance(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMaintenance: %v", err)
+	}
+	if _, err := m.Set(true, "Переезд БД"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	restarted, err := handler.NewMaintenance(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMaintenance: %v", err)
+	}
+	if state := restarted.State(); !state.Enabled || state.Message != "Переезд БД" || state.Since == nil {
+		t.Fatalf("unexpected state after restart: %+v", state)
+	}
+
+	// файл без состояния — ошибка запуска, а не молча выключенный режим
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := handler.NewMaintenance(path, zap.NewNop()); err == nil {
+		t.Fatal("expected an error for a corrupted state file")
+	}
+}
+
+func TestGetAllEmployees_CreatedRange(t *testing.T) {
+	tests := []struct {
+		query    string
+		from, to time.Time
+	}{
+		{
+			"created_from=2024-01-01&created_to=2024-02-01",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 1, 23, 59, 59, 999999000, time.UTC),
+		},
+		{
+			"created_from=2024-01-01T10:00:00%2B05:00&created_to=2024-01-01",
+			time.Date(2024, 1, 1, 5, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 23, 59, 59, 999999000, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		var gotFrom, gotTo time.Time
+		svc := &mockService{
+			CreatedFn: func(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+				gotFrom, gotTo = from, to
+				return []*domain.Employee{{ID: 2}, {ID: 1}}, nil
+			},
+		}
+		r := newRouter(svc)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?"+tt.query+"&fields=id", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.query, http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if !gotFrom.Equal(tt.from) || !gotTo.Equal(tt.to) {
+			t.Fatalf("%s: expected %v..%v, got %v..%v", tt.query, tt.from, tt.to, gotFrom, gotTo)
+		}
+		if want := `[{"id":2},{"id":1}]`; strings.TrimSpace(rr.Body.String()) != want {
+			t.Fatalf("%s: expected %s, got %s", tt.query, want, rr.Body.String())
+		}
+	}
+}
+
+func TestGetAllEmployees_CreatedRangeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"from after to", "created_from=2024-02-01&created_to=2024-01-01"},
+		{"bad date", "created_from=01.01.2024&created_to=2024-02-01"},
+		{"only from", "created_from=2024-01-01"},
+		{"with limit", "created_from=2024-01-01&created_to=2024-02-01&limit=10"},
+		{"with ids", "created_from=2024-01-01&created_to=2024-02-01&ids=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				CreatedFn: func(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+					t.Fatal("service must not be called for an invalid range")
+					return nil, nil
+				},
+			}
+			r := newRouter(svc)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?"+tt.query, nil))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			assertErrorCode(t, rr, domain.CodeValidation)
+		})
+	}
+}
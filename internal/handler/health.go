@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"employer/traits/build"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// readinessPingTimeout ограничение на проверку БД в /readyz
+const readinessPingTimeout = 2 * time.Second
+
+// Pinger проверка доступности БД
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthHandler обработчик проб liveness/readiness
+type HealthHandler struct {
+	db          Pinger
+	ready       atomic.Bool
+	maintenance *Maintenance
+	logger      *zap.Logger
+}
+
+// HealthResponse ответ проб состояния
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Service string `json:"service,omitempty"`
+}
+
+// NewHealthHandler создает обработчик проб. До вызова SetReady(true) сервис считается не готовым.
+func NewHealthHandler(db Pinger, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetReady отмечает готовность принимать трафик: true после миграций,
+// false при остановке, чтобы балансировщик перестал направлять запросы
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+	h.logger.Info("изменена готовность сервиса", zap.Bool("ready", ready))
+}
+
+// SetMaintenance включает отчет о режиме обслуживания в /health
+func (h *HealthHandler) SetMaintenance(maintenance *Maintenance) {
+	h.maintenance = maintenance
+}
+
+// Health общий health check; в режиме обслуживания — статус "maintenance" с кодом 200:
+// чтение работает, и перезапускать сервис не нужно
+// GET /health
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	status := "OK"
+	if h.maintenance != nil && h.maintenance.Enabled() {
+		status = "maintenance"
+	}
+	h.writeJSONResponse(w, http.StatusOK, &HealthResponse{Status: status, Service: "Employee Management"})
+}
+
+// Livez отвечает 200, пока процесс жив; БД не проверяется
+// GET /livez
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	h.writeJSONResponse(w, http.StatusOK, &HealthResponse{Status: "OK"})
+}
+
+// Readyz отвечает 200, только если миграции выполнены и БД доступна, иначе 503
+// GET /readyz
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		h.writeJSONResponse(w, http.StatusServiceUnavailable, &HealthResponse{Status: "NOT_READY"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessPingTimeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		h.logger.Warn("проверка готовности: БД недоступна", zap.Error(err))
+		h.writeJSONResponse(w, http.StatusServiceUnavailable, &HealthResponse{Status: "DB_UNAVAILABLE"})
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, &HealthResponse{Status: "OK"})
+}
+
+// Version отдает версию, коммит и дату сборки, чтобы сопоставлять инциденты с выкладками
+// GET /version
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	info := build.Get()
+	h.writeJSONResponse(w, http.StatusOK, &info)
+}
+
+// RegisterRoutes регистрирует маршруты проб состояния и сведений о сборке
+func (h *HealthHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/health", h.Health).Methods("GET")
+	router.HandleFunc("/livez", h.Livez).Methods("GET")
+	router.HandleFunc("/readyz", h.Readyz).Methods("GET")
+	router.HandleFunc("/version", h.Version).Methods("GET")
+}
+
+func (h *HealthHandler) writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"employer/internal/handler"
+	"employer/traits/build"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	return p.err
+}
+
+func newHealthRouter(h *handler.HealthHandler) *mux.Router {
+	r := mux.NewRouter()
+	h.RegisterRoutes(r)
+	return r
+}
+
+func serve(r http.Handler, path string) int {
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	return rr.Code
+}
+
+func TestReadyz_NotReady(t *testing.T) {
+	h := handler.NewHealthHandler(&fakePinger{}, zap.NewNop())
+	r := newHealthRouter(h)
+
+	if code := serve(r, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d before SetReady, got %d", http.StatusServiceUnavailable, code)
+	}
+	if code := serve(r, "/livez"); code != http.StatusOK {
+		t.Fatalf("expected livez %d, got %d", http.StatusOK, code)
+	}
+}
+
+func TestReadyz_Ready(t *testing.T) {
+	h := handler.NewHealthHandler(&fakePinger{}, zap.NewNop())
+	h.SetReady(true)
+	r := newHealthRouter(h)
+
+	if code := serve(r, "/readyz"); code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, code)
+	}
+
+	// при остановке под снова становится не готовым
+	h.SetReady(false)
+	if code := serve(r, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d after shutdown, got %d", http.StatusServiceUnavailable, code)
+	}
+}
+
+func TestReadyz_DBUnavailable(t *testing.T) {
+	h := handler.NewHealthHandler(&fakePinger{err: errors.New("connection refused")}, zap.NewNop())
+	h.SetReady(true)
+	r := newHealthRouter(h)
+
+	if code := serve(r, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d, got %d", http.StatusServiceUnavailable, code)
+	}
+	if code := serve(r, "/livez"); code != http.StatusOK {
+		t.Fatalf("liveness must not depend on DB, got %d", code)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	r := newHealthRouter(handler.NewHealthHandler(&fakePinger{}, zap.NewNop()))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected application/json, got %q", ct)
+	}
+
+	var got build.Info
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rr.Body.String(), err)
+	}
+	// без -ldflags остаются значения по умолчанию
+	if got.Version != "dev" || got.Commit != "unknown" || got.BuildDate != "unknown" {
+		t.Fatalf("expected default build info, got %+v", got)
+	}
+	if got.GoVersion == "" {
+		t.Fatalf("expected go_version, got %+v", got)
+	}
+}
+
+func TestHealth_Maintenance(t *testing.T) {
+	maintenance, err := handler.NewMaintenance("", zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMaintenance: %v", err)
+	}
+	h := handler.NewHealthHandler(&fakePinger{}, zap.NewNop())
+	h.SetMaintenance(maintenance)
+	h.SetReady(true)
+	r := newHealthRouter(h)
+
+	status := func() string {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var resp handler.HealthResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("unexpected /health response: %d %v", rr.Code, err)
+		}
+		return resp.Status
+	}
+
+	if got := status(); got != "OK" {
+		t.Fatalf("expected OK, got %q", got)
+	}
+	if _, err := maintenance.Set(true, ""); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := status(); got != "maintenance" {
+		t.Fatalf("expected maintenance, got %q", got)
+	}
+	// пробы Kubernetes от режима обслуживания не зависят
+	if code := serve(r, "/livez"); code != http.StatusOK {
+		t.Fatalf("expected livez %d, got %d", http.StatusOK, code)
+	}
+	if code := serve(r, "/readyz"); code != http.StatusOK {
+		t.Fatalf("expected readyz %d, got %d", http.StatusOK, code)
+	}
+}
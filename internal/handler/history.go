@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"employer/internal/domain"
+)
+
+// asOfDateLayout формат даты в параметре as_of; дата означает начало суток по UTC
+const asOfDateLayout = "2006-01-02"
+
+// parseAsOf разбирает параметр as_of: дату YYYY-MM-DD или момент в RFC 3339
+func parseAsOf(raw string) (time.Time, error) {
+	if t, err := time.Parse(asOfDateLayout, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+// GetEmployeeHistory получает все версии сотрудника по возрастанию valid_from;
+// последняя версия без valid_to — текущая
+// GET /api/employees/{id}/history
+func (h *EmployeeHandler) GetEmployeeHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	versions, err := h.service.GetEmployeeHistory(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: versions,
+			Meta: domain.ListMeta{Count: len(versions)},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, versions)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/service"
+)
+
+// Заголовки идемпотентного создания: ключ от клиента и признак повторного ответа
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// createEmployeeOnce создает сотрудника по ключу Idempotency-Key. Тело запроса
+// сравнивается по хэшу уже разобранного req, поэтому пробелы и порядок полей JSON
+// не влияют на результат. При повторе выставляет Idempotent-Replayed: true
+func (h *EmployeeHandler) createEmployeeOnce(w http.ResponseWriter, r *http.Request, key string, req *domain.CreateEmployeeRequest, employee *domain.Employee) error {
+	if utf8.RuneCountInString(key) > service.MaxIdempotencyKeyLength || strings.IndexFunc(key, unicode.IsControl) >= 0 {
+		return badRequest(IdempotencyKeyHeader, i18n.RequestIdempotency, service.MaxIdempotencyKeyLength)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+
+	replayed, err := h.service.CreateEmployeeIdempotent(r.Context(), key, hex.EncodeToString(sum[:]), employee)
+	if err != nil {
+		return err
+	}
+	if replayed {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// NewListRowsHistogram создает гистограмму количества сотрудников в ответах списков и поиска
+// с меткой route (шаблон пути). Регистрация в реестре Prometheus остается за вызывающим кодом.
+func NewListRowsHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "employer_list_response_rows",
+		Help:    "Количество сотрудников в ответах списков и поиска",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"route"})
+}
+
+// SetListRowsHistogram задает гистограмму размера ответов списков (NewListRowsHistogram); nil — без метрик
+func (h *EmployeeHandler) SetListRowsHistogram(histogram *prometheus.HistogramVec) {
+	h.listRows = histogram
+}
+
+// observeRows фиксирует количество сотрудников rows в ответе на запрос r
+func (h *EmployeeHandler) observeRows(r *http.Request, rows int) {
+	route := r.URL.Path
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			route = template
+		}
+	}
+
+	h.logger.Debug("отдан список сотрудников", zap.String("route", route), zap.Int("rows", rows))
+	if h.listRows != nil {
+		h.listRows.WithLabelValues(route).Observe(float64(rows))
+	}
+}
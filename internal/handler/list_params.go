@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+)
+
+// ListParams параметры списка и поиска сотрудников из query:
+// limit, offset, sort, order, city, status и q
+type ListParams struct {
+	// Limit размер страницы; без limit — 0, размер выбирает обработчик (pageLimit)
+	Limit  int
+	Offset int
+	// Paged задан limit или offset: список отдается страницей
+	Paged bool
+	// limitSet limit задан явно, в том числе limit=0
+	limitSet bool
+	// Sort поле сортировки (domain.EmployeeSorts); пустое — порядок по умолчанию
+	Sort string
+	// Desc order=desc
+	Desc   bool
+	City   string
+	Status string
+	// Query строка поиска q
+	Query string
+}
+
+// ParseListParams разбирает и проверяет параметры списка. Некорректное значение
+// возвращается ошибкой badRequest с именем параметра (400 VALIDATION_ERROR).
+// Диапазон limit и допустимые статусы проверяет сервис
+func ParseListParams(r *http.Request) (ListParams, error) {
+	query := r.URL.Query()
+	params := ListParams{
+		City:   query.Get("city"),
+		Status: query.Get("status"),
+		Query:  query.Get("q"),
+		Paged:  query.Has("limit") || query.Has("offset"),
+	}
+
+	var err error
+	if raw := query.Get("limit"); raw != "" {
+		params.limitSet = true
+		if params.Limit, err = strconv.Atoi(raw); err != nil || params.Limit < 0 {
+			return ListParams{}, badRequest("limit", i18n.RequestLimit)
+		}
+	}
+	if raw := query.Get("offset"); raw != "" {
+		if params.Offset, err = strconv.Atoi(raw); err != nil || params.Offset < 0 {
+			return ListParams{}, badRequest("offset", i18n.RequestOffset)
+		}
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		params.Sort = strings.ToLower(strings.TrimSpace(raw))
+		if !slices.Contains(domain.EmployeeSorts, params.Sort) {
+			return ListParams{}, badRequest("sort", i18n.RequestSort, strings.Join(domain.EmployeeSorts, ", "))
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(query.Get("order"))) {
+	case "", "asc":
+	case "desc":
+		params.Desc = true
+	default:
+		return ListParams{}, badRequest("order", i18n.RequestOrder)
+	}
+
+	return params, nil
+}
+
+// Filter фильтр и сортировка списка для сервиса
+func (p ListParams) Filter() domain.EmployeeFilter {
+	return domain.EmployeeFilter{
+		City:   p.City,
+		Status: p.Status,
+		Sort:   p.Sort,
+		Desc:   p.Desc,
+	}
+}
+
+// SearchFilter фильтр вместе со строкой поиска q. Общий для поиска и выгрузки, поэтому
+// выгрузка результатов поиска отбирает тех же сотрудников
+func (p ListParams) SearchFilter() domain.EmployeeFilter {
+	filter := p.Filter()
+	filter.Query = p.Query
+	return filter
+}
+
+// pageLimit limit страницы: без limit — defaultLimit
+func (p ListParams) pageLimit(defaultLimit int) int {
+	if !p.limitSet {
+		return defaultLimit
+	}
+	return p.Limit
+}
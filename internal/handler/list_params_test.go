@@ -0,0 +1,155 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/handler"
+	"employer/internal/i18n"
+	"employer/internal/service"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+func TestParseListParams_Valid(t *testing.T) {
+	tests := []struct {
+		query string
+		want  handler.ListParams
+	}{
+		{"", handler.ListParams{}},
+		{"limit=20&offset=40", handler.ListParams{Limit: 20, Offset: 40, Paged: true}},
+		{"offset=10", handler.ListParams{Offset: 10, Paged: true}},
+		{"sort=created_at&order=desc", handler.ListParams{Sort: domain.SortCreatedAt, Desc: true}},
+		{"sort=City&order=ASC", handler.ListParams{Sort: domain.SortCity}},
+		{"order=desc", handler.ListParams{Desc: true}},
+		{"q=john&city=Almaty&status=active&sort=id", handler.ListParams{Query: "john", City: "Almaty", Status: "active", Sort: domain.SortID}},
+	}
+	for _, tt := range tests {
+		got, err := handler.ParseListParams(httptest.NewRequest(http.MethodGet, "/api/employees?"+tt.query, nil))
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.query, err)
+		}
+		if got.Filter() != tt.want.Filter() || got.Query != tt.want.Query || got.Paged != tt.want.Paged ||
+			got.Limit != tt.want.Limit || got.Offset != tt.want.Offset {
+			t.Fatalf("%q: got %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseListParams_Invalid(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"limit=abc", "limit"},
+		{"limit=-1", "limit"},
+		{"offset=1.5", "offset"},
+		{"offset=-20", "offset"},
+		{"sort=phone", "sort"},
+		{"sort=name&order=up", "order"},
+	}
+	for _, tt := range tests {
+		_, err := handler.ParseListParams(httptest.NewRequest(http.MethodGet, "/api/employees?"+tt.query, nil))
+		var verr *service.ValidationError
+		if !errors.As(err, &verr) || verr.Field != tt.field {
+			t.Fatalf("%q: expected validation error on %s, got %v", tt.query, tt.field, err)
+		}
+	}
+}
+
+func TestGetAllEmployees_Sort(t *testing.T) {
+	svc := &mockService{}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?city=Almaty&sort=created_at&order=desc&limit=5", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	want := domain.EmployeeFilter{City: "Almaty", Sort: domain.SortCreatedAt, Desc: true}
+	if svc.lastFilter != want {
+		t.Fatalf("expected filter %+v, got %+v", want, svc.lastFilter)
+	}
+
+	for _, path := range []string{"/api/employees?sort=phone", "/api/employees/search?q=john&order=random"} {
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeValidation)
+	}
+}
+
+func TestSearchEmployees_Sort(t *testing.T) {
+	var gotLimit int
+	svc := &mockService{
+		SearchPageFn: func(ctx context.Context, query string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit = limit
+			return nil, 0, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/search?q=john&sort=name&order=desc", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if svc.lastFilter.Sort != domain.SortName || !svc.lastFilter.Desc {
+		t.Fatalf("expected name desc, got %+v", svc.lastFilter)
+	}
+	if gotLimit != 100 {
+		t.Fatalf("expected default search limit 100, got %d", gotLimit)
+	}
+}
+
+func TestGetAllEmployees_ListRowsMetric(t *testing.T) {
+	svc := &mockService{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			return []*domain.Employee{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+		},
+	}
+	h := handler.NewEmployeeHandler(svc, zap.NewNop())
+	histogram := handler.NewListRowsHistogram()
+	h.SetListRowsHistogram(histogram)
+	r := mux.NewRouter()
+	h.RegisterRoutes(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	metric := &dto.Metric{}
+	if err := histogram.WithLabelValues("/api/employees").(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if metric.GetHistogram().GetSampleCount() != 1 || metric.GetHistogram().GetSampleSum() != 3 {
+		t.Fatalf("expected one observation of 3 rows, got %v", metric.GetHistogram())
+	}
+}
+
+func TestGetAllEmployees_TooManyRows(t *testing.T) {
+	svc := &mockService{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			return nil, service.NewValidationError("limit", i18n.ListTooLarge, 500000, 10000)
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees", nil))
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeValidation)
+}
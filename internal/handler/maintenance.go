@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// MaintenanceRetryAfter через сколько клиенту повторить изменение, отклоненное
+// режимом обслуживания (заголовок Retry-After)
+const MaintenanceRetryAfter = 60 * time.Second
+
+// MaxMaintenanceMessageLength наибольшая длина сообщения режима обслуживания в символах
+const MaxMaintenanceMessageLength = 500
+
+// MaintenanceState состояние режима обслуживания
+type MaintenanceState struct {
+	Enabled bool `json:"enabled"`
+	// Message отдается клиентам вместо стандартного сообщения об обслуживании
+	Message string `json:"message,omitempty"`
+	// Since время включения режима
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// Maintenance режим обслуживания: пока он включен, изменяющие запросы к /api/
+// (POST, PUT, PATCH, DELETE) отклоняются с 503, чтение продолжает работать.
+// Состояние хранится в памяти и, если задан файл, в нем — чтобы пережить перезапуск
+type Maintenance struct {
+	mu     sync.RWMutex
+	state  MaintenanceState
+	path   string
+	logger *zap.Logger
+}
+
+// NewMaintenance создает режим обслуживания. path — файл состояния (MAINTENANCE_FILE);
+// если он существует, состояние читается из него. Пустой path — только в памяти
+func NewMaintenance(path string, logger *zap.Logger) (*Maintenance, error) {
+	m := &Maintenance{path: path, logger: logger}
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение состояния режима обслуживания: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.state); err != nil {
+		return nil, fmt.Errorf("разбор состояния режима обслуживания %s: %w", path, err)
+	}
+	if m.state.Enabled {
+		logger.Warn("режим обслуживания включен с прошлого запуска",
+			zap.String("message", m.state.Message), zap.Timep("since", m.state.Since))
+	}
+	return m, nil
+}
+
+// State возвращает текущее состояние
+func (m *Maintenance) State() MaintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Enabled включен ли режим обслуживания
+func (m *Maintenance) Enabled() bool {
+	return m.State().Enabled
+}
+
+// Set включает или выключает режим обслуживания. Состояние сначала записывается
+// в файл: если запись не удалась, прежнее состояние сохраняется
+func (m *Maintenance) Set(enabled bool, message string) (MaintenanceState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := MaintenanceState{Enabled: enabled}
+	if enabled {
+		state.Message = message
+		// повторное включение только меняет сообщение
+		since := time.Now().UTC()
+		if m.state.Enabled && m.state.Since != nil {
+			since = *m.state.Since
+		}
+		state.Since = &since
+	}
+	if err := m.save(state); err != nil {
+		return m.state, err
+	}
+
+	m.state = state
+	m.logger.Warn("изменен режим обслуживания", zap.Bool("enabled", enabled), zap.String("message", message))
+	return state, nil
+}
+
+// save записывает состояние во временный файл и переименовывает его, чтобы
+// при сбое не остался файл, записанный наполовину
+func (m *Maintenance) save(state MaintenanceState) error {
+	if m.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".*")
+	if err != nil {
+		return fmt.Errorf("запись состояния режима обслуживания: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("запись состояния режима обслуживания: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("запись состояния режима обслуживания: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("запись состояния режима обслуживания: %w", err)
+	}
+	return nil
+}
+
+// Middleware отклоняет изменяющие запросы к basePath/api/ и формам простого
+// интерфейса (basePath/employees/plain/) с 503 MAINTENANCE и заголовком Retry-After,
+// пока включен режим обслуживания. Служебные маршруты /api/admin/ доступны всегда,
+// иначе режим нельзя было бы выключить
+func (m *Maintenance) Middleware(basePath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if !isMutation(r.Method) ||
+				!(strings.HasPrefix(path, basePath+"/api/") || strings.HasPrefix(path, basePath+"/employees/plain/")) ||
+				strings.HasPrefix(path, basePath+"/api/admin/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			state := m.State()
+			if !state.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+			message := state.Message
+			if message == "" {
+				message = i18n.T(locale, i18n.Maintenance)
+			}
+			w.Header().Add("Vary", "Accept-Language")
+			w.Header().Set("Content-Language", locale)
+			w.Header().Set("Retry-After", strconv.Itoa(int(MaintenanceRetryAfter.Seconds())))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if err := json.NewEncoder(w).Encode(newErrorResponse(domain.CodeMaintenance, message, nil)); err != nil {
+				m.logger.Error("failed to encode response", zap.Error(err))
+			}
+		})
+	}
+}
+
+// isMutation изменяет ли запрос с методом method данные
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
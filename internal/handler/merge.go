@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"employer/internal/domain"
+)
+
+// MergeEmployee вливает дубликат source_id в сотрудника из пути: ссылки на дубликат
+// переходят к сотруднику, слияние записывается в журнал, дубликат удаляется.
+// С fill_missing у сотрудника заполняются пустые поля из дубликата.
+// Слияние с самим собой — 422, отсутствующий сотрудник или дубликат — 404
+// POST /api/employees/{id}/merge {"source_id": 12, "fill_missing": true}
+func (h *EmployeeHandler) MergeEmployee(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	var req domain.MergeRequest
+	if err := h.decodeBody(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	result, err := h.service.MergeEmployees(r.Context(), id, req.SourceID, req.FillMissing)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writeResponse(w, r, http.StatusOK, &domain.MergeResponse{
+		Employee:     toEmployeeResponse(result.Employee),
+		SourceID:     result.SourceID,
+		CopiedFields: result.CopiedFields,
+	})
+}
+
+// GetDuplicates отчет о возможных дубликатах: пары сотрудников, у которых телефоны
+// совпадают без последней цифры или совпадают имя и город. Пары идут по возрастанию ID
+// GET /api/employees/duplicates?limit=100
+func (h *EmployeeHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	params, err := ParseListParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	pairs, err := h.service.FindDuplicates(r.Context(), params.Limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	items := make([]*domain.DuplicatePairResponse, len(pairs))
+	for i, pair := range pairs {
+		items[i] = &domain.DuplicatePairResponse{
+			Employees: toEmployeeResponses([]*domain.Employee{pair.First, pair.Second}),
+			Reasons:   pair.Reasons,
+		}
+	}
+
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: items,
+			Meta: domain.ListMeta{Count: len(items)},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, items)
+}
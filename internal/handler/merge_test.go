@@ -0,0 +1,108 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+	"employer/internal/service"
+)
+
+func TestMergeEmployee(t *testing.T) {
+	var gotTarget, gotSource int64
+	var gotFill bool
+	svc := &mockService{
+		MergeFn: func(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error) {
+			gotTarget, gotSource, gotFill = targetID, sourceID, fillMissing
+			return &domain.MergeResult{
+				Employee:     &domain.Employee{ID: targetID, Name: "Алия", Phone: "+77010000001", City: "Almaty"},
+				SourceID:     sourceID,
+				CopiedFields: []string{"birth_date"},
+			}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees/3/merge", bytes.NewBufferString(`{"source_id": 7, "fill_missing": true}`)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if gotTarget != 3 || gotSource != 7 || !gotFill {
+		t.Fatalf("unexpected merge call target=%d source=%d fill=%v", gotTarget, gotSource, gotFill)
+	}
+	var resp domain.MergeResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Employee.ID != 3 || resp.SourceID != 7 || len(resp.CopiedFields) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestMergeEmployee_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"self", service.NewValidationError("source_id", i18n.MergeSelf), http.StatusUnprocessableEntity, domain.CodeValidation},
+		{"missing target", &repository.NotFoundError{Entity: "employee", ID: 3}, http.StatusNotFound, domain.CodeNotFound},
+	}
+	for _, tt := range tests {
+		svc := &mockService{
+			MergeFn: func(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error) {
+				return nil, tt.err
+			},
+		}
+		rr := httptest.NewRecorder()
+		newRouter(svc).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/employees/3/merge", bytes.NewBufferString(`{"source_id": 3}`)))
+		if rr.Code != tt.status {
+			t.Fatalf("%s: expected %d, got %d", tt.name, tt.status, rr.Code)
+		}
+		assertErrorCode(t, rr, tt.code)
+	}
+}
+
+func TestGetDuplicates(t *testing.T) {
+	var gotLimit int
+	svc := &mockService{
+		DuplicatesFn: func(ctx context.Context, limit int) ([]*domain.DuplicatePair, error) {
+			gotLimit = limit
+			return []*domain.DuplicatePair{{
+				First:   &domain.Employee{ID: 1, Name: "Алия", Phone: "+77010000001", City: "Almaty"},
+				Second:  &domain.Employee{ID: 4, Name: "Алия", Phone: "+77010000002", City: "Almaty"},
+				Reasons: []string{domain.DuplicateNameCity, domain.DuplicatePhonePrefix},
+			}}, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/duplicates?limit=50", nil))
+
+	if rr.Code != http.StatusOK || gotLimit != 50 {
+		t.Fatalf("expected 200 with limit 50, got %d (limit=%d)", rr.Code, gotLimit)
+	}
+	var pairs []domain.DuplicatePairResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &pairs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(pairs) != 1 || len(pairs[0].Employees) != 2 || pairs[0].Employees[1].ID != 4 || len(pairs[0].Reasons) != 2 {
+		t.Fatalf("unexpected response %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees/duplicates", nil))
+	if rr.Code != http.StatusOK || gotLimit != 0 {
+		t.Fatalf("expected default limit from service, got %d (limit=%d)", rr.Code, gotLimit)
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// methodOrder порядок методов в заголовке Allow; незнакомые методы идут следом по алфавиту
+var methodOrder = map[string]int{
+	http.MethodGet:     1,
+	http.MethodHead:    2,
+	http.MethodPost:    3,
+	http.MethodPut:     4,
+	http.MethodPatch:   5,
+	http.MethodDelete:  6,
+	http.MethodOptions: 7,
+}
+
+// AutoMethods оборачивает router так, чтобы все его маршруты отвечали на OPTIONS и HEAD:
+// OPTIONS получает 204 с заголовком Allow, HEAD выполняется как GET того же маршрута без тела.
+// Явно зарегистрированные HEAD и OPTIONS не затрагиваются. Вызывается после регистрации всех
+// маршрутов: методы собираются обходом router. Несовпадение метода определяется здесь, а не
+// через MethodNotAllowedHandler: mux сбрасывает его на следующих маршрутах подроутера
+func AutoMethods(router *mux.Router) (http.Handler, error) {
+	seen := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Маршрут без ограничения методов (например, PathPrefix подроутера)
+			return nil
+		}
+		for _, method := range methods {
+			seen[method] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sortMethods(methods)
+	return &autoMethods{router: router, methods: methods}, nil
+}
+
+// autoMethods обертка роутера; methods — все методы его маршрутов
+type autoMethods struct {
+	router  *mux.Router
+	methods []string
+}
+
+func (a *autoMethods) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != http.MethodOptions && r.Method != http.MethodHead) || matches(a.router, r) {
+		a.router.ServeHTTP(w, r)
+		return
+	}
+
+	allow := a.allowed(r)
+	switch {
+	case r.Method == http.MethodOptions && len(allow) > 1:
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead && slices.Contains(allow, http.MethodGet):
+		get := r.Clone(r.Context())
+		get.Method = http.MethodGet
+		a.router.ServeHTTP(&headWriter{ResponseWriter: w}, get)
+	default:
+		a.router.ServeHTTP(w, r)
+	}
+}
+
+// matches совпадает ли запрос r с маршрутом router вместе с методом
+func matches(router *mux.Router, r *http.Request) bool {
+	var match mux.RouteMatch
+	return router.Match(r, &match) && match.MatchErr == nil
+}
+
+// allowed методы, с которыми путь запроса r совпадает с маршрутом; GET дополняется HEAD,
+// OPTIONS разрешен всегда. Только OPTIONS означает, что маршрута с таким путем нет
+func (a *autoMethods) allowed(r *http.Request) []string {
+	allow := []string{http.MethodOptions}
+	for _, method := range a.methods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if !matches(a.router, probe) {
+			continue
+		}
+		if !slices.Contains(allow, method) {
+			allow = append(allow, method)
+		}
+		if method == http.MethodGet && !slices.Contains(allow, http.MethodHead) {
+			allow = append(allow, http.MethodHead)
+		}
+	}
+	sortMethods(allow)
+	return allow
+}
+
+// sortMethods упорядочивает методы по methodOrder
+func sortMethods(methods []string) {
+	sort.Slice(methods, func(i, j int) bool {
+		oi, oj := methodOrder[methods[i]], methodOrder[methods[j]]
+		if oi == 0 || oj == 0 {
+			if oi != oj {
+				return oj == 0
+			}
+			return methods[i] < methods[j]
+		}
+		return oi < oj
+	})
+}
+
+// headWriter отбрасывает тело ответа на HEAD; статус и заголовки передаются как есть.
+// Потоковые ответы (Flush) не поддерживаются, поэтому HEAD не держит соединение открытым
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/handler"
+)
+
+func TestAutoMethods_OptionsAllow(t *testing.T) {
+	r, err := handler.AutoMethods(newWebRouter(t, t.TempDir(), ""))
+	if err != nil {
+		t.Fatalf("AutoMethods: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/api/employees", "GET, HEAD, POST, DELETE, OPTIONS"},
+		{"/api/employees/5", "GET, HEAD, PUT, DELETE, OPTIONS"},
+		{"/api/employees/5/merge", "POST, OPTIONS"},
+		{"/api/employees/search", "GET, HEAD, OPTIONS"},
+		{"/api/cities", "GET, HEAD, OPTIONS"},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, tt.path, nil))
+
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("%s: expected 204, got %d", tt.path, rr.Code)
+		}
+		if got := rr.Header().Get("Allow"); got != tt.allow {
+			t.Fatalf("%s: expected Allow %q, got %q", tt.path, tt.allow, got)
+		}
+	}
+
+	// неизвестный путь остается за NotFoundHandler
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/api/unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeNotFound)
+}
+
+func TestAutoMethods_Head(t *testing.T) {
+	svc := &mockService{
+		GetFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return &domain.Employee{ID: id, Name: "John"}, nil
+		},
+	}
+	r, err := handler.AutoMethods(newRouter(svc))
+	if err != nil {
+		t.Fatalf("AutoMethods: %v", err)
+	}
+
+	for _, path := range []string{"/api/employees", "/api/employees/5", "/api/employees/recent"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodHead, path, nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rr.Code)
+		}
+		if rr.Body.Len() != 0 {
+			t.Fatalf("%s: expected empty body, got %q", path, rr.Body.String())
+		}
+	}
+
+	// HEAD повторяет заголовки GET
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodHead, "/api/employees/5", nil))
+	get := httptest.NewRecorder()
+	r.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/api/employees/5", nil))
+	for _, name := range []string{"Content-Type", "Content-Length", "ETag", "X-Request-ID"} {
+		if (rr.Header().Get(name) == "") != (get.Header().Get(name) == "") {
+			t.Fatalf("%s: HEAD %q, GET %q", name, rr.Header().Get(name), get.Header().Get(name))
+		}
+	}
+	if rr.Header().Get("Content-Length") != get.Header().Get("Content-Length") {
+		t.Fatalf("expected Content-Length %s, got %s", get.Header().Get("Content-Length"), rr.Header().Get("Content-Length"))
+	}
+}
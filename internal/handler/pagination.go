@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"employer/internal/domain"
+	"employer/internal/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Размер страницы, пока не вызваны SetPageLimits и SetSearchMaxResults
+const (
+	defaultPageLimit = service.DefaultPageLimit
+	maxPageLimit     = service.MaxPageLimit
+	maxSearchResults = service.MaxSearchLimit
+)
+
+// ResultsTruncatedHeader заголовок ответа поиска: "true", если совпадений больше, чем в ответе
+const ResultsTruncatedHeader = "X-Results-Truncated"
+
+// SetPageLimits задает размер страницы списка без limit и наибольший размер:
+// больший limit в запросе уменьшается до maxLimit, а не отклоняется
+func (h *EmployeeHandler) SetPageLimits(defaultLimit, maxLimit int) {
+	h.pageDefaultLimit = defaultLimit
+	h.pageMaxLimit = maxLimit
+}
+
+// SetSearchMaxResults задает размер страницы поиска без limit; он же наибольший
+// (SEARCH_MAX_RESULTS), больший limit отклоняется сервисом
+func (h *EmployeeHandler) SetSearchMaxResults(maxResults int) {
+	h.searchMaxResults = maxResults
+}
+
+// getEmployeesPage отдает страницу сотрудников с заголовками Link (RFC 5988)
+// и X-Total-Count
+func (h *EmployeeHandler) getEmployeesPage(w http.ResponseWriter, r *http.Request, params ListParams, fields []employeeField) {
+	limit, offset := params.pageLimit(h.pageDefaultLimit), params.Offset
+	if limit > h.pageMaxLimit {
+		limit = h.pageMaxLimit
+	}
+
+	employees, total, err := h.service.GetEmployeesPage(r.Context(), params.Filter(), limit, offset)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.writePageResponse(w, r, toEmployeeResponses(employees), fields, total, limit, offset)
+}
+
+// writePageResponse отдает страницу списка с заголовками Link и X-Total-Count;
+// в конверте meta дополняется total, limit и offset. С fields у сотрудников остаются только эти поля
+func (h *EmployeeHandler) writePageResponse(w http.ResponseWriter, r *http.Request, items []*domain.EmployeeResponse, fields []employeeField, total int64, limit, offset int) {
+	h.writePage(w, r, projectEmployees(items, fields), len(items), total, limit, offset)
+}
+
+// writePage отдает страницу data из count элементов с заголовками Link и X-Total-Count
+func (h *EmployeeHandler) writePage(w http.ResponseWriter, r *http.Request, data interface{}, count int, total int64, limit, offset int) {
+	h.observeRows(r, count)
+	if links := paginationLinks(r, limit, offset, total); links != "" {
+		w.Header().Set("Link", links)
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if wantsEnvelope(r) {
+		h.writeResponse(w, r, http.StatusOK, &domain.ListResponse{
+			Data: data,
+			Meta: domain.ListMeta{Count: count, Total: total, Limit: limit, Offset: offset},
+		})
+		return
+	}
+	h.writeResponse(w, r, http.StatusOK, data)
+}
+
+// paginationLinks строит значение заголовка Link со ссылками first, prev, next и last.
+// Ссылки повторяют схему, хост, путь и прочие параметры запроса.
+func paginationLinks(r *http.Request, limit, offset int, total int64) string {
+	if limit <= 0 {
+		return ""
+	}
+
+	last := 0
+	if total > 0 {
+		last = int((total - 1) / int64(limit) * int64(limit))
+	}
+
+	links := []string{pageLink(r, limit, 0, "first")}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(r, limit, prev, "prev"))
+	}
+	if int64(offset+limit) < total {
+		links = append(links, pageLink(r, limit, offset+limit, "next"))
+	}
+	links = append(links, pageLink(r, limit, last, "last"))
+
+	return strings.Join(links, ", ")
+}
+
+// pageLink формирует одну ссылку вида <url>; rel="next"
+func pageLink(r *http.Request, limit, offset int, rel string) string {
+	query := r.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	u := url.URL{
+		Scheme:   scheme,
+		Host:     r.Host,
+		Path:     r.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return "<" + u.String() + `>; rel="` + rel + `"`
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/service"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// maxPlainFormSize наибольший размер тела формы простого интерфейса
+const maxPlainFormSize = 64 << 10
+
+//go:embed templates/plain_list.html
+var plainTemplates embed.FS
+
+// plainTemplate страница списка сотрудников без JavaScript. html/template экранирует
+// данные по контексту (текст, атрибуты, URL), поэтому имена и города из БД безопасны
+var plainTemplate = template.Must(template.New("plain_list.html").
+	Funcs(template.FuncMap{"formatPhone": formatPhone}).
+	ParseFS(plainTemplates, "templates/plain_list.html"))
+
+// plainPage данные страницы списка
+type plainPage struct {
+	BasePath  string
+	Query     string
+	Employees []*domain.Employee
+	Total     int64
+	Page      int
+	Pages     int
+	PrevURL   string
+	NextURL   string
+	Flash     string
+	Error     string
+}
+
+// PlainHandler простой веб-интерфейс, который формируется на сервере и работает без
+// JavaScript: запасной вариант, если браузер не выполняет скрипты или страница SPA
+// не развернута. Формы создания и удаления отправляются обычным POST, после чего
+// браузер перенаправляется обратно на список с сообщением в параметре flash или error
+type PlainHandler struct {
+	service  service.EmployeeService
+	basePath string
+	pageSize int
+	logger   *zap.Logger
+}
+
+// NewPlainHandler создает простой веб-интерфейс. pageSize — сотрудников на странице;
+// он не должен превышать наибольший размер страницы и поиска сервиса
+func NewPlainHandler(svc service.EmployeeService, basePath string, pageSize int, logger *zap.Logger) *PlainHandler {
+	return &PlainHandler{
+		service:  svc,
+		basePath: strings.TrimRight(basePath, "/"),
+		pageSize: pageSize,
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes регистрирует страницу списка и обработчики форм
+func (h *PlainHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/employees/plain", h.List).Methods("GET")
+	router.HandleFunc("/employees/plain/create", h.Create).Methods("POST")
+	router.HandleFunc("/employees/plain/{id:[0-9]+}/delete", h.Delete).Methods("POST")
+}
+
+// List отдает страницу сотрудников; q — поиск, page — номер страницы с 1
+// GET /employees/plain?q=...&page=2
+func (h *PlainHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page := &plainPage{
+		BasePath: h.basePath,
+		Query:    strings.TrimSpace(query.Get("q")),
+		Page:     1,
+		Flash:    query.Get("flash"),
+		Error:    query.Get("error"),
+	}
+	if n, err := strconv.Atoi(query.Get("page")); err == nil && n > 1 {
+		page.Page = n
+	}
+
+	offset := (page.Page - 1) * h.pageSize
+	var err error
+	if page.Query != "" {
+		page.Employees, page.Total, err = h.service.SearchEmployees(r.Context(), page.Query, domain.EmployeeFilter{}, h.pageSize, offset)
+	} else {
+		page.Employees, page.Total, err = h.service.GetEmployeesPage(r.Context(), domain.EmployeeFilter{}, h.pageSize, offset)
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		var resp *domain.ErrorResponse
+		status, resp = errorResponse(locale, err)
+		if status == http.StatusInternalServerError {
+			h.logger.Error("ошибка простого интерфейса", zap.Error(err))
+		}
+		page.Error = resp.Message
+	}
+
+	page.Pages = int((page.Total + int64(h.pageSize) - 1) / int64(h.pageSize))
+	if page.Pages < 1 {
+		page.Pages = 1
+	}
+	if page.Page > 1 {
+		page.PrevURL = h.listURL(page.Query, page.Page-1, "", "")
+	}
+	if page.Page < page.Pages {
+		page.NextURL = h.listURL(page.Query, page.Page+1, "", "")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// Страница не использует скрипты: запрещаем их совсем
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+	w.WriteHeader(status)
+	if err := plainTemplate.Execute(w, page); err != nil {
+		h.logger.Error("ошибка шаблона простого интерфейса", zap.Error(err))
+	}
+}
+
+// Create создает сотрудника из формы и возвращает на первую страницу
+// POST /employees/plain/create (name, phone, city)
+func (h *PlainHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.parseForm(w, r) {
+		return
+	}
+
+	employee := &domain.Employee{
+		Name:  r.PostForm.Get("name"),
+		Phone: r.PostForm.Get("phone"),
+		City:  r.PostForm.Get("city"),
+	}
+	if err := h.service.CreateEmployee(r.Context(), employee); err != nil {
+		h.redirect(w, r, "", 1, "error", h.errorMessage(r, err))
+		return
+	}
+	h.redirect(w, r, "", 1, "flash", fmt.Sprintf("Сотрудник «%s» добавлен (ID %d)", employee.Name, employee.ID))
+}
+
+// Delete удаляет сотрудника и возвращает на ту же страницу списка
+// POST /employees/plain/{id}/delete (q, page)
+func (h *PlainHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !h.parseForm(w, r) {
+		return
+	}
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	q := r.PostForm.Get("q")
+	page, _ := strconv.Atoi(r.PostForm.Get("page"))
+
+	if err := h.service.DeleteEmployee(r.Context(), id); err != nil {
+		h.redirect(w, r, q, page, "error", h.errorMessage(r, err))
+		return
+	}
+	h.redirect(w, r, q, page, "flash", fmt.Sprintf("Сотрудник %d удален", id))
+}
+
+// parseForm проверяет, что форма отправлена со страниц этого сервера, и разбирает ее.
+// Без проверки любой сайт мог бы удалить сотрудника, подсунув пользователю форму (CSRF)
+func (h *PlainHandler) parseForm(w http.ResponseWriter, r *http.Request) bool {
+	if !sameOrigin(r) {
+		h.logger.Warn("форма отправлена с другого сайта",
+			zap.String("origin", r.Header.Get("Origin")),
+			zap.String("path", r.URL.Path))
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPlainFormSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// sameOrigin сообщает, что запрос отправлен со страницы того же сайта: по заголовку
+// Origin, а без него по Sec-Fetch-Site. Запросы без обоих заголовков (старые браузеры,
+// curl) пропускаются
+func sameOrigin(r *http.Request) bool {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	site := r.Header.Get("Sec-Fetch-Site")
+	return site == "" || site == "same-origin" || site == "none"
+}
+
+// errorMessage текст ошибки сервиса на языке клиента
+func (h *PlainHandler) errorMessage(r *http.Request, err error) string {
+	locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	status, resp := errorResponse(locale, err)
+	if status == http.StatusInternalServerError {
+		h.logger.Error("ошибка простого интерфейса", zap.Error(err))
+	}
+	return resp.Message
+}
+
+// redirect перенаправляет на список (303, чтобы обновление страницы не отправило форму повторно)
+func (h *PlainHandler) redirect(w http.ResponseWriter, r *http.Request, q string, page int, kind, message string) {
+	http.Redirect(w, r, h.listURL(q, page, kind, message), http.StatusSeeOther)
+}
+
+// listURL адрес страницы списка с поиском q, номером page и сообщением kind (flash или error)
+func (h *PlainHandler) listURL(q string, page int, kind, message string) string {
+	values := url.Values{}
+	if q != "" {
+		values.Set("q", q)
+	}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if message != "" {
+		values.Set(kind, message)
+	}
+	target := h.basePath + "/employees/plain"
+	if len(values) > 0 {
+		target += "?" + values.Encode()
+	}
+	return target
+}
+
+// formatPhone форматирует казахстанские и российские номера для чтения:
+// "+77011234567" -> "+7 701 123 45 67"; остальные возвращаются как есть
+func formatPhone(phone string) string {
+	digits := strings.TrimPrefix(phone, "+")
+	if !strings.HasPrefix(phone, "+7") || len(digits) != 11 || strings.Trim(digits, "0123456789") != "" {
+		return phone
+	}
+	return fmt.Sprintf("+7 %s %s %s %s", digits[1:4], digits[4:7], digits[7:9], digits[9:11])
+}
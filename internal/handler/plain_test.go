@@ -0,0 +1,191 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/handler"
+	"employer/internal/i18n"
+	"employer/internal/service"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func newPlainRouter(svc *mockService) *mux.Router {
+	r := mux.NewRouter()
+	handler.NewPlainHandler(svc, "", 2, zap.NewNop()).RegisterRoutes(r)
+	return r
+}
+
+func TestPlainList_RendersTableAndPagination(t *testing.T) {
+	var gotLimit, gotOffset int
+	svc := &mockService{
+		PageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*domain.Employee{
+				{ID: 3, Name: `<script>alert("x")</script>`, Phone: "+77011234567", City: "Almaty", Status: domain.StatusActive},
+				{ID: 4, Name: "Bob", Phone: "12345", City: "Astana", Status: domain.StatusActive},
+			}, 5, nil
+		},
+	}
+	r := newPlainRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/employees/plain?page=2&flash="+url.QueryEscape("Готово"), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if gotLimit != 2 || gotOffset != 2 {
+		t.Fatalf("expected page 2 of size 2, got limit=%d offset=%d", gotLimit, gotOffset)
+	}
+	body := rr.Body.String()
+	for _, fragment := range []string{
+		`<td>&#43;7 701 123 45 67</td>`,
+		`<td>12345</td>`,
+		`&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;`,
+		`action="/employees/plain/3/delete"`,
+		`href="/employees/plain" rel="prev"`,
+		`href="/employees/plain?page=3" rel="next"`,
+		`Страница 2 из 3`,
+		`<p class="flash" role="status">Готово</p>`,
+		`action="/employees/plain/create"`,
+		`<input type="search" name="q" value="">`,
+	} {
+		if !strings.Contains(body, fragment) {
+			t.Fatalf("expected %q in page:\n%s", fragment, body)
+		}
+	}
+	if strings.Contains(body, "<script>") {
+		t.Fatalf("employee name must be escaped")
+	}
+	if !strings.Contains(rr.Header().Get("Content-Security-Policy"), "default-src 'none'") {
+		t.Fatalf("expected restrictive CSP, got %q", rr.Header().Get("Content-Security-Policy"))
+	}
+}
+
+func TestPlainList_Search(t *testing.T) {
+	var gotQuery string
+	svc := &mockService{
+		SearchPageFn: func(ctx context.Context, query string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotQuery = query
+			return []*domain.Employee{{ID: 1, Name: "Алия", Phone: "+77010000001", City: "Almaty"}}, 1, nil
+		},
+	}
+	r := newPlainRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/employees/plain?q="+url.QueryEscape(`Алия "x"`), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || gotQuery != `Алия "x"` {
+		t.Fatalf("unexpected status %d or query %q", rr.Code, gotQuery)
+	}
+	body := rr.Body.String()
+	for _, fragment := range []string{
+		`name="q" value="Алия &#34;x&#34;"`,
+		`<td>Алия</td>`,
+		`Страница 1 из 1`,
+	} {
+		if !strings.Contains(body, fragment) {
+			t.Fatalf("expected %q in page:\n%s", fragment, body)
+		}
+	}
+	if strings.Contains(body, `rel="next"`) || strings.Contains(body, `rel="prev"`) {
+		t.Fatalf("single page must not have pagination links")
+	}
+}
+
+func TestPlainCreate_RedirectsWithFlash(t *testing.T) {
+	var created *domain.Employee
+	svc := &mockService{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			if e.Phone == "bad" {
+				return service.NewValidationError("phone", i18n.PhoneFormatKZ)
+			}
+			e.ID = 9
+			created = e
+			return nil
+		},
+	}
+	r := newPlainRouter(svc)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/employees/plain/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(url.Values{"name": {"Алия"}, "phone": {"+77010000001"}, "city": {"Almaty"}})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rr.Code)
+	}
+	if created == nil || created.Name != "Алия" || created.City != "Almaty" {
+		t.Fatalf("unexpected created employee: %+v", created)
+	}
+	location, _ := url.Parse(rr.Header().Get("Location"))
+	if location.Path != "/employees/plain" || !strings.Contains(location.Query().Get("flash"), "Алия") {
+		t.Fatalf("unexpected redirect: %s", rr.Header().Get("Location"))
+	}
+
+	rr = post(url.Values{"name": {"Bob"}, "phone": {"bad"}, "city": {"Astana"}})
+	location, _ = url.Parse(rr.Header().Get("Location"))
+	if rr.Code != http.StatusSeeOther || location.Query().Get("error") == "" {
+		t.Fatalf("expected redirect with error, got %d %s", rr.Code, rr.Header().Get("Location"))
+	}
+}
+
+func TestPlainDelete(t *testing.T) {
+	var deleted int64
+	svc := &mockService{
+		DeleteFn: func(ctx context.Context, id int64) error {
+			deleted = id
+			return nil
+		},
+	}
+	r := newPlainRouter(svc)
+
+	form := url.Values{"q": {"Bob"}, "page": {"2"}}
+	req := httptest.NewRequest(http.MethodPost, "/employees/plain/7/delete", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "http://example.com")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusSeeOther || deleted != 7 {
+		t.Fatalf("expected employee 7 deleted with 303, got %d (deleted=%d)", rr.Code, deleted)
+	}
+	location, _ := url.Parse(rr.Header().Get("Location"))
+	if location.Query().Get("q") != "Bob" || location.Query().Get("page") != "2" || location.Query().Get("flash") == "" {
+		t.Fatalf("expected redirect back to the same page, got %s", rr.Header().Get("Location"))
+	}
+}
+
+func TestPlainDelete_RejectsCrossSite(t *testing.T) {
+	svc := &mockService{
+		DeleteFn: func(ctx context.Context, id int64) error {
+			t.Fatalf("cross-site form must not delete")
+			return nil
+		},
+	}
+	r := newPlainRouter(svc)
+
+	for _, header := range [][2]string{{"Origin", "https://evil.example"}, {"Sec-Fetch-Site", "cross-site"}} {
+		req := httptest.NewRequest(http.MethodPost, "/employees/plain/7/delete", nil)
+		req.Header.Set(header[0], header[1])
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d", header[0], rr.Code)
+		}
+	}
+}
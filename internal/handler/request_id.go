@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"employer/internal/service"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса. Идентификатор попадает в поле
+// request_id логов сервиса и репозитория и возвращается в ответе
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength максимальная длина идентификатора из заголовка
+const maxRequestIDLength = 128
+
+// requestIDMiddleware берет идентификатор запроса из X-Request-ID, а если его нет или он
+// некорректен (слишком длинный, с пробелами или управляющими символами) — создает новый
+func (h *EmployeeHandler) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength || strings.IndexFunc(id, invalidRequestIDRune) >= 0 {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(service.WithRequestID(r.Context(), id)))
+	})
+}
+
+func invalidRequestIDRune(r rune) bool {
+	return r > unicode.MaxASCII || unicode.IsSpace(r) || unicode.IsControl(r)
+}
+
+// newRequestID создает случайный идентификатор из 32 шестнадцатеричных символов
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// streamFlushEvery через сколько сотрудников потоковый список отправляется клиенту
+const streamFlushEvery = 500
+
+// streamWriteTimeout сколько времени дается на запись очередной порции потокового списка.
+// Срок записи сервера (WriteTimeout) продлевается на него перед каждой порцией: большой
+// список пишется дольше WriteTimeout, а зависший клиент все равно отключается
+const streamWriteTimeout = 30 * time.Second
+
+// wantsStream проверяет параметр ?stream=true; некорректное значение — ошибка
+func wantsStream(r *http.Request) (bool, error) {
+	stream, ok := parseBoolParam(r.URL.Query().Get("stream"))
+	if !ok {
+		return false, badRequest("stream", i18n.RequestStream)
+	}
+	return stream, nil
+}
+
+// streamEmployees пишет список сотрудников JSON-массивом по мере чтения из БД, не собирая
+// его в памяти: "[", сотрудники через запятую с отправкой каждые streamFlushEvery, "]".
+// Пока не записан ни один сотрудник, ошибка отдается обычным ответом. Ошибка после начала
+// ответа логируется, а массив завершается объектом ошибки INTERNAL с полем code
+func (h *EmployeeHandler) streamEmployees(w http.ResponseWriter, r *http.Request, filter domain.EmployeeFilter, fields []employeeField) {
+	rc := http.NewResponseController(w)
+	// Без поддержки срока записи (ErrNotSupported) действует WriteTimeout сервера
+	extendDeadline := func() {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	}
+	enc := json.NewEncoder(w)
+	count := 0
+	start := func() {
+		extendDeadline()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("["))
+	}
+
+	err := h.service.StreamAllEmployees(r.Context(), filter, func(e *domain.Employee) error {
+		if count == 0 {
+			start()
+		} else if _, err := w.Write([]byte(",")); err != nil {
+			return err
+		}
+		response := toEmployeeResponse(e)
+		var item interface{} = response
+		if fields != nil {
+			item = &projectedEmployee{employee: response, fields: fields}
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		count++
+		if count%streamFlushEvery == 0 {
+			// Без поддержки Flush данные уходят по мере заполнения буфера сервера
+			_ = rc.Flush()
+			extendDeadline()
+		}
+		return nil
+	})
+	if err != nil && count == 0 {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.observeRows(r, count)
+	if count == 0 {
+		start()
+	}
+	if err != nil {
+		h.logger.Error("ошибка потоковой выдачи сотрудников", zap.Error(err), zap.Int("written", count))
+		w.Write([]byte(","))
+		enc.Encode(newErrorResponse(domain.CodeInternal, i18n.T(i18n.FromContext(r.Context()), i18n.Internal), nil))
+	}
+	w.Write([]byte("]\n"))
+}
@@ -0,0 +1,215 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"employer/internal/domain"
+)
+
+func TestGetAllEmployees_Stream(t *testing.T) {
+	svc := &mockService{
+		StreamAllFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			for _, e := range []*domain.Employee{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Асель"}} {
+				if err := fn(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			t.Fatal("stream must not load the whole list")
+			return nil, nil
+		},
+	}
+	r := newRouter(svc)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?stream=true&status=active&sort=id&fields=id,name", nil))
+
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("expected 200 JSON, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON array: %v: %s", err, rr.Body.String())
+	}
+	if len(got) != 3 || got[2]["name"] != "Асель" || len(got[0]) != 2 {
+		t.Fatalf("unexpected employees: %v", got)
+	}
+	if want := (domain.EmployeeFilter{Status: "active", Sort: domain.SortID}); svc.lastFilter != want {
+		t.Fatalf("expected filter %+v, got %+v", want, svc.lastFilter)
+	}
+
+	// пустой список — пустой массив
+	svc.StreamAllFn = nil
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?stream=true", nil))
+	if strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Fatalf("expected [], got %q", rr.Body.String())
+	}
+}
+
+func TestGetAllEmployees_StreamErrors(t *testing.T) {
+	failAfter := func(n int) func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+		return func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			for i := 1; i <= n; i++ {
+				if err := fn(&domain.Employee{ID: int64(i)}); err != nil {
+					return err
+				}
+			}
+			return errors.New("connection reset")
+		}
+	}
+
+	// ошибка до первой строки — обычный ответ с ошибкой
+	r := newRouter(&mockService{StreamAllFn: failAfter(0)})
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?stream=true", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rr.Code)
+	}
+	assertErrorCode(t, rr, domain.CodeInternal)
+
+	// ошибка после начала ответа — массив завершается объектом ошибки
+	r = newRouter(&mockService{StreamAllFn: failAfter(2)})
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?stream=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON array: %v: %s", err, rr.Body.String())
+	}
+	if len(got) != 3 || got[1]["id"] != float64(2) || got[2]["code"] != domain.CodeInternal {
+		t.Fatalf("expected two employees and an error sentinel, got %v", got)
+	}
+
+	for _, query := range []string{"stream=yes", "stream=true&limit=10", "stream=true&envelope=true"} {
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/employees?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeValidation)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees?stream=true", nil)
+	req.Header.Set("Accept", "application/xml")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("XML: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestGetAllEmployees_StreamOutlivesWriteTimeout(t *testing.T) {
+	const rows = 1500
+	svc := &mockService{
+		StreamAllFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			for i := 1; i <= rows; i++ {
+				if err := fn(&domain.Employee{ID: int64(i), Name: "Alice"}); err != nil {
+					return err
+				}
+				// медленная БД: весь список пишется дольше WriteTimeout сервера
+				if i%500 == 0 {
+					time.Sleep(150 * time.Millisecond)
+				}
+			}
+			return nil
+		},
+	}
+	srv := httptest.NewUnstartedServer(newRouter(svc))
+	srv.Config.WriteTimeout = 200 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/employees?stream=true")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("stream cut by write timeout: %v", err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil || len(got) != rows {
+		t.Fatalf("expected %d employees, got %d: %v", rows, len(got), err)
+	}
+}
+
+// discardWriter ResponseWriter без буфера тела: в отличие от httptest.ResponseRecorder
+// не хранит ответ, поэтому не влияет на замер памяти
+type discardWriter struct {
+	header  http.Header
+	written int
+}
+
+func (w *discardWriter) Header() http.Header { return w.header }
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	w.written += len(p)
+	return len(p), nil
+}
+
+func (w *discardWriter) WriteHeader(int) {}
+
+// liveHeap объем живых объектов в куче после сборки мусора
+func liveHeap() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func TestGetAllEmployees_StreamConstantMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("100k rows")
+	}
+	const rows = 100000
+	var heapEarly, heapLate uint64
+	svc := &mockService{
+		StreamAllFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			for i := 1; i <= rows; i++ {
+				if err := fn(&domain.Employee{ID: int64(i), Name: "Синтетический Сотрудник", Phone: "+77010000000", City: "Almaty", Status: "active"}); err != nil {
+					return err
+				}
+				switch i {
+				case 1000:
+					heapEarly = liveHeap()
+				case rows:
+					heapLate = liveHeap()
+				}
+			}
+			return nil
+		},
+	}
+	r := newRouter(svc)
+	w := &discardWriter{header: http.Header{}}
+
+	allocs := testing.AllocsPerRun(1, func() {
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/employees?stream=true", nil))
+	})
+
+	if w.written < rows*50 {
+		t.Fatalf("expected all rows written, got %d bytes", w.written)
+	}
+	// живая куча не растет вместе с количеством отданных строк
+	if heapLate > heapEarly+1<<20 {
+		t.Fatalf("heap grew from %d to %d bytes while streaming", heapEarly, heapLate)
+	}
+	if perRow := allocs / rows; perRow > 10 {
+		t.Fatalf("expected a bounded number of allocations per row, got %.1f", perRow)
+	}
+	t.Logf("%.1f allocations per row", allocs/rows)
+}
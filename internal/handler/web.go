@@ -0,0 +1,148 @@
+package handler
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/static"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Страницы веб-интерфейса: index.html, а если его нет — employee.html
+const (
+	indexPage    = "index.html"
+	employeePage = "employee.html"
+)
+
+// pageMissingHTML страница-заглушка, которая отдается с 500, если страницы
+// веб-интерфейса нет в каталоге STATIC_DIR: статические файлы не развернуты
+//
+//go:embed page_missing.html
+var pageMissingHTML []byte
+
+// WebHandler отдает веб-интерфейс, встроенный в бинарный файл, или из каталога staticDir
+type WebHandler struct {
+	files     fs.FS
+	staticDir string
+	basePath  string
+	logger    *zap.Logger
+}
+
+// NewWebHandler создает обработчик веб-интерфейса. staticDir — каталог на диске
+// вместо встроенных файлов (STATIC_DIR, для разработки), пустой — встроенные.
+// basePath — префикс, под которым смонтировано приложение (API_BASE_PATH), пустой для корня
+func NewWebHandler(staticDir, basePath string, logger *zap.Logger) *WebHandler {
+	var files fs.FS = static.Files
+	if staticDir != "" {
+		files = os.DirFS(staticDir)
+	}
+	return &WebHandler{
+		files:     files,
+		staticDir: staticDir,
+		basePath:  strings.TrimRight(basePath, "/"),
+		logger:    logger,
+	}
+}
+
+// RegisterRoutes регистрирует страницу и статические файлы (CSS, JS, изображения).
+// Остальные пути веб-интерфейса обслуживает Fallback
+func (h *WebHandler) RegisterRoutes(router *mux.Router) {
+	router.PathPrefix("/static/").Handler(http.StripPrefix(h.basePath+"/static/", http.FileServer(http.FS(h.files))))
+	router.HandleFunc("/", h.ServePage).Methods("GET")
+}
+
+// Source описывает, откуда отдается веб-интерфейс: каталог STATIC_DIR или "embedded"
+func (h *WebHandler) Source() string {
+	if h.staticDir == "" {
+		return "embedded"
+	}
+	return h.staticDir
+}
+
+// Page возвращает имя страницы веб-интерфейса и есть ли она среди файлов
+func (h *WebHandler) Page() (string, bool) {
+	for _, page := range []string{indexPage, employeePage} {
+		if _, err := fs.Stat(h.files, page); err == nil {
+			return page, true
+		}
+	}
+	return employeePage, false
+}
+
+// ServePage обслуживает страницу веб-интерфейса
+// GET /
+func (h *WebHandler) ServePage(w http.ResponseWriter, r *http.Request) {
+	// Устанавливаем заголовки
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+
+	// Без страницы http.ServeFile ответил бы голым 404; отдаем заглушку с объяснением
+	page, ok := h.Page()
+	if !ok {
+		h.logger.Error("страница веб-интерфейса не найдена: статические файлы не развернуты",
+			zap.String("source", h.Source()),
+			zap.String("expected_page", page),
+			zap.String("path", r.URL.Path),
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		if r.Method != http.MethodHead {
+			w.Write(pageMissingHTML)
+		}
+		return
+	}
+
+	// Обслуживаем файл
+	http.ServeFileFS(w, r, h.files, page)
+
+	h.logger.Info("employee page served",
+		zap.String("remote_addr", clientIP(r)),
+		zap.String("path", r.URL.Path),
+	)
+}
+
+// Fallback обрабатывает запросы, для которых не нашлось маршрута; назначается
+// как NotFoundHandler корневого роутера. Неизвестные пути /api отвечают 404 JSON,
+// остальные GET запросы под basePath получают страницу, чтобы работала
+// маршрутизация на стороне клиента (/dashboard, /employees/5 и т.п.)
+func (h *WebHandler) Fallback(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if h.basePath != "" {
+		if path != h.basePath && !strings.HasPrefix(path, h.basePath+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		path = strings.TrimPrefix(path, h.basePath)
+	}
+
+	switch {
+	case path == "/api" || strings.HasPrefix(path, "/api/"):
+		locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		message := i18n.T(locale, i18n.RouteNotFound)
+		w.Header().Set("Content-Language", locale)
+		h.writeJSONResponse(w, http.StatusNotFound, newErrorResponse(domain.CodeNotFound, message, nil))
+	case strings.HasPrefix(path, "/static/"):
+		http.NotFound(w, r)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		h.ServePage(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *WebHandler) writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
@@ -0,0 +1,156 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/handler"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// newWebRouter собирает роутер как в serve: API, веб-интерфейс из staticDir и SPA fallback
+func newWebRouter(t *testing.T, staticDir, basePath string) *mux.Router {
+	t.Helper()
+	log := zap.NewNop()
+	router := mux.NewRouter()
+	app := handler.WithBasePath(router, basePath)
+
+	handler.NewEmployeeHandler(&mockService{}, log).RegisterRoutes(app)
+	web := handler.NewWebHandler(staticDir, basePath, log)
+	web.RegisterRoutes(app)
+	router.NotFoundHandler = http.HandlerFunc(web.Fallback)
+	return router
+}
+
+func writeStaticFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestWebFallback_ServesPageForUnknownPath(t *testing.T) {
+	dir := t.TempDir()
+	writeStaticFile(t, dir, "employee.html", "<html>employees</html>")
+	r := newWebRouter(t, dir, "")
+
+	for _, path := range []string{"/", "/dashboard", "/employees/5"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "employees") || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("%s: expected HTML page, got %q (%s)", path, rr.Body.String(), rr.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestWebFallback_UnknownAPIReturnsJSON404(t *testing.T) {
+	dir := t.TempDir()
+	writeStaticFile(t, dir, "employee.html", "<html>employees</html>")
+	r := newWebRouter(t, dir, "")
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req := httptest.NewRequest(method, "/api/unknown", nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", method, rr.Code)
+		}
+		assertErrorCode(t, rr, domain.CodeNotFound)
+	}
+}
+
+func TestWebFallback_KeepsMethodNotAllowed(t *testing.T) {
+	r := newWebRouter(t, t.TempDir(), "")
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/employees/1", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+}
+
+func TestWebHandler_PrefersIndexAndServesStatic(t *testing.T) {
+	dir := t.TempDir()
+	writeStaticFile(t, dir, "employee.html", "<html>employees</html>")
+	writeStaticFile(t, dir, "index.html", "<html>spa</html>")
+	writeStaticFile(t, dir, "app.js", "console.log('app')")
+	r := newWebRouter(t, dir, "/hr")
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"/hr/dashboard", http.StatusOK, "spa"},
+		{"/hr/static/app.js", http.StatusOK, "console.log"},
+		{"/hr/static/missing.js", http.StatusNotFound, ""},
+		{"/other", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tt.wantCode || !strings.Contains(rr.Body.String(), tt.wantBody) {
+			t.Fatalf("%s: expected %d with %q, got %d %q", tt.path, tt.wantCode, tt.wantBody, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestWebHandler_MissingPageServesFallback(t *testing.T) {
+	r := newWebRouter(t, t.TempDir(), "")
+
+	for _, path := range []string{"/", "/dashboard"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: expected %d, got %d", path, http.StatusInternalServerError, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "STATIC_DIR") || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("%s: expected fallback HTML page, got %q (%s)", path, rr.Body.String(), rr.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestWebHandler_ServesEmbeddedAssets(t *testing.T) {
+	r := newWebRouter(t, "", "")
+
+	for _, path := range []string{"/static/employee.html", "/", "/dashboard"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Управление Сотрудниками") || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("%s: expected embedded page, got %d bytes (%s)", path, rr.Body.Len(), rr.Header().Get("Content-Type"))
+		}
+	}
+
+	// исходники пакета static не встраиваются
+	req := httptest.NewRequest(http.MethodGet, "/static/embed.go", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for package source, got %d", rr.Code)
+	}
+}
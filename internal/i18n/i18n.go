@@ -0,0 +1,160 @@
+// Package i18n переводит сообщения API на русский, казахский и английский.
+// Сообщения задаются ключами (см. keys.go), переводы лежат в locales/*.json
+// и встраиваются в бинарник.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые языки
+const (
+	RU = "ru"
+	KK = "kk"
+	EN = "en"
+)
+
+// Default язык, если клиент не прислал Accept-Language или ни один из его языков не поддерживается
+const Default = RU
+
+// fallback порядок, в котором ищется перевод после запрошенного языка
+var fallback = []string{EN, RU}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// translations переводы по языку и ключу
+var translations = loadTranslations()
+
+func loadTranslations() map[string]map[string]string {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: чтение переводов: %v", err))
+	}
+
+	result := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: чтение %s: %v", file.Name(), err))
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: разбор %s: %v", file.Name(), err))
+		}
+		result[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return result
+}
+
+// Supported возвращает поддерживаемые языки в алфавитном порядке
+func Supported() []string {
+	locales := make([]string, 0, len(translations))
+	for locale := range translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// T возвращает сообщение key на языке locale, подставляя args через fmt.Sprintf.
+// Если перевода нет, используется английский, затем русский; если ключа нет
+// нигде, возвращается сам ключ.
+func T(locale, key string, args ...interface{}) string {
+	for _, l := range append([]string{locale}, fallback...) {
+		if format, ok := translations[l][key]; ok {
+			if len(args) == 0 {
+				return format
+			}
+			return fmt.Sprintf(format, args...)
+		}
+	}
+	return key
+}
+
+// Error ошибка с ключом сообщения; Error() возвращает текст на языке по умолчанию
+type Error struct {
+	Key  string
+	Args []interface{}
+}
+
+func (e *Error) Error() string {
+	return T(Default, e.Key, e.Args...)
+}
+
+// ParseAcceptLanguage выбирает поддерживаемый язык из заголовка Accept-Language
+// с учетом q-значений: "kk-KZ,ru;q=0.8,en;q=0.5" -> "kk". Региональные варианты
+// сводятся к основному языку; "*" означает язык по умолчанию.
+func ParseAcceptLanguage(header string) string {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, q := parseLanguageRange(part)
+		if q <= bestQ {
+			continue
+		}
+		if tag == "*" {
+			best, bestQ = Default, q
+			continue
+		}
+		if base, _, _ := strings.Cut(tag, "-"); translations[base] != nil {
+			best, bestQ = base, q
+		}
+	}
+	return best
+}
+
+// parseLanguageRange разбирает один элемент Accept-Language: "en-US;q=0.7".
+// Некорректное q-значение считается нулевым, то есть язык пропускается.
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(part, ";")
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", 0
+	}
+
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(name) != "q" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return tag, 0
+		}
+		q = parsed
+	}
+	return tag, q
+}
+
+type localeKey struct{}
+
+// WithLocale возвращает контекст с выбранным языком
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext возвращает язык из контекста или язык по умолчанию
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return Default
+}
+
+// Middleware выбирает язык ответа по Accept-Language и сохраняет его в контексте запроса
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), locale)))
+	})
+}
@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// declaredKeys возвращает значения всех строковых констант из keys.go
+func declaredKeys(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "keys.go", nil, 0)
+	if err != nil {
+		t.Fatalf("разбор keys.go: %v", err)
+	}
+
+	var keys []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if ok && lit.Kind == token.STRING {
+			key, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatalf("ключ %s: %v", lit.Value, err)
+			}
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if len(keys) == 0 {
+		t.Fatal("в keys.go не найдено ни одного ключа")
+	}
+	return keys
+}
+
+func TestKeysTranslated(t *testing.T) {
+	keys := declaredKeys(t)
+	for _, locale := range []string{RU, KK, EN} {
+		messages, ok := translations[locale]
+		if !ok {
+			t.Fatalf("нет файла переводов locales/%s.json", locale)
+		}
+		for _, key := range keys {
+			if messages[key] == "" {
+				t.Errorf("%s: нет перевода ключа %q", locale, key)
+			}
+		}
+	}
+}
+
+func TestLocalesHaveNoUnknownKeys(t *testing.T) {
+	known := make(map[string]bool)
+	for _, key := range declaredKeys(t) {
+		known[key] = true
+	}
+	for locale, messages := range translations {
+		for key := range messages {
+			if !known[key] {
+				t.Errorf("%s: ключ %q не объявлен в keys.go", locale, key)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []interface{}
+		want   string
+	}{
+		{name: "русский", locale: RU, key: NotFoundEmployee, want: "сотрудник не найден"},
+		{name: "казахский", locale: KK, key: NotFoundEmployee, want: "қызметкер табылмады"},
+		{name: "английский", locale: EN, key: NotFoundEmployee, want: "employee not found"},
+		{name: "аргументы", locale: EN, key: IDsTooMany, args: []interface{}{1000}, want: "at most 1000 IDs per request"},
+		{name: "неизвестный язык", locale: "de", key: NotFoundEmployee, want: "employee not found"},
+		{name: "неизвестный ключ", locale: RU, key: "no.such.key", want: "no.such.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := T(tt.locale, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, ожидалось %q", tt.locale, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT_FallbackChain(t *testing.T) {
+	translations["test-only"] = map[string]string{}
+	translations[EN]["test.fallback"] = "english"
+	translations[RU]["test.ru_only"] = "только русский"
+	defer func() {
+		delete(translations, "test-only")
+		delete(translations[EN], "test.fallback")
+		delete(translations[RU], "test.ru_only")
+	}()
+
+	if got := T(KK, "test.fallback"); got != "english" {
+		t.Errorf("kk -> en: получено %q", got)
+	}
+	if got := T(KK, "test.ru_only"); got != "только русский" {
+		t.Errorf("kk -> en -> ru: получено %q", got)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: RU},
+		{header: "en", want: EN},
+		{header: "kk-KZ", want: KK},
+		{header: "EN-us", want: EN},
+		{header: "fr, de", want: RU},
+		{header: "fr, en;q=0.5", want: EN},
+		{header: "ru;q=0.3, kk;q=0.9, en;q=0.5", want: KK},
+		{header: "en;q=0.5, kk;q=0.5", want: EN},
+		{header: "en;q=0, kk;q=0.1", want: KK},
+		{header: "en;q=abc, kk;q=0.1", want: KK},
+		{header: "*", want: RU},
+		{header: "en;q=0.5, *;q=0.9", want: RU},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); got != tt.want {
+				t.Errorf("ParseAcceptLanguage(%q) = %q, ожидалось %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext_Default(t *testing.T) {
+	if got := FromContext(context.Background()); got != Default {
+		t.Errorf("FromContext() = %q, ожидалось %q", got, Default)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "kk-KZ,ru;q=0.8")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if got != KK {
+		t.Errorf("язык в контексте = %q, ожидался %q", got, KK)
+	}
+	if vary := rr.Header().Get("Vary"); vary != "Accept-Language" {
+		t.Errorf("Vary = %q, ожидался Accept-Language", vary)
+	}
+}
@@ -0,0 +1,144 @@
+package i18n
+
+// Ключи сообщений. Перевод каждого ключа должен быть во всех файлах locales/*.json,
+// иначе упадет TestKeysTranslated.
+const (
+	// Ошибки валидации поиска и пагинации
+	SearchEmpty        = "validation.search.empty"
+	SearchControlChars = "validation.search.control_chars"
+	SearchTooShort     = "validation.search.too_short"
+	SearchTooLong      = "validation.search.too_long"
+	SearchTooManyTerms = "validation.search.too_many_terms"
+	LimitRange         = "validation.limit.range"
+	OffsetNegative     = "validation.offset.negative"
+	ListTooLarge       = "validation.list.too_large"
+
+	// Окно годовщин ?days=
+	AnniversaryDaysRange = "validation.days.range"
+
+	// Ошибки валидации пакетных операций
+	IDsEmpty   = "validation.ids.empty"
+	IDsTooMany = "validation.ids.too_many"
+	IDsInvalid = "validation.ids.invalid"
+
+	// Пакетное обновление: ID или телефон уже есть в пакете (номер элемента с 1)
+	BatchIDRepeated    = "validation.batch.id_repeated"
+	BatchPhoneRepeated = "validation.batch.phone_repeated"
+
+	// Ошибки валидации полей сотрудника
+	NameRequired      = "validation.name.required"
+	NameControlChars  = "validation.name.control_chars"
+	NameMarkup        = "validation.name.markup"
+	PhoneRequired     = "validation.phone.required"
+	PhoneTaken        = "validation.phone.taken"
+	PhoneFormatKZ     = "validation.phone.format_kz"
+	PhoneFormatRU     = "validation.phone.format_ru"
+	PhoneFormatINTL   = "validation.phone.format_intl"
+	CityRequired      = "validation.city.required"
+	CityControlChars  = "validation.city.control_chars"
+	CityMarkup        = "validation.city.markup"
+	CityTooLong       = "validation.city.too_long"
+	StatusInvalid     = "validation.status.invalid"
+	DateFormat        = "validation.date.format"
+	BirthDateFuture   = "validation.birth_date.future"
+	HireDateTooEarly  = "validation.hire_date.too_early"
+	ImportModeUnknown = "validation.import.mode"
+	ImportOverwriteID = "validation.import.overwrite_id"
+
+	// Импорт CSV: заголовок файла, соответствие колонок (?mapping=) и повтор телефона в файле
+	ImportCSVEmpty      = "validation.import.csv_empty"
+	ImportCSVHeader     = "validation.import.csv_header"
+	ImportCSVColumn     = "validation.import.csv_column"
+	ImportMappingField  = "validation.import.mapping_field"
+	ImportPhoneRepeated = "validation.import.phone_repeated"
+
+	// Слияние дубликатов: источник не указан или совпадает с сотрудником, в которого вливается
+	MergeSourceRequired = "validation.merge.source_required"
+	MergeSelf           = "validation.merge.self"
+
+	// Посещаемость: длинное примечание, период from..to в обратном порядке или слишком длинный
+	AttendanceNoteTooLong  = "validation.attendance.note_too_long"
+	AttendanceRangeOrder   = "validation.attendance.range_order"
+	AttendanceRangeTooLong = "validation.attendance.range_too_long"
+
+	// Ошибки параметров запроса
+	RequestID           = "request.id"
+	RequestBody         = "request.body"
+	RequestAsOf         = "request.as_of"
+	RequestIfMatch      = "request.if_match"
+	RequestQuery        = "request.q_required"
+	RequestExcludeID    = "request.exclude_id"
+	RequestLimit        = "request.limit"
+	RequestOffset       = "request.offset"
+	RequestSort         = "request.sort"
+	RequestOrder        = "request.order"
+	RequestExportFormat = "request.export_format"
+	RequestImportFormat = "request.import_format"
+	RequestStrict       = "request.strict"
+	RequestDryRun       = "request.dry_run"
+	RequestMapping      = "request.mapping"
+	RequestActor        = "request.actor"
+	RequestIdempotency  = "request.idempotency_key"
+	RequestHighlight    = "request.highlight"
+	// Обновление без поля version и заголовка If-Match
+	RequestVersionRequired = "request.version_required"
+	// ?highlight=true нельзя сочетать с ?fields=
+	RequestHighlightFields = "request.highlight_fields"
+	// ?ids= — список ID через запятую, без пагинации и фильтров
+	RequestIDs         = "request.ids"
+	RequestIDsCombined = "request.ids_combined"
+	// ?created_from=&created_to= — интервал даты создания, без пагинации и фильтров
+	RequestCreatedDate     = "request.created_date"
+	RequestCreatedBounds   = "request.created_bounds"
+	RequestCreatedOrder    = "request.created_order"
+	RequestCreatedCombined = "request.created_combined"
+	// ?stream=true — список потоком JSON без пагинации, конверта и XML
+	RequestStream         = "request.stream"
+	RequestStreamCombined = "request.stream_combined"
+	// ?if_exists=true — DELETE без 404 для отсутствующего сотрудника
+	RequestIfExists = "request.if_exists"
+
+	// Конфликты, отсутствующие записи и внутренние ошибки
+	ConflictPhone     = "conflict.phone"
+	ConflictDuplicate = "conflict.duplicate"
+	ConflictVersion   = "conflict.version"
+	QuotaExceeded     = "quota.exceeded"
+	NotFoundEmployee  = "not_found.employee"
+	RouteNotFound     = "not_found.route"
+	Internal          = "internal"
+
+	// Ключ Idempotency-Key уже использован с другим телом запроса или занят параллельным запросом
+	ConflictIdempotencyKey        = "conflict.idempotency_key"
+	ConflictIdempotencyInProgress = "conflict.idempotency_in_progress"
+
+	// Очистку удаленных сотрудников выполняет другой экземпляр приложения
+	ConflictPurgeInProgress = "conflict.purge_in_progress"
+
+	// Перезагруженная конфигурация некорректна и не применена
+	ConfigInvalid = "validation.config.invalid"
+
+	// Включен режим обслуживания; сообщение слишком длинное или без поля enabled
+	Maintenance               = "maintenance"
+	RequestMaintenanceEnabled = "request.maintenance_enabled"
+	RequestMaintenanceMessage = "request.maintenance_message"
+
+	// Нет или неверный токен администратора (ADMIN_TOKEN) для /api/admin
+	AdminUnauthorized = "unauthorized.admin"
+
+	// Превышен предел одновременных запросов (MAX_CONCURRENT_REQUESTS)
+	Overloaded = "overloaded"
+
+	// Некорректное XML тело, неподдерживаемые заголовки Accept и Content-Type
+	RequestBodyXML     = "request.body_xml"
+	RequestAccept      = "request.accept"
+	RequestContentType = "request.content_type"
+
+	// Разбор JSON тела: пустое тело, синтаксическая ошибка, поле не того типа
+	RequestBodyEmpty  = "request.body_empty"
+	RequestBodySyntax = "request.body_syntax"
+	RequestBodyType   = "request.body_type"
+
+	// Неизвестные значения ?expand= и ?fields=
+	RequestExpand = "request.expand"
+	RequestFields = "request.fields"
+)
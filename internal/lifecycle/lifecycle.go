@@ -0,0 +1,146 @@
+// Package lifecycle запуск и упорядоченная остановка компонентов приложения:
+// HTTP серверов, фоновых задач, подключений к БД.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LateStopTimeout сколько ждать компонент, до которого очередь дошла после общего
+// срока остановки: ресурсы вроде БД нужно закрыть, даже если предыдущий компонент завис
+const LateStopTimeout = time.Second
+
+// Hook компонент приложения. Start и Stop необязательны
+type Hook struct {
+	// Name имя компонента для логов и ошибок
+	Name string
+	// Start запускает компонент; долгую работу нужно вести в отдельной горутине
+	Start func(ctx context.Context) error
+	// Stop останавливает компонент, соблюдая срок ctx
+	Stop func(ctx context.Context) error
+	// Timeout собственный срок остановки; 0 — только общий срок Manager.Stop
+	Timeout time.Duration
+}
+
+// Manager запускает компоненты в порядке регистрации и останавливает в обратном
+type Manager struct {
+	mu    sync.Mutex
+	hooks []Hook
+	// started[i] — компонент hooks[i] запущен и должен быть остановлен
+	started []bool
+	logger  *zap.Logger
+}
+
+// New создает менеджер без компонентов
+func New(logger *zap.Logger) *Manager {
+	return &Manager{logger: logger}
+}
+
+// Append регистрирует компонент. Компонент без Start считается запущенным сразу:
+// так регистрируются уже открытые ресурсы, чтобы Stop закрыл их и при ошибке запуска
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+	m.started = append(m.started, hook.Start == nil)
+}
+
+// Start запускает зарегистрированные и еще не запущенные компоненты по порядку.
+// На первой ошибке останавливается; уже запущенные компоненты закрывает Stop
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, hook := range m.hooks {
+		if m.started[i] {
+			continue
+		}
+		if err := hook.Start(ctx); err != nil {
+			return fmt.Errorf("запуск %s: %w", hook.Name, err)
+		}
+		m.started[i] = true
+		m.logger.Debug("компонент запущен", zap.String("component", hook.Name))
+	}
+	return nil
+}
+
+// Stop останавливает запущенные компоненты в порядке, обратном регистрации. Компонент,
+// не уложившийся в свой Timeout или общий срок ctx, не задерживает остальные. Возвращает
+// ошибки всех компонентов; повторный вызов ничего не делает
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	hooks, started := m.hooks, m.started
+	m.hooks, m.started = nil, nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if !started[i] || hooks[i].Stop == nil {
+			continue
+		}
+		if err := m.stopHook(ctx, hooks[i]); err != nil {
+			m.logger.Error("ошибка остановки компонента",
+				zap.String("component", hooks[i].Name),
+				zap.Error(err))
+			errs = append(errs, fmt.Errorf("остановка %s: %w", hooks[i].Name, err))
+			continue
+		}
+		m.logger.Debug("компонент остановлен", zap.String("component", hooks[i].Name))
+	}
+	return errors.Join(errs...)
+}
+
+// stopHook вызывает hook.Stop и ждет его не дольше срока остановки компонента
+func (m *Manager) stopHook(ctx context.Context, hook Hook) error {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), LateStopTimeout)
+		defer cancel()
+	}
+	if hook.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- hook.Stop(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("не остановлен за отведенное время: %w", ctx.Err())
+	}
+}
+
+// Go компонент-фоновая задача: Start запускает run в горутине, Stop отменяет ее
+// контекст и ждет завершения
+func Go(name string, run func(ctx context.Context)) Hook {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			go func() {
+				defer close(done)
+				run(ctx)
+			}()
+			return nil
+		},
+		Stop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	}
+}
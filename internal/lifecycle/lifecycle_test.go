@@ -0,0 +1,168 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"employer/internal/lifecycle"
+
+	"go.uber.org/zap"
+)
+
+// recorder запоминает порядок вызовов Start и Stop
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) hook(name string) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:  name,
+		Start: func(context.Context) error { r.add("start " + name); return nil },
+		Stop:  func(context.Context) error { r.add("stop " + name); return nil },
+	}
+}
+
+func (r *recorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func TestManager_Order(t *testing.T) {
+	rec := &recorder{}
+	m := lifecycle.New(zap.NewNop())
+	m.Append(lifecycle.Hook{Name: "db", Stop: func(context.Context) error { rec.add("stop db"); return nil }})
+	m.Append(rec.hook("server"))
+	m.Append(rec.hook("worker"))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	want := []string{"start server", "start worker", "stop worker", "stop server", "stop db"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// повторная остановка ничего не делает
+	if err := m.Stop(context.Background()); err != nil || len(rec.get()) != len(want) {
+		t.Fatalf("second Stop must be a no-op, got %v, %v", rec.get(), err)
+	}
+}
+
+func TestManager_StartFailureStopsStarted(t *testing.T) {
+	rec := &recorder{}
+	m := lifecycle.New(zap.NewNop())
+	m.Append(rec.hook("server"))
+	m.Append(lifecycle.Hook{Name: "broken", Start: func(context.Context) error { return errors.New("boom") }})
+	m.Append(rec.hook("worker"))
+
+	err := m.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected start error naming the hook, got %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	want := []string{"start server", "stop server"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestManager_HookTimeoutDoesNotBlockOthers(t *testing.T) {
+	rec := &recorder{}
+	hang := make(chan struct{})
+	defer close(hang)
+
+	m := lifecycle.New(zap.NewNop())
+	m.Append(lifecycle.Hook{Name: "db", Stop: func(context.Context) error { rec.add("stop db"); return nil }})
+	// зависший компонент не смотрит на ctx
+	m.Append(lifecycle.Hook{Name: "stuck", Timeout: 50 * time.Millisecond, Stop: func(context.Context) error {
+		<-hang
+		return nil
+	}})
+	m.Append(lifecycle.Hook{Name: "failing", Stop: func(context.Context) error { return errors.New("flush failed") }})
+
+	started := time.Now()
+	err := m.Stop(context.Background())
+	elapsed := time.Since(started)
+
+	if elapsed > time.Second {
+		t.Fatalf("stuck hook blocked Stop for %v", elapsed)
+	}
+	if got := rec.get(); !reflect.DeepEqual(got, []string{"stop db"}) {
+		t.Fatalf("expected remaining hooks to stop, got %v", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "stuck") || !strings.Contains(err.Error(), "failing") ||
+		!errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected aggregated errors for stuck and failing hooks, got %v", err)
+	}
+}
+
+func TestManager_GlobalDeadline(t *testing.T) {
+	rec := &recorder{}
+	hang := make(chan struct{})
+	defer close(hang)
+
+	m := lifecycle.New(zap.NewNop())
+	m.Append(lifecycle.Hook{Name: "db", Stop: func(context.Context) error { rec.add("stop db"); return nil }})
+	// без собственного срока компонент ограничен общим сроком
+	m.Append(lifecycle.Hook{Name: "stuck", Stop: func(context.Context) error {
+		<-hang
+		return nil
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	err := m.Stop(ctx)
+	if elapsed := time.Since(started); elapsed > 100*time.Millisecond+lifecycle.LateStopTimeout {
+		t.Fatalf("Stop exceeded the global deadline: %v", elapsed)
+	}
+	// БД закрывается и после истечения общего срока
+	if got := rec.get(); !reflect.DeepEqual(got, []string{"stop db"}) {
+		t.Fatalf("expected db to stop after the deadline, got %v", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "stuck") {
+		t.Fatalf("expected error for stuck hook, got %v", err)
+	}
+}
+
+func TestGo(t *testing.T) {
+	stopped := make(chan struct{})
+	m := lifecycle.New(zap.NewNop())
+	m.Append(lifecycle.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	}))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatalf("Stop must wait for the worker to return")
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"employer/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// GetUpcomingAnniversaries получает дни рождения и годовщины приема на работу,
+// приходящиеся на [from, from + withinDays], ближайшие первыми. Сотрудник без даты
+// в выборку по ней не попадает; день самого приема (0 лет стажа) годовщиной не считается.
+//
+// Годовщина в году Y — дата + (Y - год даты) лет; PostgreSQL переносит 29 февраля
+// на 28 февраля невисокосного года. Если годовщина этого года уже прошла, берется
+// следующий год, поэтому окно через Новый год (декабрь → январь) находит январские даты.
+func (r *employeeRepository) GetUpcomingAnniversaries(ctx context.Context, from time.Time, withinDays int) ([]*domain.Anniversary, error) {
+	query := `
+		SELECT id, name, phone, city, status, created_by, updated_by, birth_date, hire_date, kind, next_date,
+			EXTRACT(YEAR FROM next_date)::int - EXTRACT(YEAR FROM original_date)::int
+		FROM (
+			SELECT e.*, a.kind, a.original_date,
+				CASE WHEN (a.original_date + make_interval(years => y.years))::date >= $1::date
+					THEN (a.original_date + make_interval(years => y.years))::date
+					ELSE (a.original_date + make_interval(years => y.years + 1))::date
+				END AS next_date
+			FROM employees e
+			CROSS JOIN LATERAL (VALUES ('` + domain.AnniversaryBirthday + `', e.birth_date), ('` + domain.AnniversaryHire + `', e.hire_date)) AS a(kind, original_date)
+			CROSS JOIN LATERAL (SELECT EXTRACT(YEAR FROM $1::date)::int - EXTRACT(YEAR FROM a.original_date)::int AS years) AS y
+			WHERE a.original_date IS NOT NULL AND e.deleted_at IS NULL
+		) AS anniversaries
+		WHERE next_date > original_date AND next_date <= $1::date + $2::int
+		ORDER BY next_date, ` + r.nameOrder + `, id, kind`
+
+	rows, err := r.replica.QueryContext(ctx, query, from, withinDays)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения годовщин сотрудников", zap.Error(err), zap.Int("within_days", withinDays))
+		return nil, fmt.Errorf("получение годовщин сотрудников: %w", err)
+	}
+	defer rows.Close()
+
+	var anniversaries []*domain.Anniversary
+	for rows.Next() {
+		employee := &domain.Employee{}
+		anniversary := &domain.Anniversary{Employee: employee}
+		var birthDate, hireDate sql.NullTime
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy, &birthDate, &hireDate,
+			&anniversary.Kind, &anniversary.Date, &anniversary.Years)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования годовщины", zap.Error(err))
+			return nil, fmt.Errorf("сканирование годовщины: %w", err)
+		}
+		employee.BirthDate, employee.HireDate = nullDate(birthDate), nullDate(hireDate)
+		anniversaries = append(anniversaries, anniversary)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по результатам: %w", err)
+	}
+
+	return anniversaries, nil
+}
+
+// nullDate переводит дату из БД в поле сотрудника: NULL — nil
+func nullDate(date sql.NullTime) *time.Time {
+	if !date.Valid {
+		return nil
+	}
+	return &date.Time
+}
@@ -0,0 +1,102 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+func TestIntegration_GetUpcomingAnniversaries(t *testing.T) {
+	db := openIntegrationDB(t)
+
+	employees := []struct {
+		name      string
+		birthDate interface{}
+		hireDate  interface{}
+	}{
+		{"New Year", "1990-01-03", nil},
+		{"Leap", "2000-02-29", nil},
+		{"Veteran", nil, "2015-12-28"},
+		{"Hired Today", nil, "2024-12-25"},
+		{"No Dates", nil, nil},
+		{"Summer", "1985-07-15", "2010-07-15"},
+	}
+	for i, e := range employees {
+		_, err := db.Exec(`INSERT INTO employees (name, phone, city, birth_date, hire_date) VALUES ($1, $2, 'Almaty', $3, $4)`,
+			e.name, fmt.Sprintf("+7701000000%d", i), e.birthDate, e.hireDate)
+		if err != nil {
+			t.Fatalf("insert %s: %v", e.name, err)
+		}
+	}
+
+	repos := repository.NewRepositories(db, zap.NewNop())
+	date := func(s string) time.Time {
+		d, _ := time.Parse(domain.DateLayout, s)
+		return d
+	}
+
+	tests := []struct {
+		name string
+		from string
+		days int
+		want []string // "имя kind дата лет"
+	}{
+		{
+			// окно через Новый год находит январские даты следующего года;
+			// день самого приема годовщиной не считается
+			name: "year wrap",
+			from: "2024-12-25", days: 14,
+			want: []string{"Veteran hire 2024-12-28 9", "New Year birthday 2025-01-03 35"},
+		},
+		{
+			// в невисокосный год день рождения 29 февраля — 28 февраля
+			name: "leap day in a common year",
+			from: "2023-02-20", days: 10,
+			want: []string{"Leap birthday 2023-02-28 23"},
+		},
+		{
+			name: "leap day in a leap year",
+			from: "2024-02-28", days: 1,
+			want: []string{"Leap birthday 2024-02-29 24"},
+		},
+		{
+			// годовщина этого года уже прошла — берется следующий год
+			name: "passed this year",
+			from: "2023-03-01", days: 366,
+			want: []string{
+				"Summer birthday 2023-07-15 38", "Summer hire 2023-07-15 13",
+				"Veteran hire 2023-12-28 8", "New Year birthday 2024-01-03 34",
+				"Leap birthday 2024-02-29 24",
+			},
+		},
+		{
+			name: "today is included",
+			from: "2025-07-15", days: 1,
+			want: []string{"Summer birthday 2025-07-15 40", "Summer hire 2025-07-15 15"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anniversaries, err := repos.Employee.GetUpcomingAnniversaries(context.Background(), date(tt.from), tt.days)
+			if err != nil {
+				t.Fatalf("GetUpcomingAnniversaries: %v", err)
+			}
+			var got []string
+			for _, a := range anniversaries {
+				got = append(got, fmt.Sprintf("%s %s %s %d", a.Employee.Name, a.Kind, domain.FormatDate(&a.Date), a.Years))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
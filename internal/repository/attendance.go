@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"employer/internal/domain"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// foreignKeyViolation код ошибки PostgreSQL foreign_key_violation
+const foreignKeyViolation = "23503"
+
+// isForeignKeyViolation определяет, что запрос сослался на несуществующую запись
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation
+}
+
+// UpsertAttendance сохраняет отметку сотрудника за attendance.Date: если отметка за эту
+// дату уже есть, меняет ее статус и примечание. Заполняет CreatedAt и UpdatedAt.
+// Отсутствующий или удаленный сотрудник — NotFoundError
+func (r *employeeRepository) UpsertAttendance(ctx context.Context, attendance *domain.Attendance) error {
+	// Внешний ключ не мешает отметить мягко удаленного сотрудника, поэтому вставка
+	// идет только при живой записи; без нее запрос не возвращает строк
+	query := `
+		INSERT INTO employee_attendance (employee_id, date, status, note)
+		SELECT $1, $2, $3, $4
+		WHERE EXISTS (SELECT 1 FROM employees WHERE id = $1 AND deleted_at IS NULL)
+		ON CONFLICT (employee_id, date) DO UPDATE
+		SET status = EXCLUDED.status, note = EXCLUDED.note, updated_at = CURRENT_TIMESTAMP
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, attendance.EmployeeID, attendance.Date,
+		attendance.Status, attendance.Note).Scan(&attendance.CreatedAt, &attendance.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isForeignKeyViolation(err) {
+			r.log(ctx).Warn("сотрудник для отметки посещаемости не найден", zap.Int64("id", attendance.EmployeeID))
+			return &NotFoundError{Entity: "employee", ID: attendance.EmployeeID}
+		}
+		r.log(ctx).Error("ошибка сохранения отметки посещаемости", zap.Error(err),
+			zap.Int64("id", attendance.EmployeeID), zap.Time("date", attendance.Date))
+		return fmt.Errorf("сохранение отметки посещаемости: %w", err)
+	}
+	return nil
+}
+
+// GetAttendance получает отметки сотрудника id за даты [from, to] по возрастанию даты.
+// Сотрудник без отметок за период — пустой список, отсутствующий или удаленный — NotFoundError
+func (r *employeeRepository) GetAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+	// LEFT JOIN от employees отличает сотрудника без отметок (одна строка с NULL)
+	// от отсутствующего или удаленного (ни одной строки)
+	query := `
+		SELECT a.date, a.status, a.note, a.created_at, a.updated_at
+		FROM employees e
+		LEFT JOIN employee_attendance a ON a.employee_id = e.id AND a.date BETWEEN $2::date AND $3::date
+		WHERE e.id = $1 AND e.deleted_at IS NULL
+		ORDER BY a.date`
+
+	rows, err := r.replica.QueryContext(ctx, query, id, from, to)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения посещаемости сотрудника", zap.Error(err), zap.Int64("id", id))
+		return nil, fmt.Errorf("получение посещаемости сотрудника: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	records := []*domain.Attendance{}
+	for rows.Next() {
+		found = true
+		var date, createdAt, updatedAt sql.NullTime
+		var status, note sql.NullString
+		if err := rows.Scan(&date, &status, &note, &createdAt, &updatedAt); err != nil {
+			r.log(ctx).Error("ошибка сканирования отметки посещаемости", zap.Error(err))
+			return nil, fmt.Errorf("сканирование отметки посещаемости: %w", err)
+		}
+		if !date.Valid {
+			continue
+		}
+		records = append(records, &domain.Attendance{
+			EmployeeID: id,
+			Date:       date.Time,
+			Status:     status.String,
+			Note:       note.String,
+			CreatedAt:  createdAt.Time,
+			UpdatedAt:  updatedAt.Time,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по посещаемости", zap.Error(err))
+		return nil, fmt.Errorf("итерация по посещаемости: %w", err)
+	}
+
+	if !found {
+		r.log(ctx).Warn("сотрудник не найден", zap.Int64("id", id))
+		return nil, &NotFoundError{Entity: "employee", ID: id}
+	}
+	return records, nil
+}
+
+// GetAttendanceSummary считает отметки каждого вида за день date; отметки удаленных
+// сотрудников не учитываются
+func (r *employeeRepository) GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE a.status = '` + domain.AttendancePresent + `'),
+			COUNT(*) FILTER (WHERE a.status = '` + domain.AttendanceAbsent + `'),
+			COUNT(*) FILTER (WHERE a.status = '` + domain.AttendanceSick + `')
+		FROM employee_attendance a
+		JOIN employees e ON e.id = a.employee_id AND e.deleted_at IS NULL
+		WHERE a.date = $1::date`
+
+	summary := &domain.AttendanceSummary{Date: date}
+	err := r.replica.QueryRowContext(ctx, query, date).Scan(&summary.Present, &summary.Absent, &summary.Sick)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения сводки посещаемости", zap.Error(err), zap.Time("date", date))
+		return nil, fmt.Errorf("получение сводки посещаемости: %w", err)
+	}
+	return summary, nil
+}
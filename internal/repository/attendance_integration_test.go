@@ -0,0 +1,99 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+	"employer/internal/repository/seedtest"
+
+	"go.uber.org/zap"
+)
+
+func TestIntegration_Attendance(t *testing.T) {
+	db := openIntegrationDB(t)
+	employees := seedtest.Load(t, db, 3)
+	repos := repository.NewRepositories(db, zap.NewNop())
+	ctx := context.Background()
+
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	marks := []*domain.Attendance{
+		{EmployeeID: employees[0].ID, Date: day, Status: domain.AttendancePresent},
+		{EmployeeID: employees[1].ID, Date: day, Status: domain.AttendanceAbsent},
+		{EmployeeID: employees[2].ID, Date: day, Status: domain.AttendancePresent},
+		{EmployeeID: employees[0].ID, Date: day.AddDate(0, 0, 1), Status: domain.AttendancePresent},
+	}
+	for _, m := range marks {
+		if err := repos.Employee.UpsertAttendance(ctx, m); err != nil {
+			t.Fatalf("UpsertAttendance: %v", err)
+		}
+	}
+
+	// Повторная отметка за тот же день заменяет статус, а не создает вторую строку
+	again := &domain.Attendance{EmployeeID: employees[2].ID, Date: day, Status: domain.AttendanceSick, Note: "температура"}
+	if err := repos.Employee.UpsertAttendance(ctx, again); err != nil {
+		t.Fatalf("UpsertAttendance again: %v", err)
+	}
+	if again.UpdatedAt.Before(again.CreatedAt) {
+		t.Fatalf("updated_at before created_at: %+v", again)
+	}
+
+	summary, err := repos.Employee.GetAttendanceSummary(ctx, day)
+	if err != nil {
+		t.Fatalf("GetAttendanceSummary: %v", err)
+	}
+	if summary.Present != 1 || summary.Absent != 1 || summary.Sick != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	records, err := repos.Employee.GetAttendance(ctx, employees[0].ID, day, day.AddDate(0, 0, 30))
+	if err != nil {
+		t.Fatalf("GetAttendance: %v", err)
+	}
+	if len(records) != 2 || !records[0].Date.Equal(day) || !records[1].Date.After(records[0].Date) {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	// Отметка отсутствующего сотрудника — NotFoundError
+	var nf *repository.NotFoundError
+	err = repos.Employee.UpsertAttendance(ctx, &domain.Attendance{EmployeeID: 1 << 40, Date: day, Status: domain.AttendancePresent})
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+
+	// Удаленный сотрудник пропадает из посещаемости сразу, а его отметки удаляются
+	// вместе с записью при очистке
+	if err := repos.Employee.Delete(ctx, employees[0].ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repos.Employee.GetAttendance(ctx, employees[0].ID, day, day); !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError for deleted employee, got %v", err)
+	}
+	err = repos.Employee.UpsertAttendance(ctx, &domain.Attendance{EmployeeID: employees[0].ID, Date: day, Status: domain.AttendancePresent})
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError when checking in deleted employee, got %v", err)
+	}
+	summary, err = repos.Employee.GetAttendanceSummary(ctx, day)
+	if err != nil {
+		t.Fatalf("GetAttendanceSummary: %v", err)
+	}
+	if summary.Present != 0 || summary.Absent != 1 || summary.Sick != 1 {
+		t.Fatalf("deleted employee still counted: %+v", summary)
+	}
+
+	if _, err := repos.Employee.PurgeSoftDeleted(ctx, time.Now().Add(24*time.Hour)); err != nil {
+		t.Fatalf("PurgeSoftDeleted: %v", err)
+	}
+	var left int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM employee_attendance WHERE employee_id = $1`, employees[0].ID).Scan(&left); err != nil {
+		t.Fatalf("count attendance: %v", err)
+	}
+	if left != 0 {
+		t.Fatalf("expected attendance to cascade on purge, %d rows left", left)
+	}
+}
@@ -4,69 +4,166 @@ import (
 	"context"
 	"database/sql"
 	"employer/internal/domain"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 type employeeRepository struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db DBTX
+	// replica соединение для чтения списков, поиска и статистики; без реплики совпадает с db.
+	// Поиск по телефону идет в db: он проверяет уникальность перед записью
+	replica DBTX
+	logger  *zap.Logger
+	// nameOrder выражение сортировки по имени (см. nameOrderExpr)
+	nameOrder string
+	// stmts подготовленные частые запросы; у репозитория поверх транзакции пусты
+	stmts preparedStatements
+	// purgeLockKey ключ advisory lock очистки; с префиксом таблиц свой для каждого префикса
+	purgeLockKey int64
 }
 
-func NewEmployeeRepository(db *sql.DB, logger *zap.Logger) *employeeRepository {
+// NewEmployeeRepository создает репозиторий поверх *sql.DB или *sql.Tx
+// с сортировкой имен по локали DefaultSortLocale
+func NewEmployeeRepository(db DBTX, logger *zap.Logger) *employeeRepository {
+	return NewEmployeeRepositoryWithCollation(db, logger, sortCollations[DefaultSortLocale])
+}
+
+// NewEmployeeRepositoryWithCollation создает репозиторий, сортирующий имена по collation
+// (значение из SortCollation)
+func NewEmployeeRepositoryWithCollation(db DBTX, logger *zap.Logger, collation string) *employeeRepository {
 	return &employeeRepository{
-		db:     db,
-		logger: logger,
+		db:           db,
+		replica:      db,
+		logger:       logger,
+		nameOrder:    nameOrderExpr(collation),
+		purgeLockKey: purgeLockKey,
 	}
 }
 
-// Create создает нового сотрудника в БД
-func (r *employeeRepository) Create(ctx context.Context, employee *domain.Employee) error {
-	query := `
-		INSERT INTO employees (name, phone, city) 
-		VALUES ($1, $2, $3) 
-		RETURNING id`
+// NewEmployeeRepositoryWithReplica создает репозиторий, читающий из replica и пишущий в db
+func NewEmployeeRepositoryWithReplica(db, replica DBTX, logger *zap.Logger, collation string) *employeeRepository {
+	repo := NewEmployeeRepositoryWithCollation(db, logger, collation)
+	repo.replica = replica
+	return repo
+}
 
-	err := r.db.QueryRowContext(ctx, query, employee.Name, employee.Phone, employee.City).Scan(&employee.ID)
+// Create создает нового сотрудника в БД. Без CreatedBy автором записи считается system
+func (r *employeeRepository) Create(ctx context.Context, employee *domain.Employee) error {
+	err := queryRow(ctx, r.stmts.create, r.db, createQuery, employee.Name, employee.Phone, employee.City, employee.Status,
+		employee.CreatedBy, employee.BirthDate, employee.HireDate).Scan(&employee.ID)
 	if err != nil {
-		r.logger.Error("ошибка создания сотрудника", zap.Error(err))
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("телефон уже занят", zap.String("phone", employee.Phone))
+			return &AlreadyExistsError{Entity: "employee", Field: "phone", Value: employee.Phone}
+		}
+		r.log(ctx).Error("ошибка создания сотрудника", zap.Error(err))
 		return fmt.Errorf("создание сотрудника: %w", err)
 	}
 
-	r.logger.Info("сотрудник создан", zap.Int("id", employee.ID))
+	r.log(ctx).Info("сотрудник создан", zap.Int64("id", employee.ID))
 	return nil
 }
 
 // GetByID получает сотрудника по ID
-func (r *employeeRepository) GetByID(ctx context.Context, id int) (*domain.Employee, error) {
+func (r *employeeRepository) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
 	employee := &domain.Employee{}
-	query := `SELECT id, name, phone, city FROM employees WHERE id = $1`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&employee.ID, &employee.Name, &employee.Phone, &employee.City,
+	var updatedAt, birthDate, hireDate sql.NullTime
+	err := queryRow(ctx, r.stmts.getByID, r.replica, getByIDQuery, id).Scan(
+		&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+		&employee.CreatedBy, &employee.UpdatedBy, &updatedAt, &employee.Version, &birthDate, &hireDate,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			r.logger.Warn("сотрудник не найден", zap.Int("id", id))
+		if errors.Is(err, sql.ErrNoRows) {
+			r.log(ctx).Warn("сотрудник не найден", zap.Int64("id", id))
 			return nil, &NotFoundError{Entity: "employee", ID: id}
 		}
-		r.logger.Error("ошибка получения сотрудника", zap.Error(err), zap.Int("id", id))
+		r.log(ctx).Error("ошибка получения сотрудника", zap.Error(err), zap.Int64("id", id))
 		return nil, fmt.Errorf("получение сотрудника: %w", err)
 	}
+	employee.UpdatedAt = updatedAt.Time
+	employee.BirthDate, employee.HireDate = nullDate(birthDate), nullDate(hireDate)
 
 	return employee, nil
 }
 
-// GetAll получает всех сотрудников
-func (r *employeeRepository) GetAll(ctx context.Context) ([]*domain.Employee, error) {
-	query := `SELECT id, name, phone, city FROM employees ORDER BY id`
+// GetByIDs получает сотрудников с указанными ID в порядке ids; отсутствующие ID
+// в результат не попадают, повторы в ids дают одну запись
+func (r *employeeRepository) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+	query := `SELECT id, name, phone, city, status, created_by, updated_by, COALESCE(updated_at, created_at), version, birth_date, hire_date FROM employees WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	rows, err := r.replica.QueryContext(ctx, query, pq.Int64Array(ids))
+	if err != nil {
+		r.log(ctx).Error("ошибка получения сотрудников по ID", zap.Error(err), zap.Int("requested", len(ids)))
+		return nil, fmt.Errorf("получение сотрудников по ID: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]*domain.Employee, len(ids))
+	for rows.Next() {
+		employee := &domain.Employee{}
+		var updatedAt, birthDate, hireDate sql.NullTime
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy, &updatedAt, &employee.Version, &birthDate, &hireDate)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования сотрудника", zap.Error(err))
+			return nil, fmt.Errorf("сканирование сотрудника: %w", err)
+		}
+		employee.UpdatedAt = updatedAt.Time
+		employee.BirthDate, employee.HireDate = nullDate(birthDate), nullDate(hireDate)
+		byID[employee.ID] = employee
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по результатам: %w", err)
+	}
+
+	employees := make([]*domain.Employee, 0, len(byID))
+	for _, id := range ids {
+		if employee, ok := byID[id]; ok {
+			employees = append(employees, employee)
+			delete(byID, id)
+		}
+	}
+	return employees, nil
+}
+
+// GetAll получает всех сотрудников, подходящих под фильтр, отсортированных по filter.Sort
+// (по умолчанию по имени). При maxRows > 0 сотрудники сначала подсчитываются: если их
+// больше maxRows, строки не читаются и возвращается TooManyRowsError. Сам запрос тоже
+// ограничен, чтобы записи, добавленные после подсчета, не превысили предел
+func (r *employeeRepository) GetAll(ctx context.Context, filter domain.EmployeeFilter, maxRows int) ([]*domain.Employee, error) {
+	if maxRows > 0 {
+		count, err := r.CountWithFilter(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if count > int64(maxRows) {
+			r.log(ctx).Warn("сотрудников больше предела списка", zap.Int64("count", count), zap.Int("max_rows", maxRows))
+			return nil, &TooManyRowsError{Limit: maxRows, Count: count}
+		}
+	}
+
+	conditions, args := filterConditions(filter, nil)
+	query := `SELECT id, name, phone, city, status, created_by, updated_by FROM employees` + whereClause(conditions) +
+		` ORDER BY ` + r.orderClause(filter)
+	if maxRows > 0 {
+		args = append(args, maxRows+1)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.replica.QueryContext(ctx, query, args...)
 	if err != nil {
-		r.logger.Error("ошибка получения списка сотрудников", zap.Error(err))
+		r.log(ctx).Error("ошибка получения списка сотрудников", zap.Error(err))
 		return nil, fmt.Errorf("получение списка сотрудников: %w", err)
 	}
 	defer rows.Close()
@@ -74,152 +171,436 @@ func (r *employeeRepository) GetAll(ctx context.Context) ([]*domain.Employee, er
 	var employees []*domain.Employee
 	for rows.Next() {
 		employee := &domain.Employee{}
-		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City)
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования сотрудника", zap.Error(err))
+			return nil, fmt.Errorf("сканирование сотрудника: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по результатам: %w", err)
+	}
+	if maxRows > 0 && len(employees) > maxRows {
+		r.log(ctx).Warn("сотрудников больше предела списка", zap.Int("max_rows", maxRows))
+		return nil, &TooManyRowsError{Limit: maxRows, Count: int64(len(employees))}
+	}
+
+	r.log(ctx).Info("получен список сотрудников", zap.Int("count", len(employees)))
+	return employees, nil
+}
+
+// GetAllCursor передает в fn по одному сотрудников, подходящих под фильтр, в порядке
+// filter.Sort, как GetAll, но без загрузки выборки в память и без предела количества.
+// Ошибка fn прерывает чтение и возвращается как есть
+func (r *employeeRepository) GetAllCursor(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	conditions, args := filterConditions(filter, nil)
+	query := `SELECT id, name, phone, city, status, created_by, updated_by FROM employees` + whereClause(conditions) +
+		` ORDER BY ` + r.orderClause(filter)
+
+	rows, err := r.replica.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения списка сотрудников", zap.Error(err))
+		return fmt.Errorf("получение списка сотрудников: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		employee := &domain.Employee{}
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования сотрудника", zap.Error(err))
+			return fmt.Errorf("сканирование сотрудника: %w", err)
+		}
+		if err := fn(employee); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
+		return fmt.Errorf("итерация по результатам: %w", err)
+	}
+
+	r.log(ctx).Info("передан список сотрудников", zap.Int("count", count))
+	return nil
+}
+
+// GetPage получает страницу сотрудников, подходящих под фильтр, отсортированных по
+// filter.Sort (по умолчанию по имени)
+func (r *employeeRepository) GetPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, error) {
+	conditions, args := filterConditions(filter, nil)
+	args = append(args, limit, offset)
+	query := `SELECT id, name, phone, city, status, created_by, updated_by FROM employees` + whereClause(conditions) +
+		` ORDER BY ` + r.orderClause(filter) + fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.replica.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения страницы сотрудников", zap.Error(err))
+		return nil, fmt.Errorf("получение страницы сотрудников: %w", err)
+	}
+	defer rows.Close()
+
+	var employees []*domain.Employee
+	for rows.Next() {
+		employee := &domain.Employee{}
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования сотрудника", zap.Error(err))
+			return nil, fmt.Errorf("сканирование сотрудника: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по результатам: %w", err)
+	}
+
+	return employees, nil
+}
+
+// GetRecent получает последних добавленных сотрудников, новые первыми
+func (r *employeeRepository) GetRecent(ctx context.Context, limit int) ([]*domain.Employee, error) {
+	query := `SELECT id, name, phone, city, status, created_by, updated_by, created_at, updated_at FROM employees WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $1`
+
+	rows, err := r.replica.QueryContext(ctx, query, limit)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения последних сотрудников", zap.Error(err))
+		return nil, fmt.Errorf("получение последних сотрудников: %w", err)
+	}
+	defer rows.Close()
+
+	var employees []*domain.Employee
+	for rows.Next() {
+		employee := &domain.Employee{}
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy, &employee.CreatedAt, &employee.UpdatedAt)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования сотрудника", zap.Error(err))
+			return nil, fmt.Errorf("сканирование сотрудника: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по результатам: %w", err)
+	}
+
+	r.log(ctx).Info("получены последние сотрудники", zap.Int("count", len(employees)))
+	return employees, nil
+}
+
+// GetByCreatedRange получает сотрудников, созданных с from по to включительно,
+// по возрастанию даты создания
+func (r *employeeRepository) GetByCreatedRange(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+	conditions, args := filterConditions(domain.EmployeeFilter{CreatedFrom: from, CreatedTo: to}, nil)
+	query := `SELECT id, name, phone, city, status, created_by, updated_by, created_at, COALESCE(updated_at, created_at), version, birth_date, hire_date
+		FROM employees` + whereClause(conditions) + ` ORDER BY created_at, id`
+
+	rows, err := r.replica.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения сотрудников по дате создания", zap.Error(err),
+			zap.Time("from", from), zap.Time("to", to))
+		return nil, fmt.Errorf("получение сотрудников по дате создания: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []*domain.Employee{}
+	for rows.Next() {
+		employee := &domain.Employee{}
+		var birthDate, hireDate sql.NullTime
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy, &employee.CreatedAt, &employee.UpdatedAt, &employee.Version, &birthDate, &hireDate)
 		if err != nil {
-			r.logger.Error("ошибка сканирования сотрудника", zap.Error(err))
+			r.log(ctx).Error("ошибка сканирования сотрудника", zap.Error(err))
 			return nil, fmt.Errorf("сканирование сотрудника: %w", err)
 		}
+		employee.BirthDate, employee.HireDate = nullDate(birthDate), nullDate(hireDate)
 		employees = append(employees, employee)
 	}
 
 	if err = rows.Err(); err != nil {
-		r.logger.Error("ошибка итерации по результатам", zap.Error(err))
+		r.log(ctx).Error("ошибка итерации по результатам", zap.Error(err))
 		return nil, fmt.Errorf("итерация по результатам: %w", err)
 	}
 
-	r.logger.Info("получен список сотрудников", zap.Int("count", len(employees)))
+	r.log(ctx).Info("получены сотрудники по дате создания", zap.Int("count", len(employees)))
 	return employees, nil
 }
 
-// SearchEmployees ищет сотрудников по имени, телефону или городу
-func (r *employeeRepository) SearchEmployees(ctx context.Context, searchQuery string) ([]*domain.Employee, error) {
+// MaxSearchTerms наибольшее количество слов в поисковом запросе
+const MaxSearchTerms = 5
+
+// SearchEmployees ищет сотрудников среди подходящих под фильтр и возвращает страницу
+// результатов вместе с общим количеством совпадений. Запрос делится на слова по пробелам:
+// каждое слово должно найтись в имени, телефоне или городе ("john almaty" — Джон из Алматы)
+func (r *employeeRepository) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
 	// Валидация входных данных
 	searchQuery = strings.TrimSpace(searchQuery)
 	if searchQuery == "" {
-		r.logger.Warn("пустой поисковый запрос")
-		return []*domain.Employee{}, nil
+		r.log(ctx).Warn("пустой поисковый запрос")
+		return []*domain.Employee{}, 0, nil
 	}
+	terms := strings.Fields(searchQuery)
+	filter.Query = searchQuery
 
-	// SQL запрос с поиском по всем полям
+	// SQL запрос с поиском по всем полям. ILIKE по самим колонкам использует
+	// триграммные GIN индексы (pg_trgm), в отличие от LOWER(...) LIKE.
+	// count(*) OVER() считает все совпадения до применения LIMIT/OFFSET.
+	// Явная сортировка (?sort=, ?order=) заменяет сортировку по релевантности
+	conditions, args := filterConditions(filter, nil)
+	order := r.orderClause(filter)
+	if filter.Sort == "" && !filter.Desc {
+		var rank string
+		rank, args = searchRank(terms, args)
+		order = rank + `,
+			` + r.nameOrder + `,
+			id`
+	}
+	args = append(args, limit, offset)
 	query := `
-		SELECT id, name, phone, city 
-		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
+		FROM employees` + whereClause(conditions) + `
 		ORDER BY 
-			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
-				ELSE 4
-			END,
-			name ASC
-		LIMIT 100`
-
-	searchPattern := "%" + searchQuery + "%"
-	exactSearchPattern := searchQuery + "%"
+			` + order + `
+		` + fmt.Sprintf(`LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
 
-	rows, err := r.db.QueryContext(ctx, query, searchPattern, exactSearchPattern)
+	rows, err := r.replica.QueryContext(ctx, query, args...)
 	if err != nil {
-		r.logger.Error("ошибка выполнения поискового запроса",
+		r.log(ctx).Error("ошибка выполнения поискового запроса",
 			zap.Error(err),
 			zap.String("search_query", searchQuery))
-		return nil, fmt.Errorf("поиск сотрудников: %w", err)
+		return nil, 0, fmt.Errorf("поиск сотрудников: %w", err)
 	}
 	defer rows.Close()
 
 	var employees []*domain.Employee
+	var total int64
 	for rows.Next() {
 		employee := &domain.Employee{}
-		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City)
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy, &total)
 		if err != nil {
-			r.logger.Error("ошибка сканирования результата поиска", zap.Error(err))
-			return nil, fmt.Errorf("сканирование результата поиска: %w", err)
+			r.log(ctx).Error("ошибка сканирования результата поиска", zap.Error(err))
+			return nil, 0, fmt.Errorf("сканирование результата поиска: %w", err)
 		}
 		employees = append(employees, employee)
 	}
 
 	if err = rows.Err(); err != nil {
-		r.logger.Error("ошибка итерации по результатам поиска", zap.Error(err))
-		return nil, fmt.Errorf("итерация по результатам поиска: %w", err)
+		r.log(ctx).Error("ошибка итерации по результатам поиска", zap.Error(err))
+		return nil, 0, fmt.Errorf("итерация по результатам поиска: %w", err)
 	}
 
-	r.logger.Info("поиск сотрудников выполнен",
+	// За пределами последней страницы строк нет и оконная функция ничего не вернула
+	if len(employees) == 0 && offset > 0 {
+		if total, err = r.CountWithFilter(ctx, filter); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	r.log(ctx).Info("поиск сотрудников выполнен",
 		zap.String("search_query", searchQuery),
-		zap.Int("results_count", len(employees)))
+		zap.Int("results_count", len(employees)),
+		zap.Int64("total", total))
 
-	return employees, nil
+	return employees, total, nil
 }
 
-// Update обновляет сотрудника
+// Update обновляет сотрудника. Автор изменения берется из UpdatedBy (без него — system);
+// пустые статус и даты не меняются. В employee возвращаются итоговые статус и даты,
+// неизменный created_by и новая версия.
+// Запись обновляется только в версии employee.Version, иначе — *VersionConflictError
+// с текущей версией: обновления без проверки версии нет, чтобы не терять чужие изменения
 func (r *employeeRepository) Update(ctx context.Context, employee *domain.Employee) error {
 	query := `
 		UPDATE employees 
-		SET name = $2, phone = $3, city = $4 
-		WHERE id = $1`
+		SET name = $2, phone = $3, city = $4, status = COALESCE(NULLIF($5, ''), status), 
+			updated_by = COALESCE(NULLIF($6, ''), 'system'), updated_at = CURRENT_TIMESTAMP, version = version + 1,
+			birth_date = COALESCE($8, birth_date), hire_date = COALESCE($9, hire_date) 
+		WHERE id = $1 AND deleted_at IS NULL AND version = $7
+		RETURNING status, created_by, updated_by, version, birth_date, hire_date`
 
-	result, err := r.db.ExecContext(ctx, query, employee.ID, employee.Name, employee.Phone, employee.City)
+	expected := employee.Version
+	var birthDate, hireDate sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, employee.ID, employee.Name, employee.Phone, employee.City, employee.Status,
+		employee.UpdatedBy, expected, employee.BirthDate, employee.HireDate).Scan(
+		&employee.Status, &employee.CreatedBy, &employee.UpdatedBy, &employee.Version, &birthDate, &hireDate)
 	if err != nil {
-		r.logger.Error("ошибка обновления сотрудника", zap.Error(err), zap.Int("id", employee.ID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.versionConflict(ctx, employee.ID, expected)
+		}
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("телефон уже занят", zap.String("phone", employee.Phone), zap.Int64("id", employee.ID))
+			return &AlreadyExistsError{Entity: "employee", Field: "phone", Value: employee.Phone}
+		}
+		r.log(ctx).Error("ошибка обновления сотрудника", zap.Error(err), zap.Int64("id", employee.ID))
 		return fmt.Errorf("обновление сотрудника: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	employee.BirthDate, employee.HireDate = nullDate(birthDate), nullDate(hireDate)
+
+	r.log(ctx).Info("сотрудник обновлен", zap.Int64("id", employee.ID))
+	return nil
+}
+
+// UpdateBatch обновляет имя, телефон и город сотрудников одним запросом и возвращает ID
+// фактически обновленных; отсутствующие ID пропускаются. Статус и даты не меняются,
+// автор изменения — UpdatedBy каждого сотрудника (без него — system), версия растет на 1
+func (r *employeeRepository) UpdateBatch(ctx context.Context, employees []*domain.Employee) ([]int64, error) {
+	query := `
+		UPDATE employees AS e
+		SET name = v.name, phone = v.phone, city = v.city,
+			updated_by = COALESCE(NULLIF(v.updated_by, ''), 'system'), updated_at = CURRENT_TIMESTAMP, version = e.version + 1
+		FROM unnest($1::bigint[], $2::text[], $3::text[], $4::text[], $5::text[]) AS v(id, name, phone, city, updated_by)
+		WHERE e.id = v.id AND e.deleted_at IS NULL
+		RETURNING e.id`
+
+	ids := make(pq.Int64Array, len(employees))
+	names := make(pq.StringArray, len(employees))
+	phones := make(pq.StringArray, len(employees))
+	cities := make(pq.StringArray, len(employees))
+	actors := make(pq.StringArray, len(employees))
+	for i, e := range employees {
+		ids[i], names[i], phones[i], cities[i], actors[i] = e.ID, e.Name, e.Phone, e.City, e.UpdatedBy
+	}
+
+	updated, err := queryIDColumn(ctx, r.db, query, ids, names, phones, cities, actors)
 	if err != nil {
-		r.logger.Error("ошибка получения количества обновленных строк", zap.Error(err))
-		return fmt.Errorf("получение количества обновленных строк: %w", err)
+		// телефон из ошибки PostgreSQL не разбирается: занятый номер может быть у любого из пакета
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("телефон уже занят при пакетном обновлении", zap.Error(err))
+			return nil, &AlreadyExistsError{Entity: "employee", Field: "phone"}
+		}
+		r.log(ctx).Error("ошибка пакетного обновления сотрудников", zap.Error(err), zap.Int("requested", len(employees)))
+		return nil, fmt.Errorf("пакетное обновление сотрудников: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		r.logger.Warn("сотрудник для обновления не найден", zap.Int("id", employee.ID))
-		return &NotFoundError{Entity: "employee", ID: employee.ID}
+	r.log(ctx).Info("сотрудники обновлены", zap.Int("requested", len(employees)), zap.Int("updated", len(updated)))
+	return updated, nil
+}
+
+// versionConflict выясняет, почему обновление с ожидаемой версией expected не нашло
+// строку: сотрудника нет (*NotFoundError) или его версия уже другая (*VersionConflictError)
+func (r *employeeRepository) versionConflict(ctx context.Context, id int64, expected int) error {
+	var current int
+	err := r.db.QueryRowContext(ctx, `SELECT version FROM employees WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Warn("сотрудник для обновления не найден", zap.Int64("id", id))
+		return &NotFoundError{Entity: "employee", ID: id}
+	}
+	if err != nil {
+		r.log(ctx).Error("ошибка получения версии сотрудника", zap.Error(err), zap.Int64("id", id))
+		return fmt.Errorf("получение версии сотрудника: %w", err)
 	}
 
-	r.logger.Info("сотрудник обновлен", zap.Int("id", employee.ID))
-	return nil
+	r.log(ctx).Warn("сотрудник изменен после чтения",
+		zap.Int64("id", id), zap.Int("expected_version", expected), zap.Int("current_version", current))
+	return &VersionConflictError{ID: id, Expected: expected, Current: current}
 }
 
-// Delete удаляет сотрудника
-func (r *employeeRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM employees WHERE id = $1`
+// Delete мягко удаляет сотрудника: запись получает deleted_at и пропадает из всех выборок,
+// а окончательно удаляется очисткой (PurgeSoftDeleted) после срока хранения.
+// Уже удаленный сотрудник — NotFoundError
+func (r *employeeRepository) Delete(ctx context.Context, id int64) error {
+	query := `UPDATE employees SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		r.logger.Error("ошибка удаления сотрудника", zap.Error(err), zap.Int("id", id))
+		r.log(ctx).Error("ошибка удаления сотрудника", zap.Error(err), zap.Int64("id", id))
 		return fmt.Errorf("удаление сотрудника: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		r.logger.Error("ошибка получения количества удаленных строк", zap.Error(err))
+		r.log(ctx).Error("ошибка получения количества удаленных строк", zap.Error(err))
 		return fmt.Errorf("получение количества удаленных строк: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Warn("сотрудник для удаления не найден", zap.Int("id", id))
+		r.log(ctx).Warn("сотрудник для удаления не найден", zap.Int64("id", id))
 		return &NotFoundError{Entity: "employee", ID: id}
 	}
 
-	r.logger.Info("сотрудник удален", zap.Int("id", id))
+	r.log(ctx).Info("сотрудник удален", zap.Int64("id", id))
 	return nil
 }
 
+// DeleteMany мягко удаляет сотрудников с указанными ID одним запросом (см. Delete) и
+// возвращает ID фактически удаленных; отсутствующие и уже удаленные ID пропускаются
+func (r *employeeRepository) DeleteMany(ctx context.Context, ids []int64) ([]int64, error) {
+	query := `UPDATE employees SET deleted_at = CURRENT_TIMESTAMP WHERE id = ANY($1) AND deleted_at IS NULL RETURNING id`
+
+	deleted, err := r.queryIDs(ctx, r.db, query, ids)
+	if err != nil {
+		r.log(ctx).Error("ошибка пакетного удаления сотрудников", zap.Error(err), zap.Int("requested", len(ids)))
+		return nil, fmt.Errorf("пакетное удаление сотрудников: %w", err)
+	}
+
+	r.log(ctx).Info("сотрудники удалены", zap.Int("requested", len(ids)), zap.Int("deleted", len(deleted)))
+	return deleted, nil
+}
+
+// ExistingIDs возвращает те из ids, для которых есть сотрудники
+func (r *employeeRepository) ExistingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	query := `SELECT id FROM employees WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	existing, err := r.queryIDs(ctx, r.db, query, ids)
+	if err != nil {
+		r.log(ctx).Error("ошибка проверки существования сотрудников", zap.Error(err), zap.Int("requested", len(ids)))
+		return nil, fmt.Errorf("проверка существования сотрудников: %w", err)
+	}
+	return existing, nil
+}
+
+// queryIDs выполняет запрос с массивом ids в $1 и читает столбец id из результата
+func (r *employeeRepository) queryIDs(ctx context.Context, db DBTX, query string, ids []int64) ([]int64, error) {
+	return queryIDColumn(ctx, db, query, pq.Int64Array(ids))
+}
+
+// queryIDColumn выполняет запрос и читает столбец id из результата
+func queryIDColumn(ctx context.Context, db DBTX, query string, args ...interface{}) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+	return result, rows.Err()
+}
+
 // GetByPhone получает сотрудника по телефону
 func (r *employeeRepository) GetByPhone(ctx context.Context, phone string) (*domain.Employee, error) {
 	employee := &domain.Employee{}
-	query := `SELECT id, name, phone, city FROM employees WHERE phone = $1`
 
-	err := r.db.QueryRowContext(ctx, query, phone).Scan(
-		&employee.ID, &employee.Name, &employee.Phone, &employee.City,
+	err := queryRow(ctx, r.stmts.getByPhone, r.db, getByPhoneQuery, phone).Scan(
+		&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+		&employee.CreatedBy, &employee.UpdatedBy,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			r.logger.Warn("сотрудник не найден по телефону", zap.String("phone", phone))
+		if errors.Is(err, sql.ErrNoRows) {
+			r.log(ctx).Warn("сотрудник не найден по телефону", zap.String("phone", phone))
 			return nil, &NotFoundError{Entity: "employee by phone", Data: phone}
 		}
-		r.logger.Error("ошибка получения сотрудника по телефону", zap.Error(err), zap.String("phone", phone))
+		r.log(ctx).Error("ошибка получения сотрудника по телефону", zap.Error(err), zap.String("phone", phone))
 		return nil, fmt.Errorf("получение сотрудника по телефону: %w", err)
 	}
 
@@ -232,22 +613,35 @@ func (r *employeeRepository) GetEmployeeStats(ctx context.Context) (*EmployeeSta
 		SELECT 
 			COUNT(*) as total_count,
 			COUNT(DISTINCT city) as cities_count,
-			(SELECT city FROM employees GROUP BY city ORDER BY COUNT(*) DESC LIMIT 1) as most_common_city
-		FROM employees`
+			COALESCE((SELECT city FROM employees WHERE deleted_at IS NULL GROUP BY city ORDER BY COUNT(*) DESC LIMIT 1), '') as most_common_city,
+			COUNT(*) FILTER (WHERE status = 'active') as active_count,
+			COUNT(*) FILTER (WHERE status = 'inactive') as inactive_count,
+			COUNT(*) FILTER (WHERE status = 'on_leave') as on_leave_count
+		FROM employees
+		WHERE deleted_at IS NULL`
 
 	stats := &EmployeeStats{}
-	err := r.db.QueryRowContext(ctx, query).Scan(
+	var active, inactive, onLeave int
+	err := r.replica.QueryRowContext(ctx, query).Scan(
 		&stats.TotalCount,
 		&stats.CitiesCount,
 		&stats.MostCommonCity,
+		&active,
+		&inactive,
+		&onLeave,
 	)
 
 	if err != nil {
-		r.logger.Error("ошибка получения статистики сотрудников", zap.Error(err))
+		r.log(ctx).Error("ошибка получения статистики сотрудников", zap.Error(err))
 		return nil, fmt.Errorf("получение статистики сотрудников: %w", err)
 	}
+	stats.ByStatus = map[string]int{
+		domain.StatusActive:   active,
+		domain.StatusInactive: inactive,
+		domain.StatusOnLeave:  onLeave,
+	}
 
-	r.logger.Info("статистика сотрудников получена",
+	r.log(ctx).Info("статистика сотрудников получена",
 		zap.Int("total", stats.TotalCount),
 		zap.Int("cities", stats.CitiesCount))
 
@@ -256,11 +650,11 @@ func (r *employeeRepository) GetEmployeeStats(ctx context.Context) (*EmployeeSta
 
 // GetEmployeesByCity получает сотрудников по городу
 func (r *employeeRepository) GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error) {
-	query := `SELECT id, name, phone, city FROM employees WHERE LOWER(city) = LOWER($1) ORDER BY name`
+	query := `SELECT id, name, phone, city, status, created_by, updated_by FROM employees WHERE LOWER(city) = LOWER($1) AND deleted_at IS NULL ORDER BY ` + r.nameOrder + `, id`
 
-	rows, err := r.db.QueryContext(ctx, query, city)
+	rows, err := r.replica.QueryContext(ctx, query, city)
 	if err != nil {
-		r.logger.Error("ошибка получения сотрудников по городу",
+		r.log(ctx).Error("ошибка получения сотрудников по городу",
 			zap.Error(err),
 			zap.String("city", city))
 		return nil, fmt.Errorf("получение сотрудников по городу: %w", err)
@@ -270,43 +664,80 @@ func (r *employeeRepository) GetEmployeesByCity(ctx context.Context, city string
 	var employees []*domain.Employee
 	for rows.Next() {
 		employee := &domain.Employee{}
-		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City)
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy)
 		if err != nil {
-			r.logger.Error("ошибка сканирования сотрудника по городу", zap.Error(err))
+			r.log(ctx).Error("ошибка сканирования сотрудника по городу", zap.Error(err))
 			return nil, fmt.Errorf("сканирование сотрудника: %w", err)
 		}
 		employees = append(employees, employee)
 	}
 
 	if err = rows.Err(); err != nil {
-		r.logger.Error("ошибка итерации по сотрудникам города", zap.Error(err))
+		r.log(ctx).Error("ошибка итерации по сотрудникам города", zap.Error(err))
 		return nil, fmt.Errorf("итерация по сотрудникам: %w", err)
 	}
 
-	r.logger.Info("получены сотрудники по городу",
+	r.log(ctx).Info("получены сотрудники по городу",
 		zap.String("city", city),
 		zap.Int("count", len(employees)))
 
 	return employees, nil
 }
 
-// CheckPhoneExists проверяет существование телефона
-func (r *employeeRepository) CheckPhoneExists(ctx context.Context, phone string, excludeID ...int) (bool, error) {
+// GetCityCounts возвращает города с количеством сотрудников по убыванию количества.
+// Города сравниваются без учета регистра; название — самое частое написание среди
+// сотрудников города. Пустая таблица дает пустой срез
+func (r *employeeRepository) GetCityCounts(ctx context.Context) ([]*domain.CityCount, error) {
+	query := `
+		SELECT MODE() WITHIN GROUP (ORDER BY city) AS city, COUNT(*) AS employee_count
+		FROM employees
+		WHERE deleted_at IS NULL
+		GROUP BY LOWER(city)
+		ORDER BY employee_count DESC, city`
+
+	rows, err := r.replica.QueryContext(ctx, query)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения количества сотрудников по городам", zap.Error(err))
+		return nil, fmt.Errorf("получение количества сотрудников по городам: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []*domain.CityCount{}
+	for rows.Next() {
+		count := &domain.CityCount{}
+		if err := rows.Scan(&count.City, &count.EmployeeCount); err != nil {
+			r.log(ctx).Error("ошибка сканирования количества сотрудников города", zap.Error(err))
+			return nil, fmt.Errorf("сканирование количества сотрудников города: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по городам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по городам: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CheckPhoneExists проверяет, занят ли телефон; телефон удаленного сотрудника свободен
+func (r *employeeRepository) CheckPhoneExists(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
 	var query string
 	var args []interface{}
 
 	if len(excludeID) > 0 {
-		query = `SELECT EXISTS(SELECT 1 FROM employees WHERE phone = $1 AND id != $2)`
+		query = `SELECT EXISTS(SELECT 1 FROM employees WHERE phone = $1 AND id != $2 AND deleted_at IS NULL)`
 		args = []interface{}{phone, excludeID[0]}
 	} else {
-		query = `SELECT EXISTS(SELECT 1 FROM employees WHERE phone = $1)`
+		query = `SELECT EXISTS(SELECT 1 FROM employees WHERE phone = $1 AND deleted_at IS NULL)`
 		args = []interface{}{phone}
 	}
 
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, args...).Scan(&exists)
 	if err != nil {
-		r.logger.Error("ошибка проверки существования телефона",
+		r.log(ctx).Error("ошибка проверки существования телефона",
 			zap.Error(err),
 			zap.String("phone", phone))
 		return false, fmt.Errorf("проверка существования телефона: %w", err)
@@ -315,17 +746,276 @@ func (r *employeeRepository) CheckPhoneExists(ctx context.Context, phone string,
 	return exists, nil
 }
 
+// CountWithFilter возвращает количество сотрудников, подходящих под фильтр. Условия
+// строит filterConditions, как и для списков и поиска, поэтому итог совпадает с выборкой
+func (r *employeeRepository) CountWithFilter(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	conditions, args := filterConditions(filter, nil)
+	query := `SELECT COUNT(*) FROM employees` + whereClause(conditions)
+
+	var count int64
+	if err := r.replica.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		r.log(ctx).Error("ошибка подсчета сотрудников", zap.Error(err))
+		return 0, fmt.Errorf("подсчет сотрудников: %w", err)
+	}
+
+	return count, nil
+}
+
+// StreamEmployees построчно передает сотрудников, подходящих под фильтр, в fn
+// без загрузки всей выборки в память. Сотрудник читается со всеми колонками, чтобы
+// выгрузку JSON Lines можно было восстановить через CreateWithID без потерь
+func (r *employeeRepository) StreamEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	conditions, args := filterConditions(filter, nil)
+	query := `SELECT id, name, phone, city, status, created_by, updated_by, created_at, updated_at,
+		version, birth_date, hire_date FROM employees` + whereClause(conditions) + ` ORDER BY id`
+
+	rows, err := r.replica.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("ошибка выгрузки сотрудников", zap.Error(err))
+		return fmt.Errorf("выгрузка сотрудников: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		employee := &domain.Employee{}
+		var birthDate, hireDate sql.NullTime
+		err := rows.Scan(&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+			&employee.CreatedBy, &employee.UpdatedBy, &employee.CreatedAt, &employee.UpdatedAt,
+			&employee.Version, &birthDate, &hireDate)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования сотрудника при выгрузке", zap.Error(err))
+			return fmt.Errorf("сканирование сотрудника: %w", err)
+		}
+		employee.BirthDate, employee.HireDate = nullDate(birthDate), nullDate(hireDate)
+		if err := fn(employee); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации при выгрузке сотрудников", zap.Error(err))
+		return fmt.Errorf("итерация по результатам выгрузки: %w", err)
+	}
+
+	r.log(ctx).Info("выгрузка сотрудников завершена",
+		zap.String("city", filter.City),
+		zap.String("status", filter.Status),
+		zap.Int("count", count))
+
+	return nil
+}
+
+// CreateWithID создает сотрудника с явно заданным ID, временными метками, авторами,
+// статусом, датами и версией — так, как они записаны в выгрузке. Существующая запись
+// с тем же ID перезаписывается целиком; ее версия не уменьшается, чтобы клиент с прежним
+// If-Match увидел изменение. Без авторов — system, без статуса — active
+func (r *employeeRepository) CreateWithID(ctx context.Context, employee *domain.Employee) error {
+	query := `
+		INSERT INTO employees (id, name, phone, city, status, created_at, updated_at, created_by, updated_by,
+			birth_date, hire_date, version)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'active'),
+			COALESCE($6::timestamp, CURRENT_TIMESTAMP), COALESCE($7::timestamp, CURRENT_TIMESTAMP),
+			COALESCE(NULLIF($8, ''), 'system'), COALESCE(NULLIF($9, ''), NULLIF($8, ''), 'system'),
+			$10, $11, GREATEST($12, 1))
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name, phone = EXCLUDED.phone, city = EXCLUDED.city, status = EXCLUDED.status,
+			created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at,
+			created_by = EXCLUDED.created_by, updated_by = EXCLUDED.updated_by,
+			birth_date = EXCLUDED.birth_date, hire_date = EXCLUDED.hire_date, deleted_at = NULL,
+			version = GREATEST(EXCLUDED.version, employees.version + 1)`
+
+	_, err := r.db.ExecContext(ctx, query, employee.ID, employee.Name, employee.Phone, employee.City, employee.Status,
+		nullTime(employee.CreatedAt), nullTime(employee.UpdatedAt), employee.CreatedBy, employee.UpdatedBy,
+		employee.BirthDate, employee.HireDate, employee.Version)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("телефон уже занят", zap.String("phone", employee.Phone), zap.Int64("id", employee.ID))
+			return &AlreadyExistsError{Entity: "employee", Field: "phone", Value: employee.Phone}
+		}
+		r.log(ctx).Error("ошибка создания сотрудника с ID", zap.Error(err), zap.Int64("id", employee.ID))
+		return fmt.Errorf("создание сотрудника с ID: %w", err)
+	}
+
+	r.log(ctx).Info("сотрудник создан с заданным ID", zap.Int64("id", employee.ID))
+	return nil
+}
+
+// SyncIDSequence выставляет последовательность id после максимального ID в таблице,
+// чтобы новые записи не конфликтовали с импортированными
+func (r *employeeRepository) SyncIDSequence(ctx context.Context) error {
+	query := `SELECT setval(pg_get_serial_sequence('employees', 'id'), COALESCE((SELECT MAX(id) FROM employees), 0) + 1, false)`
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		r.log(ctx).Error("ошибка синхронизации последовательности id", zap.Error(err))
+		return fmt.Errorf("синхронизация последовательности id: %w", err)
+	}
+
+	r.log(ctx).Info("последовательность id синхронизирована")
+	return nil
+}
+
+// UpdateStatus меняет статус сотрудника от имени updatedBy (без него — system)
+func (r *employeeRepository) UpdateStatus(ctx context.Context, id int64, status, updatedBy string) error {
+	query := `UPDATE employees SET status = $2, updated_by = COALESCE(NULLIF($3, ''), 'system'), updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, updatedBy)
+	if err != nil {
+		r.log(ctx).Error("ошибка изменения статуса сотрудника", zap.Error(err), zap.Int64("id", id))
+		return fmt.Errorf("изменение статуса сотрудника: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("ошибка получения количества обновленных строк", zap.Error(err))
+		return fmt.Errorf("получение количества обновленных строк: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("сотрудник для изменения статуса не найден", zap.Int64("id", id))
+		return &NotFoundError{Entity: "employee", ID: id}
+	}
+
+	r.log(ctx).Info("статус сотрудника изменен", zap.Int64("id", id), zap.String("status", status))
+	return nil
+}
+
+// filterConditions возвращает условия WHERE для фильтра и args с добавленными
+// значениями; параметры нумеруются после уже имеющихся в args. Мягко удаленные
+// сотрудники не подходят ни под один фильтр
+func filterConditions(filter domain.EmployeeFilter, args []interface{}) ([]string, []interface{}) {
+	var conditions []string
+	if terms := strings.Fields(filter.Query); len(terms) > 0 {
+		var match string
+		match, args = searchMatch(terms, args)
+		conditions = append(conditions, match)
+	}
+	if filter.City != "" {
+		args = append(args, filter.City)
+		conditions = append(conditions, fmt.Sprintf("LOWER(city) = LOWER($%d)", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo)
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", len(args)))
+	}
+	conditions = append(conditions, "deleted_at IS NULL")
+	return conditions, args
+}
+
+// searchMatch строит условие поиска: каждое слово запроса должно найтись в имени,
+// телефоне или городе. Параметры "%слово%" дописываются к args по порядку слов.
+// Запрос, похожий на телефон, дополнительно ищется по цифрам телефона (phone_digits),
+// чтобы "7011234567" находил "+7 701 123 45 67"; его параметр идет следующим
+func searchMatch(terms []string, args []interface{}) (string, []interface{}) {
+	matches := make([]string, len(terms))
+	for i, term := range terms {
+		args = append(args, "%"+term+"%")
+		matches[i] = fmt.Sprintf(`(name ILIKE $%[1]d 
+		   OR phone ILIKE $%[1]d 
+		   OR city ILIKE $%[1]d)`, len(args))
+	}
+	match := strings.Join(matches, " AND ")
+
+	if digits, ok := phoneDigits(terms); ok {
+		args = append(args, "%"+digits+"%")
+		match = fmt.Sprintf("(%s OR phone_digits LIKE $%d)", match, len(args))
+	}
+	return match, args
+}
+
+// phoneDigits возвращает цифры запроса, если он состоит только из цифр и символов
+// оформления телефона (+, -, скобки, точки); false для остальных запросов
+func phoneDigits(terms []string) (string, bool) {
+	var digits strings.Builder
+	for _, term := range terms {
+		for _, r := range term {
+			switch {
+			case r >= '0' && r <= '9':
+				digits.WriteRune(r)
+			case strings.ContainsRune("+-().", r):
+			default:
+				return "", false
+			}
+		}
+	}
+	return digits.String(), digits.Len() > 0
+}
+
+// searchRank строит выражение ранжирования: слова, с которых начинается имя, затем телефон
+// и город, поднимают запись выше. Параметры "слово%" дописываются к args
+func searchRank(terms []string, args []interface{}) (string, []interface{}) {
+	ranks := make([]string, len(terms))
+	for i, term := range terms {
+		args = append(args, term+"%")
+		ranks[i] = fmt.Sprintf(`CASE 
+				WHEN name ILIKE $%[1]d THEN 1
+				WHEN phone ILIKE $%[1]d THEN 2
+				WHEN city ILIKE $%[1]d THEN 3
+				ELSE 4
+			END`, len(args))
+	}
+	return strings.Join(ranks, " +\n\t\t\t"), args
+}
+
+// whereClause собирает условия в " WHERE a AND b"; без условий возвращает пустую строку
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
 // EmployeeStats статистика сотрудников
 type EmployeeStats struct {
 	TotalCount     int    `json:"total_count"`
 	CitiesCount    int    `json:"cities_count"`
 	MostCommonCity string `json:"most_common_city"`
+	// ByStatus количество сотрудников по статусам; есть все статусы, в том числе с нулем
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// statusCountXML количество сотрудников со статусом Status в XML
+type statusCountXML struct {
+	Status string `xml:"name,attr"`
+	Count  int    `xml:",chardata"`
+}
+
+// MarshalXML кодирует статистику как <stats>; encoding/xml не умеет кодировать map,
+// поэтому ByStatus отдается списком <status name="active">N</status> по имени статуса
+func (s EmployeeStats) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	statuses := make([]string, 0, len(s.ByStatus))
+	for status := range s.ByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	byStatus := make([]statusCountXML, len(statuses))
+	for i, status := range statuses {
+		byStatus[i] = statusCountXML{Status: status, Count: s.ByStatus[status]}
+	}
+
+	start.Name = xml.Name{Local: "stats"}
+	return e.EncodeElement(struct {
+		TotalCount     int              `xml:"total_count"`
+		CitiesCount    int              `xml:"cities_count"`
+		MostCommonCity string           `xml:"most_common_city"`
+		ByStatus       []statusCountXML `xml:"by_status>status"`
+	}{s.TotalCount, s.CitiesCount, s.MostCommonCity, byStatus}, start)
 }
 
 // NotFoundError ошибка "не найден"
 type NotFoundError struct {
 	Entity string
-	ID     int
+	ID     int64
 	Data   interface{}
 }
 
@@ -335,3 +1025,118 @@ func (e *NotFoundError) Error() string {
 	}
 	return fmt.Sprintf("%s не найден: %v", e.Entity, e.Data)
 }
+
+// MessageKey ключ сообщения для ответа API (см. пакет i18n) по первому слову
+// Entity: "employee by phone" -> "not_found.employee"
+func (e *NotFoundError) MessageKey() string {
+	entity, _, _ := strings.Cut(e.Entity, " ")
+	return "not_found." + entity
+}
+
+// IsNotFound сообщает, что err — *NotFoundError, в том числе обернутая через %w
+// в сервисе или выше
+func IsNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// AlreadyExistsError запись с таким значением поля уже есть: нарушение уникальности
+// при создании или изменении (в таблице employees уникален только телефон).
+// В отличие от ошибки валидации, данные корректны, но конфликтуют с существующими (409)
+type AlreadyExistsError struct {
+	Entity string
+	Field  string
+	Value  string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return fmt.Sprintf("%s со значением %q поля %s уже существует", e.Entity, e.Value, e.Field)
+}
+
+// VersionConflictError сотрудник изменен после того, как клиент его прочитал:
+// ожидаемая версия Expected не совпала с текущей Current
+type VersionConflictError struct {
+	ID       int64
+	Expected int
+	Current  int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("версия сотрудника %d изменилась: ожидалась %d, текущая %d", e.ID, e.Expected, e.Current)
+}
+
+// TooManyRowsError под фильтр списка подходит больше сотрудников, чем можно отдать
+// одним ответом (Limit); Count — сколько их. Если строки уже читались, Count равен Limit+1
+type TooManyRowsError struct {
+	Limit int
+	Count int64
+}
+
+func (e *TooManyRowsError) Error() string {
+	return fmt.Sprintf("сотрудников в списке %d, больше предела %d", e.Count, e.Limit)
+}
+
+// uniqueViolation код ошибки PostgreSQL unique_violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation определяет, что запрос нарушил ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// nullTime возвращает nil для нулевого времени, чтобы в БД сработало значение по умолчанию
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// purgeLockKey ключ advisory lock очистки: при нескольких репликах приложения
+// очистку в каждый момент выполняет только одна. С префиксом таблиц ключ выводится
+// из префикса (database.PrefixedLockKey), чтобы очистки разных префиксов в одной БД
+// не исключали друг друга
+const purgeLockKey int64 = 0x656d706c6f796572 // "employer"
+
+// ErrPurgeLocked очистку сейчас выполняет другой экземпляр приложения
+var ErrPurgeLocked = errors.New("очистка удаленных сотрудников уже выполняется")
+
+// PurgeSoftDeleted окончательно удаляет сотрудников, мягко удаленных раньше olderThan,
+// и возвращает количество удаленных строк. Удаление выполняется под транзакционным
+// advisory lock; если его держит другой экземпляр, ничего не удаляется и возвращается
+// ErrPurgeLocked
+func (r *employeeRepository) PurgeSoftDeleted(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := `
+		WITH lock AS (SELECT pg_try_advisory_xact_lock($2) AS locked),
+		purged AS (
+			DELETE FROM employees WHERE deleted_at < $1 AND (SELECT locked FROM lock)
+			RETURNING 1
+		)
+		SELECT (SELECT locked FROM lock), (SELECT count(*) FROM purged)`
+
+	var (
+		locked bool
+		purged int64
+	)
+	if err := r.db.QueryRowContext(ctx, query, olderThan, r.purgeLockKey).Scan(&locked, &purged); err != nil {
+		r.log(ctx).Error("ошибка очистки удаленных сотрудников", zap.Error(err))
+		return 0, fmt.Errorf("очистка удаленных сотрудников: %w", err)
+	}
+	if !locked {
+		return 0, ErrPurgeLocked
+	}
+
+	return purged, nil
+}
+
+// Truncate удаляет всех сотрудников вместе с историей, слияниями, посещаемостью и ключами
+// идемпотентности и сбрасывает последовательности ID. Используется командой seed --force в разработке
+func (r *employeeRepository) Truncate(ctx context.Context) error {
+	query := `TRUNCATE employees, employees_history, employee_merges, employee_attendance, idempotency_keys RESTART IDENTITY`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		r.log(ctx).Error("ошибка очистки таблиц сотрудников", zap.Error(err))
+		return fmt.Errorf("очистка таблиц сотрудников: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"employer/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// ArchiveVersion сохраняет текущую версию сотрудника в employees_history.
+// Версия действовала с последнего изменения записи до текущего момента транзакции,
+// поэтому вызывать ArchiveVersion нужно в одной транзакции с Update.
+func (r *employeeRepository) ArchiveVersion(ctx context.Context, id int64) error {
+	query := `
+		INSERT INTO employees_history (employee_id, name, phone, city, status, valid_from, valid_to)
+		SELECT id, name, phone, city, status, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP
+		FROM employees WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.log(ctx).Error("ошибка сохранения версии сотрудника", zap.Error(err), zap.Int64("id", id))
+		return fmt.Errorf("сохранение версии сотрудника: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("ошибка получения количества сохраненных версий", zap.Error(err))
+		return fmt.Errorf("получение количества сохраненных версий: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("сотрудник для сохранения версии не найден", zap.Int64("id", id))
+		return &NotFoundError{Entity: "employee", ID: id}
+	}
+
+	return nil
+}
+
+// GetHistory возвращает все версии сотрудника по возрастанию valid_from.
+// Последней идет текущая версия с пустым ValidTo, если сотрудник не удален.
+func (r *employeeRepository) GetHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+	query := `
+		SELECT name, phone, city, status, valid_from, valid_to FROM (
+			SELECT name, phone, city, status, valid_from, valid_to
+			FROM employees_history WHERE employee_id = $1
+			UNION ALL
+			SELECT name, phone, city, status, COALESCE(updated_at, created_at), NULL
+			FROM employees WHERE id = $1 AND deleted_at IS NULL
+		) v
+		ORDER BY valid_from, valid_to NULLS LAST`
+
+	rows, err := r.replica.QueryContext(ctx, query, id)
+	if err != nil {
+		r.log(ctx).Error("ошибка получения истории сотрудника", zap.Error(err), zap.Int64("id", id))
+		return nil, fmt.Errorf("получение истории сотрудника: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*domain.EmployeeVersion
+	for rows.Next() {
+		version := &domain.EmployeeVersion{EmployeeID: id}
+		var validTo sql.NullTime
+		if err := rows.Scan(&version.Name, &version.Phone, &version.City, &version.Status, &version.ValidFrom, &validTo); err != nil {
+			r.log(ctx).Error("ошибка сканирования версии сотрудника", zap.Error(err))
+			return nil, fmt.Errorf("сканирование версии сотрудника: %w", err)
+		}
+		if validTo.Valid {
+			version.ValidTo = &validTo.Time
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по истории сотрудника", zap.Error(err))
+		return nil, fmt.Errorf("итерация по истории сотрудника: %w", err)
+	}
+
+	if len(versions) == 0 {
+		r.log(ctx).Warn("история сотрудника не найдена", zap.Int64("id", id))
+		return nil, &NotFoundError{Entity: "employee", ID: id}
+	}
+
+	return versions, nil
+}
+
+// GetAsOf возвращает сотрудника в том виде, в котором он был в момент at: версию из
+// истории, действовавшую в at, либо текущую запись, если она не менялась после at.
+// Если в момент at сотрудника еще не было, возвращается NotFoundError.
+func (r *employeeRepository) GetAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+	query := `
+		SELECT employee_id, name, phone, city, status FROM employees_history
+		WHERE employee_id = $1 AND valid_from <= $2 AND valid_to > $2
+		UNION ALL
+		SELECT id, name, phone, city, status FROM employees
+		WHERE id = $1 AND deleted_at IS NULL AND created_at <= $2 AND COALESCE(updated_at, created_at) <= $2
+		LIMIT 1`
+
+	employee := &domain.Employee{}
+	err := r.replica.QueryRowContext(ctx, query, id, at).Scan(
+		&employee.ID, &employee.Name, &employee.Phone, &employee.City, &employee.Status,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.log(ctx).Warn("версия сотрудника не найдена", zap.Int64("id", id), zap.Time("as_of", at))
+			return nil, &NotFoundError{Entity: "employee", ID: id}
+		}
+		r.log(ctx).Error("ошибка получения версии сотрудника", zap.Error(err), zap.Int64("id", id))
+		return nil, fmt.Errorf("получение версии сотрудника: %w", err)
+	}
+
+	return employee, nil
+}
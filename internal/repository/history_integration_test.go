@@ -0,0 +1,101 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+func TestIntegration_GetAsOfBoundaries(t *testing.T) {
+	db := openIntegrationDB(t)
+
+	// Сотрудник создан 2024-01-01 как "Old", 2024-03-01 переименован в "New"
+	var id int64
+	err := db.QueryRow(`
+		INSERT INTO employees (name, phone, city, created_at, updated_at)
+		VALUES ('New', '+77010000001', 'Almaty', '2024-01-01', '2024-03-01') RETURNING id`).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert employee: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO employees_history (employee_id, name, phone, city, valid_from, valid_to)
+		VALUES ($1, 'Old', '+77010000001', 'Almaty', '2024-01-01', '2024-03-01')`, id)
+	if err != nil {
+		t.Fatalf("insert history: %v", err)
+	}
+
+	repos := repository.NewRepositories(db, zap.NewNop())
+	tests := []struct {
+		name string
+		at   time.Time
+		want string // пусто — сотрудник еще не существовал
+	}{
+		{"before creation", time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), ""},
+		{"exactly at creation", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "Old"},
+		{"inside prior version", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), "Old"},
+		{"exactly at change", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "New"},
+		{"after change", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "New"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := repos.Employee.GetAsOf(context.Background(), id, tt.at)
+			if tt.want == "" {
+				var nf *repository.NotFoundError
+				if !errors.As(err, &nf) {
+					t.Fatalf("expected NotFoundError, got %v, %+v", err, e)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAsOf: %v", err)
+			}
+			if e.Name != tt.want {
+				t.Fatalf("got %q, want %q", e.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntegration_ArchiveVersionOnUpdate(t *testing.T) {
+	db := openIntegrationDB(t)
+	repos := repository.NewRepositories(db, zap.NewNop())
+	ctx := context.Background()
+
+	e := &domain.Employee{Name: "Old", Phone: "+77010000001", City: "Almaty"}
+	if err := repos.Employee.Create(ctx, e); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	err := repos.UnitOfWork.WithTx(ctx, func(repo repository.EmployeeRepository) error {
+		if err := repo.ArchiveVersion(ctx, e.ID); err != nil {
+			return err
+		}
+		return repo.Update(ctx, &domain.Employee{ID: e.ID, Name: "New", Phone: e.Phone, City: e.City})
+	})
+	if err != nil {
+		t.Fatalf("update in tx: %v", err)
+	}
+
+	versions, err := repos.Employee.GetHistory(ctx, e.ID)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Name != "Old" || versions[1].Name != "New" {
+		t.Fatalf("unexpected history: %+v", versions)
+	}
+	if versions[0].ValidTo == nil || !versions[0].ValidTo.Equal(versions[1].ValidFrom) {
+		t.Fatalf("prior version must end where current begins: %+v", versions)
+	}
+	if versions[1].ValidTo != nil {
+		t.Fatalf("current version must be open-ended: %+v", versions[1])
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyRecord ключ Idempotency-Key и сотрудник, созданный по нему
+type IdempotencyRecord struct {
+	Key string
+	// RequestHash SHA-256 тела первого запроса с этим ключом (hex)
+	RequestHash string
+	EmployeeID  int64
+	CreatedAt   time.Time
+}
+
+// GetIdempotencyKey возвращает ключ, сохраненный не раньше since; nil, если его нет
+// или срок его действия истек. Читает из основной БД: ключ мог быть сохранен только что
+func (r *employeeRepository) GetIdempotencyKey(ctx context.Context, key string, since time.Time) (*IdempotencyRecord, error) {
+	query := `SELECT key, request_hash, employee_id, created_at FROM idempotency_keys WHERE key = $1 AND created_at >= $2`
+
+	record := &IdempotencyRecord{}
+	err := r.db.QueryRowContext(ctx, query, key, since).Scan(
+		&record.Key, &record.RequestHash, &record.EmployeeID, &record.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log(ctx).Error("ошибка получения ключа идемпотентности", zap.Error(err))
+		return nil, fmt.Errorf("получение ключа идемпотентности: %w", err)
+	}
+
+	return record, nil
+}
+
+// SaveIdempotencyKey сохраняет ключ, предварительно удалив ключи, сохраненные раньше since.
+// Возвращает false, если действующий ключ с тем же значением уже есть (его сохранил
+// параллельный запрос); вызывать нужно в одной транзакции с созданием сотрудника.
+func (r *employeeRepository) SaveIdempotencyKey(ctx context.Context, record *IdempotencyRecord, since time.Time) (bool, error) {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, since); err != nil {
+		r.log(ctx).Error("ошибка удаления просроченных ключей идемпотентности", zap.Error(err))
+		return false, fmt.Errorf("удаление просроченных ключей идемпотентности: %w", err)
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (key, request_hash, employee_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, record.Key, record.RequestHash, record.EmployeeID)
+	if err != nil {
+		r.log(ctx).Error("ошибка сохранения ключа идемпотентности", zap.Error(err))
+		return false, fmt.Errorf("сохранение ключа идемпотентности: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("ошибка получения количества сохраненных ключей", zap.Error(err))
+		return false, fmt.Errorf("получение количества сохраненных ключей: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
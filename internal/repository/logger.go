@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// WithLogger возвращает контекст, в котором репозиторий логирует через logger. Сервис
+// передает так логгер с полями запроса и операции, чтобы записи обоих слоев связывались
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext возвращает логгер из контекста или fallback
+func LoggerFromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok && logger != nil {
+		return logger
+	}
+	return fallback
+}
+
+// log логгер для запроса ctx. Репозиторий логирует результат обращения к БД:
+// что сделано и сколько строк затронуто, ошибки и конфликты
+func (r *employeeRepository) log(ctx context.Context) *zap.Logger {
+	return LoggerFromContext(ctx, r.logger)
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"employer/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// ReassignEmployee переносит на сотрудника toID строки других таблиц, ссылающиеся на
+// fromID: ключи идемпотентности (повтор создания вернет toID), прежние слияния в fromID
+// и отметки посещаемости за дни, в которые у toID отметки нет. История версий остается
+// у fromID. Вызывать нужно в одной транзакции с удалением fromID
+func (r *employeeRepository) ReassignEmployee(ctx context.Context, fromID, toID int64) error {
+	queries := []struct {
+		name  string
+		query string
+	}{
+		{"idempotency_keys", `UPDATE idempotency_keys SET employee_id = $2 WHERE employee_id = $1`},
+		{"employee_merges", `UPDATE employee_merges SET target_id = $2 WHERE target_id = $1`},
+		{"employee_attendance", `UPDATE employee_attendance a SET employee_id = $2 WHERE employee_id = $1
+			AND NOT EXISTS (SELECT 1 FROM employee_attendance t WHERE t.employee_id = $2 AND t.date = a.date)`},
+	}
+
+	for _, q := range queries {
+		if _, err := r.db.ExecContext(ctx, q.query, fromID, toID); err != nil {
+			r.log(ctx).Error("ошибка переноса ссылок на сотрудника",
+				zap.Error(err), zap.String("table", q.name), zap.Int64("from", fromID), zap.Int64("to", toID))
+			return fmt.Errorf("перенос ссылок на сотрудника из %s: %w", q.name, err)
+		}
+	}
+	return nil
+}
+
+// SaveMerge записывает слияние в журнал employee_merges. Без MergedBy автором считается system
+func (r *employeeRepository) SaveMerge(ctx context.Context, merge *domain.EmployeeMerge) error {
+	query := `
+		INSERT INTO employee_merges (source_id, target_id, source_name, source_phone, source_city, copied_fields, merged_by)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'system'))
+		RETURNING merged_at`
+
+	err := r.db.QueryRowContext(ctx, query, merge.SourceID, merge.TargetID,
+		merge.Source.Name, merge.Source.Phone, merge.Source.City,
+		strings.Join(merge.CopiedFields, ","), merge.MergedBy).Scan(&merge.MergedAt)
+	if err != nil {
+		r.log(ctx).Error("ошибка записи слияния сотрудников", zap.Error(err),
+			zap.Int64("source_id", merge.SourceID), zap.Int64("target_id", merge.TargetID))
+		return fmt.Errorf("запись слияния сотрудников: %w", err)
+	}
+	return nil
+}
+
+// FindDuplicates возвращает до limit пар возможных дубликатов по возрастанию ID: сотрудников,
+// у которых совпадают первые phoneDigits цифр телефона или имя и город без учета регистра.
+// Каждое условие — отдельное соединение по равенству, чтобы PostgreSQL мог выполнить его хешем
+func (r *employeeRepository) FindDuplicates(ctx context.Context, phoneDigits, limit int) ([]*domain.DuplicatePair, error) {
+	query := `
+		WITH e AS (
+			SELECT id, left(regexp_replace(phone, '\D', '', 'g'), $1) AS prefix, lower(name) AS lname, lower(city) AS lcity
+			FROM employees
+			WHERE deleted_at IS NULL
+		),
+		pairs AS (
+			SELECT a.id AS first_id, b.id AS second_id, '` + domain.DuplicatePhonePrefix + `' AS reason
+			FROM e a JOIN e b ON a.prefix = b.prefix AND a.id < b.id
+			WHERE length(a.prefix) = $1
+			UNION ALL
+			SELECT a.id, b.id, '` + domain.DuplicateNameCity + `'
+			FROM e a JOIN e b ON a.lname = b.lname AND a.lcity = b.lcity AND a.id < b.id
+		),
+		found AS (
+			SELECT first_id, second_id, string_agg(reason, ',' ORDER BY reason) AS reasons
+			FROM pairs
+			GROUP BY first_id, second_id
+			ORDER BY first_id, second_id
+			LIMIT $2
+		)
+		SELECT p.reasons,
+			f.id, f.name, f.phone, f.city, f.status,
+			s.id, s.name, s.phone, s.city, s.status
+		FROM found p
+		JOIN employees f ON f.id = p.first_id
+		JOIN employees s ON s.id = p.second_id
+		ORDER BY p.first_id, p.second_id`
+
+	rows, err := r.replica.QueryContext(ctx, query, phoneDigits, limit)
+	if err != nil {
+		r.log(ctx).Error("ошибка поиска дубликатов сотрудников", zap.Error(err))
+		return nil, fmt.Errorf("поиск дубликатов сотрудников: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := []*domain.DuplicatePair{}
+	for rows.Next() {
+		pair := &domain.DuplicatePair{First: &domain.Employee{}, Second: &domain.Employee{}}
+		var reasons string
+		err := rows.Scan(&reasons,
+			&pair.First.ID, &pair.First.Name, &pair.First.Phone, &pair.First.City, &pair.First.Status,
+			&pair.Second.ID, &pair.Second.Name, &pair.Second.Phone, &pair.Second.City, &pair.Second.Status)
+		if err != nil {
+			r.log(ctx).Error("ошибка сканирования пары дубликатов", zap.Error(err))
+			return nil, fmt.Errorf("сканирование пары дубликатов: %w", err)
+		}
+		pair.Reasons = strings.Split(reasons, ",")
+		pairs = append(pairs, pair)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Error("ошибка итерации по дубликатам", zap.Error(err))
+		return nil, fmt.Errorf("итерация по дубликатам: %w", err)
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"employer/traits/database"
+)
+
+// prefixedDB выполняет запросы репозитория к таблицам с префиксом имен
+// (DB_TABLE_PREFIX): "employees" в тексте запроса заменяется на "tenant1_employees".
+// Переписанные запросы запоминаются в prefixer, общем для всех соединений и транзакций
+type prefixedDB struct {
+	db       DBTX
+	prefixer *database.TablePrefixer
+}
+
+// withTablePrefix оборачивает db подстановкой префикса; без префикса (nil) возвращает db как есть
+func withTablePrefix(db DBTX, prefixer *database.TablePrefixer) DBTX {
+	if prefixer == nil || db == nil {
+		return db
+	}
+	return &prefixedDB{db: db, prefixer: prefixer}
+}
+
+func (p *prefixedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.db.ExecContext(ctx, p.prefixer.Query(query), args...)
+}
+
+func (p *prefixedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, p.prefixer.Query(query), args...)
+}
+
+func (p *prefixedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.db.QueryRowContext(ctx, p.prefixer.Query(query), args...)
+}
+
+func (p *prefixedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	db, ok := p.db.(preparer)
+	if !ok {
+		return nil, errors.New("соединение не поддерживает подготовку запросов")
+	}
+	return db.PrepareContext(ctx, p.prefixer.Query(query))
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// Частые запросы, которые репозиторий подготавливает заранее (см. prepareStatements)
+const (
+	createQuery = `
+		INSERT INTO employees (name, phone, city, status, created_by, updated_by, birth_date, hire_date) 
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'active'), COALESCE(NULLIF($5, ''), 'system'), COALESCE(NULLIF($5, ''), 'system'), $6, $7) 
+		RETURNING id`
+	// updated_at пуст у записей, импортированных без него; тогда время изменения — created_at
+	getByIDQuery    = `SELECT id, name, phone, city, status, created_by, updated_by, COALESCE(updated_at, created_at), version, birth_date, hire_date FROM employees WHERE id = $1 AND deleted_at IS NULL`
+	getByPhoneQuery = `SELECT id, name, phone, city, status, created_by, updated_by FROM employees WHERE phone = $1 AND deleted_at IS NULL`
+)
+
+// preparer соединение, умеющее подготавливать запросы (*sql.DB, *sql.Tx)
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// preparedStatements подготовленные частые запросы; nil-поле — запрос выполняется напрямую
+type preparedStatements struct {
+	create     *sql.Stmt
+	getByID    *sql.Stmt
+	getByPhone *sql.Stmt
+}
+
+// prepareStatements подготавливает частые запросы: Create и GetByPhone на db, GetByID на реплике.
+// Запрос, который не удалось подготовить, дальше выполняется напрямую.
+// Вызывается один раз для репозитория поверх пула соединений, не для транзакций.
+func (r *employeeRepository) prepareStatements(ctx context.Context) {
+	r.stmts.create = r.prepare(ctx, r.db, "Create", createQuery)
+	r.stmts.getByID = r.prepare(ctx, r.replica, "GetByID", getByIDQuery)
+	r.stmts.getByPhone = r.prepare(ctx, r.db, "GetByPhone", getByPhoneQuery)
+}
+
+func (r *employeeRepository) prepare(ctx context.Context, db DBTX, name, query string) *sql.Stmt {
+	p, ok := db.(preparer)
+	if !ok {
+		return nil
+	}
+	stmt, err := p.PrepareContext(ctx, query)
+	if err != nil {
+		r.logger.Warn("не удалось подготовить запрос, он будет выполняться напрямую",
+			zap.String("method", name), zap.Error(err))
+		return nil
+	}
+	return stmt
+}
+
+// queryRow выполняет запрос через подготовленный stmt, а без него — напрямую через db
+func queryRow(ctx context.Context, stmt *sql.Stmt, db DBTX, query string, args ...interface{}) *sql.Row {
+	if stmt != nil {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return db.QueryRowContext(ctx, query, args...)
+}
+
+// Close закрывает подготовленные запросы. Соединения с БД не закрывает.
+func (r *employeeRepository) Close() error {
+	var errs []error
+	for _, stmt := range []*sql.Stmt{r.stmts.create, r.stmts.getByID, r.stmts.getByPhone} {
+		if stmt != nil {
+			errs = append(errs, stmt.Close())
+		}
+	}
+	r.stmts = preparedStatements{}
+	return errors.Join(errs...)
+}
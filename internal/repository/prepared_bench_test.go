@@ -0,0 +1,73 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// BenchmarkGetByID сравнивает подготовленный и прямой GetByID на локальном Postgres:
+//
+//	EMPLOYER_TEST_DATABASE_URL=postgres://... go test -tags integration -run '^$' -bench GetByID ./internal/repository
+func BenchmarkGetByID(b *testing.B) {
+	db := openIntegrationDB(b)
+	ctx := context.Background()
+
+	prepared := repository.NewRepositories(db, zap.NewNop())
+	defer prepared.Close()
+
+	e := &domain.Employee{Name: "Bench", Phone: "+77010000001", City: "Almaty"}
+	if err := prepared.Employee.Create(ctx, e); err != nil {
+		b.Fatalf("Create: %v", err)
+	}
+
+	repos := map[string]repository.EmployeeRepository{
+		"prepared": prepared.Employee,
+		"direct":   repository.NewEmployeeRepository(db, zap.NewNop()),
+	}
+	for _, name := range []string{"prepared", "direct"} {
+		repo := repos[name]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetByID(ctx, e.ID); err != nil {
+					b.Fatalf("GetByID: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCreate сравнивает подготовленный и прямой Create
+func BenchmarkCreate(b *testing.B) {
+	db := openIntegrationDB(b)
+	ctx := context.Background()
+
+	prepared := repository.NewRepositories(db, zap.NewNop())
+	defer prepared.Close()
+
+	repos := map[string]repository.EmployeeRepository{
+		"prepared": prepared.Employee,
+		"direct":   repository.NewEmployeeRepository(db, zap.NewNop()),
+	}
+	// b.Run вызывает функцию несколько раз с растущим b.N, поэтому телефоны берутся из общего счетчика
+	seq := 0
+	for _, name := range []string{"prepared", "direct"} {
+		repo := repos[name]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				seq++
+				e := &domain.Employee{Name: "Bench", Phone: fmt.Sprintf("+77%010d", seq), City: "Almaty"}
+				if err := repo.Create(ctx, e); err != nil {
+					b.Fatalf("Create: %v", err)
+				}
+			}
+		})
+	}
+}
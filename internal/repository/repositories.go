@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"employer/internal/domain"
+	"employer/traits/database"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -11,29 +13,143 @@ import (
 // EmployeeRepository интерфейс для работы с БД
 type EmployeeRepository interface {
 	Create(ctx context.Context, employee *domain.Employee) error
-	GetByID(ctx context.Context, id int) (*domain.Employee, error)
-	GetAll(ctx context.Context) ([]*domain.Employee, error)
+	GetByID(ctx context.Context, id int64) (*domain.Employee, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error)
+	GetAll(ctx context.Context, filter domain.EmployeeFilter, maxRows int) ([]*domain.Employee, error)
+	GetAllCursor(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	GetRecent(ctx context.Context, limit int) ([]*domain.Employee, error)
+	GetByCreatedRange(ctx context.Context, from, to time.Time) ([]*domain.Employee, error)
+	GetPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, error)
+	CountWithFilter(ctx context.Context, filter domain.EmployeeFilter) (int64, error)
 	Update(ctx context.Context, employee *domain.Employee) error
-	Delete(ctx context.Context, id int) error
+	UpdateBatch(ctx context.Context, employees []*domain.Employee) ([]int64, error)
+	UpdateStatus(ctx context.Context, id int64, status, updatedBy string) error
+	Delete(ctx context.Context, id int64) error
+	DeleteMany(ctx context.Context, ids []int64) ([]int64, error)
+	ExistingIDs(ctx context.Context, ids []int64) ([]int64, error)
 
 	// Поиск и фильтрация
-	SearchEmployees(ctx context.Context, searchQuery string) ([]*domain.Employee, error)
+	SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error)
 	GetByPhone(ctx context.Context, phone string) (*domain.Employee, error)
 	GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error)
+	GetCityCounts(ctx context.Context) ([]*domain.CityCount, error)
+	GetUpcomingAnniversaries(ctx context.Context, from time.Time, withinDays int) ([]*domain.Anniversary, error)
+
+	// Выгрузка
+	StreamEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+
+	// Импорт
+	CreateWithID(ctx context.Context, employee *domain.Employee) error
+	SyncIDSequence(ctx context.Context) error
+
+	// История изменений
+	ArchiveVersion(ctx context.Context, id int64) error
+	GetHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error)
+	GetAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error)
+
+	// Ключи идемпотентности создания
+	GetIdempotencyKey(ctx context.Context, key string, since time.Time) (*IdempotencyRecord, error)
+	SaveIdempotencyKey(ctx context.Context, record *IdempotencyRecord, since time.Time) (bool, error)
+
+	// Слияние дубликатов
+	ReassignEmployee(ctx context.Context, fromID, toID int64) error
+	SaveMerge(ctx context.Context, merge *domain.EmployeeMerge) error
+	FindDuplicates(ctx context.Context, phoneDigits, limit int) ([]*domain.DuplicatePair, error)
+
+	// Посещаемость
+	UpsertAttendance(ctx context.Context, attendance *domain.Attendance) error
+	GetAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error)
+	GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error)
+
+	// Обслуживание
+	PurgeSoftDeleted(ctx context.Context, olderThan time.Time) (int64, error)
+	Truncate(ctx context.Context) error
 
 	// Дополнительные методы
 	GetEmployeeStats(ctx context.Context) (*EmployeeStats, error)
-	CheckPhoneExists(ctx context.Context, phone string, excludeID ...int) (bool, error)
+	CheckPhoneExists(ctx context.Context, phone string, excludeID ...int64) (bool, error)
 }
 
 // Repositories объединяет все репозитории
 type IRepositories struct {
-	Employee EmployeeRepository
+	Employee   EmployeeRepository
+	UnitOfWork UnitOfWork
+
+	// prepared репозиторий с подготовленными запросами, их закрывает Close
+	prepared *employeeRepository
+}
+
+// Options настройки репозиториев
+type Options struct {
+	// SortCollation collation сортировки имен (см. SortCollation); пусто — локаль DefaultSortLocale
+	SortCollation string
+	// Timing замер длительности запросов; nil — без замера
+	Timing *TimingOptions
+	// Replica соединение для чтения (см. employeeRepository.replica); nil — читать из основной БД.
+	// В транзакции чтение и запись идут через нее
+	Replica DBTX
+	// TablePrefix префикс имен таблиц (DB_TABLE_PREFIX), например "tenant1_"; пусто — без префикса.
+	// Должен пройти database.ValidateTablePrefix
+	TablePrefix string
 }
 
 // NewRepositories создает все репозитории
 func NewRepositories(db *sql.DB, logger *zap.Logger) *IRepositories {
+	return NewRepositoriesWithOptions(db, logger, Options{})
+}
+
+// NewRepositoriesWithTiming создает все репозитории с замером длительности запросов
+func NewRepositoriesWithTiming(db *sql.DB, logger *zap.Logger, opts TimingOptions) *IRepositories {
+	return NewRepositoriesWithOptions(db, logger, Options{Timing: &opts})
+}
+
+// NewRepositoriesWithOptions создает все репозитории с заданными настройками
+func NewRepositoriesWithOptions(db *sql.DB, logger *zap.Logger, opts Options) *IRepositories {
+	collation := opts.SortCollation
+	if collation == "" {
+		collation = sortCollations[DefaultSortLocale]
+	}
+
+	wrap := func(repo EmployeeRepository) EmployeeRepository {
+		if opts.Timing != nil {
+			repo = NewTimingRepository(repo, logger, *opts.Timing)
+		}
+		return repo
+	}
+	// Префикс подставляется в запросы одним prefixer на все соединения и транзакции,
+	// чтобы каждый текст запроса переписывался один раз
+	var prefixer *database.TablePrefixer
+	if opts.TablePrefix != "" {
+		prefixer = database.NewTablePrefixer(opts.TablePrefix)
+	}
+	lockKey := database.PrefixedLockKey(purgeLockKey, opts.TablePrefix)
+
+	newRepo := func(db DBTX) EmployeeRepository {
+		repo := NewEmployeeRepositoryWithCollation(withTablePrefix(db, prefixer), logger, collation)
+		repo.purgeLockKey = lockKey
+		return wrap(repo)
+	}
+
+	primary := withTablePrefix(db, prefixer)
+	prepared := NewEmployeeRepositoryWithCollation(primary, logger, collation)
+	if opts.Replica != nil {
+		prepared = NewEmployeeRepositoryWithReplica(primary, withTablePrefix(opts.Replica, prefixer), logger, collation)
+	}
+	prepared.purgeLockKey = lockKey
+	prepared.prepareStatements(context.Background())
+
 	return &IRepositories{
-		Employee: NewEmployeeRepository(db, logger),
+		Employee:   wrap(prepared),
+		UnitOfWork: &transactionalRepository{db: db, logger: logger, newRepo: newRepo},
+		prepared:   prepared,
+	}
+}
+
+// Close закрывает подготовленные запросы репозиториев; вызывается при остановке
+// до закрытия соединений с БД
+func (r *IRepositories) Close() error {
+	if r.prepared == nil {
+		return nil
 	}
+	return r.prepared.Close()
 }
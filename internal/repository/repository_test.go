@@ -3,14 +3,23 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"employer/internal/domain"
 	"employer/internal/repository"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func newRepo(t *testing.T) (*repository.IRepositories, sqlmock.Sqlmock, func()) {
@@ -27,11 +36,11 @@ func TestCreate_SimpleSuccess(t *testing.T) {
 	defer done()
 
 	q := regexp.QuoteMeta(`
-		INSERT INTO employees (name, phone, city) 
-		VALUES ($1, $2, $3) 
+		INSERT INTO employees (name, phone, city, status, created_by, updated_by, birth_date, hire_date) 
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'active'), COALESCE(NULLIF($5, ''), 'system'), COALESCE(NULLIF($5, ''), 'system'), $6, $7) 
 		RETURNING id`)
 	mock.ExpectQuery(q).
-		WithArgs("Alice", "+7701", "Almaty").
+		WithArgs("Alice", "+7701", "Almaty", "", "", nil, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
 
 	e := &domain.Employee{Name: "Alice", Phone: "+7701", City: "Almaty"}
@@ -50,7 +59,7 @@ func TestGetByID_NotFound(t *testing.T) {
 	repo, mock, done := newRepo(t)
 	defer done()
 
-	q := regexp.QuoteMeta(`SELECT id, name, phone, city FROM employees WHERE id = $1`)
+	q := regexp.QuoteMeta(`SELECT id, name, phone, city, status, created_by, updated_by, COALESCE(updated_at, created_at), version, birth_date, hire_date FROM employees WHERE id = $1`)
 	mock.ExpectQuery(q).WithArgs(404).WillReturnError(sql.ErrNoRows)
 
 	_, err := repo.Employee.GetByID(context.Background(), 404)
@@ -62,6 +71,21 @@ func TestGetByID_NotFound(t *testing.T) {
 	}
 }
 
+func TestIsNotFound(t *testing.T) {
+	notFound := &repository.NotFoundError{Entity: "employee", ID: 7}
+
+	if !repository.IsNotFound(notFound) {
+		t.Fatalf("bare NotFoundError not recognized")
+	}
+	wrapped := fmt.Errorf("обновление сотрудника 7: %w", fmt.Errorf("транзакция: %w", notFound))
+	if !repository.IsNotFound(wrapped) {
+		t.Fatalf("twice wrapped NotFoundError not recognized: %v", wrapped)
+	}
+	if repository.IsNotFound(fmt.Errorf("получение сотрудника: %w", sql.ErrConnDone)) || repository.IsNotFound(nil) {
+		t.Fatalf("only NotFoundError is not found")
+	}
+}
+
 // --- Search Tests ---
 
 func TestSearchEmployees_Success(t *testing.T) {
@@ -73,30 +97,31 @@ func TestSearchEmployees_Success(t *testing.T) {
 	exactSearchPattern := "john%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $2 THEN 1
+				WHEN phone ILIKE $2 THEN 2
+				WHEN city ILIKE $2 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $3 OFFSET $4`)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city"}).
-		AddRow(1, "John Doe", "+77777777777", "Almaty").
-		AddRow(2, "John Smith", "+77777777778", "Astana")
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+		AddRow(1, "John Doe", "+77777777777", "Almaty", "active", "system", "system", 2).
+		AddRow(2, "John Smith", "+77777777778", "Astana", "active", "system", "system", 2)
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, exactSearchPattern, 100, 0).
 		WillReturnRows(rows)
 
-	results, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -127,28 +152,29 @@ func TestSearchEmployees_NoResults(t *testing.T) {
 	exactSearchPattern := "nonexistent%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $2 THEN 1
+				WHEN phone ILIKE $2 THEN 2
+				WHEN city ILIKE $2 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $3 OFFSET $4`)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city"})
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"})
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, exactSearchPattern, 100, 0).
 		WillReturnRows(rows)
 
-	results, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -167,7 +193,7 @@ func TestSearchEmployees_EmptyQuery(t *testing.T) {
 	defer done()
 
 	// Empty query should return empty results without database call
-	results, err := repo.Employee.SearchEmployees(context.Background(), "")
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), "", domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -187,7 +213,7 @@ func TestSearchEmployees_WhitespaceQuery(t *testing.T) {
 	defer done()
 
 	// Whitespace-only query should return empty results without database call
-	results, err := repo.Employee.SearchEmployees(context.Background(), "   ")
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), "   ", domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -211,29 +237,30 @@ func TestSearchEmployees_ByPhone(t *testing.T) {
 	exactSearchPattern := "777%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE ((name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) OR phone_digits LIKE $2) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $3 THEN 1
+				WHEN phone ILIKE $3 THEN 2
+				WHEN city ILIKE $3 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $4 OFFSET $5`)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city"}).
-		AddRow(5, "Alice Johnson", "+77777777777", "Almaty")
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+		AddRow(5, "Alice Johnson", "+77777777777", "Almaty", "active", "system", "system", 1)
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, searchPattern, exactSearchPattern, 100, 0).
 		WillReturnRows(rows)
 
-	results, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -251,6 +278,54 @@ func TestSearchEmployees_ByPhone(t *testing.T) {
 	}
 }
 
+func TestSearchEmployees_PhoneDigits(t *testing.T) {
+	tests := []struct {
+		query string
+		// параметры условия поиска; при поиске по цифрам последний — цифры запроса
+		args   []driver.Value
+		digits bool
+	}{
+		{"7011234567", []driver.Value{"%7011234567%", "%7011234567%"}, true},
+		{"+77011234567", []driver.Value{"%+77011234567%", "%77011234567%"}, true},
+		{"+7 701 123 45 67", []driver.Value{"%+7%", "%701%", "%123%", "%45%", "%67%", "%77011234567%"}, true},
+		{"8 (701) 123-45-67", []driver.Value{"%8%", "%(701)%", "%123-45-67%", "%87011234567%"}, true},
+		// часть номера с конца
+		{"45-67", []driver.Value{"%45-67%", "%4567%"}, true},
+		{"john 701", []driver.Value{"%john%", "%701%"}, false},
+		{"+", []driver.Value{"%+%"}, false},
+	}
+
+	for _, tt := range tests {
+		repo, mock, done := newRepo(t)
+
+		match := regexp.QuoteMeta(fmt.Sprintf(" OR phone_digits LIKE $%d) AND deleted_at IS NULL ORDER BY", len(tt.args)))
+		if !tt.digits {
+			match = `\) AND deleted_at IS NULL ORDER BY`
+		}
+		args := tt.args
+		for _, term := range strings.Fields(tt.query) {
+			args = append(args, term+"%")
+		}
+		args = append(args, 100, 0)
+		mock.ExpectQuery(`FROM employees WHERE \(+name ILIKE \$1 .*` + match).
+			WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+				AddRow(1, "Alice", "+7 701 123 45 67", "Almaty", "active", "system", "system", 1))
+
+		results, _, err := repo.Employee.SearchEmployees(context.Background(), tt.query, domain.EmployeeFilter{}, 100, 0)
+		if err != nil {
+			t.Fatalf("%q: SearchEmployees: %v", tt.query, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("%q: expected 1 result, got %d", tt.query, len(results))
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("%q: unmet expectations: %v", tt.query, err)
+		}
+		done()
+	}
+}
+
 func TestSearchEmployees_ByCity(t *testing.T) {
 	repo, mock, done := newRepo(t)
 	defer done()
@@ -260,30 +335,31 @@ func TestSearchEmployees_ByCity(t *testing.T) {
 	exactSearchPattern := "almaty%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $2 THEN 1
+				WHEN phone ILIKE $2 THEN 2
+				WHEN city ILIKE $2 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $3 OFFSET $4`)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city"}).
-		AddRow(3, "Alice Brown", "+77777777779", "Almaty").
-		AddRow(4, "Bob Green", "+77777777780", "Almaty")
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+		AddRow(3, "Alice Brown", "+77777777779", "Almaty", "active", "system", "system", 2).
+		AddRow(4, "Bob Green", "+77777777780", "Almaty", "active", "system", "system", 2)
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, exactSearchPattern, 100, 0).
 		WillReturnRows(rows)
 
-	results, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -311,26 +387,27 @@ func TestSearchEmployees_DatabaseError(t *testing.T) {
 	exactSearchPattern := "test%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $2 THEN 1
+				WHEN phone ILIKE $2 THEN 2
+				WHEN city ILIKE $2 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $3 OFFSET $4`)
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, exactSearchPattern, 100, 0).
 		WillReturnError(sql.ErrConnDone)
 
-	_, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	_, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err == nil {
 		t.Fatalf("expected database error, got nil")
 	}
@@ -349,30 +426,31 @@ func TestSearchEmployees_ScanError(t *testing.T) {
 	exactSearchPattern := "test%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $2 THEN 1
+				WHEN phone ILIKE $2 THEN 2
+				WHEN city ILIKE $2 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $3 OFFSET $4`)
 
 	// Return invalid data that will cause scan error
-	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city"}).
-		AddRow("invalid_id", "John Doe", "+77777777777", "Almaty")
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+		AddRow("invalid_id", "John Doe", "+77777777777", "Almaty", "active", "system", "system", 1)
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, exactSearchPattern, 100, 0).
 		WillReturnRows(rows)
 
-	_, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	_, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err == nil {
 		t.Fatalf("expected scan error, got nil")
 	}
@@ -391,29 +469,30 @@ func TestSearchEmployees_CaseInsensitive(t *testing.T) {
 	exactSearchPattern := "JOHN%"
 
 	q := regexp.QuoteMeta(`
-		SELECT id, name, phone, city 
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
 		FROM employees 
-		WHERE LOWER(name) LIKE LOWER($1) 
-		   OR LOWER(phone) LIKE LOWER($1) 
-		   OR LOWER(city) LIKE LOWER($1)
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND deleted_at IS NULL
 		ORDER BY 
 			CASE 
-				WHEN LOWER(name) LIKE LOWER($2) THEN 1
-				WHEN LOWER(phone) LIKE LOWER($2) THEN 2
-				WHEN LOWER(city) LIKE LOWER($2) THEN 3
+				WHEN name ILIKE $2 THEN 1
+				WHEN phone ILIKE $2 THEN 2
+				WHEN city ILIKE $2 THEN 3
 				ELSE 4
 			END,
-			name ASC
-		LIMIT 100`)
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $3 OFFSET $4`)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city"}).
-		AddRow(1, "john doe", "+77777777777", "almaty")
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+		AddRow(1, "john doe", "+77777777777", "almaty", "active", "system", "system", 1)
 
 	mock.ExpectQuery(q).
-		WithArgs(searchPattern, exactSearchPattern).
+		WithArgs(searchPattern, exactSearchPattern, 100, 0).
 		WillReturnRows(rows)
 
-	results, err := repo.Employee.SearchEmployees(context.Background(), searchQuery)
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), searchQuery, domain.EmployeeFilter{}, 100, 0)
 	if err != nil {
 		t.Fatalf("SearchEmployees: %v", err)
 	}
@@ -430,3 +509,1461 @@ func TestSearchEmployees_CaseInsensitive(t *testing.T) {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
+
+// --- Export Tests ---
+
+func TestStreamEmployees_CityFilter(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	q := `SELECT id, name, phone, city, status, created_by, updated_by, created_at, updated_at,\s+version, birth_date, hire_date FROM employees WHERE LOWER\(city\) = LOWER\(\$1\) AND deleted_at IS NULL ORDER BY id`
+	now := time.Now()
+	birth := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "created_at", "updated_at", "version", "birth_date", "hire_date"}).
+		AddRow(1, "John Doe", "+77777777777", "Almaty", "active", "system", "system", now, now, 3, birth, nil).
+		AddRow(2, "Jane Doe", "+77777777778", "Almaty", "inactive", "admin", "hr", now, now, 1, nil, nil)
+	mock.ExpectQuery(q).WithArgs("almaty").WillReturnRows(rows)
+
+	var got []*domain.Employee
+	err := repo.Employee.StreamEmployees(context.Background(), domain.EmployeeFilter{City: "almaty"}, func(e *domain.Employee) error {
+		got = append(got, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamEmployees: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].Name != "Jane Doe" {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+	if got[0].Version != 3 || got[0].BirthDate == nil || !got[0].BirthDate.Equal(birth) || got[0].HireDate != nil {
+		t.Fatalf("version and dates not streamed: %+v", got[0])
+	}
+	if got[1].Status != "inactive" || got[1].CreatedBy != "admin" || got[1].UpdatedBy != "hr" {
+		t.Fatalf("status and authors not streamed: %+v", got[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// --- Import Tests ---
+
+func TestCreateWithID_Success(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	hired := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	// Все колонки записи, включая created_by, перезаписываются при конфликте
+	mock.ExpectExec(`INSERT INTO employees \(id, name, phone, city, status, created_at, updated_at, created_by, updated_by,\s+birth_date, hire_date, version\)(.|\n)*created_by = EXCLUDED.created_by`).
+		WithArgs(7, "Alice", "+7701", "Almaty", "inactive", created, nil, "admin", "hr", nil, hired, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT setval(pg_get_serial_sequence('employees', 'id')`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	e := &domain.Employee{ID: 7, Name: "Alice", Phone: "+7701", City: "Almaty", Status: "inactive", CreatedAt: created,
+		CreatedBy: "admin", UpdatedBy: "hr", HireDate: &hired, Version: 4}
+	if err := repo.Employee.CreateWithID(context.Background(), e); err != nil {
+		t.Fatalf("CreateWithID: %v", err)
+	}
+	if err := repo.Employee.SyncIDSequence(context.Background()); err != nil {
+		t.Fatalf("SyncIDSequence: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchEmployees_UsesIndexFriendlyILike(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	// ILIKE по колонке без LOWER(), чтобы запрос мог использовать триграммный индекс
+	mock.ExpectQuery(`WHERE \(name ILIKE \$1\s+OR phone ILIKE \$1\s+OR city ILIKE \$1\)`).
+		WithArgs("%Алм%", "Алм%", 100, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+			AddRow(1, "Aigerim", "+77010000001", "Алматы", "active", "system", "system", 2).
+			AddRow(2, "Алмас", "+77010000002", "Astana", "active", "system", "system", 2))
+
+	results, _, err := repo.Employee.SearchEmployees(context.Background(), "Алм", domain.EmployeeFilter{}, 100, 0)
+	if err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if len(results) != 2 || results[0].City != "Алматы" || results[1].Name != "Алмас" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPurgeSoftDeleted(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	olderThan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	purge := regexp.QuoteMeta(`DELETE FROM employees WHERE deleted_at < $1 AND (SELECT locked FROM lock)`)
+	mock.ExpectQuery(`pg_try_advisory_xact_lock\(\$2\)(?s).*`+purge).
+		WithArgs(olderThan, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"locked", "count"}).AddRow(true, 3))
+
+	purged, err := repo.Employee.PurgeSoftDeleted(context.Background(), olderThan)
+	if err != nil {
+		t.Fatalf("PurgeSoftDeleted: %v", err)
+	}
+	if purged != 3 {
+		t.Fatalf("want 3 purged, got %d", purged)
+	}
+
+	// блокировку держит другой экземпляр
+	mock.ExpectQuery(purge).
+		WithArgs(olderThan, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"locked", "count"}).AddRow(false, 0))
+
+	if _, err := repo.Employee.PurgeSoftDeleted(context.Background(), olderThan); !errors.Is(err, repository.ErrPurgeLocked) {
+		t.Fatalf("expected ErrPurgeLocked, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectExec(regexp.QuoteMeta(`TRUNCATE employees, employees_history, employee_merges, employee_attendance, idempotency_keys RESTART IDENTITY`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.Employee.Truncate(context.Background()); err != nil {
+		t.Fatalf("Truncate: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+// slowRepo подменяет SearchEmployees медленной реализацией; остальные методы не используются
+type slowRepo struct {
+	repository.EmployeeRepository
+	delay time.Duration
+}
+
+func (r *slowRepo) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	time.Sleep(r.delay)
+	return nil, 0, nil
+}
+
+func TestTimingRepository_SlowQuery(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	histogram := repository.NewQueryDurationHistogram()
+
+	repo := repository.NewTimingRepository(&slowRepo{delay: 20 * time.Millisecond}, zap.New(core), repository.TimingOptions{
+		SlowThreshold: 5 * time.Millisecond,
+		Histogram:     histogram,
+	})
+
+	if _, _, err := repo.SearchEmployees(context.Background(), "al", domain.EmployeeFilter{}, 100, 0); err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+
+	entries := logs.FilterMessage("медленный запрос к БД").All()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 slow query log, got %d", len(entries))
+	}
+	if op := entries[0].ContextMap()["operation"]; op != "SearchEmployees" {
+		t.Fatalf("want operation SearchEmployees, got %v", op)
+	}
+
+	metric := &dto.Metric{}
+	if err := histogram.WithLabelValues("SearchEmployees").(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatalf("read histogram: %v", err)
+	}
+	if got := metric.GetHistogram().GetSampleCount(); got != 1 {
+		t.Fatalf("want 1 observation, got %d", got)
+	}
+}
+
+func TestTimingRepository_FastQueryNotLogged(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+
+	repo := repository.NewTimingRepository(&slowRepo{}, zap.New(core), repository.TimingOptions{
+		SlowThreshold: time.Second,
+	})
+
+	if _, _, err := repo.SearchEmployees(context.Background(), "al", domain.EmployeeFilter{}, 100, 0); err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("want no slow query logs, got %d", logs.Len())
+	}
+}
+
+func TestGetRecent_OrderAndLimit(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	newer := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`ORDER BY created_at DESC, id DESC LIMIT \$1`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "created_at", "updated_at"}).
+			AddRow(2, "Bob", "+77010000002", "Astana", "active", "system", "system", newer, newer).
+			AddRow(1, "Alice", "+77010000001", "Almaty", "active", "system", "system", older, older))
+
+	employees, err := repo.Employee.GetRecent(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("GetRecent: %v", err)
+	}
+	if len(employees) != 2 || employees[0].ID != 2 || !employees[0].CreatedAt.Equal(newer) {
+		t.Fatalf("unexpected employees: %+v", employees)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetAllCursor_StopsOnCallbackError(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`FROM employees WHERE status = \$1 AND deleted_at IS NULL ORDER BY id`).
+		WithArgs("active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}).
+			AddRow(1, "Alice", "+77010000001", "Almaty", "active", "system", "system").
+			AddRow(2, "Bob", "+77010000002", "Astana", "active", "system", "system").
+			AddRow(3, "Asel", "+77010000003", "Astana", "active", "system", "system"))
+
+	stop := errors.New("client gone")
+	var ids []int64
+	err := repo.Employee.GetAllCursor(context.Background(), domain.EmployeeFilter{Status: "active", Sort: domain.SortID},
+		func(e *domain.Employee) error {
+			ids = append(ids, e.ID)
+			if len(ids) == 2 {
+				return stop
+			}
+			return nil
+		})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("unexpected rows: %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetByCreatedRange_Between(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 23, 59, 59, 999999000, time.UTC)
+	first := time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM employees WHERE created_at >= \$1 AND created_at <= \$2 AND deleted_at IS NULL ORDER BY created_at, id`).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "created_at", "updated_at", "version", "birth_date", "hire_date"}).
+			AddRow(4, "Asel", "+77010000004", "Astana", "active", "system", "system", first, first, 1, nil, nil).
+			AddRow(2, "John", "+77010000002", "Almaty", "active", "system", "system", second, second, 3, nil, nil))
+
+	employees, err := repo.Employee.GetByCreatedRange(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("GetByCreatedRange: %v", err)
+	}
+	if len(employees) != 2 || employees[0].ID != 4 || !employees[0].CreatedAt.Equal(first) || employees[1].Version != 3 {
+		t.Fatalf("unexpected employees: %+v", employees)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestCountWithFilter_MatchesListWhere(t *testing.T) {
+	var queries []string
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(_, actual string) error {
+		queries = append(queries, strings.Join(strings.Fields(actual), " "))
+		return nil
+	})))
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	repo := repository.NewRepositories(db, zap.NewNop())
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	filter := domain.EmployeeFilter{City: "Almaty", Status: "active", Query: "john 777", CreatedFrom: from, CreatedTo: to, Sort: domain.SortCreatedAt}
+	whereArgs := []driver.Value{"%john%", "%777%", "Almaty", "active", from, to}
+
+	mock.ExpectQuery("list").WithArgs(append(whereArgs, 10, 20)...).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}))
+	mock.ExpectQuery("count").WithArgs(whereArgs...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	if _, err := repo.Employee.GetPage(context.Background(), filter, 10, 20); err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if _, err := repo.Employee.CountWithFilter(context.Background(), filter); err != nil {
+		t.Fatalf("CountWithFilter: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(queries))
+	}
+	_, listWhere, _ := strings.Cut(queries[0], " WHERE ")
+	listWhere, _, _ = strings.Cut(listWhere, " ORDER BY ")
+	_, countWhere, _ := strings.Cut(queries[1], " WHERE ")
+	if listWhere == "" || listWhere != countWhere {
+		t.Fatalf("count WHERE differs from list WHERE:\nlist:  %s\ncount: %s", listWhere, countWhere)
+	}
+	for _, cond := range []string{"name ILIKE $1", "name ILIKE $2", "LOWER(city) = LOWER($3)", "status = $4", "created_at >= $5", "created_at <= $6"} {
+		if !strings.Contains(countWhere, cond) {
+			t.Fatalf("expected %q in WHERE %s", cond, countWhere)
+		}
+	}
+}
+
+func TestCount_CityFilter(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM employees WHERE LOWER\(city\) = LOWER\(\$1\)`).
+		WithArgs("Almaty").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	count, err := repo.Employee.CountWithFilter(context.Background(), domain.EmployeeFilter{City: "Almaty"})
+	if err != nil {
+		t.Fatalf("CountWithFilter: %v", err)
+	}
+	if count != 12 {
+		t.Fatalf("expected 12, got %d", count)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetCityCounts(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`MODE\(\) WITHIN GROUP \(ORDER BY city\) AS city, COUNT\(\*\) AS employee_count\s+FROM employees\s+WHERE deleted_at IS NULL\s+GROUP BY LOWER\(city\)\s+ORDER BY employee_count DESC, city`).
+		WillReturnRows(sqlmock.NewRows([]string{"city", "employee_count"}).
+			AddRow("Almaty", 7).
+			AddRow("Astana", 2))
+
+	counts, err := repo.Employee.GetCityCounts(context.Background())
+	if err != nil {
+		t.Fatalf("GetCityCounts: %v", err)
+	}
+	if len(counts) != 2 || counts[0].City != "Almaty" || counts[0].EmployeeCount != 7 || counts[1].City != "Astana" {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetCityCounts_Empty(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`GROUP BY LOWER\(city\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"city", "employee_count"}))
+
+	counts, err := repo.Employee.GetCityCounts(context.Background())
+	if err != nil {
+		t.Fatalf("GetCityCounts: %v", err)
+	}
+	if counts == nil || len(counts) != 0 {
+		t.Fatalf("expected empty non-nil slice, got %#v", counts)
+	}
+}
+
+func TestGetAll_StatusFilter(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`SELECT id, name, phone, city, status, created_by, updated_by FROM employees WHERE LOWER\(city\) = LOWER\(\$1\) AND status = \$2 AND deleted_at IS NULL ORDER BY`).
+		WithArgs("Almaty", domain.StatusInactive).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}).
+			AddRow(3, "Carol", "+77010000003", "Almaty", domain.StatusInactive, "system", "system"))
+
+	employees, err := repo.Employee.GetAll(context.Background(), domain.EmployeeFilter{City: "Almaty", Status: domain.StatusInactive}, 0)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(employees) != 1 || employees[0].Status != domain.StatusInactive {
+		t.Fatalf("unexpected employees: %+v", employees)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetAll_MaxRows(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	// сотрудников больше предела: строки не читаются
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM employees`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10001))
+
+	_, err := repo.Employee.GetAll(context.Background(), domain.EmployeeFilter{}, 10000)
+	var tooMany *repository.TooManyRowsError
+	if !errors.As(err, &tooMany) || tooMany.Limit != 10000 || tooMany.Count != 10001 {
+		t.Fatalf("expected TooManyRowsError, got %v", err)
+	}
+
+	// в пределе: запрос ограничен на одну строку больше предела
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM employees WHERE LOWER\(city\) = LOWER\(\$1\)`).
+		WithArgs("Almaty").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT id, name, phone, city, status, created_by, updated_by FROM employees WHERE .* ORDER BY .* LIMIT \$2`).
+		WithArgs("Almaty", 10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}).
+			AddRow(1, "Alice", "+77010000001", "Almaty", domain.StatusActive, "system", "system"))
+
+	employees, err := repo.Employee.GetAll(context.Background(), domain.EmployeeFilter{City: "Almaty"}, 10000)
+	if err != nil || len(employees) != 1 {
+		t.Fatalf("expected 1 employee, got %d (%v)", len(employees), err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpdateStatus_NotFound(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectExec(`UPDATE employees SET status = \$2`).
+		WithArgs(99, domain.StatusInactive, "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Employee.UpdateStatus(context.Background(), 99, domain.StatusInactive, "")
+	var notFound *repository.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestWithTx_RollbackOnError(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE employees SET deleted_at = CURRENT_TIMESTAMP WHERE id = \$1`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	failure := errors.New("boom")
+	err := repo.UnitOfWork.WithTx(context.Background(), func(tx repository.EmployeeRepository) error {
+		if err := tx.Delete(context.Background(), 1); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestWithTx_Commit(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE employees SET deleted_at = CURRENT_TIMESTAMP WHERE id = \$1`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.UnitOfWork.WithTx(context.Background(), func(tx repository.EmployeeRepository) error {
+		return tx.Delete(context.Background(), 1)
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetPage_LimitOffset(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`ORDER BY lower\(name\) COLLATE "und-x-icu", id LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 40).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}).
+			AddRow(41, "Alice", "+77010000041", "Almaty", "active", "system", "system"))
+
+	employees, err := repo.Employee.GetPage(context.Background(), domain.EmployeeFilter{}, 20, 40)
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if len(employees) != 1 || employees[0].ID != 41 {
+		t.Fatalf("unexpected employees: %+v", employees)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetPage_Sort(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}))
+	mock.ExpectQuery(`ORDER BY lower\(name\) COLLATE "und-x-icu" DESC, id DESC LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}))
+
+	if _, err := repo.Employee.GetPage(context.Background(), domain.EmployeeFilter{Sort: domain.SortCreatedAt, Desc: true}, 20, 0); err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if _, err := repo.Employee.GetPage(context.Background(), domain.EmployeeFilter{Desc: true}, 20, 0); err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestSearchEmployees_SortReplacesRank(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	// без ранжирования параметр "john%" не нужен: LIMIT и OFFSET сдвигаются на $2 и $3
+	mock.ExpectQuery(`ORDER BY\s+id\s+LIMIT \$2 OFFSET \$3`).
+		WithArgs("%john%", 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}))
+
+	if _, _, err := repo.Employee.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{Sort: domain.SortID}, 10, 0); err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestCreate_UniqueViolation(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`INSERT INTO employees`).
+		WithArgs("Alice", "+77010000001", "Almaty", "", "", nil, nil).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "employees_phone_key"})
+
+	e := &domain.Employee{Name: "Alice", Phone: "+77010000001", City: "Almaty"}
+	err := repo.Employee.Create(context.Background(), e)
+
+	var exists *repository.AlreadyExistsError
+	if !errors.As(err, &exists) || exists.Entity != "employee" || exists.Field != "phone" || exists.Value != "+77010000001" {
+		t.Fatalf("want AlreadyExistsError for phone, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpdate_UniqueViolation(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees`).
+		WithArgs(5, "Alice", "+77010000001", "Almaty", "", "", 0, nil, nil).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "employees_phone_key"})
+
+	e := &domain.Employee{ID: 5, Name: "Alice", Phone: "+77010000001", City: "Almaty"}
+	err := repo.Employee.Update(context.Background(), e)
+
+	var exists *repository.AlreadyExistsError
+	if !errors.As(err, &exists) {
+		t.Fatalf("want AlreadyExistsError, got %v", err)
+	}
+}
+
+func TestCreateAndUpdate_Actor(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`INSERT INTO employees`).
+		WithArgs("Alice", "+77010000001", "Almaty", "", "alice", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(`UPDATE employees .* updated_by = COALESCE\(NULLIF\(\$6, ''\), 'system'\)`).
+		WithArgs(5, "Alice", "+77010000001", "Astana", "", "bob", 0, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_by", "updated_by", "version", "birth_date", "hire_date"}).AddRow("active", "alice", "bob", 2, nil, nil))
+
+	e := &domain.Employee{Name: "Alice", Phone: "+77010000001", City: "Almaty", CreatedBy: "alice", UpdatedBy: "alice"}
+	if err := repo.Employee.Create(context.Background(), e); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	updated := &domain.Employee{ID: 5, Name: "Alice", Phone: "+77010000001", City: "Astana", UpdatedBy: "bob"}
+	if err := repo.Employee.Update(context.Background(), updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.CreatedBy != "alice" || updated.UpdatedBy != "bob" || updated.Status != "active" {
+		t.Fatalf("unexpected employee after update: %+v", updated)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees`).
+		WithArgs(9, "Alice", "+77010000001", "Almaty", "", "", 2, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_by", "updated_by", "version", "birth_date", "hire_date"}))
+	mock.ExpectQuery(`SELECT version FROM employees WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+	err := repo.Employee.Update(context.Background(), &domain.Employee{ID: 9, Name: "Alice", Phone: "+77010000001", City: "Almaty", Version: 2})
+	var notFound *repository.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestUpdate_VersionMatched(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees .* version = version \+ 1,\s+birth_date = COALESCE\(\$8, birth_date\), hire_date = COALESCE\(\$9, hire_date\)\s+WHERE id = \$1 AND deleted_at IS NULL AND version = \$7\s+RETURNING status, created_by, updated_by, version`).
+		WithArgs(5, "Alice", "+77010000001", "Almaty", "", "", 3, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_by", "updated_by", "version", "birth_date", "hire_date"}).AddRow("active", "system", "system", 4, nil, nil))
+
+	e := &domain.Employee{ID: 5, Name: "Alice", Phone: "+77010000001", City: "Almaty", Version: 3}
+	if err := repo.Employee.Update(context.Background(), e); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if e.Version != 4 {
+		t.Fatalf("expected version 4, got %d", e.Version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpdate_VersionStale(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees`).
+		WithArgs(5, "Alice", "+77010000001", "Almaty", "", "", 3, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_by", "updated_by", "version", "birth_date", "hire_date"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM employees WHERE id = $1`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(7))
+
+	err := repo.Employee.Update(context.Background(), &domain.Employee{ID: 5, Name: "Alice", Phone: "+77010000001", City: "Almaty", Version: 3})
+	var conflict *repository.VersionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected VersionConflictError, got %v", err)
+	}
+	if conflict.ID != 5 || conflict.Expected != 3 || conflict.Current != 7 {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpdate_VersionMissingEmployee(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees`).
+		WithArgs(9, "Alice", "+77010000001", "Almaty", "", "", 3, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_by", "updated_by", "version", "birth_date", "hire_date"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM employees WHERE id = $1`)).
+		WithArgs(9).
+		WillReturnError(sql.ErrNoRows)
+
+	err := repo.Employee.Update(context.Background(), &domain.Employee{ID: 9, Name: "Alice", Phone: "+77010000001", City: "Almaty", Version: 3})
+	if !repository.IsNotFound(err) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestIdempotencyKey_SaveAndGet(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT key, request_hash, employee_id, created_at FROM idempotency_keys WHERE key = \$1 AND created_at >= \$2`).
+		WithArgs("key-1", since).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "request_hash", "employee_id", "created_at"}))
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE created_at < \$1`).
+		WithArgs(since).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO idempotency_keys .* ON CONFLICT \(key\) DO NOTHING`).
+		WithArgs("key-1", "hash", 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	record, err := repo.Employee.GetIdempotencyKey(context.Background(), "key-1", since)
+	if err != nil || record != nil {
+		t.Fatalf("expected no record, got %+v, %v", record, err)
+	}
+	saved, err := repo.Employee.SaveIdempotencyKey(context.Background(), &repository.IdempotencyRecord{Key: "key-1", RequestHash: "hash", EmployeeID: 7}, since)
+	if err != nil {
+		t.Fatalf("SaveIdempotencyKey: %v", err)
+	}
+	if saved {
+		t.Fatal("expected a key taken by a concurrent request to be reported as not saved")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetAll_SortCollation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	collation, err := repository.SortCollation("kk")
+	if err != nil {
+		t.Fatalf("SortCollation: %v", err)
+	}
+	repos := repository.NewRepositoriesWithOptions(db, zap.NewNop(), repository.Options{SortCollation: collation})
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY lower(name) COLLATE "kk-x-icu", id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}))
+
+	if _, err := repos.Employee.GetAll(context.Background(), domain.EmployeeFilter{}, 0); err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+
+	if _, err := repository.SortCollation("fr"); err == nil {
+		t.Fatal("expected error for unsupported locale")
+	}
+}
+
+func TestSearchEmployees_PageAndTotal(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`count\(\*\) OVER\(\) AS total .*LIMIT \$3 OFFSET \$4`).
+		WithArgs("%john%", "john%", 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+			AddRow(3, "John Brown", "+77010000003", "Almaty", "active", "system", "system", 5).
+			AddRow(4, "John Green", "+77010000004", "Astana", "active", "system", "system", 5))
+
+	results, total, err := repo.Employee.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, 2, 2)
+	if err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != 3 || results[1].ID != 4 {
+		t.Fatalf("unexpected page: %+v", results)
+	}
+	if total != 5 {
+		t.Fatalf("expected total 5, got %d", total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchEmployees_TotalBeyondLastPage(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`LIMIT \$3 OFFSET \$4`).
+		WithArgs("%john%", "john%", 10, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM employees WHERE (name ILIKE $1 OR phone ILIKE $1 OR city ILIKE $1)`)).
+		WithArgs("%john%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	results, total, err := repo.Employee.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, 10, 50)
+	if err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if len(results) != 0 || total != 5 {
+		t.Fatalf("expected empty page with total 5, got %d results, total %d", len(results), total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchEmployees_MultipleTerms(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	q := regexp.QuoteMeta(`
+		SELECT id, name, phone, city, status, created_by, updated_by, count(*) OVER() AS total 
+		FROM employees 
+		WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND (name ILIKE $2 
+		   OR phone ILIKE $2 
+		   OR city ILIKE $2) AND deleted_at IS NULL
+		ORDER BY 
+			CASE 
+				WHEN name ILIKE $3 THEN 1
+				WHEN phone ILIKE $3 THEN 2
+				WHEN city ILIKE $3 THEN 3
+				ELSE 4
+			END +
+			CASE 
+				WHEN name ILIKE $4 THEN 1
+				WHEN phone ILIKE $4 THEN 2
+				WHEN city ILIKE $4 THEN 3
+				ELSE 4
+			END,
+			lower(name) COLLATE "und-x-icu",
+			id
+		LIMIT $5 OFFSET $6`)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}).
+		AddRow(1, "John Doe", "+77777777777", "Almaty", "active", "system", "system", 1)
+
+	// слова разделены несколькими пробелами
+	mock.ExpectQuery(q).
+		WithArgs("%john%", "%almaty%", "john%", "almaty%", 100, 0).
+		WillReturnRows(rows)
+
+	results, total, err := repo.Employee.SearchEmployees(context.Background(), "john   almaty", domain.EmployeeFilter{}, 100, 0)
+	if err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if len(results) != 1 || total != 1 || results[0].City != "Almaty" {
+		t.Fatalf("unexpected results: %+v, total %d", results, total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchEmployees_MultipleTermsWithFilter(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	// параметры фильтра идут после параметров слов, параметры ранжирования — следом
+	mock.ExpectQuery(`\(name ILIKE \$1 .*AND \(name ILIKE \$2 .* AND LOWER\(city\) = LOWER\(\$3\).*LIMIT \$6 OFFSET \$7`).
+		WithArgs("%john%", "%777%", "Almaty", "john%", "777%", 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "total"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM employees WHERE (name ILIKE $1 
+		   OR phone ILIKE $1 
+		   OR city ILIKE $1) AND (name ILIKE $2 
+		   OR phone ILIKE $2 
+		   OR city ILIKE $2) AND LOWER(city) = LOWER($3)`)).
+		WithArgs("%john%", "%777%", "Almaty").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	filter := domain.EmployeeFilter{City: "Almaty"}
+	results, total, err := repo.Employee.SearchEmployees(context.Background(), "john 777", filter, 10, 20)
+	if err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if len(results) != 0 || total != 3 {
+		t.Fatalf("expected empty page with total 3, got %d results, total %d", len(results), total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestArchiveVersion_NotFound(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectExec(`INSERT INTO employees_history`).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Employee.ArchiveVersion(context.Background(), 42)
+	var nf *repository.NotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestGetHistory_CurrentVersionLast(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"name", "phone", "city", "status", "valid_from", "valid_to"}).
+		AddRow("Old", "+77010000001", "Almaty", "active", from, to).
+		AddRow("New", "+77010000001", "Almaty", "active", to, nil)
+	mock.ExpectQuery(`FROM employees_history WHERE employee_id = \$1`).
+		WithArgs(5).
+		WillReturnRows(rows)
+
+	versions, err := repo.Employee.GetHistory(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(versions) != 2 || versions[0].EmployeeID != 5 || versions[0].Name != "Old" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	if versions[0].ValidTo == nil || !versions[0].ValidTo.Equal(to) || versions[1].ValidTo != nil {
+		t.Fatalf("unexpected validity: %+v, %+v", versions[0], versions[1])
+	}
+}
+
+func TestGetHistory_NotFound(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`FROM employees_history`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "phone", "city", "status", "valid_from", "valid_to"}))
+
+	_, err := repo.Employee.GetHistory(context.Background(), 5)
+	var nf *repository.NotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestGetAsOf_BeforeCreation(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	at := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`valid_from <= \$2 AND valid_to > \$2`).
+		WithArgs(5, at).
+		WillReturnRows(sqlmock.NewRows([]string{"employee_id", "name", "phone", "city", "status"}))
+
+	_, err := repo.Employee.GetAsOf(context.Background(), 5, at)
+	var nf *repository.NotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestDeleteMany_ReturnsDeletedIDs(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	// удаление мягкое: запись получает deleted_at, уже удаленные пропускаются
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE employees SET deleted_at = CURRENT_TIMESTAMP WHERE id = ANY($1) AND deleted_at IS NULL RETURNING id`)).
+		WithArgs(pq.Int64Array{1, 2, 3}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(1))
+
+	deleted, err := repo.Employee.DeleteMany(context.Background(), []int64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("DeleteMany: %v", err)
+	}
+	if len(deleted) != 2 || deleted[0] != 3 || deleted[1] != 1 {
+		t.Fatalf("unexpected deleted ids: %v", deleted)
+	}
+}
+
+func TestUpdateBatch(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees AS e .*FROM unnest\(\$1::bigint\[\], \$2::text\[\], \$3::text\[\], \$4::text\[\], \$5::text\[\]\) .*RETURNING e.id`).
+		WithArgs(pq.Int64Array{1, 2}, pq.StringArray{"Alice", "Bob"}, pq.StringArray{"+77010000001", "+77010000002"},
+			pq.StringArray{"Almaty", "Astana"}, pq.StringArray{"hr-bot", ""}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	updated, err := repo.Employee.UpdateBatch(context.Background(), []*domain.Employee{
+		{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty", UpdatedBy: "hr-bot"},
+		{ID: 2, Name: "Bob", Phone: "+77010000002", City: "Astana"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateBatch: %v", err)
+	}
+	if fmt.Sprint(updated) != "[2]" {
+		t.Fatalf("unexpected updated ids: %v", updated)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpdateBatch_UniqueViolation(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`UPDATE employees AS e`).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "employees_phone_key"})
+
+	_, err := repo.Employee.UpdateBatch(context.Background(), []*domain.Employee{{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty"}})
+	var exists *repository.AlreadyExistsError
+	if !errors.As(err, &exists) || exists.Field != "phone" {
+		t.Fatalf("want AlreadyExistsError, got %v", err)
+	}
+}
+
+func TestExistingIDs(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM employees WHERE id = ANY($1)`)).
+		WithArgs(pq.Int64Array{4, 5}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+	existing, err := repo.Employee.ExistingIDs(context.Background(), []int64{4, 5})
+	if err != nil {
+		t.Fatalf("ExistingIDs: %v", err)
+	}
+	if len(existing) != 1 || existing[0] != 5 {
+		t.Fatalf("unexpected ids: %v", existing)
+	}
+}
+
+func TestGetByIDs_MissingIDsSkipped(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	updated := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	birthDate := time.Date(1990, 2, 28, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, phone, city, status, created_by, updated_by, COALESCE(updated_at, created_at), version, birth_date, hire_date FROM employees WHERE id = ANY($1)`)).
+		WithArgs(pq.Int64Array{3, 99, 1}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "updated_at", "version", "birth_date", "hire_date"}).
+			AddRow(1, "John", "+77010000001", "Almaty", "active", "", "", updated, 1, nil, nil).
+			AddRow(3, "Asel", "+77010000003", "Astana", "active", "", "", updated, 2, birthDate, nil))
+
+	employees, err := repo.Employee.GetByIDs(context.Background(), []int64{3, 99, 1})
+	if err != nil {
+		t.Fatalf("GetByIDs: %v", err)
+	}
+	if len(employees) != 2 {
+		t.Fatalf("expected 2 employees, got %d", len(employees))
+	}
+	if employees[0].ID != 3 || employees[1].ID != 1 {
+		t.Fatalf("expected request order [3 1], got [%d %d]", employees[0].ID, employees[1].ID)
+	}
+	if employees[0].Version != 2 || !employees[0].UpdatedAt.Equal(updated) || domain.FormatDate(employees[0].BirthDate) != "1990-02-28" || employees[0].HireDate != nil {
+		t.Fatalf("unexpected employee: %+v", employees[0])
+	}
+	if employees[1].BirthDate != nil {
+		t.Fatalf("expected no birth date for NULL, got %v", employees[1].BirthDate)
+	}
+}
+
+func TestPreparedStatements_UsedAndClosed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	create := mock.ExpectPrepare(regexp.QuoteMeta(`INSERT INTO employees`)).WillBeClosed()
+	getByID := mock.ExpectPrepare(regexp.QuoteMeta(`FROM employees WHERE id = $1`)).WillBeClosed()
+	mock.ExpectPrepare(regexp.QuoteMeta(`FROM employees WHERE phone = $1`)).WillBeClosed()
+
+	repos := repository.NewRepositories(db, zap.NewNop())
+
+	create.ExpectQuery().
+		WithArgs("John", "+77010000001", "Almaty", "", "", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	getByID.ExpectQuery().
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "updated_at", "version", "birth_date", "hire_date"}).
+			AddRow(7, "John", "+77010000001", "Almaty", "active", "system", "system", time.Now(), 1, nil, nil))
+
+	ctx := context.Background()
+	e := &domain.Employee{Name: "John", Phone: "+77010000001", City: "Almaty"}
+	if err := repos.Employee.Create(ctx, e); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repos.Employee.GetByID(ctx, e.ID); err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if err := repos.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTablePrefix_QueriesUsePrefixedTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	prepare := mock.ExpectPrepare(regexp.QuoteMeta(`INSERT INTO tenant1_employees (name, phone, city, status, created_by, updated_by, birth_date, hire_date)`))
+	mock.ExpectPrepare(regexp.QuoteMeta(`FROM tenant1_employees WHERE id = $1`))
+	mock.ExpectPrepare(regexp.QuoteMeta(`FROM tenant1_employees WHERE phone = $1`))
+	repos := repository.NewRepositoriesWithOptions(db, zap.NewNop(), repository.Options{TablePrefix: "tenant1_"})
+
+	prepare.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO tenant1_employees_history (employee_id, name, phone, city, status, valid_from, valid_to)`) +
+		`(.|\n)*` + regexp.QuoteMeta(`FROM tenant1_employees WHERE id = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tenant1_employees WHERE id = ANY($1)`)).
+		WithArgs(pq.Int64Array{1}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	ctx := context.Background()
+	if err := repos.Employee.Create(ctx, &domain.Employee{Name: "John", Phone: "+77010000001", City: "Almaty"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repos.Employee.ArchiveVersion(ctx, 1); err != nil {
+		t.Fatalf("ArchiveVersion: %v", err)
+	}
+	if _, err := repos.Employee.ExistingIDs(ctx, []int64{1}); err != nil {
+		t.Fatalf("ExistingIDs: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// lockKeyArg запоминает ключ advisory lock, переданный в запрос
+type lockKeyArg struct{ key *int64 }
+
+func (a lockKeyArg) Match(v driver.Value) bool {
+	key, ok := v.(int64)
+	*a.key = key
+	return ok
+}
+
+func TestTablePrefix_PurgeLockKeyPerPrefix(t *testing.T) {
+	purgeLockKey := func(prefix string) int64 {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New: %v", err)
+		}
+		defer db.Close()
+		mock.MatchExpectationsInOrder(false)
+		for i := 0; i < 3; i++ {
+			mock.ExpectPrepare(`.*`).WillBeClosed()
+		}
+		repos := repository.NewRepositoriesWithOptions(db, zap.NewNop(), repository.Options{TablePrefix: prefix})
+
+		var key int64
+		mock.ExpectQuery(`pg_try_advisory_xact_lock`).
+			WithArgs(sqlmock.AnyArg(), lockKeyArg{&key}).
+			WillReturnRows(sqlmock.NewRows([]string{"locked", "count"}).AddRow(true, 0))
+		if _, err := repos.Employee.PurgeSoftDeleted(context.Background(), time.Now()); err != nil {
+			t.Fatalf("PurgeSoftDeleted(%q): %v", prefix, err)
+		}
+		return key
+	}
+
+	base, tenant1, tenant2 := purgeLockKey(""), purgeLockKey("tenant1_"), purgeLockKey("tenant2_")
+	if base == tenant1 || base == tenant2 || tenant1 == tenant2 {
+		t.Fatalf("expected distinct lock keys per prefix, got %d, %d, %d", base, tenant1, tenant2)
+	}
+	if again := purgeLockKey("tenant1_"); again != tenant1 {
+		t.Fatalf("lock key must be stable for a prefix: %d != %d", again, tenant1)
+	}
+}
+
+func TestReplica_ReadsGoToReplicaWritesToPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer replica.Close()
+
+	repos := repository.NewRepositoriesWithOptions(primary, zap.NewNop(), repository.Options{Replica: replica})
+	ctx := context.Background()
+
+	replicaMock.ExpectQuery(`SELECT id, name, phone, city, status, created_by, updated_by, COALESCE\(updated_at, created_at\), version, birth_date, hire_date FROM employees WHERE id = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "updated_at", "version", "birth_date", "hire_date"}).AddRow(1, "Alice", "+77010000001", "Almaty", "active", "system", "system", time.Now(), 1, nil, nil))
+	replicaMock.ExpectQuery(`FROM employees`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by"}))
+	replicaMock.ExpectQuery(`total_count`).
+		WillReturnRows(sqlmock.NewRows([]string{"total_count", "cities_count", "most_common_city", "active_count", "inactive_count", "on_leave_count"}).
+			AddRow(1, 1, "Almaty", 1, 0, 0))
+	primaryMock.ExpectQuery(`UPDATE employees`).
+		WithArgs(1, "Alice", "+77010000001", "Astana", "", "", 1, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_by", "updated_by", "version", "birth_date", "hire_date"}).AddRow("active", "system", "system", 2, nil, nil))
+
+	if _, err := repos.Employee.GetByID(ctx, 1); err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if _, err := repos.Employee.GetAll(ctx, domain.EmployeeFilter{}, 0); err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if _, err := repos.Employee.GetEmployeeStats(ctx); err != nil {
+		t.Fatalf("GetEmployeeStats: %v", err)
+	}
+	if err := repos.Employee.Update(ctx, &domain.Employee{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Astana", Version: 1}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("replica: %v", err)
+	}
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("primary: %v", err)
+	}
+}
+
+func TestReplica_TransactionUsesPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer replica.Close()
+
+	repos := repository.NewRepositoriesWithOptions(primary, zap.NewNop(), repository.Options{Replica: replica})
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectQuery(`SELECT id, name, phone, city, status, created_by, updated_by, COALESCE\(updated_at, created_at\), version, birth_date, hire_date FROM employees WHERE id = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "updated_at", "version", "birth_date", "hire_date"}).AddRow(1, "Alice", "+77010000001", "Almaty", "active", "system", "system", time.Now(), 1, nil, nil))
+	primaryMock.ExpectCommit()
+
+	err = repos.UnitOfWork.WithTx(context.Background(), func(repo repository.EmployeeRepository) error {
+		_, err := repo.GetByID(context.Background(), 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("primary: %v", err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("replica: %v", err)
+	}
+}
+
+func TestGetUpcomingAnniversaries(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	from := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	birth := time.Date(1990, 1, 3, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`CROSS JOIN LATERAL \(VALUES \('birthday', e.birth_date\), \('hire', e.hire_date\)\)`).
+		WithArgs(from, 14).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone", "city", "status", "created_by", "updated_by", "birth_date", "hire_date", "kind", "next_date", "years"}).
+			AddRow(1, "Alice", "+77010000001", "Almaty", "active", "system", "system", birth, nil, "birthday", time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), 35))
+
+	anniversaries, err := repo.Employee.GetUpcomingAnniversaries(context.Background(), from, 14)
+	if err != nil {
+		t.Fatalf("GetUpcomingAnniversaries: %v", err)
+	}
+	if len(anniversaries) != 1 {
+		t.Fatalf("expected 1 anniversary, got %d", len(anniversaries))
+	}
+	a := anniversaries[0]
+	if a.Kind != domain.AnniversaryBirthday || domain.FormatDate(&a.Date) != "2025-01-03" || a.Years != 35 ||
+		a.Employee.Name != "Alice" || !a.Employee.BirthDate.Equal(birth) || a.Employee.HireDate != nil {
+		t.Fatalf("unexpected anniversary: %+v %+v", a, a.Employee)
+	}
+}
+
+func TestMerge_ReassignAndSave(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE idempotency_keys SET employee_id = $2 WHERE employee_id = $1`)).
+		WithArgs(7, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE employee_merges SET target_id = $2 WHERE target_id = $1`)).
+		WithArgs(7, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE employee_attendance a SET employee_id = \$2 WHERE employee_id = \$1\s+AND NOT EXISTS`).
+		WithArgs(7, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+	mergedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`INSERT INTO employee_merges`).
+		WithArgs(7, 3, "Alice", "+77010000007", "Almaty", "birth_date,hire_date", "hr").
+		WillReturnRows(sqlmock.NewRows([]string{"merged_at"}).AddRow(mergedAt))
+
+	ctx := context.Background()
+	if err := repo.Employee.ReassignEmployee(ctx, 7, 3); err != nil {
+		t.Fatalf("ReassignEmployee: %v", err)
+	}
+	merge := &domain.EmployeeMerge{
+		SourceID:     7,
+		TargetID:     3,
+		Source:       &domain.Employee{Name: "Alice", Phone: "+77010000007", City: "Almaty"},
+		CopiedFields: []string{"birth_date", "hire_date"},
+		MergedBy:     "hr",
+	}
+	if err := repo.Employee.SaveMerge(ctx, merge); err != nil {
+		t.Fatalf("SaveMerge: %v", err)
+	}
+	if !merge.MergedAt.Equal(mergedAt) {
+		t.Fatalf("expected merged_at from db, got %v", merge.MergedAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`GROUP BY first_id, second_id(.|\n)*LIMIT \$2`).
+		WithArgs(10, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"reasons", "id", "name", "phone", "city", "status", "id", "name", "phone", "city", "status"}).
+			AddRow("name_city,phone_prefix", 1, "Alice", "+77010000001", "Almaty", "active", 4, "alice", "+77010000002", "almaty", "active"))
+
+	pairs, err := repo.Employee.FindDuplicates(context.Background(), 10, 50)
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+	if len(pairs) != 1 || pairs[0].First.ID != 1 || pairs[0].Second.ID != 4 {
+		t.Fatalf("unexpected pairs %+v", pairs)
+	}
+	if len(pairs[0].Reasons) != 2 || pairs[0].Reasons[0] != domain.DuplicateNameCity || pairs[0].Reasons[1] != domain.DuplicatePhonePrefix {
+		t.Fatalf("unexpected reasons %v", pairs[0].Reasons)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpsertAttendance(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	date := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 10, 5, 9, 30, 0, 0, time.UTC)
+	mock.ExpectQuery(`INSERT INTO employee_attendance(.|\n)*ON CONFLICT \(employee_id, date\) DO UPDATE`).
+		WithArgs(3, date, "sick", "температура").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(created, updated))
+
+	attendance := &domain.Attendance{EmployeeID: 3, Date: date, Status: domain.AttendanceSick, Note: "температура"}
+	if err := repo.Employee.UpsertAttendance(context.Background(), attendance); err != nil {
+		t.Fatalf("UpsertAttendance: %v", err)
+	}
+	if !attendance.CreatedAt.Equal(created) || !attendance.UpdatedAt.Equal(updated) {
+		t.Fatalf("unexpected timestamps %+v", attendance)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestUpsertAttendance_EmployeeNotFound(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	mock.ExpectQuery(`INSERT INTO employee_attendance`).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "employee_attendance_employee_id_fkey"})
+
+	attendance := &domain.Attendance{EmployeeID: 42, Date: time.Now(), Status: domain.AttendancePresent}
+	err := repo.Employee.UpsertAttendance(context.Background(), attendance)
+	var nf *repository.NotFoundError
+	if !errors.As(err, &nf) || nf.ID != 42 {
+		t.Fatalf("expected NotFoundError for 42, got %v", err)
+	}
+
+	// мягко удаленный сотрудник: внешний ключ проходит, но вставка не выполняется
+	mock.ExpectQuery(`INSERT INTO employee_attendance(.|\n)*WHERE EXISTS \(SELECT 1 FROM employees WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}))
+	if err := repo.Employee.UpsertAttendance(context.Background(), attendance); !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError for deleted employee, got %v", err)
+	}
+}
+
+func TestGetAttendance(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	stamp := time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC)
+	columns := []string{"date", "status", "note", "created_at", "updated_at"}
+	mock.ExpectQuery(`LEFT JOIN employee_attendance a(.|\n)*ORDER BY a.date`).
+		WithArgs(3, from, to).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), "present", "", stamp, stamp).
+			AddRow(time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), "sick", "справка", stamp, stamp))
+	mock.ExpectQuery(`LEFT JOIN employee_attendance a`).
+		WithArgs(4, from, to).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(nil, nil, nil, nil, nil))
+	mock.ExpectQuery(`LEFT JOIN employee_attendance a`).
+		WithArgs(5, from, to).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	ctx := context.Background()
+	records, err := repo.Employee.GetAttendance(ctx, 3, from, to)
+	if err != nil {
+		t.Fatalf("GetAttendance: %v", err)
+	}
+	if len(records) != 2 || records[0].EmployeeID != 3 || records[1].Status != domain.AttendanceSick || records[1].Note != "справка" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	// Сотрудник без отметок за период — пустой список
+	records, err = repo.Employee.GetAttendance(ctx, 4, from, to)
+	if err != nil || records == nil || len(records) != 0 {
+		t.Fatalf("expected empty list, got %v %v", records, err)
+	}
+
+	// Отсутствующий сотрудник
+	_, err = repo.Employee.GetAttendance(ctx, 5, from, to)
+	var nf *repository.NotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestGetAttendanceSummary(t *testing.T) {
+	repo, mock, done := newRepo(t)
+	defer done()
+
+	date := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	// отметки удаленных сотрудников не учитываются
+	mock.ExpectQuery(`COUNT\(\*\) FILTER \(WHERE a.status = 'present'\)(.|\n)*FROM employee_attendance a\s+JOIN employees e ON e.id = a.employee_id AND e.deleted_at IS NULL`).
+		WithArgs(date).
+		WillReturnRows(sqlmock.NewRows([]string{"present", "absent", "sick"}).AddRow(12, 3, 1))
+
+	summary, err := repo.Employee.GetAttendanceSummary(context.Background(), date)
+	if err != nil {
+		t.Fatalf("GetAttendanceSummary: %v", err)
+	}
+	if summary.Present != 12 || summary.Absent != 3 || summary.Sick != 1 || summary.Total() != 16 || !summary.Date.Equal(date) {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+	"employer/internal/repository/seedtest"
+
+	"go.uber.org/zap"
+)
+
+// Подсчет по фильтру совпадает со списком по тому же фильтру на наборе seedtest
+func TestIntegration_CountWithFilterOnSeed(t *testing.T) {
+	db := openIntegrationDB(t)
+	employees := seedtest.Load(t, db, 100)
+	repo := repository.NewEmployeeRepository(db, zap.NewNop())
+	ctx := context.Background()
+
+	byCity := make(map[string]int64)
+	for _, e := range employees {
+		if e.ID == 0 {
+			t.Fatalf("seedtest.Load не назначил ID сотруднику %s", e.Name)
+		}
+		byCity[e.City]++
+	}
+
+	total, err := repo.CountWithFilter(ctx, domain.EmployeeFilter{})
+	if err != nil {
+		t.Fatalf("CountWithFilter: %v", err)
+	}
+	if total != int64(len(employees)) {
+		t.Fatalf("want %d employees, got %d", len(employees), total)
+	}
+
+	for city, want := range byCity {
+		filter := domain.EmployeeFilter{City: city}
+		count, err := repo.CountWithFilter(ctx, filter)
+		if err != nil {
+			t.Fatalf("CountWithFilter(%s): %v", city, err)
+		}
+		list, err := repo.GetAll(ctx, filter, 0)
+		if err != nil {
+			t.Fatalf("GetAll(%s): %v", city, err)
+		}
+		if count != want || int64(len(list)) != want {
+			t.Fatalf("%s: want %d, count %d, list %d", city, want, count, len(list))
+		}
+	}
+}
@@ -0,0 +1,32 @@
+// Package seedtest заполняет БД интеграционных тестов тестовыми сотрудниками
+package seedtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+	"employer/internal/seed"
+
+	"go.uber.org/zap"
+)
+
+// Load создает в db n сотрудников seed.Employees(n, seed.DefaultSeed) и возвращает их
+// с назначенными ID. Записи создаются напрямую репозиторием, без проверок сервиса:
+// набор заранее корректен, а тесты репозитория не должны зависеть от сервиса.
+// Одинаковое n дает одинаковых сотрудников при каждом запуске
+func Load(t testing.TB, db *sql.DB, n int) []*domain.Employee {
+	t.Helper()
+
+	repo := repository.NewEmployeeRepository(db, zap.NewNop())
+	employees := seed.Employees(n, seed.DefaultSeed)
+	for i, e := range employees {
+		e.CreatedBy = seed.Actor
+		if err := repo.Create(context.Background(), e); err != nil {
+			t.Fatalf("seedtest: создание сотрудника %d (%s): %v", i+1, e.Name, err)
+		}
+	}
+	return employees
+}
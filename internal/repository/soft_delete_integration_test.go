@@ -0,0 +1,65 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/repository"
+	"employer/internal/repository/seedtest"
+
+	"go.uber.org/zap"
+)
+
+func TestIntegration_SoftDelete(t *testing.T) {
+	db := openIntegrationDB(t)
+	employees := seedtest.Load(t, db, 2)
+	repos := repository.NewRepositories(db, zap.NewNop())
+	ctx := context.Background()
+	deleted := employees[0]
+
+	if err := repos.Employee.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Удаленный сотрудник пропадает из чтения, изменения и повторного удаления
+	var nf *repository.NotFoundError
+	if _, err := repos.Employee.GetByID(ctx, deleted.ID); !errors.As(err, &nf) {
+		t.Fatalf("GetByID: expected NotFoundError, got %v", err)
+	}
+	if err := repos.Employee.UpdateStatus(ctx, deleted.ID, domain.StatusInactive, "admin"); !errors.As(err, &nf) {
+		t.Fatalf("UpdateStatus: expected NotFoundError, got %v", err)
+	}
+	if err := repos.Employee.Delete(ctx, deleted.ID); !errors.As(err, &nf) {
+		t.Fatalf("second Delete: expected NotFoundError, got %v", err)
+	}
+	if count, err := repos.Employee.CountWithFilter(ctx, domain.EmployeeFilter{}); err != nil || count != 1 {
+		t.Fatalf("CountWithFilter: expected 1, got %d, %v", count, err)
+	}
+
+	// Телефон удаленного сотрудника снова свободен
+	if exists, err := repos.Employee.CheckPhoneExists(ctx, deleted.Phone); err != nil || exists {
+		t.Fatalf("CheckPhoneExists: expected free phone, got %v, %v", exists, err)
+	}
+	again := &domain.Employee{Name: deleted.Name, Phone: deleted.Phone, City: deleted.City}
+	if err := repos.Employee.Create(ctx, again); err != nil {
+		t.Fatalf("Create with deleted employee's phone: %v", err)
+	}
+
+	// Запись остается в таблице до очистки
+	var rows int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM employees WHERE id = $1`, deleted.ID).Scan(&rows); err != nil || rows != 1 {
+		t.Fatalf("expected soft-deleted row to stay until purge, got %d, %v", rows, err)
+	}
+	purged, err := repos.Employee.PurgeSoftDeleted(ctx, time.Now().Add(24*time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeSoftDeleted: expected 1, got %d, %v", purged, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM employees WHERE id = $1`, deleted.ID).Scan(&rows); err != nil || rows != 0 {
+		t.Fatalf("expected row to be purged, got %d, %v", rows, err)
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"employer/internal/domain"
+)
+
+// DefaultSortLocale локаль сортировки имен по умолчанию
+const DefaultSortLocale = "und"
+
+// sortCollations поддерживаемые локали сортировки (SORT_LOCALE) и collation PostgreSQL.
+// ICU-правила (нужен PostgreSQL со сборкой ICU) ставят "Ábai" рядом с "Abai",
+// а не после "Zarina"; C — побайтовое сравнение.
+var sortCollations = map[string]string{
+	// und корневые правила ICU: латиница, затем кириллица
+	"und": "und-x-icu",
+	// ru русский алфавит: кириллица перед латиницей
+	"ru": "ru-x-icu",
+	// kk казахский алфавит: Ә после А, Қ после К и т.д.
+	"kk": "kk-x-icu",
+	"C":  "C",
+}
+
+// SortLocales возвращает поддерживаемые значения SORT_LOCALE
+func SortLocales() []string {
+	locales := make([]string, 0, len(sortCollations))
+	for locale := range sortCollations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SortCollation возвращает collation PostgreSQL для локали сортировки
+func SortCollation(locale string) (string, error) {
+	collation, ok := sortCollations[locale]
+	if !ok {
+		return "", fmt.Errorf("неизвестная локаль сортировки %q, допустимо: %s",
+			locale, strings.Join(SortLocales(), ", "))
+	}
+	return collation, nil
+}
+
+// orderClause выражение ORDER BY для сортировки filter.Sort; без сортировки — по имени.
+// Последним всегда идет id, чтобы порядок страниц был устойчивым
+func (r *employeeRepository) orderClause(filter domain.EmployeeFilter) string {
+	dir := ""
+	if filter.Desc {
+		dir = " DESC"
+	}
+	switch filter.Sort {
+	case domain.SortID:
+		return "id" + dir
+	case domain.SortCity:
+		return "lower(city)" + dir + ", id" + dir
+	case domain.SortCreatedAt:
+		return "created_at" + dir + ", id" + dir
+	default:
+		return r.nameOrder + dir + ", id" + dir
+	}
+}
+
+// nameOrderExpr выражение сортировки по имени без учета регистра. Совпадает с
+// выражением индекса из database.CreateSortIndex, поэтому сортировка использует индекс.
+func nameOrderExpr(collation string) string {
+	return fmt.Sprintf(`lower(name) COLLATE "%s"`, collation)
+}
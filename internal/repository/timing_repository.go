@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"employer/internal/domain"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// TimingOptions настройки замера длительности запросов
+type TimingOptions struct {
+	// SlowThreshold порог, после которого запрос логируется как медленный; 0 отключает лог
+	SlowThreshold time.Duration
+	// Histogram гистограмма длительности с меткой operation; nil — без метрик
+	Histogram *prometheus.HistogramVec
+}
+
+// NewQueryDurationHistogram создает гистограмму длительности запросов к БД по операциям.
+// Регистрация в реестре Prometheus остается за вызывающим кодом.
+func NewQueryDurationHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "employer_db_query_duration_seconds",
+		Help:    "Длительность запросов репозитория сотрудников",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+}
+
+// timingRepository декоратор EmployeeRepository, замеряющий длительность каждого
+// метода: пишет ее в гистограмму и логирует медленные запросы
+type timingRepository struct {
+	next   EmployeeRepository
+	logger *zap.Logger
+	opts   TimingOptions
+}
+
+// NewTimingRepository оборачивает next замером длительности запросов
+func NewTimingRepository(next EmployeeRepository, logger *zap.Logger, opts TimingOptions) EmployeeRepository {
+	return &timingRepository{
+		next:   next,
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// observe фиксирует длительность операции, начатой в start
+func (r *timingRepository) observe(operation string, start time.Time) {
+	duration := time.Since(start)
+
+	if r.opts.Histogram != nil {
+		r.opts.Histogram.WithLabelValues(operation).Observe(duration.Seconds())
+	}
+
+	if r.opts.SlowThreshold > 0 && duration > r.opts.SlowThreshold {
+		r.logger.Warn("медленный запрос к БД",
+			zap.String("operation", operation),
+			zap.Duration("duration", duration),
+			zap.Duration("threshold", r.opts.SlowThreshold))
+	}
+}
+
+func (r *timingRepository) Create(ctx context.Context, employee *domain.Employee) error {
+	defer r.observe("Create", time.Now())
+	return r.next.Create(ctx, employee)
+}
+
+func (r *timingRepository) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
+	defer r.observe("GetByID", time.Now())
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *timingRepository) GetAll(ctx context.Context, filter domain.EmployeeFilter, maxRows int) ([]*domain.Employee, error) {
+	defer r.observe("GetAll", time.Now())
+	return r.next.GetAll(ctx, filter, maxRows)
+}
+
+func (r *timingRepository) ArchiveVersion(ctx context.Context, id int64) error {
+	defer r.observe("ArchiveVersion", time.Now())
+	return r.next.ArchiveVersion(ctx, id)
+}
+
+func (r *timingRepository) GetHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+	defer r.observe("GetHistory", time.Now())
+	return r.next.GetHistory(ctx, id)
+}
+
+func (r *timingRepository) GetAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+	defer r.observe("GetAsOf", time.Now())
+	return r.next.GetAsOf(ctx, id, at)
+}
+
+func (r *timingRepository) UpdateBatch(ctx context.Context, employees []*domain.Employee) ([]int64, error) {
+	defer r.observe("UpdateBatch", time.Now())
+	return r.next.UpdateBatch(ctx, employees)
+}
+
+func (r *timingRepository) DeleteMany(ctx context.Context, ids []int64) ([]int64, error) {
+	defer r.observe("DeleteMany", time.Now())
+	return r.next.DeleteMany(ctx, ids)
+}
+
+func (r *timingRepository) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+	defer r.observe("GetByIDs", time.Now())
+	return r.next.GetByIDs(ctx, ids)
+}
+
+func (r *timingRepository) ExistingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	defer r.observe("ExistingIDs", time.Now())
+	return r.next.ExistingIDs(ctx, ids)
+}
+
+func (r *timingRepository) GetRecent(ctx context.Context, limit int) ([]*domain.Employee, error) {
+	defer r.observe("GetRecent", time.Now())
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *timingRepository) GetByCreatedRange(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+	defer r.observe("GetByCreatedRange", time.Now())
+	return r.next.GetByCreatedRange(ctx, from, to)
+}
+
+func (r *timingRepository) GetPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, error) {
+	defer r.observe("GetPage", time.Now())
+	return r.next.GetPage(ctx, filter, limit, offset)
+}
+
+func (r *timingRepository) CountWithFilter(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	defer r.observe("CountWithFilter", time.Now())
+	return r.next.CountWithFilter(ctx, filter)
+}
+
+func (r *timingRepository) Update(ctx context.Context, employee *domain.Employee) error {
+	defer r.observe("Update", time.Now())
+	return r.next.Update(ctx, employee)
+}
+
+func (r *timingRepository) GetIdempotencyKey(ctx context.Context, key string, since time.Time) (*IdempotencyRecord, error) {
+	defer r.observe("GetIdempotencyKey", time.Now())
+	return r.next.GetIdempotencyKey(ctx, key, since)
+}
+
+func (r *timingRepository) SaveIdempotencyKey(ctx context.Context, record *IdempotencyRecord, since time.Time) (bool, error) {
+	defer r.observe("SaveIdempotencyKey", time.Now())
+	return r.next.SaveIdempotencyKey(ctx, record, since)
+}
+
+func (r *timingRepository) UpdateStatus(ctx context.Context, id int64, status, updatedBy string) error {
+	defer r.observe("UpdateStatus", time.Now())
+	return r.next.UpdateStatus(ctx, id, status, updatedBy)
+}
+
+func (r *timingRepository) Delete(ctx context.Context, id int64) error {
+	defer r.observe("Delete", time.Now())
+	return r.next.Delete(ctx, id)
+}
+
+func (r *timingRepository) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	defer r.observe("SearchEmployees", time.Now())
+	return r.next.SearchEmployees(ctx, searchQuery, filter, limit, offset)
+}
+
+func (r *timingRepository) GetByPhone(ctx context.Context, phone string) (*domain.Employee, error) {
+	defer r.observe("GetByPhone", time.Now())
+	return r.next.GetByPhone(ctx, phone)
+}
+
+func (r *timingRepository) GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error) {
+	defer r.observe("GetEmployeesByCity", time.Now())
+	return r.next.GetEmployeesByCity(ctx, city)
+}
+
+func (r *timingRepository) GetCityCounts(ctx context.Context) ([]*domain.CityCount, error) {
+	defer r.observe("GetCityCounts", time.Now())
+	return r.next.GetCityCounts(ctx)
+}
+
+func (r *timingRepository) GetUpcomingAnniversaries(ctx context.Context, from time.Time, withinDays int) ([]*domain.Anniversary, error) {
+	defer r.observe("GetUpcomingAnniversaries", time.Now())
+	return r.next.GetUpcomingAnniversaries(ctx, from, withinDays)
+}
+
+// GetAllCursor замеряется вместе с обработкой строк в fn (например записью ответа)
+func (r *timingRepository) GetAllCursor(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	defer r.observe("GetAllCursor", time.Now())
+	return r.next.GetAllCursor(ctx, filter, fn)
+}
+
+// StreamEmployees замеряется вместе с обработкой строк в fn (например записью ответа)
+func (r *timingRepository) StreamEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	defer r.observe("StreamEmployees", time.Now())
+	return r.next.StreamEmployees(ctx, filter, fn)
+}
+
+func (r *timingRepository) CreateWithID(ctx context.Context, employee *domain.Employee) error {
+	defer r.observe("CreateWithID", time.Now())
+	return r.next.CreateWithID(ctx, employee)
+}
+
+func (r *timingRepository) SyncIDSequence(ctx context.Context) error {
+	defer r.observe("SyncIDSequence", time.Now())
+	return r.next.SyncIDSequence(ctx)
+}
+
+func (r *timingRepository) ReassignEmployee(ctx context.Context, fromID, toID int64) error {
+	defer r.observe("ReassignEmployee", time.Now())
+	return r.next.ReassignEmployee(ctx, fromID, toID)
+}
+
+func (r *timingRepository) SaveMerge(ctx context.Context, merge *domain.EmployeeMerge) error {
+	defer r.observe("SaveMerge", time.Now())
+	return r.next.SaveMerge(ctx, merge)
+}
+
+func (r *timingRepository) FindDuplicates(ctx context.Context, phoneDigits, limit int) ([]*domain.DuplicatePair, error) {
+	defer r.observe("FindDuplicates", time.Now())
+	return r.next.FindDuplicates(ctx, phoneDigits, limit)
+}
+
+func (r *timingRepository) UpsertAttendance(ctx context.Context, attendance *domain.Attendance) error {
+	defer r.observe("UpsertAttendance", time.Now())
+	return r.next.UpsertAttendance(ctx, attendance)
+}
+
+func (r *timingRepository) GetAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+	defer r.observe("GetAttendance", time.Now())
+	return r.next.GetAttendance(ctx, id, from, to)
+}
+
+func (r *timingRepository) GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+	defer r.observe("GetAttendanceSummary", time.Now())
+	return r.next.GetAttendanceSummary(ctx, date)
+}
+
+func (r *timingRepository) PurgeSoftDeleted(ctx context.Context, olderThan time.Time) (int64, error) {
+	defer r.observe("PurgeSoftDeleted", time.Now())
+	return r.next.PurgeSoftDeleted(ctx, olderThan)
+}
+
+func (r *timingRepository) Truncate(ctx context.Context) error {
+	defer r.observe("Truncate", time.Now())
+	return r.next.Truncate(ctx)
+}
+
+func (r *timingRepository) GetEmployeeStats(ctx context.Context) (*EmployeeStats, error) {
+	defer r.observe("GetEmployeeStats", time.Now())
+	return r.next.GetEmployeeStats(ctx)
+}
+
+func (r *timingRepository) CheckPhoneExists(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+	defer r.observe("CheckPhoneExists", time.Now())
+	return r.next.CheckPhoneExists(ctx, phone, excludeID...)
+}
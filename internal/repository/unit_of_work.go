@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// DBTX общие методы *sql.DB и *sql.Tx, которых достаточно репозиториям
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// UnitOfWork выполняет несколько вызовов репозитория в одной транзакции
+type UnitOfWork interface {
+	// WithTx вызывает fn с репозиторием, работающим в транзакции. Транзакция
+	// фиксируется, если fn вернула nil, иначе откатывается.
+	WithTx(ctx context.Context, fn func(repo EmployeeRepository) error) error
+}
+
+// transactionalRepository реализация UnitOfWork поверх *sql.DB
+type transactionalRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+	// newRepo создает репозиторий поверх транзакции
+	newRepo func(db DBTX) EmployeeRepository
+}
+
+// NewUnitOfWork создает UnitOfWork для репозитория сотрудников
+func NewUnitOfWork(db *sql.DB, logger *zap.Logger) UnitOfWork {
+	return &transactionalRepository{
+		db:     db,
+		logger: logger,
+		newRepo: func(db DBTX) EmployeeRepository {
+			return NewEmployeeRepository(db, logger)
+		},
+	}
+}
+
+// WithTx выполняет fn в транзакции. Паника в fn откатывает транзакцию и пробрасывается дальше.
+func (r *transactionalRepository) WithTx(ctx context.Context, fn func(repo EmployeeRepository) error) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		LoggerFromContext(ctx, r.logger).Error("ошибка начала транзакции", zap.Error(err))
+		return fmt.Errorf("начало транзакции: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(r.newRepo(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			LoggerFromContext(ctx, r.logger).Error("ошибка отката транзакции", zap.Error(rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		LoggerFromContext(ctx, r.logger).Error("ошибка фиксации транзакции", zap.Error(err))
+		return fmt.Errorf("фиксация транзакции: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package seed генерирует правдоподобных сотрудников для разработки: казахские имена,
+// мобильные номера Казахстана и реальные города. Набор детерминирован: случайность
+// берется только из math/rand с явным источником rand.NewSource(seed), последовательность
+// которого не меняется между версиями Go, поэтому одинаковые n и seed всегда дают
+// одних и тех же сотрудников в одном порядке
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/service"
+)
+
+// DefaultSeed начальное значение генератора для команды seed, SEED_ON_START и seedtest
+const DefaultSeed int64 = 1
+
+// DefaultCount количество сотрудников, загружаемых по умолчанию
+const DefaultCount = 100
+
+// Actor автор записей, созданных загрузкой (created_by)
+const Actor = "seed"
+
+var (
+	maleNames = []string{
+		"Нурлан", "Ерлан", "Асхат", "Бауыржан", "Данияр", "Ержан", "Мадияр", "Нурсултан", "Арман", "Айдос",
+		"Серик", "Талгат", "Жандос", "Кайрат", "Темирлан", "Ерасыл", "Санжар", "Алибек", "Дастан", "Бекзат",
+	}
+	femaleNames = []string{
+		"Айгерим", "Динара", "Жанар", "Асель", "Гульнара", "Мадина", "Аружан", "Салтанат", "Камила", "Жансая",
+		"Айжан", "Дана", "Меруерт", "Назерке", "Томирис", "Алия", "Гаухар", "Индира", "Сабина", "Акбота",
+	}
+	// surnames фамилии в мужской форме; женская образуется окончанием "а"
+	surnames = []string{
+		"Ахметов", "Сулейменов", "Жумабаев", "Нурланов", "Серикбаев", "Касымов", "Абдрахманов", "Омаров", "Байжанов", "Тулегенов",
+		"Искаков", "Мухамедиев", "Ермеков", "Сейтказин", "Жаксыбеков", "Есенов", "Кенжебаев", "Бекмуханов", "Оспанов", "Садыков",
+	}
+	// cities города с весами: в Алматы и Астане сотрудников больше
+	cities = []struct {
+		name   string
+		weight int
+	}{
+		{"Алматы", 8}, {"Астана", 6}, {"Шымкент", 3}, {"Караганда", 2}, {"Актобе", 2},
+		{"Тараз", 1}, {"Павлодар", 1}, {"Усть-Каменогорск", 1}, {"Семей", 1}, {"Атырау", 1},
+		{"Костанай", 1}, {"Кызылорда", 1}, {"Актау", 1}, {"Петропавловск", 1}, {"Туркестан", 1},
+	}
+	// operatorCodes коды мобильных операторов Казахстана
+	operatorCodes = []int{700, 701, 702, 705, 707, 708, 747, 771, 775, 776, 777, 778}
+
+	birthFrom = time.Date(1965, time.January, 1, 0, 0, 0, 0, time.UTC)
+	hireFrom  = time.Date(2008, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Employees возвращает n сотрудников, сгенерированных из seed. Телефоны в формате
+// +77XXXXXXXXX и уникальны в наборе; примерно у каждого пятого нет дат рождения и приема.
+// Даты не зависят от текущего времени и лежат в прошлом
+func Employees(n int, seed int64) []*domain.Employee {
+	rng := rand.New(rand.NewSource(seed))
+	totalWeight := 0
+	for _, c := range cities {
+		totalWeight += c.weight
+	}
+
+	employees := make([]*domain.Employee, 0, n)
+	phones := make(map[string]bool, n)
+	for len(employees) < n {
+		e := &domain.Employee{Status: domain.StatusActive}
+
+		surname := surnames[rng.Intn(len(surnames))]
+		if rng.Intn(2) == 0 {
+			e.Name = maleNames[rng.Intn(len(maleNames))] + " " + surname
+		} else {
+			e.Name = femaleNames[rng.Intn(len(femaleNames))] + " " + surname + "а"
+		}
+
+		for e.Phone == "" || phones[e.Phone] {
+			e.Phone = fmt.Sprintf("+7%d%07d", operatorCodes[rng.Intn(len(operatorCodes))], rng.Intn(10_000_000))
+		}
+		phones[e.Phone] = true
+
+		pick := rng.Intn(totalWeight)
+		for _, c := range cities {
+			if pick < c.weight {
+				e.City = c.name
+				break
+			}
+			pick -= c.weight
+		}
+
+		switch rng.Intn(20) {
+		case 0:
+			e.Status = domain.StatusInactive
+		case 1, 2:
+			e.Status = domain.StatusOnLeave
+		}
+
+		if rng.Intn(5) != 0 {
+			birth := birthFrom.AddDate(0, 0, rng.Intn(37*365))
+			hire := hireFrom.AddDate(0, 0, rng.Intn(16*365))
+			// на работу принимают не раньше 20 лет
+			if adult := birth.AddDate(20, 0, 0); hire.Before(adult) {
+				hire = adult
+			}
+			e.BirthDate, e.HireDate = &birth, &hire
+		}
+
+		employees = append(employees, e)
+	}
+	return employees
+}
+
+// Service часть сервиса сотрудников, через которую загружаются данные: записи проходят
+// те же проверки и нормализацию, что и созданные через API
+type Service interface {
+	CountEmployees(ctx context.Context, filter domain.EmployeeFilter) (int64, error)
+	CreateEmployee(ctx context.Context, employee *domain.Employee) error
+}
+
+// Load создает через svc n сотрудников Employees(n, DefaultSeed) от имени Actor и
+// возвращает их количество. Если сотрудники уже есть, ничего не создает и возвращает 0
+func Load(ctx context.Context, svc Service, n int) (int, error) {
+	count, err := svc.CountEmployees(ctx, domain.EmployeeFilter{})
+	if err != nil {
+		return 0, fmt.Errorf("подсчет сотрудников: %w", err)
+	}
+	if count > 0 {
+		return 0, nil
+	}
+
+	ctx = service.WithActor(ctx, Actor)
+	for i, e := range Employees(n, DefaultSeed) {
+		if err := svc.CreateEmployee(ctx, e); err != nil {
+			return i, fmt.Errorf("создание сотрудника %s (%d из %d): %w", e.Name, i+1, n, err)
+		}
+	}
+	return n, nil
+}
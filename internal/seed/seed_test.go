@@ -0,0 +1,108 @@
+package seed_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"employer/internal/domain"
+	"employer/internal/seed"
+	"employer/internal/service"
+)
+
+func TestEmployees_Deterministic(t *testing.T) {
+	first := seed.Employees(seed.DefaultCount, seed.DefaultSeed)
+	second := seed.Employees(seed.DefaultCount, seed.DefaultSeed)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("одинаковый seed дал разные наборы")
+	}
+	if reflect.DeepEqual(first, seed.Employees(seed.DefaultCount, seed.DefaultSeed+1)) {
+		t.Fatalf("разные seed дали одинаковые наборы")
+	}
+}
+
+func TestEmployees_Valid(t *testing.T) {
+	validator, err := service.NewPhoneValidator(service.PhoneRegionKZ)
+	if err != nil {
+		t.Fatalf("NewPhoneValidator: %v", err)
+	}
+
+	employees := seed.Employees(500, seed.DefaultSeed)
+	if len(employees) != 500 {
+		t.Fatalf("want 500 employees, got %d", len(employees))
+	}
+	phones := make(map[string]bool)
+	for _, e := range employees {
+		if e.Name == "" || e.City == "" || !slices.Contains(domain.EmployeeStatuses, e.Status) {
+			t.Fatalf("incomplete employee: %+v", e)
+		}
+		if err := validator.ValidatePhone(e.Phone); err != nil {
+			t.Fatalf("invalid phone %q: %v", e.Phone, err)
+		}
+		if phones[e.Phone] {
+			t.Fatalf("duplicate phone %q", e.Phone)
+		}
+		phones[e.Phone] = true
+		if (e.BirthDate == nil) != (e.HireDate == nil) {
+			t.Fatalf("birth and hire dates must be set together: %+v", e)
+		}
+		if e.BirthDate != nil && e.HireDate.Before(e.BirthDate.AddDate(20, 0, 0)) {
+			t.Fatalf("hired before 20: %s", e.Name)
+		}
+	}
+}
+
+// fakeService считает созданных сотрудников и их авторов
+type fakeService struct {
+	count   int64
+	err     error
+	created []*domain.Employee
+	actors  []string
+}
+
+func (f *fakeService) CountEmployees(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	return f.count, nil
+}
+
+func (f *fakeService) CreateEmployee(ctx context.Context, employee *domain.Employee) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.created = append(f.created, employee)
+	f.actors = append(f.actors, service.ActorFromContext(ctx))
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	svc := &fakeService{}
+	loaded, err := seed.Load(context.Background(), svc, 10)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded != 10 || len(svc.created) != 10 {
+		t.Fatalf("want 10 created, got %d (%d)", loaded, len(svc.created))
+	}
+	if svc.actors[0] != seed.Actor {
+		t.Fatalf("want actor %q, got %q", seed.Actor, svc.actors[0])
+	}
+	if !reflect.DeepEqual(svc.created, seed.Employees(10, seed.DefaultSeed)) {
+		t.Fatalf("Load должен создавать набор DefaultSeed")
+	}
+}
+
+func TestLoad_SkipsNonEmpty(t *testing.T) {
+	svc := &fakeService{count: 1}
+	loaded, err := seed.Load(context.Background(), svc, 10)
+	if err != nil || loaded != 0 || len(svc.created) != 0 {
+		t.Fatalf("expected skip, got %d created, %d loaded, %v", len(svc.created), loaded, err)
+	}
+}
+
+func TestLoad_Error(t *testing.T) {
+	svc := &fakeService{err: errors.New("db down")}
+	if _, err := seed.Load(context.Background(), svc, 10); err == nil {
+		t.Fatalf("expected error")
+	}
+}
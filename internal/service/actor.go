@@ -0,0 +1,25 @@
+package service
+
+import "context"
+
+// SystemActor автор изменений, когда пользователь неизвестен: запросы без
+// X-Actor, команды CLI и фоновые задачи
+const SystemActor = "system"
+
+// MaxActorLength максимальная длина имени автора (колонки created_by и updated_by)
+const MaxActorLength = 100
+
+type actorKey struct{}
+
+// WithActor возвращает контекст, в котором изменения выполняются от имени actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает автора изменений из контекста или SystemActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
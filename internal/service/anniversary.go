@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// Границы окна GetUpcomingAnniversaries в днях
+const (
+	DefaultAnniversaryDays = 14
+	MaxAnniversaryDays     = 366
+)
+
+// MinHireDate самая ранняя допустимая дата приема на работу; более ранняя — скорее
+// всего опечатка в годе
+var MinHireDate = time.Date(1950, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// GetUpcomingAnniversaries получает дни рождения и годовщины приема на работу
+// в ближайшие withinDays дней, начиная с сегодняшнего. Допустимый диапазон 1..366
+func (s *employeeService) GetUpcomingAnniversaries(ctx context.Context, withinDays int) ([]*domain.Anniversary, error) {
+	if withinDays < 1 || withinDays > MaxAnniversaryDays {
+		return nil, NewValidationError("days", i18n.AnniversaryDaysRange, MaxAnniversaryDays)
+	}
+
+	s.logger.Info("получение годовщин сотрудников", zap.Int("days", withinDays))
+	anniversaries, err := s.repo.GetUpcomingAnniversaries(ctx, today(), withinDays)
+	return anniversaries, withOp(err, "получение годовщин сотрудников")
+}
+
+// dateErrors проверяет даты сотрудника: дата рождения не в будущем,
+// дата приема не раньше MinHireDate
+func dateErrors(employee *domain.Employee, errs *ValidationErrors) {
+	if employee.BirthDate != nil && employee.BirthDate.After(today()) {
+		errs.Add("birth_date", i18n.BirthDateFuture)
+	}
+	if employee.HireDate != nil && employee.HireDate.Before(MinHireDate) {
+		errs.Add("hire_date", i18n.HireDateTooEarly, domain.FormatDate(&MinHireDate))
+	}
+}
+
+// today сегодняшняя дата (полночь UTC), в том же виде, что и даты из domain.ParseDate
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// Период GetEmployeeAttendance в днях: по умолчанию и наибольший
+const (
+	DefaultAttendanceDays = 30
+	MaxAttendanceDays     = 366
+)
+
+// MaxAttendanceNoteLength наибольшая длина примечания к отметке в символах
+const MaxAttendanceNoteLength = 500
+
+// CheckIn отмечает сотрудника id за сегодня (UTC). Пустой status — present; повторная
+// отметка за тот же день заменяет статус и примечание. Отсутствующий сотрудник — NotFoundError
+func (s *employeeService) CheckIn(ctx context.Context, id int64, status, note string) (*domain.Attendance, error) {
+	if status == "" {
+		status = domain.AttendancePresent
+	}
+	note = strings.TrimSpace(note)
+
+	errs := &ValidationErrors{}
+	if !domain.ValidAttendanceStatus(status) {
+		errs.Add("status", i18n.StatusInvalid, strings.Join(domain.AttendanceStatuses, ", "))
+	}
+	if utf8.RuneCountInString(note) > MaxAttendanceNoteLength {
+		errs.Add("note", i18n.AttendanceNoteTooLong, MaxAttendanceNoteLength)
+	}
+	if err := errs.OrNil(); err != nil {
+		return nil, err
+	}
+
+	attendance := &domain.Attendance{EmployeeID: id, Date: today(), Status: status, Note: note}
+	s.logger.Info("отметка посещаемости", zap.Int64("id", id), zap.String("status", status))
+	if err := s.repo.UpsertAttendance(ctx, attendance); err != nil {
+		return nil, withOp(err, "отметка посещаемости сотрудника %d", id)
+	}
+	return attendance, nil
+}
+
+// GetEmployeeAttendance получает отметки сотрудника id за даты [from, to] по возрастанию.
+// Нулевой to — сегодня, нулевой from — DefaultAttendanceDays дней по to включительно.
+// from позже to или период длиннее MaxAttendanceDays дней — ошибка валидации
+func (s *employeeService) GetEmployeeAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+	if to.IsZero() {
+		to = today()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(DefaultAttendanceDays - 1))
+	}
+	if from.After(to) {
+		return nil, NewValidationError("from", i18n.AttendanceRangeOrder)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > MaxAttendanceDays {
+		return nil, NewValidationError("to", i18n.AttendanceRangeTooLong, MaxAttendanceDays)
+	}
+
+	s.logger.Info("получение посещаемости сотрудника", zap.Int64("id", id),
+		zap.String("from", domain.FormatDate(&from)), zap.String("to", domain.FormatDate(&to)))
+	records, err := s.repo.GetAttendance(ctx, id, from, to)
+	return records, withOp(err, "получение посещаемости сотрудника %d", id)
+}
+
+// GetAttendanceSummary считает отметки каждого вида за день date; нулевой date — сегодня
+func (s *employeeService) GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+	if date.IsZero() {
+		date = today()
+	}
+
+	s.logger.Info("получение сводки посещаемости", zap.String("date", domain.FormatDate(&date)))
+	summary, err := s.repo.GetAttendanceSummary(ctx, date)
+	return summary, withOp(err, "получение сводки посещаемости")
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// MaxBatchUpdate максимальное количество сотрудников в одном пакетном обновлении
+const MaxBatchUpdate = 1000
+
+// UpdateEmployees обновляет имя, телефон и город сотрудников в одной транзакции и сообщает
+// результат по каждому: updated, not_found или validation_error. Данные проверяются, как
+// при обновлении одного сотрудника; ID и телефон не должны повторяться в пакете.
+// Ошибочные и ненайденные сотрудники пропускаются, остальные обновляются. При strict
+// любая такая ошибка отменяет пакет целиком: корректные сотрудники получают статус skipped
+func (s *employeeService) UpdateEmployees(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error) {
+	if len(items) == 0 {
+		return nil, NewValidationError("employees", i18n.IDsEmpty)
+	}
+	if len(items) > MaxBatchUpdate {
+		return nil, NewValidationError("employees", i18n.IDsTooMany, MaxBatchUpdate)
+	}
+
+	ctx, logger := s.opLogger(ctx, "update_batch")
+	logger.Info("пакетное обновление сотрудников", zap.Int("count", len(items)), zap.Bool("strict", strict))
+
+	result := &domain.BatchUpdateResponse{Results: make([]domain.BatchUpdateResult, len(items))}
+	actor := ActorFromContext(ctx)
+	// valid индексы прошедших проверку; ids и phones — элемент (с 1), где они встретились впервые
+	var valid []int
+	employees := make([]*domain.Employee, len(items))
+	ids := make(map[int64]int, len(items))
+	phones := make(map[string]int, len(items))
+
+	for i, item := range items {
+		employee := &domain.Employee{ID: item.ID, Name: item.Name, Phone: item.Phone, City: item.City, UpdatedBy: actor}
+		fieldErrors, err := s.checkBatchItem(ctx, employee, i+1, ids, phones)
+		if err != nil {
+			return nil, err
+		}
+		result.Results[i].ID = item.ID
+		if len(fieldErrors) > 0 {
+			result.Results[i].Status = domain.BatchValidationError
+			result.Results[i].Errors = fieldErrors
+			result.Failed++
+			continue
+		}
+		employees[i] = employee
+		valid = append(valid, i)
+	}
+
+	err := s.inTx(ctx, func(repo repository.EmployeeRepository) error {
+		validIDs := make([]int64, len(valid))
+		for n, i := range valid {
+			validIDs[n] = employees[i].ID
+		}
+		existing, err := repo.ExistingIDs(ctx, validIDs)
+		if err != nil {
+			return err
+		}
+		found := make(map[int64]bool, len(existing))
+		for _, id := range existing {
+			found[id] = true
+		}
+
+		var update []*domain.Employee
+		for _, i := range valid {
+			if !found[employees[i].ID] {
+				result.Results[i].Status = domain.BatchNotFound
+				result.NotFound++
+				continue
+			}
+			result.Results[i].Status = domain.BatchUpdated
+			update = append(update, employees[i])
+		}
+		if strict && result.NotFound+result.Failed > 0 {
+			result.Aborted = true
+			return nil
+		}
+		if len(update) == 0 {
+			return nil
+		}
+
+		// Прежние версии попадают в историю в той же транзакции, что и обновление
+		for _, employee := range update {
+			if err := repo.ArchiveVersion(ctx, employee.ID); err != nil {
+				return err
+			}
+		}
+		updated, err := repo.UpdateBatch(ctx, update)
+		result.Updated = len(updated)
+		return err
+	})
+	if err != nil {
+		return nil, withOp(conflictFromRepository(err), "пакетное обновление сотрудников")
+	}
+
+	if result.Aborted {
+		for i := range result.Results {
+			if result.Results[i].Status == domain.BatchUpdated {
+				result.Results[i].Status = domain.BatchSkipped
+			}
+		}
+	}
+
+	logger.Info("пакетное обновление сотрудников завершено",
+		zap.Int("updated", result.Updated),
+		zap.Int("not_found", result.NotFound),
+		zap.Int("failed", result.Failed),
+		zap.Bool("aborted", result.Aborted))
+	return result, nil
+}
+
+// checkBatchItem проверяет сотрудника пакетного обновления с номером item (с 1) и
+// возвращает ошибки его полей. ids и phones — где ID и телефоны встретились в пакете впервые.
+// Ошибка возвращается только при сбое обращения к БД
+func (s *employeeService) checkBatchItem(ctx context.Context, employee *domain.Employee, item int, ids map[int64]int, phones map[string]int) ([]domain.FieldError, error) {
+	// ValidateEmployee нормализует поля, поэтому телефон сравнивается после нее
+	fieldErrors, err := s.ValidateEmployee(ctx, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := &ValidationErrors{}
+	if employee.ID <= 0 {
+		errs.Add("id", i18n.IDsInvalid, employee.ID)
+	} else if first, ok := ids[employee.ID]; ok {
+		errs.Add("id", i18n.BatchIDRepeated, employee.ID, first)
+	} else {
+		ids[employee.ID] = item
+	}
+	if employee.Phone != "" {
+		if first, ok := phones[employee.Phone]; ok {
+			errs.Add("phone", i18n.BatchPhoneRepeated, first)
+		} else {
+			phones[employee.Phone] = item
+		}
+	}
+	return append(errs.FieldErrors(), fieldErrors...), nil
+}
@@ -3,8 +3,14 @@ package service
 import (
 	"context"
 	"employer/internal/domain"
+	"employer/internal/i18n"
 	"employer/internal/repository"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 )
@@ -12,106 +18,821 @@ import (
 // employeeService реализация сервиса
 type employeeService struct {
 	repo   repository.EmployeeRepository
+	uow    repository.UnitOfWork
 	logger *zap.Logger
+	opts   Options
+	// stats кэш статистики; nil, если StatsCacheTTL не задан
+	stats *statsCache
 }
 
-// NewEmployeeService создает новый сервис для сотрудников
+// NewEmployeeService создает новый сервис для сотрудников с настройками по умолчанию
 func NewEmployeeService(repo repository.EmployeeRepository, logger *zap.Logger) *employeeService {
-	return &employeeService{
+	return NewEmployeeServiceWithOptions(repo, logger, DefaultOptions())
+}
+
+// NewEmployeeServiceWithOptions создает новый сервис для сотрудников
+func NewEmployeeServiceWithOptions(repo repository.EmployeeRepository, logger *zap.Logger, opts Options) *employeeService {
+	s := &employeeService{
 		repo:   repo,
 		logger: logger,
+		opts:   opts,
 	}
+	if opts.StatsCacheTTL > 0 {
+		s.stats = newStatsCache(opts.StatsCacheTTL)
+	}
+	return s
 }
 
-func (s *employeeService) SearchEmployees(ctx context.Context, searchQuery string) ([]*domain.Employee, error) {
-    searchQuery = strings.TrimSpace(searchQuery)
-    
-    if searchQuery == "" {
-        return nil, &ValidationError{
-            Field:   "search_query",
-            Message: "поисковый запрос не может быть пустым",
-        }
+// WithTx выполняет fn в транзакции. Сервис, переданный в fn, использует репозиторий
+// транзакции, остальные настройки совпадают с s; вложенный WithTx продолжает ту же транзакцию.
+func (s *employeeService) WithTx(ctx context.Context, fn func(svc EmployeeService) error) error {
+	if s.uow == nil {
+		return errors.New("транзакции не поддерживаются: UnitOfWork не задан")
+	}
+
+	return s.uow.WithTx(ctx, func(repo repository.EmployeeRepository) error {
+		txService := *s
+		txService.repo = repo
+		txService.uow = activeTx{repo: repo}
+		return fn(&txService)
+	})
+}
+
+// activeTx UnitOfWork уже открытой транзакции: вложенные вызовы выполняются в ней же
+type activeTx struct {
+	repo repository.EmployeeRepository
+}
+
+func (t activeTx) WithTx(ctx context.Context, fn func(repo repository.EmployeeRepository) error) error {
+	return fn(t.repo)
+}
+
+// MaxSearchLimit наибольшее количество результатов поиска за один запрос по умолчанию
+const MaxSearchLimit = 100
+
+// SearchEmployees ищет сотрудников среди подходящих под фильтр и возвращает страницу
+// результатов с общим количеством совпадений. limit 0 означает максимум (SearchMaxResults),
+// offset не меньше 0.
+func (s *employeeService) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+    searchQuery, err := normalizeSearchQuery(searchQuery)
+    if err != nil {
+        return nil, 0, err
     }
     
-    if len(searchQuery) < 2 {
-        return nil, &ValidationError{
-            Field:   "search_query", 
-            Message: "поисковый запрос должен содержать минимум 2 символа",
-        }
+    maxResults := s.searchMaxResults()
+    if limit == 0 {
+        limit = maxResults
     }
-    
-    if len(searchQuery) > 100 { // Add this validation
-        return nil, &ValidationError{
-            Field:   "search_query",
-            Message: "поисковый запрос не должен превышать 100 символов",
-        }
+    if limit < 1 || limit > maxResults {
+        return nil, 0, NewValidationError("limit", i18n.LimitRange, maxResults)
+    }
+    if offset < 0 {
+        return nil, 0, NewValidationError("offset", i18n.OffsetNegative)
+    }
+    filter, err = s.normalizeFilter(filter)
+    if err != nil {
+        return nil, 0, err
     }
     
-    return s.repo.SearchEmployees(ctx, searchQuery)
+    return s.repo.SearchEmployees(ctx, searchQuery, filter, limit, offset)
 }
 
 // CreateEmployee создает нового сотрудника
 func (s *employeeService) CreateEmployee(ctx context.Context, employee *domain.Employee) error {
-	s.logger.Info("создание сотрудника", zap.String("name", employee.Name))
+	ctx, logger := s.opLogger(ctx, "create")
+	logger.Info("создание сотрудника", zap.String("name", employee.Name))
 
+	s.normalizeEmployee(employee)
 	if err := s.validateEmployee(employee); err != nil {
-		s.logger.Error("валидация сотрудника", zap.Error(err))
+		logger.Error("валидация сотрудника", zap.Error(err))
+		return err
+	}
+	if err := s.checkPhoneConflict(ctx, employee); err != nil {
+		return err
+	}
+	if err := s.checkQuota(ctx, 1); err != nil {
 		return err
 	}
+	if employee.Status == "" {
+		employee.Status = domain.StatusActive
+	}
+	employee.CreatedBy = ActorFromContext(ctx)
+	employee.UpdatedBy = employee.CreatedBy
 
-	return s.repo.Create(ctx, employee)
+	return conflictFromRepository(s.repo.Create(ctx, employee))
 }
 
 // GetEmployee получает сотрудника по ID
-func (s *employeeService) GetEmployee(ctx context.Context, id int) (*domain.Employee, error) {
-	s.logger.Info("получение сотрудника", zap.Int("id", id))
-	return s.repo.GetByID(ctx, id)
+func (s *employeeService) GetEmployee(ctx context.Context, id int64) (*domain.Employee, error) {
+	ctx, logger := s.opLogger(ctx, "get")
+	logger.Info("получение сотрудника", zap.Int64("id", id))
+	employee, err := s.repo.GetByID(ctx, id)
+	return employee, withOp(err, "получение сотрудника %d", id)
+}
+
+// GetEmployeeAsOf получает сотрудника в том виде, в котором он был в момент at.
+// Для текущего и будущего момента возвращается текущая запись.
+func (s *employeeService) GetEmployeeAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+	if !at.Before(time.Now()) {
+		return s.GetEmployee(ctx, id)
+	}
+
+	s.logger.Info("получение версии сотрудника", zap.Int64("id", id), zap.Time("as_of", at))
+	employee, err := s.repo.GetAsOf(ctx, id, at)
+	return employee, withOp(err, "получение версии сотрудника %d", id)
+}
+
+// GetEmployeeStats получает статистику сотрудников; при заданном StatsCacheTTL — из кэша
+func (s *employeeService) GetEmployeeStats(ctx context.Context) (*repository.EmployeeStats, error) {
+	if s.stats == nil {
+		return s.repo.GetEmployeeStats(ctx)
+	}
+	return s.stats.get(ctx, s.repo.GetEmployeeStats)
+}
+
+// GetEmployeeHistory получает все версии сотрудника по возрастанию valid_from
+func (s *employeeService) GetEmployeeHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+	s.logger.Info("получение истории сотрудника", zap.Int64("id", id))
+	history, err := s.repo.GetHistory(ctx, id)
+	return history, withOp(err, "получение истории сотрудника %d", id)
+}
+
+// GetAllEmployees получает всех сотрудников, подходящих под фильтр. Если их больше
+// ListMaxRows — ошибка валидации с предложением постраничной выдачи
+func (s *employeeService) GetAllEmployees(ctx context.Context, filter domain.EmployeeFilter) ([]*domain.Employee, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("получение всех сотрудников", zap.String("status", filter.Status))
+	employees, err := s.repo.GetAll(ctx, filter, s.listMaxRows())
+	var tooMany *repository.TooManyRowsError
+	if errors.As(err, &tooMany) {
+		return nil, NewValidationError("limit", i18n.ListTooLarge, tooMany.Count, tooMany.Limit)
+	}
+	return employees, err
+}
+
+// StreamAllEmployees передает сотрудников, подходящих под фильтр, в fn по одному в порядке
+// списка. В отличие от GetAllEmployees не ограничен ListMaxRows: выборка не хранится в памяти
+func (s *employeeService) StreamAllEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("потоковое получение всех сотрудников", zap.String("status", filter.Status))
+	return s.repo.GetAllCursor(ctx, filter, fn)
 }
 
-// GetAllEmployees получает всех сотрудников
-func (s *employeeService) GetAllEmployees(ctx context.Context) ([]*domain.Employee, error) {
-	s.logger.Info("получение всех сотрудников")
-	return s.repo.GetAll(ctx)
+// DefaultListMaxRows наибольшее количество сотрудников в списке без limit и offset по умолчанию
+const DefaultListMaxRows = 10000
+
+// listMaxRows наибольшее количество сотрудников в списке без постраничной выдачи из настроек
+func (s *employeeService) listMaxRows() int {
+	if s.opts.ListMaxRows <= 0 {
+		return DefaultListMaxRows
+	}
+	return s.opts.ListMaxRows
+}
+
+// Ограничения постраничной выдачи по умолчанию (см. Options.PageDefaultLimit, Options.PageMaxLimit)
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// GetEmployeesPage получает страницу сотрудников, подходящих под фильтр, и их общее
+// количество. limit 0 означает значение по умолчанию, допустимый диапазон 1..PageMaxLimit;
+// offset не меньше 0.
+func (s *employeeService) GetEmployeesPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	defaultLimit, maxLimit := s.pageLimits()
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	var errs ValidationErrors
+	if limit < 1 || limit > maxLimit {
+		errs.Add("limit", i18n.LimitRange, maxLimit)
+	}
+	if offset < 0 {
+		errs.Add("offset", i18n.OffsetNegative)
+	}
+	if err := errs.OrNil(); err != nil {
+		return nil, 0, err
+	}
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.logger.Info("получение страницы сотрудников", zap.Int("limit", limit), zap.Int("offset", offset))
+
+	employees, err := s.repo.GetPage(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.CountWithFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return employees, total, nil
+}
+
+// pageLimits размер страницы по умолчанию и наибольший размер из настроек
+func (s *employeeService) pageLimits() (defaultLimit, maxLimit int) {
+	defaultLimit, maxLimit = s.opts.PageDefaultLimit, s.opts.PageMaxLimit
+	if defaultLimit <= 0 {
+		defaultLimit = DefaultPageLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = MaxPageLimit
+	}
+	return defaultLimit, maxLimit
+}
+
+// searchMaxResults наибольшее количество результатов поиска за запрос из настроек
+func (s *employeeService) searchMaxResults() int {
+	if s.opts.SearchMaxResults <= 0 {
+		return MaxSearchLimit
+	}
+	return s.opts.SearchMaxResults
+}
+
+// Ограничения выборки последних сотрудников
+const (
+	defaultRecentLimit = 10
+	maxRecentLimit     = 100
+)
+
+// GetRecentEmployees получает последних добавленных сотрудников. limit 0 означает
+// значение по умолчанию, допустимый диапазон 1..100.
+func (s *employeeService) GetRecentEmployees(ctx context.Context, limit int) ([]*domain.Employee, error) {
+	if limit == 0 {
+		limit = defaultRecentLimit
+	}
+	if limit < 1 || limit > maxRecentLimit {
+		return nil, NewValidationError("limit", i18n.LimitRange, maxRecentLimit)
+	}
+
+	s.logger.Info("получение последних сотрудников", zap.Int("limit", limit))
+	return s.repo.GetRecent(ctx, limit)
+}
+
+// GetEmployeesCreatedBetween получает сотрудников, созданных с from по to включительно,
+// по возрастанию даты создания
+func (s *employeeService) GetEmployeesCreatedBetween(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+	s.logger.Info("получение сотрудников по дате создания", zap.Time("from", from), zap.Time("to", to))
+	employees, err := s.repo.GetByCreatedRange(ctx, from, to)
+	if err != nil {
+		return nil, withOp(err, "получение сотрудников по дате создания")
+	}
+	return employees, nil
 }
 
 // UpdateEmployee обновляет сотрудника
 func (s *employeeService) UpdateEmployee(ctx context.Context, employee *domain.Employee) error {
-	s.logger.Info("обновление сотрудника", zap.Int("id", employee.ID))
+	ctx, logger := s.opLogger(ctx, "update")
+	logger.Info("обновление сотрудника", zap.Int64("id", employee.ID))
 
+	s.normalizeEmployee(employee)
 	if err := s.validateEmployee(employee); err != nil {
-		s.logger.Error("валидация сотрудника", zap.Error(err))
+		logger.Error("валидация сотрудника", zap.Error(err))
+		return err
+	}
+	if err := s.checkPhoneConflict(ctx, employee); err != nil {
 		return err
 	}
+	employee.UpdatedBy = ActorFromContext(ctx)
 
-	return s.repo.Update(ctx, employee)
+	// Прежняя версия попадает в историю в той же транзакции, что и обновление
+	err := s.inTx(ctx, func(repo repository.EmployeeRepository) error {
+		if err := repo.ArchiveVersion(ctx, employee.ID); err != nil {
+			return err
+		}
+		return repo.Update(ctx, employee)
+	})
+	return withOp(conflictFromRepository(err), "обновление сотрудника %d", employee.ID)
+}
+
+// SetEmployeeStatus переводит сотрудника в статус status и возвращает его. Если
+// статус уже такой, ничего не меняется. Прежняя версия попадает в историю, как при обновлении
+func (s *employeeService) SetEmployeeStatus(ctx context.Context, id int64, status string) (*domain.Employee, error) {
+	if !domain.ValidStatus(status) {
+		return nil, statusError()
+	}
+
+	ctx, logger := s.opLogger(ctx, "set_status")
+	logger.Info("изменение статуса сотрудника", zap.Int64("id", id), zap.String("status", status))
+
+	var employee *domain.Employee
+	err := s.inTx(ctx, func(repo repository.EmployeeRepository) error {
+		var err error
+		if employee, err = repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		if employee.Status == status {
+			return nil
+		}
+		if err := repo.ArchiveVersion(ctx, id); err != nil {
+			return err
+		}
+		actor := ActorFromContext(ctx)
+		if err := repo.UpdateStatus(ctx, id, status, actor); err != nil {
+			return err
+		}
+		employee.Status = status
+		employee.UpdatedBy = actor
+		// UpdateStatus увеличивает версию на 1; запись прочитана в той же транзакции
+		employee.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, withOp(err, "изменение статуса сотрудника %d", id)
+	}
+	return employee, nil
+}
+
+// inTx выполняет fn в транзакции UnitOfWork, а без него — напрямую на репозитории сервиса
+func (s *employeeService) inTx(ctx context.Context, fn func(repo repository.EmployeeRepository) error) error {
+	if s.uow == nil {
+		return fn(s.repo)
+	}
+	return s.uow.WithTx(ctx, fn)
 }
 
 // DeleteEmployee удаляет сотрудника
-func (s *employeeService) DeleteEmployee(ctx context.Context, id int) error {
-	s.logger.Info("удаление сотрудника", zap.Int("id", id))
-	return s.repo.Delete(ctx, id)
+func (s *employeeService) DeleteEmployee(ctx context.Context, id int64) error {
+	ctx, logger := s.opLogger(ctx, "delete")
+	logger.Info("удаление сотрудника", zap.Int64("id", id))
+	return withOp(s.repo.Delete(ctx, id), "удаление сотрудника %d", id)
+}
+
+// DeleteEmployeeIfExists удаляет сотрудника и сообщает, был ли он. В отличие от
+// DeleteEmployee отсутствующий сотрудник не ошибка: повтор удаления безопасен
+func (s *employeeService) DeleteEmployeeIfExists(ctx context.Context, id int64) (bool, error) {
+	err := s.DeleteEmployee(ctx, id)
+	var notFound *repository.NotFoundError
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MaxBatchDelete максимальное количество ID в одном пакетном удалении
+const MaxBatchDelete = 1000
+
+// DeleteEmployees удаляет сотрудников с указанными ID в одной транзакции и сообщает,
+// кто удален, а кого не нашли. При dryRun ничего не удаляется, отчет тот же.
+func (s *employeeService) DeleteEmployees(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error) {
+	ids, err := validateBatchIDs(ids, MaxBatchDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, logger := s.opLogger(ctx, "delete_batch")
+	logger.Info("пакетное удаление сотрудников", zap.Int("count", len(ids)), zap.Bool("dry_run", dryRun))
+
+	var affected []int64
+	err = s.inTx(ctx, func(repo repository.EmployeeRepository) error {
+		var err error
+		if dryRun {
+			affected, err = repo.ExistingIDs(ctx, ids)
+		} else {
+			affected, err = repo.DeleteMany(ctx, ids)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, withOp(err, "пакетное удаление сотрудников")
+	}
+
+	found := make(map[int64]bool, len(affected))
+	for _, id := range affected {
+		found[id] = true
+	}
+	result := &domain.BatchDeleteResponse{Deleted: []int64{}, NotFound: []int64{}, DryRun: dryRun}
+	for _, id := range ids {
+		if found[id] {
+			result.Deleted = append(result.Deleted, id)
+		} else {
+			result.NotFound = append(result.NotFound, id)
+		}
+	}
+	return result, nil
+}
+
+// MaxBatchGet максимальное количество ID в одном запросе сотрудников по списку
+const MaxBatchGet = 100
+
+// GetEmployeesByIDs получает сотрудников по списку ID в порядке запроса без повторов;
+// ненайденные ID в результат не попадают
+func (s *employeeService) GetEmployeesByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+	ids, err := validateBatchIDs(ids, MaxBatchGet)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("получение сотрудников по списку ID", zap.Int("count", len(ids)))
+	employees, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, withOp(err, "получение сотрудников по списку ID")
+	}
+	return employees, nil
+}
+
+// validateBatchIDs проверяет список ID пакетной операции (не больше limit) и убирает повторы, сохраняя порядок
+func validateBatchIDs(ids []int64, limit int) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, NewValidationError("ids", i18n.IDsEmpty)
+	}
+	if len(ids) > limit {
+		return nil, NewValidationError("ids", i18n.IDsTooMany, limit)
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, NewValidationError("ids", i18n.IDsInvalid, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// GetEmployeesByCity получает сотрудников города; название нормализуется так же, как при сохранении
+func (s *employeeService) GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error) {
+	city = s.normalizeCity(city)
+	s.logger.Info("получение сотрудников по городу", zap.String("city", city))
+	return s.repo.GetEmployeesByCity(ctx, city)
+}
+
+// MaxCityLength наибольшая длина названия города в символах (колонка city VARCHAR(100))
+const MaxCityLength = 100
+
+// GetCityCounts получает города с количеством сотрудников по убыванию количества
+func (s *employeeService) GetCityCounts(ctx context.Context) ([]*domain.CityCount, error) {
+	return s.repo.GetCityCounts(ctx)
+}
+
+// GetCityEmployees получает страницу сотрудников города и их общее количество. Город
+// сравнивается без учета регистра; limit и offset проверяются как в GetEmployeesPage
+func (s *employeeService) GetCityEmployees(ctx context.Context, city string, limit, offset int) ([]*domain.Employee, int64, error) {
+	city = sanitizeText(city)
+	if city == "" {
+		return nil, 0, NewValidationError("city", i18n.CityRequired)
+	}
+	if utf8.RuneCountInString(city) > MaxCityLength {
+		return nil, 0, NewValidationError("city", i18n.CityTooLong, MaxCityLength)
+	}
+	if hasControlChars(city) {
+		return nil, 0, NewValidationError("city", i18n.CityControlChars)
+	}
+
+	s.logger.Info("получение сотрудников города", zap.String("city", city))
+	return s.GetEmployeesPage(ctx, domain.EmployeeFilter{City: city}, limit, offset)
+}
+
+// CountEmployees возвращает количество сотрудников, подходящих под фильтр
+func (s *employeeService) CountEmployees(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CountWithFilter(ctx, filter)
+}
+
+// ExportEmployees передает сотрудников, подходящих под фильтр, в fn по одному.
+// Строка поиска filter.Query проверяется так же, как в SearchEmployees: выгрузка
+// результатов поиска содержит тех же сотрудников, что и поиск
+func (s *employeeService) ExportEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return err
+	}
+	if filter.Query != "" {
+		if filter.Query, err = normalizeSearchQuery(filter.Query); err != nil {
+			return err
+		}
+	}
+	s.logger.Info("выгрузка сотрудников", zap.String("city", filter.City), zap.String("search_query", filter.Query))
+	return s.repo.StreamEmployees(ctx, filter, fn)
+}
+
+// CheckPhone ищет сотрудника с таким телефоном, кроме сотрудника excludeID (0 — без
+// исключения). Телефон нормализуется так же, как перед записью. Если телефон
+// свободен, возвращает nil без ошибки.
+func (s *employeeService) CheckPhone(ctx context.Context, phone string, excludeID int64) (*domain.Employee, error) {
+	phone = s.canonicalPhone(phone)
+	if phone == "" {
+		return nil, NewValidationError("phone", i18n.PhoneRequired)
+	}
+
+	var exclude []int64
+	if excludeID > 0 {
+		exclude = append(exclude, excludeID)
+	}
+
+	exists, err := s.repo.CheckPhoneExists(ctx, phone, exclude...)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	employee, err := s.repo.GetByPhone(ctx, phone)
+	return employee, withOp(err, "проверка телефона")
+}
+
+// ValidateEmployee проверяет данные сотрудника без сохранения и возвращает все
+// ошибки полей сразу. Поля нормализуются так же, как перед записью; телефон
+// проверяется на уникальность среди других сотрудников (кроме employee.ID).
+// Ошибка возвращается только при сбое обращения к БД.
+func (s *employeeService) ValidateEmployee(ctx context.Context, employee *domain.Employee) ([]domain.FieldError, error) {
+	s.normalizeEmployee(employee)
+
+	errs := s.fieldErrors(employee)
+	if employee.Phone != "" {
+		var excludeID []int64
+		if employee.ID > 0 {
+			excludeID = append(excludeID, employee.ID)
+		}
+		exists, err := s.repo.CheckPhoneExists(ctx, employee.Phone, excludeID...)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			errs.Add("phone", i18n.PhoneTaken)
+		}
+	}
+
+	return errs.FieldErrors(), nil
+}
+
+// normalizeEmployee очищает имя и город (NFC, лишние пробелы), приводит телефон
+// к единой записи, обрезает статус и приводит город к единому виду
+func (s *employeeService) normalizeEmployee(employee *domain.Employee) {
+	employee.Name = sanitizeText(employee.Name)
+	employee.Phone = s.canonicalPhone(employee.Phone)
+	employee.City = s.normalizeCity(employee.City)
+	employee.Status = strings.TrimSpace(employee.Status)
+}
+
+// canonicalPhone приводит телефон к записи PhoneValidator.CanonicalPhone, а без
+// проверки формата только убирает оформление. Проверка формата, поиск дубликатов
+// и запись в БД получают один и тот же номер
+func (s *employeeService) canonicalPhone(phone string) string {
+	if s.opts.PhoneValidator == nil {
+		return stripPhone(phone)
+	}
+	return s.opts.PhoneValidator.CanonicalPhone(phone)
+}
+
+// normalizeFilter приводит город фильтра к единому виду и проверяет статус
+func (s *employeeService) normalizeFilter(filter domain.EmployeeFilter) (domain.EmployeeFilter, error) {
+	filter.City = s.normalizeCity(filter.City)
+	filter.Status = strings.TrimSpace(filter.Status)
+	if filter.Status != "" && !domain.ValidStatus(filter.Status) {
+		return filter, statusError()
+	}
+	return filter, nil
+}
+
+// normalizeSearchQuery очищает строку поиска (см. sanitizeText) и проверяет ее длину
+// и количество слов: каждое слово добавляет условие в SQL запрос репозитория
+func normalizeSearchQuery(searchQuery string) (string, error) {
+	searchQuery = sanitizeText(searchQuery)
+	switch {
+	case searchQuery == "":
+		return "", NewValidationError("search_query", i18n.SearchEmpty)
+	case hasControlChars(searchQuery):
+		return "", NewValidationError("search_query", i18n.SearchControlChars)
+	case len(searchQuery) < 2:
+		return "", NewValidationError("search_query", i18n.SearchTooShort)
+	case len(searchQuery) > 100:
+		return "", NewValidationError("search_query", i18n.SearchTooLong)
+	case len(strings.Fields(searchQuery)) > repository.MaxSearchTerms:
+		return "", NewValidationError("search_query", i18n.SearchTooManyTerms)
+	}
+	return searchQuery, nil
+}
+
+// statusError ошибка неизвестного статуса сотрудника
+func statusError() error {
+	return NewValidationError("status", i18n.StatusInvalid, strings.Join(domain.EmployeeStatuses, ", "))
 }
 
-// validateEmployee валидирует данные сотрудника
+// normalizeCity очищает название (см. sanitizeText) и, если включено NormalizeCity, приводит
+// название к виду "Усть-Каменогорск": каждое слово и часть через дефис
+// с заглавной буквы, остальные строчные
+func (s *employeeService) normalizeCity(city string) string {
+	city = sanitizeText(city)
+	if !s.opts.NormalizeCity {
+		return city
+	}
+
+	runes := []rune(strings.ToLower(city))
+	for i, r := range runes {
+		if i == 0 || runes[i-1] == ' ' || runes[i-1] == '-' {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// validateEmployee валидирует данные сотрудника и возвращает *ValidationErrors
+// со всеми ошибками полей или nil
 func (s *employeeService) validateEmployee(employee *domain.Employee) error {
+	return s.fieldErrors(employee).OrNil()
+}
+
+// fieldErrors собирает ошибки полей, не требующие обращения к БД
+func (s *employeeService) fieldErrors(employee *domain.Employee) *ValidationErrors {
+	errs := requiredFieldErrors(employee)
+	if hasControlChars(employee.Name) {
+		errs.Add("name", i18n.NameControlChars)
+	} else if hasMarkup(employee.Name) {
+		errs.Add("name", i18n.NameMarkup)
+	}
+	if hasControlChars(employee.City) {
+		errs.Add("city", i18n.CityControlChars)
+	} else if hasMarkup(employee.City) {
+		errs.Add("city", i18n.CityMarkup)
+	}
+	if employee.Status != "" && !domain.ValidStatus(employee.Status) {
+		errs.Add("status", i18n.StatusInvalid, strings.Join(domain.EmployeeStatuses, ", "))
+	}
+	if employee.Phone != "" && s.opts.PhoneValidator != nil {
+		if err := s.opts.PhoneValidator.ValidatePhone(employee.Phone); err != nil {
+			errs.AddError("phone", err)
+		}
+	}
+	dateErrors(employee, errs)
+	return errs
+}
+
+// requiredFieldErrors собирает ошибки незаполненных обязательных полей
+func requiredFieldErrors(employee *domain.Employee) *ValidationErrors {
+	errs := &ValidationErrors{}
 	if employee.Name == "" {
-		return &ValidationError{Field: "name", Message: "имя обязательно"}
+		errs.Add("name", i18n.NameRequired)
 	}
 	if employee.Phone == "" {
-		return &ValidationError{Field: "phone", Message: "телефон обязателен"}
+		errs.Add("phone", i18n.PhoneRequired)
 	}
 	if employee.City == "" {
-		return &ValidationError{Field: "city", Message: "город обязателен"}
+		errs.Add("city", i18n.CityRequired)
+	}
+	return errs
+}
+
+// checkPhoneConflict возвращает ConflictError, если телефон занят другим сотрудником.
+// Гонку между проверкой и записью закрывает ограничение уникальности в БД.
+func (s *employeeService) checkPhoneConflict(ctx context.Context, employee *domain.Employee) error {
+	conflicting, err := s.CheckPhone(ctx, employee.Phone, employee.ID)
+	if err != nil {
+		return err
+	}
+	if conflicting == nil {
+		return nil
+	}
+
+	s.log(ctx).Warn("телефон занят другим сотрудником",
+		zap.String("phone", employee.Phone),
+		zap.Int64("conflicting_id", conflicting.ID))
+	return &ConflictError{
+		Field:      "phone",
+		Message:    i18n.T(i18n.Default, i18n.ConflictPhone),
+		Key:        i18n.ConflictPhone,
+		EmployeeID: conflicting.ID,
+	}
+}
+
+// conflictFromRepository превращает нарушение уникальности и конфликт версий из
+// репозитория в ConflictError
+func conflictFromRepository(err error) error {
+	var (
+		exists  *repository.AlreadyExistsError
+		version *repository.VersionConflictError
+	)
+	switch {
+	case errors.As(err, &exists):
+		return &ConflictError{
+			Field:   exists.Field,
+			Message: i18n.T(i18n.Default, i18n.ConflictPhone),
+			Key:     i18n.ConflictPhone,
+		}
+	case errors.As(err, &version):
+		return &ConflictError{
+			Field:      "version",
+			Message:    i18n.T(i18n.Default, i18n.ConflictVersion),
+			Key:        i18n.ConflictVersion,
+			EmployeeID: version.ID,
+			Version:    version.Current,
+		}
+	}
+	return err
+}
+
+// withOp добавляет к ошибке репозитория операцию сервиса. Ошибка оборачивается через %w,
+// поэтому ее тип по-прежнему доступен через errors.As (см. repository.IsNotFound)
+func withOp(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
 	}
-	return nil
+	return fmt.Errorf(format+": %w", append(args, err)...)
 }
 
-// ValidationError ошибка валидации
+// ConflictError конфликт с существующим сотрудником
+type ConflictError struct {
+	Field   string
+	Message string
+	// Key ключ сообщения для перевода (см. пакет i18n); пустой, если Message не переводится
+	Key string
+	// EmployeeID ID конфликтующего сотрудника; 0, если неизвестен
+	EmployeeID int64
+	// Version текущая версия сотрудника при конфликте версий (Field "version")
+	Version int
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// ValidationError ошибка валидации. Message содержит текст на языке по умолчанию,
+// Key и Args позволяют отдать его на языке клиента; пустой Key означает, что
+// Message отдается как есть.
 type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
+	Field   string        `json:"field"`
+	Message string        `json:"message"`
+	Key     string        `json:"-"`
+	Args    []interface{} `json:"-"`
+}
+
+// NewValidationError создает ошибку поля field с сообщением по ключу key
+func NewValidationError(field, key string, args ...interface{}) *ValidationError {
+	return &ValidationError{Field: field, Message: i18n.T(i18n.Default, key, args...), Key: key, Args: args}
 }
 
 func (e *ValidationError) Error() string {
 	return e.Message
 }
+
+// IsValidation сообщает, что err — *ValidationError или *ValidationErrors, в том числе
+// обернутая через %w
+func IsValidation(err error) bool {
+	var (
+		validation  *ValidationError
+		validations *ValidationErrors
+	)
+	return errors.As(err, &validation) || errors.As(err, &validations)
+}
+
+// ValidationErrors набор ошибок валидации по нескольким полям
+type ValidationErrors struct {
+	Errors []ValidationError
+}
+
+// Add добавляет ошибку поля с сообщением по ключу key
+func (e *ValidationErrors) Add(field, key string, args ...interface{}) {
+	e.Errors = append(e.Errors, *NewValidationError(field, key, args...))
+}
+
+// AddError добавляет ошибку поля из err: ключ берется из *i18n.Error, иначе
+// сообщение err отдается без перевода
+func (e *ValidationErrors) AddError(field string, err error) {
+	var keyed *i18n.Error
+	if errors.As(err, &keyed) {
+		e.Add(field, keyed.Key, keyed.Args...)
+		return
+	}
+	e.Errors = append(e.Errors, ValidationError{Field: field, Message: err.Error()})
+}
+
+// OrNil возвращает e, если есть хотя бы одна ошибка, иначе nil
+func (e *ValidationErrors) OrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// FieldErrors возвращает ошибки в виде DTO для ответа API
+func (e *ValidationErrors) FieldErrors() []domain.FieldError {
+	fieldErrors := make([]domain.FieldError, len(e.Errors))
+	for i, fe := range e.Errors {
+		fieldErrors[i] = domain.FieldError{Field: fe.Field, Message: fe.Message, Key: fe.Key, Args: fe.Args}
+	}
+	return fieldErrors
+}
+
+func (e *ValidationErrors) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"sync"
+
+	"employer/internal/domain"
+)
+
+// eventBufferSize сколько событий может ждать отправки одному подписчику
+const eventBufferSize = 64
+
+// EventBroker рассылает события об изменениях сотрудников подписчикам (клиентам SSE).
+// Медленный подписчик не задерживает остальных: событие, которое не помещается
+// в его буфер, для него теряется
+type EventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan domain.EmployeeEvent]struct{}
+	closed      bool
+}
+
+// NewEventBroker создает брокер событий без подписчиков
+func NewEventBroker() *EventBroker {
+	return &EventBroker{subscribers: make(map[chan domain.EmployeeEvent]struct{})}
+}
+
+// Subscribe возвращает канал событий и функцию отписки. После Close канал закрыт
+func (b *EventBroker) Subscribe() (<-chan domain.EmployeeEvent, func()) {
+	ch := make(chan domain.EmployeeEvent, eventBufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish отправляет событие всем подписчикам, не дожидаясь их
+func (b *EventBroker) Publish(event domain.EmployeeEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Close закрывает каналы всех подписчиков, чтобы потоки SSE завершились
+// до остановки сервера
+func (b *EventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"employer/internal/domain"
+)
+
+// Поля сотрудника, в которых ищет SearchEmployees, в порядке проверки
+const (
+	MatchFieldName  = "name"
+	MatchFieldPhone = "phone"
+	MatchFieldCity  = "city"
+)
+
+// SearchMatch место совпадения поискового запроса в сотруднике для подсветки
+type SearchMatch struct {
+	// Field поле с совпадением (MatchFieldName, MatchFieldPhone или MatchFieldCity);
+	// пусто, если совпадение не найдено
+	Field string
+	// Start и End позиции совпадения в символах (рунах) значения поля, End не включается
+	Start, End int
+}
+
+// MatchSearch находит, где в сотруднике, найденном SearchEmployees, совпал запрос: сначала
+// весь запрос, затем, для запроса из нескольких слов, его первое слово. Поля проверяются
+// в порядке name, phone, city, регистр не учитывается
+func MatchSearch(employee *domain.Employee, searchQuery string) SearchMatch {
+	searchQuery = sanitizeText(searchQuery)
+	candidates := []string{searchQuery}
+	if terms := strings.Fields(searchQuery); len(terms) > 1 {
+		candidates = append(candidates, terms[0])
+	}
+
+	fields := []struct{ name, value string }{
+		{MatchFieldName, employee.Name},
+		{MatchFieldPhone, employee.Phone},
+		{MatchFieldCity, employee.City},
+	}
+	for _, candidate := range candidates {
+		for _, field := range fields {
+			if start := indexFold(field.value, candidate); start >= 0 {
+				return SearchMatch{Field: field.name, Start: start, End: start + len([]rune(candidate))}
+			}
+		}
+	}
+	return SearchMatch{}
+}
+
+// indexFold позиция (в рунах) первого вхождения substr в s без учета регистра; -1, если
+// вхождения нет. Сравнение посимвольное, поэтому позиции совпадают с исходной строкой
+func indexFold(s, substr string) int {
+	haystack, needle := foldRunes(s), foldRunes(substr)
+	if len(needle) == 0 {
+		return -1
+	}
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if equalRunes(haystack[i:i+len(needle)], needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// foldRunes руны строки в нижнем регистре; их количество совпадает с количеством рун s
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// DefaultIdempotencyTTL сколько действует ключ Idempotency-Key по умолчанию
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// MaxIdempotencyKeyLength максимальная длина ключа (колонка idempotency_keys.key)
+const MaxIdempotencyKeyLength = 255
+
+// CreateEmployeeIdempotent создает сотрудника не больше одного раза на ключ key.
+// Повтор с тем же ключом и тем же requestHash в течение IdempotencyTTL не создает
+// запись, а заполняет employee ранее созданным сотрудником и возвращает true.
+// Тот же ключ с другим телом запроса — ConflictError.
+func (s *employeeService) CreateEmployeeIdempotent(ctx context.Context, key, requestHash string, employee *domain.Employee) (bool, error) {
+	ttl := s.opts.IdempotencyTTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	since := time.Now().Add(-ttl)
+
+	replayed := false
+	err := s.inTx(ctx, func(repo repository.EmployeeRepository) error {
+		record, err := repo.GetIdempotencyKey(ctx, key, since)
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			if record.RequestHash != requestHash {
+				return idempotencyConflict(i18n.ConflictIdempotencyKey)
+			}
+			stored, err := repo.GetByID(ctx, record.EmployeeID)
+			if err != nil {
+				return err
+			}
+			*employee = *stored
+			replayed = true
+			return nil
+		}
+
+		txService := *s
+		txService.repo = repo
+		txService.uow = activeTx{repo: repo}
+		if err := txService.CreateEmployee(ctx, employee); err != nil {
+			return err
+		}
+
+		saved, err := repo.SaveIdempotencyKey(ctx, &repository.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			EmployeeID:  employee.ID,
+		}, since)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			// Ключ сохранил параллельный запрос: созданная здесь запись откатывается
+			return idempotencyConflict(i18n.ConflictIdempotencyInProgress)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if replayed {
+		s.logger.Info("повтор создания сотрудника по ключу идемпотентности", zap.Int64("id", employee.ID))
+	}
+	return replayed, nil
+}
+
+// idempotencyConflict конфликт по заголовку Idempotency-Key с сообщением по ключу key
+func idempotencyConflict(key string) *ConflictError {
+	return &ConflictError{Field: "Idempotency-Key", Message: i18n.T(i18n.Default, key), Key: key}
+}
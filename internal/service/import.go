@@ -0,0 +1,384 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// ImportMode режим обработки ID при импорте
+type ImportMode string
+
+const (
+	// ImportModeReassign игнорирует ID из файла, сотрудникам назначаются новые ID
+	ImportModeReassign ImportMode = "reassign"
+	// ImportModeOverwrite сохраняет ID из файла и перезаписывает существующие записи
+	ImportModeOverwrite ImportMode = "overwrite"
+)
+
+// ImportOptions параметры импорта
+type ImportOptions struct {
+	Mode ImportMode
+	// Strict прерывает импорт на первой ошибочной строке
+	Strict bool
+	// DryRun проверяет строки так же, как импорт, но ничего не сохраняет; итог
+	// дополняется строками с нормализованными значениями
+	DryRun bool
+}
+
+// EmployeeReader источник записей для импорта
+type EmployeeReader interface {
+	// Next возвращает номер строки и очередную запись, io.EOF по окончании
+	// или *RecordError, если строку не удалось разобрать
+	Next() (int, *domain.Employee, error)
+}
+
+// RecordError ошибка разбора отдельной записи, после которой чтение можно продолжать
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("строка %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// jsonlReader читает сотрудников в формате JSON Lines, по объекту на строку
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewJSONLReader создает читатель JSON Lines
+func NewJSONLReader(r io.Reader) EmployeeReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &jsonlReader{scanner: scanner}
+}
+
+func (r *jsonlReader) Next() (int, *domain.Employee, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		employee := &domain.Employee{}
+		if err := json.Unmarshal(data, employee); err != nil {
+			return r.line, nil, &RecordError{Line: r.line, Err: fmt.Errorf("некорректный JSON: %w", err)}
+		}
+		return r.line, employee, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return r.line, nil, fmt.Errorf("чтение строки %d: %w", r.line+1, err)
+	}
+	return r.line, nil, io.EOF
+}
+
+// csvColumns соответствие заголовков CSV полям сотрудника; принимаются
+// как английские названия, так и заголовки выгрузки
+var csvColumns = map[string]string{
+	"id":      "id",
+	"name":    "name",
+	"имя":     "name",
+	"phone":   "phone",
+	"телефон": "phone",
+	"city":    "city",
+	"город":   "city",
+}
+
+// csvFields поля сотрудника, которые читаются из CSV
+var csvFields = []string{"id", "name", "phone", "city"}
+
+// csvReader читает сотрудников из CSV с заголовком в первой строке
+type csvReader struct {
+	reader  *csv.Reader
+	mapping map[string]string
+	columns map[string]int
+}
+
+// NewCSVReader создает читатель CSV. Обязательны колонки name, phone и city, колонка id необязательна.
+func NewCSVReader(r io.Reader) EmployeeReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return &csvReader{reader: reader}
+}
+
+// NewMappedCSVReader создает читатель CSV с собственным соответствием заголовков
+// полям, например {"ФИО": "name", "Телефон": "phone"}. Заголовки сравниваются без
+// учета регистра; колонки, которых нет в mapping, распознаются как в NewCSVReader
+func NewMappedCSVReader(r io.Reader, mapping map[string]string) (EmployeeReader, error) {
+	normalized := make(map[string]string, len(mapping))
+	for title, field := range mapping {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(csvFields, field) {
+			return nil, NewValidationError("mapping", i18n.ImportMappingField, field)
+		}
+		normalized[csvTitle(title)] = field
+	}
+
+	reader := NewCSVReader(r).(*csvReader)
+	reader.mapping = normalized
+	return reader, nil
+}
+
+// csvTitle приводит заголовок колонки к виду для сравнения
+func csvTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(title, "\ufeff")))
+}
+
+func (r *csvReader) Next() (int, *domain.Employee, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return 1, nil, err
+		}
+	}
+
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return 0, nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, nil, &RecordError{Line: parseErr.StartLine, Err: fmt.Errorf("некорректный CSV: %w", parseErr.Err)}
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("чтение CSV: %w", err)
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	field := func(name string) string {
+		idx, ok := r.columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	employee := &domain.Employee{
+		Name:  field("name"),
+		Phone: field("phone"),
+		City:  field("city"),
+	}
+	if raw := field("id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return line, nil, &RecordError{Line: line, Err: fmt.Errorf("некорректный id %q", raw)}
+		}
+		employee.ID = id
+	}
+
+	return line, employee, nil
+}
+
+// readHeader разбирает строку заголовка и запоминает позиции колонок
+func (r *csvReader) readHeader() error {
+	header, err := r.reader.Read()
+	if err == io.EOF {
+		return NewValidationError("file", i18n.ImportCSVEmpty)
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return NewValidationError("file", i18n.ImportCSVHeader)
+	}
+	if err != nil {
+		return fmt.Errorf("чтение заголовка CSV: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, title := range header {
+		title = csvTitle(title)
+		name, ok := r.mapping[title]
+		if !ok {
+			name, ok = csvColumns[title]
+		}
+		if ok {
+			columns[name] = i
+		}
+	}
+
+	for _, required := range []string{"name", "phone", "city"} {
+		if _, ok := columns[required]; !ok {
+			return NewValidationError("file", i18n.ImportCSVColumn, required)
+		}
+	}
+
+	r.columns = columns
+	return nil
+}
+
+// ImportEmployees импортирует сотрудников из reader. Ошибки отдельных строк
+// попадают в итог, а импорт продолжается; в строгом режиме импорт
+// останавливается на первой ошибке, ранее импортированные строки сохраняются.
+// При opts.DryRun строки проверяются теми же правилами, но не сохраняются.
+// С квотой (MaxEmployees) импорт, который бы ее превысил, отклоняется целиком.
+func (s *employeeService) ImportEmployees(ctx context.Context, reader EmployeeReader, opts ImportOptions) (*domain.ImportSummary, error) {
+	if opts.Mode == "" {
+		opts.Mode = ImportModeReassign
+	}
+	if opts.Mode != ImportModeReassign && opts.Mode != ImportModeOverwrite {
+		return nil, NewValidationError("mode", i18n.ImportModeUnknown)
+	}
+
+	if s.opts.MaxEmployees > 0 && !opts.DryRun {
+		var err error
+		if reader, err = s.checkImportQuota(ctx, reader, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, logger := s.opLogger(ctx, "import")
+	logger.Info("импорт сотрудников",
+		zap.String("mode", string(opts.Mode)),
+		zap.Bool("strict", opts.Strict),
+		zap.Bool("dry_run", opts.DryRun))
+
+	summary := &domain.ImportSummary{Errors: []domain.ImportLineError{}, DryRun: opts.DryRun}
+	// phones строка файла, в которой телефон встретился впервые
+	phones := make(map[string]int)
+
+	for {
+		line, employee, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = s.checkImportRow(ctx, employee, opts.Mode, phones)
+			if err == nil && !opts.DryRun {
+				err = s.importEmployee(ctx, employee, opts.Mode)
+			}
+			if err == nil {
+				phones[employee.Phone] = line
+			}
+			if opts.DryRun {
+				summary.Rows = append(summary.Rows, importRow(line, employee, err))
+			}
+		} else if _, ok := err.(*RecordError); !ok {
+			return nil, fmt.Errorf("импорт сотрудников: %w", err)
+		}
+
+		if err != nil {
+			logger.Warn("ошибка импорта строки", zap.Int("line", line), zap.Error(err))
+			summary.Failed++
+			summary.Errors = append(summary.Errors, domain.ImportLineError{Line: line, Error: lineErrorMessage(err)})
+			if opts.Strict {
+				summary.Aborted = true
+				break
+			}
+			continue
+		}
+		summary.Imported++
+	}
+
+	if opts.Mode == ImportModeOverwrite && summary.Imported > 0 && !opts.DryRun {
+		if err := s.repo.SyncIDSequence(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("импорт сотрудников завершен",
+		zap.Int("imported", summary.Imported),
+		zap.Int("failed", summary.Failed),
+		zap.Bool("aborted", summary.Aborted),
+		zap.Bool("dry_run", opts.DryRun))
+
+	return summary, nil
+}
+
+// checkImportRow нормализует и проверяет запись импорта; общая проверка для импорта
+// и dry run. phones — телефоны предыдущих успешных строк файла. Телефон не должен
+// быть занят в БД, кроме как перезаписываемой записью с тем же ID
+func (s *employeeService) checkImportRow(ctx context.Context, employee *domain.Employee, mode ImportMode, phones map[string]int) error {
+	s.normalizeEmployee(employee)
+	if err := s.validateEmployee(employee); err != nil {
+		return err
+	}
+
+	var excludeID []int64
+	if mode == ImportModeOverwrite {
+		if employee.ID <= 0 {
+			return NewValidationError("id", i18n.ImportOverwriteID)
+		}
+		excludeID = append(excludeID, employee.ID)
+	} else {
+		employee.ID = 0
+	}
+
+	if line, ok := phones[employee.Phone]; ok {
+		return NewValidationError("phone", i18n.ImportPhoneRepeated, line)
+	}
+	exists, err := s.repo.CheckPhoneExists(ctx, employee.Phone, excludeID...)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return NewValidationError("phone", i18n.PhoneTaken)
+	}
+	return nil
+}
+
+// importEmployee сохраняет запись импорта, прошедшую checkImportRow. Авторы из файла
+// сохраняются (восстановление из выгрузки не должно их терять); пустые заполняются
+// пользователем, выполняющим импорт
+func (s *employeeService) importEmployee(ctx context.Context, employee *domain.Employee, mode ImportMode) error {
+	if employee.CreatedBy == "" {
+		employee.CreatedBy = ActorFromContext(ctx)
+	}
+	if employee.UpdatedBy == "" {
+		employee.UpdatedBy = employee.CreatedBy
+	}
+
+	if mode == ImportModeOverwrite {
+		return s.repo.CreateWithID(ctx, employee)
+	}
+	return s.repo.Create(ctx, employee)
+}
+
+// importRow строка отчета dry run с нормализованными значениями записи
+func importRow(line int, employee *domain.Employee, err error) domain.ImportRow {
+	row := domain.ImportRow{Line: line, ID: employee.ID, Name: employee.Name, Phone: employee.Phone, City: employee.City}
+	if err != nil {
+		row.Error = lineErrorMessage(err)
+	}
+	return row
+}
+
+// lineErrorMessage возвращает сообщение об ошибке строки без внутренних деталей БД
+func lineErrorMessage(err error) string {
+	var (
+		validation  *ValidationError
+		validations *ValidationErrors
+		record      *RecordError
+	)
+	switch {
+	case errors.As(err, &validation):
+		return validation.Message
+	case errors.As(err, &validations):
+		return validations.Error()
+	case errors.As(err, &record):
+		return record.Err.Error()
+	default:
+		return "ошибка сохранения записи"
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID возвращает контекст запроса с идентификатором id; он попадает в поле
+// request_id записей лога сервиса и репозитория
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext возвращает идентификатор запроса из контекста или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// opLogger возвращает логгер операции operation с идентификатором запроса и контекст,
+// через который тот же логгер получит репозиторий. Сервис логирует намерение (что и с
+// какими параметрами делается), репозиторий — результат обращения к БД. Внутри другой
+// операции (например, создание при импорте) остается логгер внешней операции
+func (s *employeeService) opLogger(ctx context.Context, operation string) (context.Context, *zap.Logger) {
+	if logger := repository.LoggerFromContext(ctx, nil); logger != nil {
+		return ctx, logger
+	}
+	logger := s.logger.With(zap.String("operation", operation))
+	if id := RequestIDFromContext(ctx); id != "" {
+		logger = logger.With(zap.String("request_id", id))
+	}
+	return repository.WithLogger(ctx, logger), logger
+}
+
+// log логгер операции из ctx (см. opLogger) или логгер сервиса
+func (s *employeeService) log(ctx context.Context) *zap.Logger {
+	return repository.LoggerFromContext(ctx, s.logger)
+}
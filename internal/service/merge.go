@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+	"employer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// DuplicatePhoneDigits сколько первых цифр телефона должно совпасть у возможных дубликатов:
+// казахстанский номер из 11 цифр (+77011234567) без последней
+const DuplicatePhoneDigits = 10
+
+// Размер отчета о дубликатах: без limit и наибольший
+const (
+	DefaultDuplicatesLimit = 100
+	MaxDuplicatesLimit     = 1000
+)
+
+// MergeEmployees вливает сотрудника sourceID в targetID в одной транзакции: переносит
+// на target ссылки других таблиц, при fillMissing — поля источника, не заполненные у target,
+// записывает слияние в журнал и удаляет источник. Последняя версия источника сохраняется
+// в историю, как при обновлении. Отсутствующий target или источник — NotFoundError
+func (s *employeeService) MergeEmployees(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error) {
+	if sourceID <= 0 {
+		return nil, NewValidationError("source_id", i18n.MergeSourceRequired)
+	}
+	if sourceID == targetID {
+		return nil, NewValidationError("source_id", i18n.MergeSelf)
+	}
+
+	ctx, logger := s.opLogger(ctx, "merge")
+	logger.Info("слияние сотрудников",
+		zap.Int64("target_id", targetID), zap.Int64("source_id", sourceID), zap.Bool("fill_missing", fillMissing))
+
+	result := &domain.MergeResult{SourceID: sourceID, CopiedFields: []string{}}
+	err := s.inTx(ctx, func(repo repository.EmployeeRepository) error {
+		target, err := repo.GetByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		source, err := repo.GetByID(ctx, sourceID)
+		if err != nil {
+			return err
+		}
+		actor := ActorFromContext(ctx)
+
+		if fillMissing {
+			result.CopiedFields = fillMissingFields(target, source)
+		}
+		if len(result.CopiedFields) > 0 {
+			if err := repo.ArchiveVersion(ctx, targetID); err != nil {
+				return err
+			}
+			// target прочитан в этой транзакции: его версия защищает от параллельного изменения
+			target.UpdatedBy = actor
+			if err := repo.Update(ctx, target); err != nil {
+				return err
+			}
+		}
+
+		if err := repo.ReassignEmployee(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+		merge := &domain.EmployeeMerge{
+			SourceID:     sourceID,
+			TargetID:     targetID,
+			Source:       source,
+			CopiedFields: result.CopiedFields,
+			MergedBy:     actor,
+		}
+		if err := repo.SaveMerge(ctx, merge); err != nil {
+			return err
+		}
+		if err := repo.ArchiveVersion(ctx, sourceID); err != nil {
+			return err
+		}
+		if err := repo.Delete(ctx, sourceID); err != nil {
+			return err
+		}
+
+		result.Employee = target
+		return nil
+	})
+	if err != nil {
+		return nil, withOp(conflictFromRepository(err), "слияние сотрудника %d с %d", sourceID, targetID)
+	}
+	return result, nil
+}
+
+// fillMissingFields переносит в target даты source, не указанные у target, и возвращает
+// имена перенесенных полей. Имя, телефон и город обязательны, поэтому всегда заполнены
+func fillMissingFields(target, source *domain.Employee) []string {
+	copied := []string{}
+	if target.BirthDate == nil && source.BirthDate != nil {
+		target.BirthDate = source.BirthDate
+		copied = append(copied, "birth_date")
+	}
+	if target.HireDate == nil && source.HireDate != nil {
+		target.HireDate = source.HireDate
+		copied = append(copied, "hire_date")
+	}
+	return copied
+}
+
+// FindDuplicates возвращает до limit пар возможных дубликатов: с совпадающими первыми
+// DuplicatePhoneDigits цифрами телефона или с одинаковыми именем и городом.
+// limit 0 — DefaultDuplicatesLimit, допустимо до MaxDuplicatesLimit
+func (s *employeeService) FindDuplicates(ctx context.Context, limit int) ([]*domain.DuplicatePair, error) {
+	if limit == 0 {
+		limit = DefaultDuplicatesLimit
+	}
+	if limit < 1 || limit > MaxDuplicatesLimit {
+		return nil, NewValidationError("limit", i18n.LimitRange, MaxDuplicatesLimit)
+	}
+
+	s.logger.Info("поиск дубликатов сотрудников", zap.Int("limit", limit))
+	pairs, err := s.repo.FindDuplicates(ctx, DuplicatePhoneDigits, limit)
+	return pairs, withOp(err, "поиск дубликатов сотрудников")
+}
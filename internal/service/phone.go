@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"employer/internal/i18n"
+)
+
+// Регионы проверки телефонов (PHONE_REGION)
+const (
+	PhoneRegionKZ   = "KZ"
+	PhoneRegionRU   = "RU"
+	PhoneRegionINTL = "INTL"
+)
+
+// PhoneValidator проверяет формат телефона. Возвращаемая ошибка содержит
+// сообщение для пользователя; *i18n.Error переводится на язык клиента.
+type PhoneValidator interface {
+	// CanonicalPhone приводит номер к единой записи (E.164 без оформления), чтобы
+	// "+7 701 000-00-00" и "87010000000" хранились и сравнивались как "+77010000000".
+	// Некорректный номер возвращается без оформления и отклоняется ValidatePhone
+	CanonicalPhone(phone string) string
+	ValidatePhone(phone string) error
+}
+
+// NewPhoneValidator возвращает проверку телефонов для региона KZ, RU или INTL
+func NewPhoneValidator(region string) (PhoneValidator, error) {
+	switch strings.ToUpper(region) {
+	case PhoneRegionKZ:
+		return kzPhoneValidator{}, nil
+	case PhoneRegionRU:
+		return ruPhoneValidator{}, nil
+	case PhoneRegionINTL:
+		return intlPhoneValidator{}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный регион телефонов %q", region)
+	}
+}
+
+// phoneFormatting символы оформления, допустимые в номере: "+7 (701) 000-00-00"
+var phoneFormatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+
+// stripPhone убирает из номера пробелы по краям и символы оформления
+func stripPhone(phone string) string {
+	return phoneFormatting.Replace(strings.TrimSpace(phone))
+}
+
+// canonicalPlus7 записывает номер с кодом 8 через +7: "87010000000" — "+77010000000"
+func canonicalPlus7(phone string) string {
+	phone = stripPhone(phone)
+	if len(phone) == 11 && strings.HasPrefix(phone, "8") {
+		return "+7" + phone[1:]
+	}
+	return phone
+}
+
+var (
+	// kzPhonePattern казахстанские номера: код страны +7 (или 8), далее код 6xx/7xx и 7 цифр
+	kzPhonePattern = regexp.MustCompile(`^(\+7|8)[67]\d{9}$`)
+	// ruPhonePattern российские номера: +7 (или 8), далее коды 3xx, 4xx, 8xx, 9xx
+	ruPhonePattern = regexp.MustCompile(`^(\+7|8)[3489]\d{9}$`)
+	// e164Pattern международный формат E.164: "+", код страны и не более 15 цифр всего
+	e164Pattern = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+)
+
+type kzPhoneValidator struct{}
+
+func (kzPhoneValidator) CanonicalPhone(phone string) string {
+	return canonicalPlus7(phone)
+}
+
+func (kzPhoneValidator) ValidatePhone(phone string) error {
+	if !kzPhonePattern.MatchString(phoneFormatting.Replace(phone)) {
+		return &i18n.Error{Key: i18n.PhoneFormatKZ}
+	}
+	return nil
+}
+
+type ruPhoneValidator struct{}
+
+func (ruPhoneValidator) CanonicalPhone(phone string) string {
+	return canonicalPlus7(phone)
+}
+
+func (ruPhoneValidator) ValidatePhone(phone string) error {
+	if !ruPhonePattern.MatchString(phoneFormatting.Replace(phone)) {
+		return &i18n.Error{Key: i18n.PhoneFormatRU}
+	}
+	return nil
+}
+
+type intlPhoneValidator struct{}
+
+func (intlPhoneValidator) CanonicalPhone(phone string) string {
+	return stripPhone(phone)
+}
+
+func (intlPhoneValidator) ValidatePhone(phone string) error {
+	if !e164Pattern.MatchString(phoneFormatting.Replace(phone)) {
+		return &i18n.Error{Key: i18n.PhoneFormatINTL}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"employer/internal/domain"
+	"employer/internal/i18n"
+
+	"go.uber.org/zap"
+)
+
+// QuotaError создание сотрудников превысило бы квоту MaxEmployees. Проверка идет
+// до записи, поэтому одновременные создания могут превысить квоту на несколько записей
+type QuotaError struct {
+	// Limit квота (MAX_EMPLOYEES)
+	Limit int
+	// Count сколько сотрудников уже есть
+	Count int64
+	// Requested сколько сотрудников пытались добавить
+	Requested int
+}
+
+func (e *QuotaError) Error() string {
+	return i18n.T(i18n.Default, i18n.QuotaExceeded, e.Limit)
+}
+
+// checkQuota возвращает QuotaError, если добавление added сотрудников превысит квоту
+func (s *employeeService) checkQuota(ctx context.Context, added int) error {
+	if s.opts.MaxEmployees <= 0 || added <= 0 {
+		return nil
+	}
+
+	count, err := s.repo.CountWithFilter(ctx, domain.EmployeeFilter{})
+	if err != nil {
+		return withOp(err, "проверка квоты сотрудников")
+	}
+	if count+int64(added) > int64(s.opts.MaxEmployees) {
+		s.log(ctx).Warn("превышена квота сотрудников",
+			zap.Int("limit", s.opts.MaxEmployees),
+			zap.Int64("count", count),
+			zap.Int("requested", added))
+		return &QuotaError{Limit: s.opts.MaxEmployees, Count: count, Requested: added}
+	}
+	return nil
+}
+
+// checkImportQuota проверяет квоту для всего импорта сразу: файл проверяется как при
+// dry run, и если новых сотрудников окажется больше, чем позволяет квота, импорт
+// отклоняется целиком. Возвращает читатель, который повторно отдает прочитанные записи
+func (s *employeeService) checkImportQuota(ctx context.Context, reader EmployeeReader, opts ImportOptions) (EmployeeReader, error) {
+	records, err := readImportRecords(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.DryRun = true
+	summary, err := s.ImportEmployees(ctx, records.replay(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	added := summary.Imported
+	if opts.Mode == ImportModeOverwrite {
+		// Перезапись существующих сотрудников их количество не меняет
+		var ids []int64
+		for _, row := range summary.Rows {
+			if row.Error == "" {
+				ids = append(ids, row.ID)
+			}
+		}
+		if len(ids) > 0 {
+			existing, err := s.repo.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, withOp(err, "проверка квоты сотрудников")
+			}
+			added -= len(existing)
+		}
+	}
+
+	if err := s.checkQuota(ctx, added); err != nil {
+		return nil, err
+	}
+	return records.replay(), nil
+}
+
+// importRecord запись импорта, прочитанная заранее
+type importRecord struct {
+	line     int
+	employee *domain.Employee
+	err      error
+}
+
+type importRecords []importRecord
+
+// readImportRecords читает все записи reader; ошибки разбора строк сохраняются вместе с записями
+func readImportRecords(reader EmployeeReader) (importRecords, error) {
+	var records importRecords
+	for {
+		line, employee, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if _, ok := err.(*RecordError); err != nil && !ok {
+			return nil, err
+		}
+		records = append(records, importRecord{line: line, employee: employee, err: err})
+	}
+}
+
+// replay возвращает читатель, отдающий копии записей: проверка не меняет данные
+// для следующего прохода
+func (r importRecords) replay() EmployeeReader {
+	return &replayReader{records: r}
+}
+
+type replayReader struct {
+	records importRecords
+	next    int
+}
+
+func (r *replayReader) Next() (int, *domain.Employee, error) {
+	if r.next >= len(r.records) {
+		return 0, nil, io.EOF
+	}
+	record := r.records[r.next]
+	r.next++
+	if record.err != nil {
+		return record.line, nil, record.err
+	}
+	employee := *record.employee
+	return record.line, &employee, nil
+}
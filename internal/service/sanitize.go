@@ -0,0 +1,30 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// sanitizeText приводит строку к NFC ("Алма-Ата", набранная с разложенными буквами,
+// совпадет с обычной), убирает пробелы по краям и схлопывает внутренние пробельные
+// последовательности (включая табуляцию и переводы строк) в один пробел
+func sanitizeText(s string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(s)), " ")
+}
+
+// markupChars символы, из которых строится HTML-разметка. Имя и город выводятся
+// в веб-интерфейсе, поэтому такие значения отклоняются, а не экранируются: в БД
+// остаются чистые данные. Апострофы и дефисы ("O'Brien", "Алма-Ата") допустимы
+const markupChars = `<>&"`
+
+// hasMarkup проверяет наличие символов HTML-разметки
+func hasMarkup(s string) bool {
+	return strings.ContainsAny(s, markupChars)
+}
+
+// hasControlChars проверяет наличие управляющих символов (например \x00 или \x1b)
+func hasControlChars(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"employer/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultSearchCacheTTL время жизни закэшированных результатов поиска по умолчанию
+const DefaultSearchCacheTTL = 5 * time.Second
+
+// MaxSearchCacheEntries наибольшее количество страниц поиска в кэше: при подсказках по
+// мере ввода каждый префикс — отдельный ключ, поэтому сверх предела вытесняются старейшие
+const MaxSearchCacheEntries = 1000
+
+// Значения метки result счетчика NewSearchCacheCounter
+const (
+	searchCacheHit    = "hit"
+	searchCacheMiss   = "miss"
+	searchCacheMerged = "merged"
+)
+
+// NewSearchCacheCounter создает счетчик обращений к кэшу поиска с меткой result:
+// hit — ответ из кэша, miss — запрос к БД, merged — ожидание такого же запроса другого клиента
+func NewSearchCacheCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "employer_search_cache_requests_total",
+		Help: "Обращения к кэшу поиска сотрудников",
+	}, []string{"result"})
+}
+
+// searchResult закэшированная страница поиска; срезы общие для всех читателей и не изменяются
+type searchResult struct {
+	employees []*domain.Employee
+	total     int64
+	expires   time.Time
+	// key и element — ключ в results и место в очереди order
+	key     string
+	element *list.Element
+}
+
+// searchCacheService декоратор EmployeeService, кэширующий SearchEmployees на ttl.
+// Одновременные одинаковые запросы выполняют один запрос к БД. Любое изменение
+// сотрудников через сервис сбрасывает весь кэш; изменения, сделанные другими
+// экземплярами приложения, видны не позже чем через ttl. Устаревшие записи удаляются
+// при обращении и при сохранении новых, записей не больше maxEntries.
+type searchCacheService struct {
+	EmployeeService
+	ttl      time.Duration
+	now      func() time.Time
+	requests *prometheus.CounterVec
+	group    singleflight.Group
+
+	mu         sync.RWMutex
+	results    map[string]*searchResult
+	maxEntries int
+	// order записи в порядке сохранения; ttl у всех одинаковый, поэтому это и порядок
+	// устаревания: в начале — старейшие
+	order *list.List
+	// generation растет при каждом сбросе: результат запроса, начатого до сброса,
+	// в кэш не попадает
+	generation uint64
+}
+
+// NewSearchCacheService оборачивает next кэшем поиска с временем жизни ttl;
+// requests — счетчик из NewSearchCacheCounter, nil — без метрик
+func NewSearchCacheService(next EmployeeService, ttl time.Duration, requests *prometheus.CounterVec) EmployeeService {
+	return &searchCacheService{
+		EmployeeService: next,
+		ttl:             ttl,
+		now:             time.Now,
+		requests:        requests,
+		results:         make(map[string]*searchResult),
+		maxEntries:      MaxSearchCacheEntries,
+		order:           list.New(),
+	}
+}
+
+// searchCacheKey ключ кэша: поиск не зависит от регистра и лишних пробелов
+func searchCacheKey(searchQuery string, filter domain.EmployeeFilter, limit, offset int) string {
+	searchQuery = strings.ToLower(strings.Join(strings.Fields(searchQuery), " "))
+	return fmt.Sprintf("%q|%q|%q|%q|%t|%d|%d|%d|%d", searchQuery, strings.ToLower(filter.City), filter.Status, filter.Sort, filter.Desc,
+		filter.CreatedFrom.UnixMicro(), filter.CreatedTo.UnixMicro(), limit, offset)
+}
+
+// SearchEmployees отдает результаты из кэша или выполняет поиск одним запросом на всех ожидающих
+func (s *searchCacheService) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	key := searchCacheKey(searchQuery, filter, limit, offset)
+
+	s.mu.RLock()
+	cached, generation := s.results[key], s.generation
+	s.mu.RUnlock()
+	if cached != nil {
+		if s.now().Before(cached.expires) {
+			s.observe(searchCacheHit)
+			return cached.employees, cached.total, nil
+		}
+		s.mu.Lock()
+		s.remove(cached)
+		s.mu.Unlock()
+	}
+
+	// Поколение в ключе: запросы после сброса не присоединяются к начатому до него.
+	// Функцию выполняет только первый из ожидающих, остальные получают ее результат
+	leader := false
+	v, err, _ := s.group.Do(fmt.Sprintf("%d|%s", generation, key), func() (interface{}, error) {
+		leader = true
+		// Запрос общий для всех ожидающих, поэтому отмена одного из них его не прерывает
+		employees, total, err := s.EmployeeService.SearchEmployees(context.WithoutCancel(ctx), searchQuery, filter, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &searchResult{employees: employees, total: total, expires: s.now().Add(s.ttl), key: key}
+		s.mu.Lock()
+		if s.generation == generation {
+			s.store(result)
+		}
+		s.mu.Unlock()
+		return result, nil
+	})
+	if leader {
+		s.observe(searchCacheMiss)
+	} else {
+		s.observe(searchCacheMerged)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	result := v.(*searchResult)
+	return result.employees, result.total, nil
+}
+
+func (s *searchCacheService) observe(result string) {
+	if s.requests != nil {
+		s.requests.WithLabelValues(result).Inc()
+	}
+}
+
+// store сохраняет результат, удаляя устаревшие записи и, сверх maxEntries, старейшие.
+// Вызывается под s.mu
+func (s *searchCacheService) store(result *searchResult) {
+	if previous := s.results[result.key]; previous != nil {
+		s.remove(previous)
+	}
+	result.element = s.order.PushBack(result)
+	s.results[result.key] = result
+
+	now := s.now()
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		oldest := front.Value.(*searchResult)
+		if now.Before(oldest.expires) && s.order.Len() <= s.maxEntries {
+			break
+		}
+		s.remove(oldest)
+	}
+}
+
+// remove удаляет запись, если она еще в кэше. Вызывается под s.mu
+func (s *searchCacheService) remove(result *searchResult) {
+	if s.results[result.key] != result {
+		return
+	}
+	delete(s.results, result.key)
+	s.order.Remove(result.element)
+}
+
+// invalidate сбрасывает весь кэш поиска
+func (s *searchCacheService) invalidate() {
+	s.mu.Lock()
+	s.results = make(map[string]*searchResult)
+	s.order.Init()
+	s.generation++
+	s.mu.Unlock()
+}
+
+// Изменения сотрудников сбрасывают кэш после выполнения, в том числе неудачного:
+// часть пакетной операции могла успеть примениться
+
+func (s *searchCacheService) CreateEmployee(ctx context.Context, employee *domain.Employee) error {
+	defer s.invalidate()
+	return s.EmployeeService.CreateEmployee(ctx, employee)
+}
+
+func (s *searchCacheService) CreateEmployeeIdempotent(ctx context.Context, key, requestHash string, employee *domain.Employee) (bool, error) {
+	defer s.invalidate()
+	return s.EmployeeService.CreateEmployeeIdempotent(ctx, key, requestHash, employee)
+}
+
+func (s *searchCacheService) UpdateEmployee(ctx context.Context, employee *domain.Employee) error {
+	defer s.invalidate()
+	return s.EmployeeService.UpdateEmployee(ctx, employee)
+}
+
+func (s *searchCacheService) SetEmployeeStatus(ctx context.Context, id int64, status string) (*domain.Employee, error) {
+	defer s.invalidate()
+	return s.EmployeeService.SetEmployeeStatus(ctx, id, status)
+}
+
+func (s *searchCacheService) UpdateEmployees(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error) {
+	defer s.invalidate()
+	return s.EmployeeService.UpdateEmployees(ctx, items, strict)
+}
+
+func (s *searchCacheService) DeleteEmployee(ctx context.Context, id int64) error {
+	defer s.invalidate()
+	return s.EmployeeService.DeleteEmployee(ctx, id)
+}
+
+func (s *searchCacheService) DeleteEmployeeIfExists(ctx context.Context, id int64) (bool, error) {
+	defer s.invalidate()
+	return s.EmployeeService.DeleteEmployeeIfExists(ctx, id)
+}
+
+func (s *searchCacheService) DeleteEmployees(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error) {
+	if !dryRun {
+		defer s.invalidate()
+	}
+	return s.EmployeeService.DeleteEmployees(ctx, ids, dryRun)
+}
+
+func (s *searchCacheService) MergeEmployees(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error) {
+	defer s.invalidate()
+	return s.EmployeeService.MergeEmployees(ctx, targetID, sourceID, fillMissing)
+}
+
+func (s *searchCacheService) ImportEmployees(ctx context.Context, reader EmployeeReader, opts ImportOptions) (*domain.ImportSummary, error) {
+	if !opts.DryRun {
+		defer s.invalidate()
+	}
+	return s.EmployeeService.ImportEmployees(ctx, reader, opts)
+}
+
+func (s *searchCacheService) WithTx(ctx context.Context, fn func(svc EmployeeService) error) error {
+	defer s.invalidate()
+	return s.EmployeeService.WithTx(ctx, fn)
+}
@@ -1,29 +1,75 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"employer/internal/domain"
+	"employer/internal/i18n"
 	"employer/internal/repository"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // мок репозитория под интерфейс repository.EmployeeRepository
 type mockRepo struct {
 	CreateFn             func(ctx context.Context, e *domain.Employee) error
-	GetByIDFn            func(ctx context.Context, id int) (*domain.Employee, error)
+	GetByIDFn            func(ctx context.Context, id int64) (*domain.Employee, error)
 	GetAllFn             func(ctx context.Context) ([]*domain.Employee, error)
+	GetRecentFn          func(ctx context.Context, limit int) ([]*domain.Employee, error)
+	GetByCreatedRangeFn  func(ctx context.Context, from, to time.Time) ([]*domain.Employee, error)
+	GetAllCursorFn       func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	GetPageFn            func(ctx context.Context, limit, offset int) ([]*domain.Employee, error)
+	CountWithFilterFn    func(ctx context.Context, filter domain.EmployeeFilter) (int64, error)
 	UpdateFn             func(ctx context.Context, e *domain.Employee) error
-	DeleteFn             func(ctx context.Context, id int) error
+	DeleteFn             func(ctx context.Context, id int64) error
 	GetByPhoneFn         func(ctx context.Context, phone string) (*domain.Employee, error)
 	SearchEmployeesFn    func(ctx context.Context, searchQuery string) ([]*domain.Employee, error)
+	SearchPageFn         func(ctx context.Context, searchQuery string, limit, offset int) ([]*domain.Employee, int64, error)
 	GetEmployeesByCityFn func(ctx context.Context, city string) ([]*domain.Employee, error)
 	GetEmployeeStatsFn   func(ctx context.Context) (*repository.EmployeeStats, error)
-	CheckPhoneExistsFn   func(ctx context.Context, phone string, excludeID ...int) (bool, error)
+	GetCityCountsFn      func(ctx context.Context) ([]*domain.CityCount, error)
+	AnniversariesFn      func(ctx context.Context, from time.Time, withinDays int) ([]*domain.Anniversary, error)
+	CheckPhoneExistsFn   func(ctx context.Context, phone string, excludeID ...int64) (bool, error)
+	StreamEmployeesFn    func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	CreateWithIDFn       func(ctx context.Context, e *domain.Employee) error
+	SyncIDSequenceFn     func(ctx context.Context) error
+	PurgeSoftDeletedFn   func(ctx context.Context, olderThan time.Time) (int64, error)
+	TruncateFn           func(ctx context.Context) error
+	ArchiveVersionFn     func(ctx context.Context, id int64) error
+	GetHistoryFn         func(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error)
+	GetAsOfFn            func(ctx context.Context, id int64, at time.Time) (*domain.Employee, error)
+	DeleteManyFn         func(ctx context.Context, ids []int64) ([]int64, error)
+	UpdateBatchFn        func(ctx context.Context, employees []*domain.Employee) ([]int64, error)
+	ExistingIDsFn        func(ctx context.Context, ids []int64) ([]int64, error)
+	GetByIDsFn           func(ctx context.Context, ids []int64) ([]*domain.Employee, error)
+	UpdateStatusFn       func(ctx context.Context, id int64, status string) error
+	GetIdempotencyKeyFn  func(ctx context.Context, key string, since time.Time) (*repository.IdempotencyRecord, error)
+	SaveIdempotencyKeyFn func(ctx context.Context, record *repository.IdempotencyRecord, since time.Time) (bool, error)
+	ReassignEmployeeFn   func(ctx context.Context, fromID, toID int64) error
+	SaveMergeFn          func(ctx context.Context, merge *domain.EmployeeMerge) error
+	FindDuplicatesFn     func(ctx context.Context, phoneDigits, limit int) ([]*domain.DuplicatePair, error)
+	UpsertAttendanceFn   func(ctx context.Context, attendance *domain.Attendance) error
+	GetAttendanceFn      func(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error)
+	AttendanceSummaryFn  func(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error)
+
+	// lastFilter фильтр последнего вызова GetAll, GetPage или SearchEmployees
+	lastFilter domain.EmployeeFilter
+	// lastMaxRows предел строк последнего вызова GetAll
+	lastMaxRows int
 }
 
 func (m *mockRepo) Create(ctx context.Context, e *domain.Employee) error {
@@ -33,20 +79,59 @@ func (m *mockRepo) Create(ctx context.Context, e *domain.Employee) error {
 	return nil
 }
 
-func (m *mockRepo) GetByID(ctx context.Context, id int) (*domain.Employee, error) {
+func (m *mockRepo) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
 	if m.GetByIDFn != nil {
 		return m.GetByIDFn(ctx, id)
 	}
 	return nil, nil
 }
 
-func (m *mockRepo) GetAll(ctx context.Context) ([]*domain.Employee, error) {
+func (m *mockRepo) GetAll(ctx context.Context, filter domain.EmployeeFilter, maxRows int) ([]*domain.Employee, error) {
+	m.lastFilter = filter
+	m.lastMaxRows = maxRows
 	if m.GetAllFn != nil {
 		return m.GetAllFn(ctx)
 	}
 	return nil, nil
 }
 
+func (m *mockRepo) GetPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, error) {
+	m.lastFilter = filter
+	if m.GetPageFn != nil {
+		return m.GetPageFn(ctx, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetRecent(ctx context.Context, limit int) ([]*domain.Employee, error) {
+	if m.GetRecentFn != nil {
+		return m.GetRecentFn(ctx, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetAllCursor(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	m.lastFilter = filter
+	if m.GetAllCursorFn != nil {
+		return m.GetAllCursorFn(ctx, filter, fn)
+	}
+	return nil
+}
+
+func (m *mockRepo) GetByCreatedRange(ctx context.Context, from, to time.Time) ([]*domain.Employee, error) {
+	if m.GetByCreatedRangeFn != nil {
+		return m.GetByCreatedRangeFn(ctx, from, to)
+	}
+	return []*domain.Employee{}, nil
+}
+
+func (m *mockRepo) CountWithFilter(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+	if m.CountWithFilterFn != nil {
+		return m.CountWithFilterFn(ctx, filter)
+	}
+	return 0, nil
+}
+
 func (m *mockRepo) Update(ctx context.Context, e *domain.Employee) error {
 	if m.UpdateFn != nil {
 		return m.UpdateFn(ctx, e)
@@ -54,7 +139,7 @@ func (m *mockRepo) Update(ctx context.Context, e *domain.Employee) error {
 	return nil
 }
 
-func (m *mockRepo) Delete(ctx context.Context, id int) error {
+func (m *mockRepo) Delete(ctx context.Context, id int64) error {
 	if m.DeleteFn != nil {
 		return m.DeleteFn(ctx, id)
 	}
@@ -68,11 +153,16 @@ func (m *mockRepo) GetByPhone(ctx context.Context, phone string) (*domain.Employ
 	return nil, nil
 }
 
-func (m *mockRepo) SearchEmployees(ctx context.Context, searchQuery string) ([]*domain.Employee, error) {
+func (m *mockRepo) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	m.lastFilter = filter
+	if m.SearchPageFn != nil {
+		return m.SearchPageFn(ctx, searchQuery, limit, offset)
+	}
 	if m.SearchEmployeesFn != nil {
-		return m.SearchEmployeesFn(ctx, searchQuery)
+		employees, err := m.SearchEmployeesFn(ctx, searchQuery)
+		return employees, int64(len(employees)), err
 	}
-	return []*domain.Employee{}, nil
+	return []*domain.Employee{}, 0, nil
 }
 
 func (m *mockRepo) GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error) {
@@ -82,6 +172,20 @@ func (m *mockRepo) GetEmployeesByCity(ctx context.Context, city string) ([]*doma
 	return []*domain.Employee{}, nil
 }
 
+func (m *mockRepo) GetCityCounts(ctx context.Context) ([]*domain.CityCount, error) {
+	if m.GetCityCountsFn != nil {
+		return m.GetCityCountsFn(ctx)
+	}
+	return []*domain.CityCount{}, nil
+}
+
+func (m *mockRepo) GetUpcomingAnniversaries(ctx context.Context, from time.Time, withinDays int) ([]*domain.Anniversary, error) {
+	if m.AnniversariesFn != nil {
+		return m.AnniversariesFn(ctx, from, withinDays)
+	}
+	return nil, nil
+}
+
 func (m *mockRepo) GetEmployeeStats(ctx context.Context) (*repository.EmployeeStats, error) {
 	if m.GetEmployeeStatsFn != nil {
 		return m.GetEmployeeStatsFn(ctx)
@@ -89,13 +193,160 @@ func (m *mockRepo) GetEmployeeStats(ctx context.Context) (*repository.EmployeeSt
 	return &repository.EmployeeStats{}, nil
 }
 
-func (m *mockRepo) CheckPhoneExists(ctx context.Context, phone string, excludeID ...int) (bool, error) {
+func (m *mockRepo) CheckPhoneExists(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
 	if m.CheckPhoneExistsFn != nil {
 		return m.CheckPhoneExistsFn(ctx, phone, excludeID...)
 	}
 	return false, nil
 }
 
+func (m *mockRepo) StreamEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	if m.StreamEmployeesFn != nil {
+		return m.StreamEmployeesFn(ctx, filter, fn)
+	}
+	return nil
+}
+
+func (m *mockRepo) CreateWithID(ctx context.Context, e *domain.Employee) error {
+	if m.CreateWithIDFn != nil {
+		return m.CreateWithIDFn(ctx, e)
+	}
+	return nil
+}
+
+func (m *mockRepo) SyncIDSequence(ctx context.Context) error {
+	if m.SyncIDSequenceFn != nil {
+		return m.SyncIDSequenceFn(ctx)
+	}
+	return nil
+}
+
+func (m *mockRepo) PurgeSoftDeleted(ctx context.Context, olderThan time.Time) (int64, error) {
+	if m.PurgeSoftDeletedFn != nil {
+		return m.PurgeSoftDeletedFn(ctx, olderThan)
+	}
+	return 0, nil
+}
+
+func (m *mockRepo) Truncate(ctx context.Context) error {
+	if m.TruncateFn != nil {
+		return m.TruncateFn(ctx)
+	}
+	return nil
+}
+
+func (m *mockRepo) DeleteMany(ctx context.Context, ids []int64) ([]int64, error) {
+	if m.DeleteManyFn != nil {
+		return m.DeleteManyFn(ctx, ids)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) UpdateBatch(ctx context.Context, employees []*domain.Employee) ([]int64, error) {
+	if m.UpdateBatchFn != nil {
+		return m.UpdateBatchFn(ctx, employees)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+	if m.GetByIDsFn != nil {
+		return m.GetByIDsFn(ctx, ids)
+	}
+	return []*domain.Employee{}, nil
+}
+
+func (m *mockRepo) ExistingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	if m.ExistingIDsFn != nil {
+		return m.ExistingIDsFn(ctx, ids)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetIdempotencyKey(ctx context.Context, key string, since time.Time) (*repository.IdempotencyRecord, error) {
+	if m.GetIdempotencyKeyFn != nil {
+		return m.GetIdempotencyKeyFn(ctx, key, since)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) SaveIdempotencyKey(ctx context.Context, record *repository.IdempotencyRecord, since time.Time) (bool, error) {
+	if m.SaveIdempotencyKeyFn != nil {
+		return m.SaveIdempotencyKeyFn(ctx, record, since)
+	}
+	return true, nil
+}
+
+func (m *mockRepo) ReassignEmployee(ctx context.Context, fromID, toID int64) error {
+	if m.ReassignEmployeeFn != nil {
+		return m.ReassignEmployeeFn(ctx, fromID, toID)
+	}
+	return nil
+}
+
+func (m *mockRepo) SaveMerge(ctx context.Context, merge *domain.EmployeeMerge) error {
+	if m.SaveMergeFn != nil {
+		return m.SaveMergeFn(ctx, merge)
+	}
+	return nil
+}
+
+func (m *mockRepo) FindDuplicates(ctx context.Context, phoneDigits, limit int) ([]*domain.DuplicatePair, error) {
+	if m.FindDuplicatesFn != nil {
+		return m.FindDuplicatesFn(ctx, phoneDigits, limit)
+	}
+	return []*domain.DuplicatePair{}, nil
+}
+
+func (m *mockRepo) UpsertAttendance(ctx context.Context, attendance *domain.Attendance) error {
+	if m.UpsertAttendanceFn != nil {
+		return m.UpsertAttendanceFn(ctx, attendance)
+	}
+	return nil
+}
+
+func (m *mockRepo) GetAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+	if m.GetAttendanceFn != nil {
+		return m.GetAttendanceFn(ctx, id, from, to)
+	}
+	return []*domain.Attendance{}, nil
+}
+
+func (m *mockRepo) GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+	if m.AttendanceSummaryFn != nil {
+		return m.AttendanceSummaryFn(ctx, date)
+	}
+	return &domain.AttendanceSummary{Date: date}, nil
+}
+
+func (m *mockRepo) UpdateStatus(ctx context.Context, id int64, status, updatedBy string) error {
+	if m.UpdateStatusFn != nil {
+		return m.UpdateStatusFn(ctx, id, status)
+	}
+	return nil
+}
+
+func (m *mockRepo) ArchiveVersion(ctx context.Context, id int64) error {
+	if m.ArchiveVersionFn != nil {
+		return m.ArchiveVersionFn(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockRepo) GetHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error) {
+	if m.GetHistoryFn != nil {
+		return m.GetHistoryFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+	if m.GetAsOfFn != nil {
+		return m.GetAsOfFn(ctx, id, at)
+	}
+	return nil, nil
+}
+
 // Убедись, что тип удовлетворяет интерфейсу (компиляционная проверка)
 var _ repository.EmployeeRepository = (*mockRepo)(nil)
 
@@ -109,7 +360,7 @@ func TestCreateEmployee_Success(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	e := &domain.Employee{Name: "Alice", Phone: "+7701", City: "Almaty"}
+	e := &domain.Employee{Name: "Alice", Phone: "+77010000000", City: "Almaty"}
 	if err := svc.CreateEmployee(context.Background(), e); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -118,25 +369,82 @@ func TestCreateEmployee_Success(t *testing.T) {
 	}
 }
 
+func TestCreateEmployee_LogsOncePerLayer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(`SELECT EXISTS`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`INSERT INTO employees`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	svc := NewEmployeeService(repository.NewEmployeeRepository(db, logger), logger)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	if err := svc.CreateEmployee(ctx, &domain.Employee{Name: "John", Phone: "+77011234567", City: "Almaty"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	// сервис логирует намерение, репозиторий — результат; оба с полями запроса
+	want := []string{"создание сотрудника", "сотрудник создан"}
+	entries := logs.All()
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d log entries, got %d: %v", len(want), len(entries), entries)
+	}
+	for i, entry := range entries {
+		fields := entry.ContextMap()
+		if entry.Message != want[i] || fields["request_id"] != "req-1" || fields["operation"] != "create" {
+			t.Fatalf("entry %d: got %q %v", i, entry.Message, fields)
+		}
+	}
+}
+
 func TestCreateEmployee_ValidationError(t *testing.T) {
 	repo := &mockRepo{}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	// отсутствует phone -> должен вернуться ValidationError
+	// отсутствует phone -> должен вернуться ValidationErrors
 	err := svc.CreateEmployee(context.Background(), &domain.Employee{
 		Name: "Bob", City: "Astana",
 	})
 	if err == nil {
 		t.Fatalf("expected validation error, got nil")
 	}
-	if _, ok := err.(*ValidationError); !ok {
-		t.Fatalf("expected *ValidationError, got %T (%v)", err, err)
+	errs, ok := err.(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected *ValidationErrors, got %T (%v)", err, err)
+	}
+	if len(errs.Errors) != 1 || errs.Errors[0].Field != "phone" {
+		t.Fatalf("expected single phone error, got %+v", errs.Errors)
+	}
+}
+
+func TestCreateEmployee_ReportsAllMissingFields(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+
+	err := svc.CreateEmployee(context.Background(), &domain.Employee{})
+	errs, ok := err.(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected *ValidationErrors, got %T (%v)", err, err)
+	}
+
+	var fields []string
+	for _, fe := range errs.Errors {
+		fields = append(fields, fe.Field)
+	}
+	if strings.Join(fields, ",") != "name,phone,city" {
+		t.Fatalf("expected name, phone and city errors, got %v", fields)
 	}
 }
 
 func TestGetEmployee_RepoError(t *testing.T) {
 	repo := &mockRepo{
-		GetByIDFn: func(ctx context.Context, id int) (*domain.Employee, error) {
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
 			return nil, errors.New("not found")
 		},
 	}
@@ -147,6 +455,85 @@ func TestGetEmployee_RepoError(t *testing.T) {
 	}
 }
 
+func TestGetEmployee_NotFoundSurvivesWrapping(t *testing.T) {
+	repo := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			// ошибку уже обернул слой под сервисом (например, декоратор репозитория)
+			return nil, fmt.Errorf("запрос: %w", &repository.NotFoundError{Entity: "employee", ID: id})
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	_, err := svc.GetEmployee(context.Background(), 99)
+	if !repository.IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	var notFound *repository.NotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != 99 {
+		t.Fatalf("expected NotFoundError for id 99, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "получение сотрудника 99: ") {
+		t.Fatalf("expected operation context in %q", err.Error())
+	}
+	if IsValidation(err) {
+		t.Fatalf("not found must not be a validation error")
+	}
+}
+
+func TestUpdateEmployee_NotFoundSurvivesTransaction(t *testing.T) {
+	repo := &mockRepo{
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &repository.NotFoundError{Entity: "employee", ID: e.ID}
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	err := svc.UpdateEmployee(context.Background(), &domain.Employee{ID: 5, Name: "Иван", Phone: "+77010000001", City: "Алматы"})
+	if !repository.IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestUpdateEmployee_VersionConflict(t *testing.T) {
+	repo := &mockRepo{
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &repository.VersionConflictError{ID: e.ID, Expected: e.Version, Current: 7}
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	err := svc.UpdateEmployee(context.Background(), &domain.Employee{ID: 5, Name: "Иван", Phone: "+77010000001", City: "Алматы", Version: 3})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if conflict.Field != "version" || conflict.EmployeeID != 5 || conflict.Version != 7 || conflict.Key != i18n.ConflictVersion {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+}
+
+func TestIsValidation(t *testing.T) {
+	multi := &ValidationErrors{}
+	multi.Add("name", i18n.NameRequired)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"single", NewValidationError("limit", i18n.OffsetNegative), true},
+		{"multiple", multi, true},
+		{"twice wrapped", fmt.Errorf("импорт: %w", fmt.Errorf("строка 2: %w", multi)), true},
+		{"not found", fmt.Errorf("получение: %w", &repository.NotFoundError{Entity: "employee", ID: 1}), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsValidation(tt.err); got != tt.want {
+			t.Errorf("%s: IsValidation = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 // Новые тесты для поиска
 func TestSearchEmployees_Success(t *testing.T) {
 	repo := &mockRepo{
@@ -162,7 +549,7 @@ func TestSearchEmployees_Success(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	results, err := svc.SearchEmployees(context.Background(), "john")
+	results, _, err := svc.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -179,7 +566,7 @@ func TestSearchEmployees_EmptyQuery(t *testing.T) {
 	svc := NewEmployeeService(repo, zap.NewNop())
 
 	// Based on the actual service behavior, empty query returns validation error
-	_, err := svc.SearchEmployees(context.Background(), "")
+	_, _, err := svc.SearchEmployees(context.Background(), "", domain.EmployeeFilter{}, 0, 0)
 	if err == nil {
 		t.Fatalf("expected validation error for empty query, got nil")
 	}
@@ -193,7 +580,7 @@ func TestSearchEmployees_WhitespaceQuery(t *testing.T) {
 	svc := NewEmployeeService(repo, zap.NewNop())
 
 	// Test whitespace-only query (should be treated as empty after trimming)
-	_, err := svc.SearchEmployees(context.Background(), "   ")
+	_, _, err := svc.SearchEmployees(context.Background(), "   ", domain.EmployeeFilter{}, 0, 0)
 	if err == nil {
 		t.Fatalf("expected validation error for whitespace query, got nil")
 	}
@@ -206,7 +593,7 @@ func TestSearchEmployees_ShortQuery(t *testing.T) {
 	repo := &mockRepo{}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	_, err := svc.SearchEmployees(context.Background(), "a")
+	_, _, err := svc.SearchEmployees(context.Background(), "a", domain.EmployeeFilter{}, 0, 0)
 	if err == nil {
 		t.Fatalf("expected validation error for short query, got nil")
 	}
@@ -222,7 +609,7 @@ func TestSearchEmployees_LongQuery(t *testing.T) {
 	// Create a query longer than 100 characters
 	longQuery := strings.Repeat("a", 101)
 
-	_, err := svc.SearchEmployees(context.Background(), longQuery)
+	_, _, err := svc.SearchEmployees(context.Background(), longQuery, domain.EmployeeFilter{}, 0, 0)
 	if err == nil {
 		t.Fatalf("expected validation error for long query, got nil")
 	}
@@ -231,6 +618,65 @@ func TestSearchEmployees_LongQuery(t *testing.T) {
 	}
 }
 
+func TestSearchEmployees_TooManyTerms(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	_, _, err := svc.SearchEmployees(context.Background(), "aa bb cc dd ee ff", domain.EmployeeFilter{}, 0, 0)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Key != i18n.SearchTooManyTerms {
+		t.Fatalf("expected %s validation error, got %v", i18n.SearchTooManyTerms, err)
+	}
+}
+
+func TestSearchEmployees_ConfiguredMaxResults(t *testing.T) {
+	var gotLimit int
+	repo := &mockRepo{
+		SearchPageFn: func(ctx context.Context, searchQuery string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit = limit
+			return nil, 0, nil
+		},
+	}
+	svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), Options{SearchMaxResults: 500})
+
+	if _, _, err := svc.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, 0, 0); err != nil {
+		t.Fatalf("expected default limit to be allowed, got %v", err)
+	}
+	if gotLimit != 500 {
+		t.Fatalf("expected limit 500 passed to repository, got %d", gotLimit)
+	}
+
+	_, _, err := svc.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, 501, 0)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Key != i18n.LimitRange || len(verr.Args) != 1 || verr.Args[0] != 500 {
+		t.Fatalf("expected limit range error with max 500, got %#v", err)
+	}
+}
+
+func TestGetAllEmployees_MaxRows(t *testing.T) {
+	repo := &mockRepo{
+		GetAllFn: func(ctx context.Context) ([]*domain.Employee, error) {
+			return nil, &repository.TooManyRowsError{Limit: 10000, Count: 500000}
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	_, err := svc.GetAllEmployees(context.Background(), domain.EmployeeFilter{})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "limit" || verr.Key != i18n.ListTooLarge {
+		t.Fatalf("expected list too large error on limit, got %#v", err)
+	}
+	if repo.lastMaxRows != DefaultListMaxRows {
+		t.Fatalf("expected default max rows %d, got %d", DefaultListMaxRows, repo.lastMaxRows)
+	}
+
+	svc = NewEmployeeServiceWithOptions(repo, zap.NewNop(), Options{ListMaxRows: 50})
+	_, _ = svc.GetAllEmployees(context.Background(), domain.EmployeeFilter{})
+	if repo.lastMaxRows != 50 {
+		t.Fatalf("expected configured max rows 50, got %d", repo.lastMaxRows)
+	}
+}
+
 func TestSearchEmployees_ValidQuery(t *testing.T) {
 	repo := &mockRepo{
 		SearchEmployeesFn: func(ctx context.Context, searchQuery string) ([]*domain.Employee, error) {
@@ -242,7 +688,7 @@ func TestSearchEmployees_ValidQuery(t *testing.T) {
 	svc := NewEmployeeService(repo, zap.NewNop())
 
 	// Test with 2-character query (minimum valid)
-	results, err := svc.SearchEmployees(context.Background(), "te")
+	results, _, err := svc.SearchEmployees(context.Background(), "te", domain.EmployeeFilter{}, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error for valid query: %v", err)
 	}
@@ -259,7 +705,7 @@ func TestSearchEmployees_RepoError(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	_, err := svc.SearchEmployees(context.Background(), "test")
+	_, _, err := svc.SearchEmployees(context.Background(), "test", domain.EmployeeFilter{}, 0, 0)
 	if err == nil {
 		t.Fatalf("expected repo error, got nil")
 	}
@@ -281,7 +727,7 @@ func TestSearchEmployees_ByPhone(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	results, err := svc.SearchEmployees(context.Background(), "777")
+	results, _, err := svc.SearchEmployees(context.Background(), "777", domain.EmployeeFilter{}, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -307,7 +753,7 @@ func TestSearchEmployees_ByCity(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	results, err := svc.SearchEmployees(context.Background(), "almaty")
+	results, _, err := svc.SearchEmployees(context.Background(), "almaty", domain.EmployeeFilter{}, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -329,7 +775,7 @@ func TestSearchEmployees_NoResults(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	results, err := svc.SearchEmployees(context.Background(), "nonexistent")
+	results, _, err := svc.SearchEmployees(context.Background(), "nonexistent", domain.EmployeeFilter{}, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -351,7 +797,7 @@ func TestSearchEmployees_CaseInsensitive(t *testing.T) {
 	}
 	svc := NewEmployeeService(repo, zap.NewNop())
 
-	results, err := svc.SearchEmployees(context.Background(), "JOHN")
+	results, _, err := svc.SearchEmployees(context.Background(), "JOHN", domain.EmployeeFilter{}, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -362,3 +808,2389 @@ func TestSearchEmployees_CaseInsensitive(t *testing.T) {
 		t.Fatalf("unexpected name: %s", results[0].Name)
 	}
 }
+
+// --- import tests ---
+
+func TestImportEmployees_JSONLRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	birth := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	hired := time.Date(2015, 9, 1, 0, 0, 0, 0, time.UTC)
+	store := map[int64]*domain.Employee{
+		3: {ID: 3, Name: "Алия", Phone: "+77010000003", City: "Almaty", Status: domain.StatusActive,
+			CreatedAt: created, UpdatedAt: created, CreatedBy: "system", UpdatedBy: "system", Version: 1},
+		7: {ID: 7, Name: "Bob", Phone: "+77010000007", City: "Astana", Status: domain.StatusInactive,
+			CreatedAt: created, UpdatedAt: created.Add(time.Hour), CreatedBy: "admin", UpdatedBy: "hr", Version: 5,
+			BirthDate: &birth, HireDate: &hired},
+	}
+	original := map[int64]domain.Employee{}
+	for id, e := range store {
+		original[id] = *e
+	}
+
+	synced := false
+	repo := &mockRepo{
+		StreamEmployeesFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			for _, id := range []int64{3, 7} {
+				if err := fn(store[id]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		CreateWithIDFn: func(ctx context.Context, e *domain.Employee) error {
+			store[e.ID] = e
+			return nil
+		},
+		SyncIDSequenceFn: func(ctx context.Context) error {
+			synced = true
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	// export
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := svc.ExportEmployees(context.Background(), domain.EmployeeFilter{}, func(e *domain.Employee) error {
+		return enc.Encode(e)
+	}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	// wipe
+	store = map[int64]*domain.Employee{}
+
+	// import: авторы берутся из файла, а не от выполняющего импорт
+	ctx := WithActor(context.Background(), "restorer")
+	summary, err := svc.ImportEmployees(ctx, NewJSONLReader(&buf), ImportOptions{Mode: ImportModeOverwrite})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if summary.Imported != 2 || summary.Failed != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if !synced {
+		t.Fatalf("expected id sequence to be synced after overwrite import")
+	}
+
+	// compare
+	for id, want := range original {
+		got, ok := store[id]
+		if !ok {
+			t.Fatalf("employee %d missing after import", id)
+		}
+		if got.Name != want.Name || got.Phone != want.Phone || got.City != want.City || got.Status != want.Status ||
+			!got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) ||
+			got.CreatedBy != want.CreatedBy || got.UpdatedBy != want.UpdatedBy || got.Version != want.Version ||
+			!sameDate(got.BirthDate, want.BirthDate) || !sameDate(got.HireDate, want.HireDate) {
+			t.Fatalf("employee %d: want %+v, got %+v", id, want, *got)
+		}
+	}
+}
+
+// sameDate сравнивает необязательные даты
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestImportEmployees_CorruptLinesReported(t *testing.T) {
+	var created []string
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			if e.ID != 0 {
+				t.Fatalf("expected id to be reassigned, got %d", e.ID)
+			}
+			created = append(created, e.Name)
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	input := `{"id":1,"name":"A","phone":"+77010000001","city":"X"}
+{broken
+{"id":2,"name":"","phone":"+77010000002","city":"Y"}
+
+{"id":3,"name":"C","phone":"+77010000003","city":"Z"}
+`
+	summary, err := svc.ImportEmployees(context.Background(), NewJSONLReader(strings.NewReader(input)), ImportOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if summary.Imported != 2 || summary.Failed != 2 || summary.Aborted {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if summary.Errors[0].Line != 2 || summary.Errors[1].Line != 3 {
+		t.Fatalf("unexpected error lines: %+v", summary.Errors)
+	}
+	if strings.Join(created, ",") != "A,C" {
+		t.Fatalf("unexpected created: %v", created)
+	}
+}
+
+func TestImportEmployees_StrictAborts(t *testing.T) {
+	calls := 0
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			calls++
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	input := "{\"name\":\"A\",\"phone\":\"+77010000001\",\"city\":\"X\"}\n{broken\n{\"name\":\"C\",\"phone\":\"+77010000003\",\"city\":\"Z\"}\n"
+	summary, err := svc.ImportEmployees(context.Background(), NewJSONLReader(strings.NewReader(input)), ImportOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !summary.Aborted || summary.Imported != 1 || calls != 1 {
+		t.Fatalf("expected abort after first error, got %+v (calls=%d)", summary, calls)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "ID,Имя,Телефон,Город\n5,Алия,+7701,Almaty\n\"broken,+7702,Astana\n"
+	reader := NewCSVReader(strings.NewReader(input))
+
+	line, e, err := reader.Next()
+	if err != nil {
+		t.Fatalf("first record: %v", err)
+	}
+	if line != 2 || e.ID != 5 || e.Name != "Алия" || e.Phone != "+7701" || e.City != "Almaty" {
+		t.Fatalf("unexpected first record at line %d: %+v", line, e)
+	}
+
+	if _, _, err = reader.Next(); err == nil {
+		t.Fatalf("expected error for broken record")
+	}
+}
+
+func TestCSVReader_MissingColumn(t *testing.T) {
+	reader := NewCSVReader(strings.NewReader("name,city\nAlice,Almaty\n"))
+	if _, _, err := reader.Next(); !IsValidation(err) {
+		t.Fatalf("expected validation error for header without phone column, got %v", err)
+	}
+}
+
+func TestImportEmployees_DryRunMappedCSV(t *testing.T) {
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			return phone == "+77010000009", nil
+		},
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("dry run must not create employees")
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	input := "ФИО,Телефон,Город\n" +
+		"  Алия   Серикова ,  +77010000001 ,Almaty\n" +
+		"Bob,+77010000001,Astana\n" +
+		"Carl,+77010000009,Astana\n" +
+		",+77010000004,Astana\n"
+	reader, err := NewMappedCSVReader(strings.NewReader(input), map[string]string{"фио": "name", "Телефон": "phone", "ГОРОД": "city"})
+	if err != nil {
+		t.Fatalf("reader: %v", err)
+	}
+
+	summary, err := svc.ImportEmployees(context.Background(), reader, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !summary.DryRun || summary.Imported != 1 || summary.Failed != 3 || len(summary.Rows) != 4 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	first := summary.Rows[0]
+	if first.Line != 2 || first.Name != "Алия Серикова" || first.Phone != "+77010000001" || first.Error != "" {
+		t.Fatalf("unexpected normalized row: %+v", first)
+	}
+	if summary.Rows[1].Error != i18n.T(i18n.Default, i18n.ImportPhoneRepeated, 2) {
+		t.Fatalf("expected repeated phone error, got %+v", summary.Rows[1])
+	}
+	if summary.Rows[2].Error != i18n.T(i18n.Default, i18n.PhoneTaken) {
+		t.Fatalf("expected taken phone error, got %+v", summary.Rows[2])
+	}
+	if summary.Errors[0].Line != 3 || summary.Errors[1].Line != 4 || summary.Errors[2].Line != 5 {
+		t.Fatalf("unexpected error lines: %+v", summary.Errors)
+	}
+}
+
+func TestImportEmployees_RepeatedPhoneInFile(t *testing.T) {
+	var created []string
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			created = append(created, e.Name)
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	input := "name,phone,city\nA,+77010000001,X\nB,+77010000001,Y\n"
+	summary, err := svc.ImportEmployees(context.Background(), NewCSVReader(strings.NewReader(input)), ImportOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if summary.Imported != 1 || summary.Failed != 1 || summary.DryRun || summary.Rows != nil {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if strings.Join(created, ",") != "A" {
+		t.Fatalf("unexpected created: %v", created)
+	}
+}
+
+func TestNewMappedCSVReader_UnknownField(t *testing.T) {
+	_, err := NewMappedCSVReader(strings.NewReader(""), map[string]string{"ФИО": "full_name"})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Field != "mapping" {
+		t.Fatalf("expected mapping validation error, got %v", err)
+	}
+}
+
+func TestCreateEmployee_Quota(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		count   int64
+		allowed bool
+	}{
+		{"below", 4, true},
+		{"at", 5, false},
+		{"above", 7, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			created := false
+			repo := &mockRepo{
+				CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+					return tc.count, nil
+				},
+				CreateFn: func(ctx context.Context, e *domain.Employee) error {
+					created = true
+					return nil
+				},
+			}
+			svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), Options{MaxEmployees: 5})
+
+			err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: "X"})
+			if tc.allowed {
+				if err != nil || !created {
+					t.Fatalf("expected employee to be created, got %v", err)
+				}
+				return
+			}
+			var quota *QuotaError
+			if !errors.As(err, &quota) || quota.Limit != 5 || quota.Count != tc.count || quota.Requested != 1 {
+				t.Fatalf("expected QuotaError, got %v", err)
+			}
+			if created {
+				t.Fatalf("employee must not be created over quota")
+			}
+		})
+	}
+}
+
+func TestCreateEmployee_NoQuota(t *testing.T) {
+	repo := &mockRepo{
+		CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			t.Fatalf("count must not be queried without quota")
+			return 0, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+	if err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: "X"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+}
+
+func TestImportEmployees_Quota(t *testing.T) {
+	// 3 строки, одна из них некорректна: импорт добавил бы 2 сотрудников
+	input := "name,phone,city\nA,+77010000001,X\nB,+77010000002,Y\n,+77010000003,Z\n"
+
+	for _, tc := range []struct {
+		name    string
+		count   int64
+		allowed bool
+	}{
+		{"below", 2, true},
+		{"at", 3, true},
+		{"above", 4, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var created []string
+			repo := &mockRepo{
+				CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+					return tc.count, nil
+				},
+				CreateFn: func(ctx context.Context, e *domain.Employee) error {
+					created = append(created, e.Name)
+					return nil
+				},
+			}
+			svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), Options{MaxEmployees: 5})
+
+			summary, err := svc.ImportEmployees(context.Background(), NewCSVReader(strings.NewReader(input)), ImportOptions{})
+			if tc.allowed {
+				if err != nil || summary.Imported != 2 || summary.Failed != 1 || strings.Join(created, ",") != "A,B" {
+					t.Fatalf("unexpected result: %+v, %v, created %v", summary, err, created)
+				}
+				return
+			}
+			var quota *QuotaError
+			if !errors.As(err, &quota) || quota.Requested != 2 {
+				t.Fatalf("expected QuotaError for the whole batch, got %v", err)
+			}
+			if len(created) != 0 {
+				t.Fatalf("batch over quota must not create anyone, created %v", created)
+			}
+		})
+	}
+}
+
+func TestImportEmployees_QuotaOverwriteExisting(t *testing.T) {
+	repo := &mockRepo{
+		CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			return 5, nil
+		},
+		GetByIDsFn: func(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+			return []*domain.Employee{{ID: 1}, {ID: 2}}, nil
+		},
+		CreateWithIDFn: func(ctx context.Context, e *domain.Employee) error {
+			return nil
+		},
+	}
+	svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), Options{MaxEmployees: 5})
+
+	input := "id,name,phone,city\n1,A,+77010000001,X\n2,B,+77010000002,Y\n"
+	summary, err := svc.ImportEmployees(context.Background(), NewCSVReader(strings.NewReader(input)), ImportOptions{Mode: ImportModeOverwrite})
+	if err != nil || summary.Imported != 2 {
+		t.Fatalf("overwriting existing employees must not use quota: %+v, %v", summary, err)
+	}
+}
+
+func TestValidateEmployee_Valid(t *testing.T) {
+	var excluded []int64
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			excluded = excludeID
+			return false, nil
+		},
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("validate must not write to the repository")
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	e := &domain.Employee{ID: 5, Name: " Alice ", Phone: "+77010000000", City: "Almaty"}
+	fieldErrors, err := svc.ValidateEmployee(context.Background(), e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fieldErrors) != 0 {
+		t.Fatalf("expected no field errors, got %+v", fieldErrors)
+	}
+	if e.Name != "Alice" {
+		t.Fatalf("expected trimmed name, got %q", e.Name)
+	}
+	if len(excluded) != 1 || excluded[0] != 5 {
+		t.Fatalf("expected own id excluded from phone check, got %v", excluded)
+	}
+}
+
+func TestValidateEmployee_ReportsAllFields(t *testing.T) {
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			return true, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	fieldErrors, err := svc.ValidateEmployee(context.Background(), &domain.Employee{Phone: "+77010000000", City: "  "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var fields []string
+	for _, fe := range fieldErrors {
+		fields = append(fields, fe.Field)
+	}
+	if strings.Join(fields, ",") != "name,city,phone" {
+		t.Fatalf("expected name, city and phone errors, got %v", fieldErrors)
+	}
+}
+
+func TestCreateEmployee_NormalizesCity(t *testing.T) {
+	tests := map[string]string{
+		"almaty":           "Almaty",
+		"ALMATY":           "Almaty",
+		"  аЛМАТЫ ":        "Алматы",
+		"усть-каменогорск": "Усть-Каменогорск",
+		"  нур   султан  ": "Нур Султан",
+	}
+
+	for input, want := range tests {
+		var stored string
+		repo := &mockRepo{
+			CreateFn: func(ctx context.Context, e *domain.Employee) error {
+				stored = e.City
+				return nil
+			},
+		}
+		svc := NewEmployeeService(repo, zap.NewNop())
+
+		if err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: input}); err != nil {
+			t.Fatalf("CreateEmployee(%q): %v", input, err)
+		}
+		if stored != want {
+			t.Fatalf("city %q: expected %q, got %q", input, want, stored)
+		}
+	}
+}
+
+func TestCreateEmployee_NormalizeCityDisabled(t *testing.T) {
+	var stored string
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			stored = e.City
+			return nil
+		},
+	}
+	svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), Options{NormalizeCity: false})
+
+	if err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: " ALMATY "}); err != nil {
+		t.Fatalf("CreateEmployee: %v", err)
+	}
+	if stored != "ALMATY" {
+		t.Fatalf("expected only trimmed city, got %q", stored)
+	}
+}
+
+func TestGetEmployeesByCity_NormalizesLookup(t *testing.T) {
+	var lookup string
+	repo := &mockRepo{
+		GetEmployeesByCityFn: func(ctx context.Context, city string) ([]*domain.Employee, error) {
+			lookup = city
+			return []*domain.Employee{{ID: 1, City: "Almaty"}}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employees, err := svc.GetEmployeesByCity(context.Background(), "almaty ")
+	if err != nil {
+		t.Fatalf("GetEmployeesByCity: %v", err)
+	}
+	if lookup != "Almaty" || len(employees) != 1 {
+		t.Fatalf("expected lookup by %q, got %q (%d results)", "Almaty", lookup, len(employees))
+	}
+}
+
+func TestGetCityEmployees(t *testing.T) {
+	repo := &mockRepo{
+		GetPageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, error) {
+			return []*domain.Employee{{ID: 1, City: "Almaty"}}, nil
+		},
+		CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			return 21, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employees, total, err := svc.GetCityEmployees(context.Background(), "  almaty ", 20, 20)
+	if err != nil {
+		t.Fatalf("GetCityEmployees: %v", err)
+	}
+	if repo.lastFilter.City != "Almaty" {
+		t.Fatalf("expected lookup by %q, got %q", "Almaty", repo.lastFilter.City)
+	}
+	if len(employees) != 1 || total != 21 {
+		t.Fatalf("unexpected page: %d employees, total %d", len(employees), total)
+	}
+}
+
+func TestGetCityEmployees_InvalidCity(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+
+	for name, city := range map[string]string{
+		"пустой":          " \t ",
+		"слишком длинный": strings.Repeat("а", MaxCityLength+1),
+		"управляющий":     "Алма\x00ты",
+	} {
+		_, _, err := svc.GetCityEmployees(context.Background(), city, 0, 0)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "city" {
+			t.Errorf("%s: expected city validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestPhoneValidators(t *testing.T) {
+	tests := []struct {
+		region string
+		phone  string
+		valid  bool
+	}{
+		{PhoneRegionKZ, "+77010000000", true},
+		{PhoneRegionKZ, "+7 (727) 250-00-00", true},
+		{PhoneRegionKZ, "87771234567", true},
+		{PhoneRegionKZ, "+79161234567", false},
+		{PhoneRegionKZ, "+7701", false},
+		{PhoneRegionRU, "+7 916 123-45-67", true},
+		{PhoneRegionRU, "84951234567", true},
+		{PhoneRegionRU, "+77010000000", false},
+		{PhoneRegionINTL, "+14155550123", true},
+		{PhoneRegionINTL, "+77010000000", true},
+		{PhoneRegionINTL, "14155550123", false},
+		{PhoneRegionINTL, "+0123456", false},
+		{PhoneRegionINTL, "+1234567890123456", false},
+	}
+
+	for _, tt := range tests {
+		validator, err := NewPhoneValidator(tt.region)
+		if err != nil {
+			t.Fatalf("NewPhoneValidator(%s): %v", tt.region, err)
+		}
+		err = validator.ValidatePhone(tt.phone)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected %q to be valid, got %v", tt.region, tt.phone, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected %q to be rejected", tt.region, tt.phone)
+		}
+	}
+
+	if _, err := NewPhoneValidator("US"); err == nil {
+		t.Fatalf("expected error for unknown region")
+	}
+}
+
+func TestCanonicalPhone(t *testing.T) {
+	tests := []struct {
+		region string
+		phone  string
+		want   string
+	}{
+		{PhoneRegionKZ, "+7 (701) 000-00-00", "+77010000000"},
+		{PhoneRegionKZ, "8 701 000 00 00", "+77010000000"},
+		{PhoneRegionRU, "84951234567", "+74951234567"},
+		{PhoneRegionINTL, " +1 415 555-01-23 ", "+14155550123"},
+		// INTL не угадывает код страны: номер без "+" остается некорректным
+		{PhoneRegionINTL, "84951234567", "84951234567"},
+	}
+
+	for _, tt := range tests {
+		validator, err := NewPhoneValidator(tt.region)
+		if err != nil {
+			t.Fatalf("NewPhoneValidator(%s): %v", tt.region, err)
+		}
+		if got := validator.CanonicalPhone(tt.phone); got != tt.want {
+			t.Errorf("%s: CanonicalPhone(%q) = %q, want %q", tt.region, tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestCreateEmployee_PhoneFormatError(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+
+	err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "12345", City: "Almaty"})
+	errs, ok := err.(*ValidationErrors)
+	if !ok || len(errs.Errors) != 1 || errs.Errors[0].Field != "phone" {
+		t.Fatalf("expected phone format error, got %v", err)
+	}
+}
+
+func TestValidationErrors_MessageKeys(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+
+	err := svc.CreateEmployee(context.Background(), &domain.Employee{Phone: "12345", City: "Almaty"})
+	errs, ok := err.(*ValidationErrors)
+	if !ok || len(errs.Errors) != 2 {
+		t.Fatalf("expected two field errors, got %v", err)
+	}
+	if errs.Errors[0].Key != i18n.NameRequired || errs.Errors[0].Message != "имя обязательно" {
+		t.Fatalf("unexpected name error: %+v", errs.Errors[0])
+	}
+	if errs.Errors[1].Key != i18n.PhoneFormatKZ {
+		t.Fatalf("expected phone format key from validator, got %+v", errs.Errors[1])
+	}
+
+	var plain ValidationErrors
+	plain.AddError("phone", errors.New("свой валидатор"))
+	if plain.Errors[0].Key != "" || plain.Errors[0].Message != "свой валидатор" {
+		t.Fatalf("expected untranslated message, got %+v", plain.Errors[0])
+	}
+}
+
+func TestGetRecentEmployees_Limit(t *testing.T) {
+	var gotLimit int
+	repo := &mockRepo{
+		GetRecentFn: func(ctx context.Context, limit int) ([]*domain.Employee, error) {
+			gotLimit = limit
+			return nil, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	if _, err := svc.GetRecentEmployees(context.Background(), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != 10 {
+		t.Fatalf("expected default limit 10, got %d", gotLimit)
+	}
+
+	for _, limit := range []int{-1, 101} {
+		if _, err := svc.GetRecentEmployees(context.Background(), limit); err == nil {
+			t.Fatalf("expected validation error for limit %d", limit)
+		}
+	}
+}
+
+func TestCreateEmployee_DateValidation(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+	date := func(s string) *time.Time {
+		d, err := domain.ParseDate(s)
+		if err != nil {
+			t.Fatalf("ParseDate(%q): %v", s, err)
+		}
+		return d
+	}
+	today := time.Now().UTC().Format(domain.DateLayout)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(domain.DateLayout)
+
+	tests := []struct {
+		name      string
+		birthDate *time.Time
+		hireDate  *time.Time
+		wantField string // пусто — без ошибки
+	}{
+		{"no dates", nil, nil, ""},
+		{"leap day birthday", date("2000-02-29"), date("2020-03-01"), ""},
+		{"born today", date(today), nil, ""},
+		{"born tomorrow", date(tomorrow), nil, "birth_date"},
+		{"hired on the first allowed day", nil, date("1950-01-01"), ""},
+		{"hired before 1950", nil, date("1949-12-31"), "hire_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &domain.Employee{Name: "John", Phone: "+77010000001", City: "Almaty", BirthDate: tt.birthDate, HireDate: tt.hireDate}
+			err := svc.CreateEmployee(context.Background(), e)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs *ValidationErrors
+			if !errors.As(err, &errs) || len(errs.Errors) != 1 || errs.Errors[0].Field != tt.wantField {
+				t.Fatalf("expected %s error, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestGetUpcomingAnniversaries_Days(t *testing.T) {
+	var gotFrom time.Time
+	var gotDays int
+	repo := &mockRepo{
+		AnniversariesFn: func(ctx context.Context, from time.Time, withinDays int) ([]*domain.Anniversary, error) {
+			gotFrom, gotDays = from, withinDays
+			return nil, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	if _, err := svc.GetUpcomingAnniversaries(context.Background(), 14); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotDays != 14 || gotFrom.Format(domain.DateLayout) != time.Now().UTC().Format(domain.DateLayout) || gotFrom.Hour() != 0 {
+		t.Fatalf("expected today and 14 days, got %v and %d", gotFrom, gotDays)
+	}
+
+	for _, days := range []int{0, -1, MaxAnniversaryDays + 1} {
+		if _, err := svc.GetUpcomingAnniversaries(context.Background(), days); !IsValidation(err) {
+			t.Fatalf("expected validation error for days %d, got %v", days, err)
+		}
+	}
+}
+
+func TestCountEmployees_NormalizesCity(t *testing.T) {
+	var gotCity string
+	repo := &mockRepo{
+		CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			gotCity = filter.City
+			return 3, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	count, err := svc.CountEmployees(context.Background(), domain.EmployeeFilter{City: "  almaty "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3, got %d", count)
+	}
+	if gotCity != "Almaty" {
+		t.Fatalf("expected normalized city Almaty, got %q", gotCity)
+	}
+}
+
+// fakeUnitOfWork подставляет txRepo в транзакцию и запоминает ее исход
+type fakeUnitOfWork struct {
+	txRepo     repository.EmployeeRepository
+	rolledBack bool
+	committed  bool
+}
+
+func (u *fakeUnitOfWork) WithTx(ctx context.Context, fn func(repo repository.EmployeeRepository) error) error {
+	if err := fn(u.txRepo); err != nil {
+		u.rolledBack = true
+		return err
+	}
+	u.committed = true
+	return nil
+}
+
+func TestWithTx_UsesTransactionRepository(t *testing.T) {
+	var created []string
+	txRepo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			created = append(created, e.Name)
+			return nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	err := svc.Employee.WithTx(context.Background(), func(tx EmployeeService) error {
+		if err := tx.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"}); err != nil {
+			return err
+		}
+		// невалидный сотрудник откатывает всю транзакцию
+		return tx.CreateEmployee(context.Background(), &domain.Employee{Name: "B"})
+	})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if !uow.rolledBack || uow.committed {
+		t.Fatalf("expected rollback, got rolledBack=%v committed=%v", uow.rolledBack, uow.committed)
+	}
+	if len(created) != 1 || created[0] != "A" {
+		t.Fatalf("expected create through tx repository, got %v", created)
+	}
+}
+
+func TestWithTx_WithoutUnitOfWork(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+
+	err := svc.WithTx(context.Background(), func(EmployeeService) error { return nil })
+	if err == nil {
+		t.Fatal("expected error without UnitOfWork")
+	}
+}
+
+func TestCheckPhone(t *testing.T) {
+	var gotExclude []int64
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			gotExclude = excludeID
+			return phone == "+77010000001", nil
+		},
+		GetByPhoneFn: func(ctx context.Context, phone string) (*domain.Employee, error) {
+			return &domain.Employee{ID: 1, Name: "A", Phone: phone}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employee, err := svc.CheckPhone(context.Background(), " +77010000001 ", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if employee == nil || employee.ID != 1 {
+		t.Fatalf("expected employee 1, got %+v", employee)
+	}
+	if len(gotExclude) != 1 || gotExclude[0] != 5 {
+		t.Fatalf("expected exclude [5], got %v", gotExclude)
+	}
+
+	employee, err = svc.CheckPhone(context.Background(), "+77010000002", 0)
+	if err != nil || employee != nil {
+		t.Fatalf("expected free phone, got %+v, %v", employee, err)
+	}
+	if len(gotExclude) != 0 {
+		t.Fatalf("expected no exclude, got %v", gotExclude)
+	}
+
+	if _, err := svc.CheckPhone(context.Background(), "  ", 0); err == nil {
+		t.Fatal("expected validation error for empty phone")
+	}
+}
+
+func TestGetEmployeesPage(t *testing.T) {
+	repo := &mockRepo{
+		GetPageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, error) {
+			if limit != 20 || offset != 40 {
+				t.Fatalf("unexpected page: limit=%d offset=%d", limit, offset)
+			}
+			return []*domain.Employee{{ID: 41}}, nil
+		},
+		CountWithFilterFn: func(ctx context.Context, filter domain.EmployeeFilter) (int64, error) {
+			return 95, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employees, total, err := svc.GetEmployeesPage(context.Background(), domain.EmployeeFilter{}, 20, 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(employees) != 1 || total != 95 {
+		t.Fatalf("unexpected result: %d employees, total %d", len(employees), total)
+	}
+
+	_, _, err = svc.GetEmployeesPage(context.Background(), domain.EmployeeFilter{}, 500, -1)
+	var verrs *ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs.Errors) != 2 {
+		t.Fatalf("expected 2 validation errors, got %v", err)
+	}
+}
+
+func TestGetEmployeesPage_ConfiguredLimits(t *testing.T) {
+	var gotLimit int
+	repo := &mockRepo{
+		GetPageFn: func(ctx context.Context, limit, offset int) ([]*domain.Employee, error) {
+			gotLimit = limit
+			return nil, nil
+		},
+	}
+	opts := DefaultOptions()
+	opts.PageDefaultLimit, opts.PageMaxLimit = 50, 200
+	svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), opts)
+
+	if _, _, err := svc.GetEmployeesPage(context.Background(), domain.EmployeeFilter{}, 0, 0); err != nil || gotLimit != 50 {
+		t.Fatalf("expected default limit 50, got %d, %v", gotLimit, err)
+	}
+	if _, _, err := svc.GetEmployeesPage(context.Background(), domain.EmployeeFilter{}, 200, 0); err != nil || gotLimit != 200 {
+		t.Fatalf("expected limit 200 within configured max, got %d, %v", gotLimit, err)
+	}
+	if _, _, err := svc.GetEmployeesPage(context.Background(), domain.EmployeeFilter{}, 201, 0); err == nil {
+		t.Fatalf("expected validation error above configured max")
+	}
+}
+
+func TestCreateEmployee_PhoneConflict(t *testing.T) {
+	created := false
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			return true, nil
+		},
+		GetByPhoneFn: func(ctx context.Context, phone string) (*domain.Employee, error) {
+			return &domain.Employee{ID: 7, Phone: phone}, nil
+		},
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			created = true
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"})
+
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.EmployeeID != 7 || conflict.Field != "phone" {
+		t.Fatalf("expected ConflictError with employee 7, got %v", err)
+	}
+	if created {
+		t.Fatal("Create must not be called on conflict")
+	}
+}
+
+func TestCreateEmployee_FormattedDuplicatePhone(t *testing.T) {
+	// В БД уже есть "+77010000000"; тот же номер с оформлением или через 8 — дубликат
+	stored := map[string]int64{"+77010000000": 7}
+	var saved string
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			_, ok := stored[phone]
+			return ok, nil
+		},
+		GetByPhoneFn: func(ctx context.Context, phone string) (*domain.Employee, error) {
+			return &domain.Employee{ID: stored[phone], Phone: phone}, nil
+		},
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			saved = e.Phone
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	for _, phone := range []string{"+7 701 000-00-00", "8 (701) 000-00-00", " +77010000000 "} {
+		err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: phone, City: "Almaty"})
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || conflict.EmployeeID != 7 {
+			t.Fatalf("%q: expected ConflictError with employee 7, got %v", phone, err)
+		}
+	}
+
+	// Свободный номер сохраняется в единой записи
+	if err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "B", Phone: "8 701 000-00-01", City: "Almaty"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved != "+77010000001" {
+		t.Fatalf("expected canonical phone +77010000001, got %q", saved)
+	}
+}
+
+func TestUpdateEmployee_PhoneCheckExcludesSelf(t *testing.T) {
+	var gotExclude []int64
+	repo := &mockRepo{
+		CheckPhoneExistsFn: func(ctx context.Context, phone string, excludeID ...int64) (bool, error) {
+			gotExclude = excludeID
+			return false, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	err := svc.UpdateEmployee(context.Background(), &domain.Employee{ID: 5, Name: "A", Phone: "+77010000001", City: "Almaty"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gotExclude) != 1 || gotExclude[0] != 5 {
+		t.Fatalf("expected exclude [5], got %v", gotExclude)
+	}
+}
+
+func TestCreateEmployee_DuplicateFromRepository(t *testing.T) {
+	// телефон заняли между проверкой и вставкой
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			return &repository.AlreadyExistsError{Entity: "employee", Field: "phone", Value: e.Phone}
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"})
+
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Field != "phone" {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"кириллица с пробелами", "  Иван   Петров ", "Иван Петров"},
+		{"NFD в NFC", "\u0418\u0306\u043e\u0448\u043a\u0430\u0440-\u041e\u043b\u0430", "Йошкар-Ола"},
+		{"разложенная ё", "Сем\u0435\u0308новка", "Семёновка"},
+		{"казахские буквы", "Өскемен\tҚала", "Өскемен Қала"},
+		{"эмодзи сохраняются", " Айгүл 🌸 ", "Айгүл 🌸"},
+		{"перевод строки", "Алма\nАта", "Алма Ата"},
+		{"только пробелы", " \t\n ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeText(tt.input); got != tt.want {
+				t.Fatalf("sanitizeText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateEmployee_Sanitization(t *testing.T) {
+	tests := []struct {
+		name      string
+		employee  domain.Employee
+		wantName  string
+		wantCity  string
+		wantField string
+	}{
+		{
+			name:     "кириллица",
+			employee: domain.Employee{Name: "  Иван   Петров ", Phone: "+77010000001", City: " алматы "},
+			wantName: "Иван Петров",
+			wantCity: "Алматы",
+		},
+		{
+			name:     "казахский город в NFD",
+			employee: domain.Employee{Name: "Айгүл", Phone: "+77010000001", City: "усть-каменогорск й"},
+			wantName: "Айгүл",
+			wantCity: "Усть-Каменогорск Й",
+		},
+		{
+			name:     "эмодзи",
+			employee: domain.Employee{Name: "Дана 🌸", Phone: "+77010000001", City: "Шымкент"},
+			wantName: "Дана 🌸",
+			wantCity: "Шымкент",
+		},
+		{
+			name:      "пустое имя после очистки",
+			employee:  domain.Employee{Name: " \t ", Phone: "+77010000001", City: "Алматы"},
+			wantField: "name",
+		},
+		{
+			name:      "управляющий символ в городе",
+			employee:  domain.Employee{Name: "Иван", Phone: "+77010000001", City: "Алма\x00ты"},
+			wantField: "city",
+		},
+		{
+			name:      "нулевой байт в имени",
+			employee:  domain.Employee{Name: "Иван\x00", Phone: "+77010000001", City: "Алматы"},
+			wantField: "name",
+		},
+		{
+			name:      "тег script в имени",
+			employee:  domain.Employee{Name: "<script>alert(1)</script>", Phone: "+77010000001", City: "Алматы"},
+			wantField: "name",
+		},
+		{
+			name:      "сущность HTML в городе",
+			employee:  domain.Employee{Name: "Иван", Phone: "+77010000001", City: "Алматы &lt;b&gt;"},
+			wantField: "city",
+		},
+		{
+			name:     "дефис и апостроф в имени",
+			employee: domain.Employee{Name: "Анна-Мария О'Коннор", Phone: "+77010000001", City: "Алма-Ата"},
+			wantName: "Анна-Мария О'Коннор",
+			wantCity: "Алма-Ата",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *domain.Employee
+			repo := &mockRepo{
+				CreateFn: func(ctx context.Context, e *domain.Employee) error {
+					saved = e
+					return nil
+				},
+			}
+			svc := NewEmployeeService(repo, zap.NewNop())
+
+			employee := tt.employee
+			err := svc.CreateEmployee(context.Background(), &employee)
+
+			if tt.wantField != "" {
+				var verrs *ValidationErrors
+				if !errors.As(err, &verrs) || len(verrs.Errors) != 1 || verrs.Errors[0].Field != tt.wantField {
+					t.Fatalf("expected validation error on %s, got %v", tt.wantField, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if saved.Name != tt.wantName || saved.City != tt.wantCity {
+				t.Fatalf("got name=%q city=%q, want name=%q city=%q", saved.Name, saved.City, tt.wantName, tt.wantCity)
+			}
+		})
+	}
+}
+
+func TestSearchEmployees_Sanitization(t *testing.T) {
+	var gotQuery string
+	repo := &mockRepo{
+		SearchEmployeesFn: func(ctx context.Context, q string) ([]*domain.Employee, error) {
+			gotQuery = q
+			return nil, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	if _, _, err := svc.SearchEmployees(context.Background(), "  йван   п ", domain.EmployeeFilter{}, 0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotQuery != "йван п" {
+		t.Fatalf("expected sanitized query, got %q", gotQuery)
+	}
+
+	if _, _, err := svc.SearchEmployees(context.Background(), "ив\x1bан", domain.EmployeeFilter{}, 0, 0); err == nil {
+		t.Fatal("expected error for control characters")
+	}
+}
+
+func TestSearchEmployees_Pagination(t *testing.T) {
+	var gotLimit, gotOffset int
+	repo := &mockRepo{
+		SearchPageFn: func(ctx context.Context, q string, limit, offset int) ([]*domain.Employee, int64, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*domain.Employee{{ID: 1}}, 250, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	_, total, err := svc.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != 100 || gotOffset != 0 || total != 250 {
+		t.Fatalf("expected default limit 100 and total 250, got limit=%d offset=%d total=%d", gotLimit, gotOffset, total)
+	}
+
+	for _, tc := range []struct{ limit, offset int }{{101, 0}, {-1, 0}, {10, -5}} {
+		if _, _, err := svc.SearchEmployees(context.Background(), "john", domain.EmployeeFilter{}, tc.limit, tc.offset); err == nil {
+			t.Fatalf("expected validation error for limit=%d offset=%d", tc.limit, tc.offset)
+		}
+	}
+}
+
+func TestUpdateEmployee_ArchivesPriorVersionInTx(t *testing.T) {
+	var calls []string
+	txRepo := &mockRepo{
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			calls = append(calls, "archive")
+			return nil
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			calls = append(calls, "update")
+			return nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	err := svc.Employee.UpdateEmployee(context.Background(), &domain.Employee{ID: 3, Name: "A", Phone: "+77010000001", City: "Almaty"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "archive" || calls[1] != "update" {
+		t.Fatalf("expected archive then update in tx, got %v", calls)
+	}
+	if !uow.committed {
+		t.Fatal("expected commit")
+	}
+}
+
+func TestUpdateEmployee_ArchiveFailureRollsBack(t *testing.T) {
+	updated := false
+	txRepo := &mockRepo{
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			return &repository.NotFoundError{Entity: "employee", ID: id}
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			updated = true
+			return nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	err := svc.Employee.UpdateEmployee(context.Background(), &domain.Employee{ID: 3, Name: "A", Phone: "+77010000001", City: "Almaty"})
+	var nf *repository.NotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if updated || !uow.rolledBack {
+		t.Fatalf("expected rollback without update, got updated=%v rolledBack=%v", updated, uow.rolledBack)
+	}
+}
+
+func TestGetEmployeeAsOf(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		at          time.Time
+		wantHistory bool
+	}{
+		{name: "past date reads history", at: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), wantHistory: true},
+		{name: "just before now reads history", at: now.Add(-time.Second), wantHistory: true},
+		{name: "future date reads current row", at: now.Add(time.Hour), wantHistory: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAt time.Time
+			repo := &mockRepo{
+				GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+					return &domain.Employee{ID: id, Name: "Current"}, nil
+				},
+				GetAsOfFn: func(ctx context.Context, id int64, at time.Time) (*domain.Employee, error) {
+					gotAt = at
+					return &domain.Employee{ID: id, Name: "Prior"}, nil
+				},
+			}
+			svc := NewEmployeeService(repo, zap.NewNop())
+
+			e, err := svc.GetEmployeeAsOf(context.Background(), 3, tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantHistory {
+				if e.Name != "Prior" || !gotAt.Equal(tt.at) {
+					t.Fatalf("expected history version at %v, got %+v at %v", tt.at, e, gotAt)
+				}
+			} else if e.Name != "Current" {
+				t.Fatalf("expected current row, got %+v", e)
+			}
+		})
+	}
+}
+
+func TestGetEmployeeStats_CachedWithinTTL(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	repo := &mockRepo{
+		GetEmployeeStatsFn: func(ctx context.Context) (*repository.EmployeeStats, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return &repository.EmployeeStats{TotalCount: 12}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.stats.now = func() time.Time { return now }
+
+	// одновременные запросы при пустом кэше выполняют один запрос к репозиторию
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats, err := svc.GetEmployeeStats(context.Background())
+			if err != nil || stats.TotalCount != 12 {
+				t.Errorf("unexpected result: %+v, %v", stats, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	now = now.Add(DefaultStatsCacheTTL - time.Second)
+	if _, err := svc.GetEmployeeStats(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected 1 repository call within TTL, got %d", got)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := svc.GetEmployeeStats(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected reload after TTL, got %d calls", got)
+	}
+}
+
+// searchStub сервис под декоратором кэша поиска: считает обращения к поиску
+type searchStub struct {
+	EmployeeService
+	calls  int32
+	search func(searchQuery string) ([]*domain.Employee, int64, error)
+}
+
+func (s *searchStub) SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return s.search(searchQuery)
+}
+
+func (s *searchStub) DeleteEmployee(ctx context.Context, id int64) error {
+	return nil
+}
+
+func searchCounterValue(t *testing.T, counter *prometheus.CounterVec, result string) float64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	if err := counter.WithLabelValues(result).Write(metric); err != nil {
+		t.Fatalf("read counter: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestSearchCache_ConcurrentIdenticalQueriesShareOneCall(t *testing.T) {
+	release := make(chan struct{})
+	stub := &searchStub{search: func(searchQuery string) ([]*domain.Employee, int64, error) {
+		<-release
+		return []*domain.Employee{{ID: 1, Name: "Ivan"}}, 1, nil
+	}}
+	counter := NewSearchCacheCounter()
+	svc := NewSearchCacheService(stub, DefaultSearchCacheTTL, counter)
+
+	// регистр и лишние пробелы не меняют ключ кэша
+	queries := []string{"ivan", "Ivan", "  IVAN "}
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(q string) {
+			defer wg.Done()
+			employees, total, err := svc.SearchEmployees(context.Background(), q, domain.EmployeeFilter{}, 10, 0)
+			if err != nil || total != 1 || len(employees) != 1 || employees[0].ID != 1 {
+				t.Errorf("unexpected result: %v, %d, %v", employees, total, err)
+			}
+		}(queries[i%len(queries)])
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&stub.calls); got != 1 {
+		t.Fatalf("expected 1 search call, got %d", got)
+	}
+	hits, misses, merged := searchCounterValue(t, counter, "hit"), searchCounterValue(t, counter, "miss"), searchCounterValue(t, counter, "merged")
+	if misses != 1 || hits+merged != 29 {
+		t.Fatalf("unexpected metrics: hit=%v miss=%v merged=%v", hits, misses, merged)
+	}
+}
+
+func TestSearchCache_ConcurrentDistinctQueries(t *testing.T) {
+	stub := &searchStub{search: func(searchQuery string) ([]*domain.Employee, int64, error) {
+		return []*domain.Employee{{Name: searchQuery}}, 1, nil
+	}}
+	svc := NewSearchCacheService(stub, DefaultSearchCacheTTL, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(q string) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				employees, _, err := svc.SearchEmployees(context.Background(), q, domain.EmployeeFilter{}, 10, 0)
+				if err != nil || len(employees) != 1 || employees[0].Name != q {
+					t.Errorf("query %q: unexpected result %v, %v", q, employees, err)
+					return
+				}
+			}
+		}(fmt.Sprintf("name%d", i))
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&stub.calls); got != 20 {
+		t.Fatalf("expected one search call per distinct query, got %d", got)
+	}
+}
+
+func TestSearchCache_MutationInvalidates(t *testing.T) {
+	stub := &searchStub{search: func(searchQuery string) ([]*domain.Employee, int64, error) {
+		return []*domain.Employee{}, 0, nil
+	}}
+	svc := NewSearchCacheService(stub, DefaultSearchCacheTTL, nil)
+	ctx := context.Background()
+
+	search := func() {
+		if _, _, err := svc.SearchEmployees(ctx, "ivan", domain.EmployeeFilter{}, 10, 0); err != nil {
+			t.Fatalf("SearchEmployees: %v", err)
+		}
+	}
+	search()
+	search()
+	if err := svc.DeleteEmployee(ctx, 1); err != nil {
+		t.Fatalf("DeleteEmployee: %v", err)
+	}
+	search()
+
+	if got := atomic.LoadInt32(&stub.calls); got != 2 {
+		t.Fatalf("expected a new search call after mutation, got %d calls", got)
+	}
+}
+
+func TestSearchCache_InFlightResultDroppedAfterMutation(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	stub := &searchStub{search: func(searchQuery string) ([]*domain.Employee, int64, error) {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+		return []*domain.Employee{}, 0, nil
+	}}
+	svc := NewSearchCacheService(stub, DefaultSearchCacheTTL, nil)
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = svc.SearchEmployees(ctx, "ivan", domain.EmployeeFilter{}, 10, 0)
+	}()
+	<-started
+	// изменение во время поиска: его результат мог устареть и в кэш не попадает
+	_ = svc.DeleteEmployee(ctx, 1)
+	close(release)
+	<-done
+
+	if _, _, err := svc.SearchEmployees(ctx, "ivan", domain.EmployeeFilter{}, 10, 0); err != nil {
+		t.Fatalf("SearchEmployees: %v", err)
+	}
+	if got := atomic.LoadInt32(&stub.calls); got != 2 {
+		t.Fatalf("expected stale result not cached, got %d calls", got)
+	}
+}
+
+func TestSearchCache_ExpiresAfterTTL(t *testing.T) {
+	stub := &searchStub{search: func(searchQuery string) ([]*domain.Employee, int64, error) {
+		return []*domain.Employee{}, 0, nil
+	}}
+	svc := NewSearchCacheService(stub, DefaultSearchCacheTTL, nil).(*searchCacheService)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, step := range []time.Duration{0, DefaultSearchCacheTTL - time.Millisecond, time.Millisecond} {
+		now = now.Add(step)
+		if _, _, err := svc.SearchEmployees(ctx, "ivan", domain.EmployeeFilter{}, 10, 0); err != nil {
+			t.Fatalf("SearchEmployees: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&stub.calls); got != 2 {
+		t.Fatalf("expected reload after TTL, got %d calls", got)
+	}
+}
+
+func TestSearchCache_DropsExpiredAndOverflowEntries(t *testing.T) {
+	stub := &searchStub{search: func(searchQuery string) ([]*domain.Employee, int64, error) {
+		return []*domain.Employee{}, 0, nil
+	}}
+	svc := NewSearchCacheService(stub, DefaultSearchCacheTTL, nil).(*searchCacheService)
+	svc.maxEntries = 3
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+	search := func(query string) {
+		t.Helper()
+		if _, _, err := svc.SearchEmployees(ctx, query, domain.EmployeeFilter{}, 10, 0); err != nil {
+			t.Fatalf("SearchEmployees: %v", err)
+		}
+	}
+	cached := func() []string {
+		svc.mu.RLock()
+		defer svc.mu.RUnlock()
+		keys := []string{}
+		for e := svc.order.Front(); e != nil; e = e.Next() {
+			keys = append(keys, e.Value.(*searchResult).key)
+		}
+		if len(keys) != len(svc.results) {
+			t.Fatalf("order has %d entries, results %d", len(keys), len(svc.results))
+		}
+		return keys
+	}
+	key := func(query string) string { return searchCacheKey(query, domain.EmployeeFilter{}, 10, 0) }
+
+	// сверх предела вытесняется старейшая запись
+	for _, query := range []string{"a", "ab", "abc", "abcd"} {
+		search(query)
+	}
+	if got, want := cached(), []string{key("ab"), key("abc"), key("abcd")}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v after overflow, got %v", want, got)
+	}
+
+	// устаревшие записи удаляются при сохранении новой
+	now = now.Add(DefaultSearchCacheTTL)
+	search("x")
+	if got, want := cached(), []string{key("x")}; !slices.Equal(got, want) {
+		t.Fatalf("expected expired entries dropped, got %v", got)
+	}
+
+	// и при обращении к устаревшей записи, даже если поиск не удался
+	now = now.Add(DefaultSearchCacheTTL)
+	stub.search = func(searchQuery string) ([]*domain.Employee, int64, error) {
+		return nil, 0, errors.New("db down")
+	}
+	if _, _, err := svc.SearchEmployees(ctx, "x", domain.EmployeeFilter{}, 10, 0); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := cached(); len(got) != 0 {
+		t.Fatalf("expected expired entry dropped on lookup, got %v", got)
+	}
+}
+
+func TestGetEmployeeStats_ErrorNotCached(t *testing.T) {
+	calls := 0
+	repo := &mockRepo{
+		GetEmployeeStatsFn: func(ctx context.Context) (*repository.EmployeeStats, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("db down")
+			}
+			return &repository.EmployeeStats{TotalCount: 1}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	if _, err := svc.GetEmployeeStats(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	stats, err := svc.GetEmployeeStats(context.Background())
+	if err != nil || stats.TotalCount != 1 || calls != 2 {
+		t.Fatalf("expected retry after error, got %+v, %v, calls=%d", stats, err, calls)
+	}
+}
+
+func TestGetEmployeeStats_CacheDisabled(t *testing.T) {
+	calls := 0
+	repo := &mockRepo{
+		GetEmployeeStatsFn: func(ctx context.Context) (*repository.EmployeeStats, error) {
+			calls++
+			return &repository.EmployeeStats{}, nil
+		},
+	}
+	opts := DefaultOptions()
+	opts.StatsCacheTTL = 0
+	svc := NewEmployeeServiceWithOptions(repo, zap.NewNop(), opts)
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.GetEmployeeStats(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 repository calls without cache, got %d", calls)
+	}
+}
+
+func TestDeleteEmployeeIfExists(t *testing.T) {
+	existing := map[int64]bool{3: true}
+	repo := &mockRepo{
+		DeleteFn: func(ctx context.Context, id int64) error {
+			if !existing[id] {
+				return &repository.NotFoundError{Entity: "employee", ID: id}
+			}
+			delete(existing, id)
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	existed, err := svc.DeleteEmployeeIfExists(context.Background(), 3)
+	if err != nil || !existed {
+		t.Fatalf("present row: expected existed, got %v (%v)", existed, err)
+	}
+	// повтор удаления и отсутствующий сотрудник — не ошибка
+	for _, id := range []int64{3, 99} {
+		existed, err := svc.DeleteEmployeeIfExists(context.Background(), id)
+		if err != nil || existed {
+			t.Fatalf("absent row %d: expected no error and existed=false, got %v (%v)", id, existed, err)
+		}
+	}
+
+	// строгое удаление по-прежнему возвращает NotFoundError
+	var notFound *repository.NotFoundError
+	if err := svc.DeleteEmployee(context.Background(), 3); !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError from strict delete, got %v", err)
+	}
+
+	repo.DeleteFn = func(ctx context.Context, id int64) error { return errors.New("db down") }
+	if _, err := svc.DeleteEmployeeIfExists(context.Background(), 3); err == nil {
+		t.Fatal("expected repository error")
+	}
+}
+
+func TestDeleteEmployees_Report(t *testing.T) {
+	var gotIDs []int64
+	txRepo := &mockRepo{
+		DeleteManyFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			gotIDs = ids
+			// RETURNING не гарантирует порядок
+			return []int64{7, 3}, nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	result, err := svc.Employee.DeleteEmployees(context.Background(), []int64{3, 5, 7, 3}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gotIDs) != 3 {
+		t.Fatalf("expected duplicates removed, got %v", gotIDs)
+	}
+	if fmt.Sprint(result.Deleted) != "[3 7]" || fmt.Sprint(result.NotFound) != "[5]" || result.DryRun {
+		t.Fatalf("unexpected report: %+v", result)
+	}
+	if !uow.committed {
+		t.Fatal("expected commit")
+	}
+}
+
+func TestDeleteEmployees_DryRunDeletesNothing(t *testing.T) {
+	txRepo := &mockRepo{
+		DeleteManyFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			t.Fatal("DeleteMany must not be called in dry run")
+			return nil, nil
+		},
+		ExistingIDsFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			return []int64{5}, nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	result, err := svc.Employee.DeleteEmployees(context.Background(), []int64{5, 6}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(result.Deleted) != "[5]" || fmt.Sprint(result.NotFound) != "[6]" || !result.DryRun {
+		t.Fatalf("unexpected report: %+v", result)
+	}
+}
+
+func TestUpdateEmployees_MixedResults(t *testing.T) {
+	var archived []int64
+	var updated []*domain.Employee
+	txRepo := &mockRepo{
+		ExistingIDsFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			if fmt.Sprint(ids) != "[1 2 3]" {
+				t.Errorf("unexpected ids checked: %v", ids)
+			}
+			return []int64{3, 1}, nil
+		},
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			archived = append(archived, id)
+			return nil
+		},
+		UpdateBatchFn: func(ctx context.Context, employees []*domain.Employee) ([]int64, error) {
+			updated = employees
+			return []int64{1, 3}, nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	ctx := WithActor(context.Background(), "hr-bot")
+	result, err := svc.Employee.UpdateEmployees(ctx, []domain.BatchUpdateItem{
+		{ID: 1, Name: "  Alice ", Phone: "+77010000001", City: "Almaty"},
+		{ID: 2, Name: "Bob", Phone: "+77010000002", City: "Astana"},
+		{ID: 3, Name: "Carol", Phone: "+77010000003", City: "Almaty"},
+		{ID: 4, Name: "", Phone: "+77010000004", City: "Almaty"},
+		{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty"},
+	}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statuses := make([]string, len(result.Results))
+	for i, r := range result.Results {
+		statuses[i] = r.Status
+	}
+	if fmt.Sprint(statuses) != "[updated not_found updated validation_error validation_error]" {
+		t.Fatalf("unexpected statuses: %v", statuses)
+	}
+	if result.Updated != 2 || result.NotFound != 1 || result.Failed != 2 || result.Aborted {
+		t.Fatalf("unexpected report: %+v", result)
+	}
+	if result.Results[3].Errors[0].Field != "name" {
+		t.Fatalf("expected name error, got %+v", result.Results[3].Errors)
+	}
+	// повтор ID и телефона в пакете
+	if errs := result.Results[4].Errors; len(errs) != 2 || errs[0].Field != "id" || errs[1].Field != "phone" {
+		t.Fatalf("expected repeated id and phone errors, got %+v", errs)
+	}
+	if fmt.Sprint(archived) != "[1 3]" || len(updated) != 2 || updated[0].Name != "Alice" || updated[1].UpdatedBy != "hr-bot" {
+		t.Fatalf("unexpected update: archived %v, updated %+v", archived, updated)
+	}
+	if !uow.committed {
+		t.Fatal("expected commit")
+	}
+}
+
+func TestUpdateEmployees_StrictAbortsOnMissingID(t *testing.T) {
+	txRepo := &mockRepo{
+		ExistingIDsFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			return []int64{1}, nil
+		},
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			t.Fatal("ArchiveVersion must not be called for aborted batch")
+			return nil
+		},
+		UpdateBatchFn: func(ctx context.Context, employees []*domain.Employee) ([]int64, error) {
+			t.Fatal("UpdateBatch must not be called for aborted batch")
+			return nil, nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	result, err := svc.Employee.UpdateEmployees(context.Background(), []domain.BatchUpdateItem{
+		{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty"},
+		{ID: 2, Name: "Bob", Phone: "+77010000002", City: "Astana"},
+	}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Aborted || result.Updated != 0 || result.NotFound != 1 ||
+		result.Results[0].Status != domain.BatchSkipped || result.Results[1].Status != domain.BatchNotFound {
+		t.Fatalf("unexpected report: %+v", result)
+	}
+}
+
+func TestUpdateEmployees_Invalid(t *testing.T) {
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}}, zap.NewNop(), DefaultOptions())
+
+	if _, err := svc.Employee.UpdateEmployees(context.Background(), nil, false); err == nil {
+		t.Fatal("expected error for empty batch")
+	}
+	items := make([]domain.BatchUpdateItem, MaxBatchUpdate+1)
+	var verr *ValidationError
+	if _, err := svc.Employee.UpdateEmployees(context.Background(), items, false); !errors.As(err, &verr) || verr.Field != "employees" {
+		t.Fatalf("expected validation error for too many items, got %v", err)
+	}
+}
+
+func TestUpdateEmployees_PhoneConflictRollsBack(t *testing.T) {
+	txRepo := &mockRepo{
+		ExistingIDsFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			return ids, nil
+		},
+		UpdateBatchFn: func(ctx context.Context, employees []*domain.Employee) ([]int64, error) {
+			return nil, &repository.AlreadyExistsError{Entity: "employee", Field: "phone"}
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	_, err := svc.Employee.UpdateEmployees(context.Background(), []domain.BatchUpdateItem{
+		{ID: 1, Name: "Alice", Phone: "+77010000001", City: "Almaty"},
+	}, false)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Field != "phone" {
+		t.Fatalf("expected phone conflict, got %v", err)
+	}
+	if !uow.rolledBack {
+		t.Fatal("expected rollback")
+	}
+}
+
+func TestGetEmployeesByIDs_DeduplicatesAndCaps(t *testing.T) {
+	var gotIDs []int64
+	repo := &mockRepo{
+		GetByIDsFn: func(ctx context.Context, ids []int64) ([]*domain.Employee, error) {
+			gotIDs = ids
+			return []*domain.Employee{{ID: 2}}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employees, err := svc.GetEmployeesByIDs(context.Background(), []int64{2, 7, 2})
+	if err != nil {
+		t.Fatalf("GetEmployeesByIDs: %v", err)
+	}
+	if len(gotIDs) != 2 || gotIDs[0] != 2 || gotIDs[1] != 7 {
+		t.Fatalf("expected deduplicated ids [2 7], got %v", gotIDs)
+	}
+	if len(employees) != 1 || employees[0].ID != 2 {
+		t.Fatalf("unexpected employees: %+v", employees)
+	}
+
+	tooMany := make([]int64, MaxBatchGet+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+	_, err = svc.GetEmployeesByIDs(context.Background(), tooMany)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "ids" {
+		t.Fatalf("expected ids validation error, got %v", err)
+	}
+}
+
+func TestDeleteEmployees_Validation(t *testing.T) {
+	tooMany := make([]int64, MaxBatchDelete+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+
+	tests := []struct {
+		name string
+		ids  []int64
+	}{
+		{"empty", nil},
+		{"too many", tooMany},
+		{"non-positive id", []int64{1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				DeleteManyFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+					t.Fatal("repository must not be called for invalid input")
+					return nil, nil
+				},
+			}
+			svc := NewEmployeeService(repo, zap.NewNop())
+
+			_, err := svc.DeleteEmployees(context.Background(), tt.ids, false)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Field != "ids" {
+				t.Fatalf("expected ids validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteEmployees_FailureRollsBack(t *testing.T) {
+	txRepo := &mockRepo{
+		DeleteManyFn: func(ctx context.Context, ids []int64) ([]int64, error) {
+			return nil, errors.New("connection reset")
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	if _, err := svc.Employee.DeleteEmployees(context.Background(), []int64{1, 2}, false); err == nil {
+		t.Fatal("expected error")
+	}
+	if !uow.rolledBack || uow.committed {
+		t.Fatalf("expected rollback, got rolledBack=%v committed=%v", uow.rolledBack, uow.committed)
+	}
+}
+
+func TestSetEmployeeStatus_ArchivesAndUpdates(t *testing.T) {
+	var calls []string
+	txRepo := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return &domain.Employee{ID: id, Name: "A", Status: domain.StatusActive}, nil
+		},
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			calls = append(calls, "archive")
+			return nil
+		},
+		UpdateStatusFn: func(ctx context.Context, id int64, status string) error {
+			calls = append(calls, "status:"+status)
+			return nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	employee, err := svc.Employee.SetEmployeeStatus(context.Background(), 3, domain.StatusInactive)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if employee.Status != domain.StatusInactive {
+		t.Fatalf("expected inactive, got %q", employee.Status)
+	}
+	if len(calls) != 2 || calls[0] != "archive" || calls[1] != "status:inactive" {
+		t.Fatalf("expected archive then status update, got %v", calls)
+	}
+	if !uow.committed {
+		t.Fatal("expected commit")
+	}
+}
+
+func TestSetEmployeeStatus_SameStatusIsNoop(t *testing.T) {
+	repo := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return &domain.Employee{ID: id, Name: "A", Status: domain.StatusInactive}, nil
+		},
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			t.Fatal("unexpected archive")
+			return nil
+		},
+		UpdateStatusFn: func(ctx context.Context, id int64, status string) error {
+			t.Fatal("unexpected status update")
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employee, err := svc.SetEmployeeStatus(context.Background(), 3, domain.StatusInactive)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if employee.ID != 3 || employee.Status != domain.StatusInactive {
+		t.Fatalf("expected current state, got %+v", employee)
+	}
+}
+
+func TestStatusValidation(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewEmployeeService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	err := svc.CreateEmployee(ctx, &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty", Status: "fired"})
+	var ve *ValidationErrors
+	if !errors.As(err, &ve) || len(ve.Errors) != 1 || ve.Errors[0].Field != "status" {
+		t.Fatalf("expected status validation error, got %v", err)
+	}
+
+	_, err = svc.GetAllEmployees(ctx, domain.EmployeeFilter{Status: "fired"})
+	var single *ValidationError
+	if !errors.As(err, &single) || single.Field != "status" {
+		t.Fatalf("expected status filter error, got %v", err)
+	}
+
+	if _, err := svc.GetAllEmployees(ctx, domain.EmployeeFilter{Status: " on_leave "}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastFilter.Status != domain.StatusOnLeave {
+		t.Fatalf("expected trimmed status filter, got %q", repo.lastFilter.Status)
+	}
+}
+
+func TestCreateEmployee_DefaultsToActive(t *testing.T) {
+	var created *domain.Employee
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			created = e
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	if err := svc.CreateEmployee(context.Background(), &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Status != domain.StatusActive {
+		t.Fatalf("expected active, got %q", created.Status)
+	}
+}
+
+func TestCreateAndUpdateEmployee_Actor(t *testing.T) {
+	stored := map[int64]*domain.Employee{}
+	repo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			e.ID = 1
+			stored[e.ID] = &domain.Employee{ID: e.ID, CreatedBy: e.CreatedBy, UpdatedBy: e.UpdatedBy}
+			return nil
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			// как и репозиторий, created_by не меняется и возвращается из БД
+			stored[e.ID].UpdatedBy = e.UpdatedBy
+			e.CreatedBy = stored[e.ID].CreatedBy
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	employee := &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty", CreatedBy: "forged"}
+	if err := svc.CreateEmployee(WithActor(context.Background(), "alice"), employee); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if employee.CreatedBy != "alice" || employee.UpdatedBy != "alice" {
+		t.Fatalf("expected alice as creator and updater, got %q/%q", employee.CreatedBy, employee.UpdatedBy)
+	}
+
+	update := &domain.Employee{ID: 1, Name: "A", Phone: "+77010000001", City: "Astana"}
+	if err := svc.UpdateEmployee(WithActor(context.Background(), "bob"), update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if update.CreatedBy != "alice" || update.UpdatedBy != "bob" {
+		t.Fatalf("expected created_by alice and updated_by bob, got %q/%q", update.CreatedBy, update.UpdatedBy)
+	}
+
+	if err := svc.UpdateEmployee(context.Background(), update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if update.UpdatedBy != SystemActor || stored[1].CreatedBy != "alice" {
+		t.Fatalf("expected system updater without actor, got %q (created_by %q)", update.UpdatedBy, stored[1].CreatedBy)
+	}
+}
+
+func TestCreateEmployeeIdempotent_Replay(t *testing.T) {
+	keys := map[string]*repository.IdempotencyRecord{}
+	employees := map[int64]*domain.Employee{}
+	txRepo := &mockRepo{
+		CreateFn: func(ctx context.Context, e *domain.Employee) error {
+			e.ID = int64(len(employees) + 1)
+			copied := *e
+			employees[e.ID] = &copied
+			return nil
+		},
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			copied := *employees[id]
+			return &copied, nil
+		},
+		GetIdempotencyKeyFn: func(ctx context.Context, key string, since time.Time) (*repository.IdempotencyRecord, error) {
+			return keys[key], nil
+		},
+		SaveIdempotencyKeyFn: func(ctx context.Context, record *repository.IdempotencyRecord, since time.Time) (bool, error) {
+			keys[record.Key] = record
+			return true, nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+	ctx := context.Background()
+
+	first := &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"}
+	replayed, err := svc.Employee.CreateEmployeeIdempotent(ctx, "key-1", "hash-a", first)
+	if err != nil || replayed {
+		t.Fatalf("first request: replayed=%v err=%v", replayed, err)
+	}
+
+	retry := &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"}
+	replayed, err = svc.Employee.CreateEmployeeIdempotent(ctx, "key-1", "hash-a", retry)
+	if err != nil || !replayed {
+		t.Fatalf("retry: replayed=%v err=%v", replayed, err)
+	}
+	if retry.ID != first.ID || len(employees) != 1 {
+		t.Fatalf("expected original employee %d without a new record, got %d (%d records)", first.ID, retry.ID, len(employees))
+	}
+
+	_, err = svc.Employee.CreateEmployeeIdempotent(ctx, "key-1", "hash-b", &domain.Employee{Name: "B", Phone: "+77010000002", City: "Almaty"})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Key != i18n.ConflictIdempotencyKey {
+		t.Fatalf("expected idempotency conflict for a different body, got %v", err)
+	}
+	if len(employees) != 1 {
+		t.Fatalf("expected no new records, got %d", len(employees))
+	}
+}
+
+func TestCreateEmployeeIdempotent_ConcurrentKeyRollsBack(t *testing.T) {
+	txRepo := &mockRepo{
+		SaveIdempotencyKeyFn: func(ctx context.Context, record *repository.IdempotencyRecord, since time.Time) (bool, error) {
+			return false, nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	_, err := svc.Employee.CreateEmployeeIdempotent(context.Background(), "key-1", "hash", &domain.Employee{Name: "A", Phone: "+77010000001", City: "Almaty"})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Key != i18n.ConflictIdempotencyInProgress {
+		t.Fatalf("expected in-progress conflict, got %v", err)
+	}
+	if !uow.rolledBack {
+		t.Fatal("expected the created employee to be rolled back")
+	}
+}
+
+func TestEventBroker(t *testing.T) {
+	broker := NewEventBroker()
+	first, unsubscribeFirst := broker.Subscribe()
+	second, _ := broker.Subscribe()
+
+	broker.Publish(domain.EmployeeEvent{Op: domain.EventInsert, ID: 1})
+	for _, ch := range []<-chan domain.EmployeeEvent{first, second} {
+		if event := <-ch; event.Op != domain.EventInsert || event.ID != 1 {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Fatalf("expected closed channel after unsubscribe")
+	}
+
+	// медленный подписчик теряет события сверх буфера, но не блокирует Publish
+	for i := 0; i < eventBufferSize+10; i++ {
+		broker.Publish(domain.EmployeeEvent{Op: domain.EventUpdate, ID: int64(i)})
+	}
+	if len(second) != eventBufferSize {
+		t.Fatalf("expected %d buffered events, got %d", eventBufferSize, len(second))
+	}
+
+	broker.Close()
+	drained := 0
+	for range second {
+		drained++
+	}
+	if drained != eventBufferSize {
+		t.Fatalf("expected buffered events before close, got %d", drained)
+	}
+	late, _ := broker.Subscribe()
+	if _, ok := <-late; ok {
+		t.Fatalf("expected closed channel when subscribing after Close")
+	}
+}
+
+func TestMatchSearch(t *testing.T) {
+	employee := &domain.Employee{Name: "Айгүл Сейітқызы", Phone: "+77011234567", City: "Алматы"}
+
+	tests := []struct {
+		name  string
+		query string
+		want  SearchMatch
+	}{
+		{"кириллица без учета регистра", "СЕЙІТ", SearchMatch{Field: MatchFieldName, Start: 6, End: 11}},
+		{"начало имени", "айгүл", SearchMatch{Field: MatchFieldName, Start: 0, End: 5}},
+		{"телефон", "123", SearchMatch{Field: MatchFieldPhone, Start: 5, End: 8}},
+		{"город", "маты", SearchMatch{Field: MatchFieldCity, Start: 2, End: 6}},
+		{"весь запрос из нескольких слов", "гүл сей", SearchMatch{Field: MatchFieldName, Start: 2, End: 9}},
+		{"первое слово запроса", "алматы айгүл", SearchMatch{Field: MatchFieldCity, Start: 0, End: 6}},
+		{"лишние пробелы", "  алма  ", SearchMatch{Field: MatchFieldCity, Start: 0, End: 4}},
+		{"нет совпадения", "астана", SearchMatch{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchSearch(employee, tt.query); got != tt.want {
+				t.Fatalf("MatchSearch(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeEmployees(t *testing.T) {
+	birth := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	var calls []string
+	var saved *domain.EmployeeMerge
+	txRepo := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			if id == 1 {
+				return &domain.Employee{ID: 1, Name: "Алия", Phone: "+77010000001", City: "Almaty", Status: domain.StatusActive, Version: 4}, nil
+			}
+			return &domain.Employee{ID: 2, Name: "Алия", Phone: "+77010000002", City: "Almaty", BirthDate: &birth}, nil
+		},
+		ArchiveVersionFn: func(ctx context.Context, id int64) error {
+			calls = append(calls, fmt.Sprintf("archive %d", id))
+			return nil
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			calls = append(calls, fmt.Sprintf("update %d v%d", e.ID, e.Version))
+			return nil
+		},
+		ReassignEmployeeFn: func(ctx context.Context, fromID, toID int64) error {
+			calls = append(calls, fmt.Sprintf("reassign %d->%d", fromID, toID))
+			return nil
+		},
+		SaveMergeFn: func(ctx context.Context, merge *domain.EmployeeMerge) error {
+			calls = append(calls, "save merge")
+			saved = merge
+			return nil
+		},
+		DeleteFn: func(ctx context.Context, id int64) error {
+			calls = append(calls, fmt.Sprintf("delete %d", id))
+			return nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	ctx := WithActor(context.Background(), "hr-bot")
+	result, err := svc.Employee.MergeEmployees(ctx, 1, 2, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"archive 1", "update 1 v4", "reassign 2->1", "save merge", "archive 2", "delete 2"}
+	if strings.Join(calls, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("expected %v, got %v", want, calls)
+	}
+	if result.Employee.BirthDate == nil || !result.Employee.BirthDate.Equal(birth) || result.Employee.UpdatedBy != "hr-bot" {
+		t.Fatalf("expected birth date copied by hr-bot, got %+v", result.Employee)
+	}
+	if len(result.CopiedFields) != 1 || result.CopiedFields[0] != "birth_date" {
+		t.Fatalf("unexpected copied fields %v", result.CopiedFields)
+	}
+	if saved.SourceID != 2 || saved.TargetID != 1 || saved.Source.Phone != "+77010000002" || saved.MergedBy != "hr-bot" {
+		t.Fatalf("unexpected merge record %+v", saved)
+	}
+	if !uow.committed {
+		t.Fatal("expected commit")
+	}
+}
+
+func TestMergeEmployees_WithoutFillMissingKeepsTarget(t *testing.T) {
+	birth := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	txRepo := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			return &domain.Employee{ID: id, Name: "Алия", Phone: fmt.Sprintf("+7701000000%d", id), City: "Almaty", BirthDate: &birth}, nil
+		},
+		UpdateFn: func(ctx context.Context, e *domain.Employee) error {
+			t.Fatalf("target must not be updated without fill_missing")
+			return nil
+		},
+	}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: &fakeUnitOfWork{txRepo: txRepo}}, zap.NewNop(), DefaultOptions())
+
+	result, err := svc.Employee.MergeEmployees(context.Background(), 1, 2, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.CopiedFields) != 0 || result.SourceID != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestMergeEmployees_Invalid(t *testing.T) {
+	svc := NewEmployeeService(&mockRepo{}, zap.NewNop())
+
+	for _, tc := range []struct {
+		source int64
+		key    string
+	}{{0, i18n.MergeSourceRequired}, {5, i18n.MergeSelf}} {
+		_, err := svc.MergeEmployees(context.Background(), 5, tc.source, false)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Key != tc.key {
+			t.Fatalf("source %d: expected %s, got %v", tc.source, tc.key, err)
+		}
+	}
+}
+
+func TestMergeEmployees_MissingTargetRollsBack(t *testing.T) {
+	deleted := false
+	txRepo := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Employee, error) {
+			if id == 1 {
+				return nil, &repository.NotFoundError{Entity: "employee", ID: id}
+			}
+			return &domain.Employee{ID: id}, nil
+		},
+		DeleteFn: func(ctx context.Context, id int64) error {
+			deleted = true
+			return nil
+		},
+	}
+	uow := &fakeUnitOfWork{txRepo: txRepo}
+	svc := NewServices(&repository.IRepositories{Employee: &mockRepo{}, UnitOfWork: uow}, zap.NewNop(), DefaultOptions())
+
+	_, err := svc.Employee.MergeEmployees(context.Background(), 1, 2, true)
+	if !repository.IsNotFound(err) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if deleted || !uow.rolledBack {
+		t.Fatalf("expected rollback without delete, got deleted=%v rolledBack=%v", deleted, uow.rolledBack)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	var gotDigits, gotLimit int
+	repo := &mockRepo{
+		FindDuplicatesFn: func(ctx context.Context, phoneDigits, limit int) ([]*domain.DuplicatePair, error) {
+			gotDigits, gotLimit = phoneDigits, limit
+			return []*domain.DuplicatePair{}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	if _, err := svc.FindDuplicates(context.Background(), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotDigits != DuplicatePhoneDigits || gotLimit != DefaultDuplicatesLimit {
+		t.Fatalf("expected %d digits and default limit, got %d and %d", DuplicatePhoneDigits, gotDigits, gotLimit)
+	}
+
+	_, err := svc.FindDuplicates(context.Background(), MaxDuplicatesLimit+1)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "limit" {
+		t.Fatalf("expected limit validation error, got %v", err)
+	}
+}
+
+func TestExportEmployees_SearchQuery(t *testing.T) {
+	var got domain.EmployeeFilter
+	streamed := false
+	repo := &mockRepo{
+		StreamEmployeesFn: func(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+			streamed = true
+			got = filter
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	filter := domain.EmployeeFilter{Query: "  Нурлан  ", City: " Almaty ", Status: "active"}
+	if err := svc.ExportEmployees(context.Background(), filter, func(*domain.Employee) error { return nil }); err != nil {
+		t.Fatalf("ExportEmployees: %v", err)
+	}
+	if got.Query != "Нурлан" || got.City != "Almaty" || got.Status != "active" {
+		t.Fatalf("unexpected filter passed to repository: %+v", got)
+	}
+
+	// та же проверка строки поиска, что и в SearchEmployees
+	streamed = false
+	err := svc.ExportEmployees(context.Background(), domain.EmployeeFilter{Query: "a"}, func(*domain.Employee) error { return nil })
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "search_query" || streamed {
+		t.Fatalf("expected search_query validation error before streaming, got %v (streamed %v)", err, streamed)
+	}
+}
+
+func TestCheckIn(t *testing.T) {
+	var saved *domain.Attendance
+	repo := &mockRepo{
+		UpsertAttendanceFn: func(ctx context.Context, attendance *domain.Attendance) error {
+			if attendance.EmployeeID == 99 {
+				return &repository.NotFoundError{Entity: "employee", ID: 99}
+			}
+			saved = attendance
+			return nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	// без статуса — присутствует, за сегодня
+	attendance, err := svc.CheckIn(context.Background(), 3, "", "  опоздал на 10 минут ")
+	if err != nil {
+		t.Fatalf("CheckIn: %v", err)
+	}
+	if attendance != saved || saved.Status != domain.AttendancePresent || saved.Note != "опоздал на 10 минут" {
+		t.Fatalf("unexpected attendance %+v", saved)
+	}
+	if domain.FormatDate(&saved.Date) != time.Now().UTC().Format(domain.DateLayout) || saved.Date.Hour() != 0 {
+		t.Fatalf("expected today, got %v", saved.Date)
+	}
+
+	if _, err := svc.CheckIn(context.Background(), 3, domain.AttendanceSick, ""); err != nil || saved.Status != domain.AttendanceSick {
+		t.Fatalf("expected sick, got %+v (%v)", saved, err)
+	}
+
+	for _, tc := range []struct{ status, note string }{
+		{"vacation", ""},
+		{domain.AttendanceAbsent, strings.Repeat("я", MaxAttendanceNoteLength+1)},
+	} {
+		saved = nil
+		if _, err := svc.CheckIn(context.Background(), 3, tc.status, tc.note); !IsValidation(err) || saved != nil {
+			t.Fatalf("expected validation error for status %q, got %v", tc.status, err)
+		}
+	}
+
+	var notFound *repository.NotFoundError
+	if _, err := svc.CheckIn(context.Background(), 99, "", ""); !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestGetEmployeeAttendance_Range(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	repo := &mockRepo{
+		GetAttendanceFn: func(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error) {
+			gotFrom, gotTo = from, to
+			return []*domain.Attendance{}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+	date := func(s string) time.Time {
+		d, _ := domain.ParseDate(s)
+		return *d
+	}
+
+	// без границ — последние DefaultAttendanceDays дней по сегодня
+	if _, err := svc.GetEmployeeAttendance(context.Background(), 3, time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTo.Format(domain.DateLayout) != time.Now().UTC().Format(domain.DateLayout) ||
+		gotTo.Sub(gotFrom) != (DefaultAttendanceDays-1)*24*time.Hour {
+		t.Fatalf("unexpected default range %v..%v", gotFrom, gotTo)
+	}
+
+	// ровно MaxAttendanceDays дней допустимо
+	from, to := date("2024-01-01"), date("2024-12-31")
+	if _, err := svc.GetEmployeeAttendance(context.Background(), 3, from, to); err != nil {
+		t.Fatalf("366 days: %v", err)
+	}
+	if !gotFrom.Equal(from) || !gotTo.Equal(to) {
+		t.Fatalf("unexpected range %v..%v", gotFrom, gotTo)
+	}
+
+	for _, tc := range []struct{ from, to string }{
+		{"2026-10-02", "2026-10-01"},
+		{"2024-01-01", "2025-01-01"},
+	} {
+		if _, err := svc.GetEmployeeAttendance(context.Background(), 3, date(tc.from), date(tc.to)); !IsValidation(err) {
+			t.Fatalf("expected validation error for %s..%s, got %v", tc.from, tc.to, err)
+		}
+	}
+}
+
+func TestGetAttendanceSummary_DefaultsToToday(t *testing.T) {
+	var gotDate time.Time
+	repo := &mockRepo{
+		AttendanceSummaryFn: func(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error) {
+			gotDate = date
+			return &domain.AttendanceSummary{Date: date, Present: 2}, nil
+		},
+	}
+	svc := NewEmployeeService(repo, zap.NewNop())
+
+	summary, err := svc.GetAttendanceSummary(context.Background(), time.Time{})
+	if err != nil || summary.Present != 2 {
+		t.Fatalf("unexpected summary %+v (%v)", summary, err)
+	}
+	if gotDate.Format(domain.DateLayout) != time.Now().UTC().Format(domain.DateLayout) || gotDate.Hour() != 0 {
+		t.Fatalf("expected today, got %v", gotDate)
+	}
+}
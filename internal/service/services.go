@@ -4,17 +4,91 @@ import (
 	"context"
 	"employer/internal/domain"
 	"employer/internal/repository"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
 type EmployeeService interface {
 	CreateEmployee(ctx context.Context, employee *domain.Employee) error
-	GetEmployee(ctx context.Context, id int) (*domain.Employee, error)
-	GetAllEmployees(ctx context.Context) ([]*domain.Employee, error)
+	CreateEmployeeIdempotent(ctx context.Context, key, requestHash string, employee *domain.Employee) (bool, error)
+	GetEmployee(ctx context.Context, id int64) (*domain.Employee, error)
+	GetEmployeeAsOf(ctx context.Context, id int64, at time.Time) (*domain.Employee, error)
+	GetEmployeeHistory(ctx context.Context, id int64) ([]*domain.EmployeeVersion, error)
+	GetAllEmployees(ctx context.Context, filter domain.EmployeeFilter) ([]*domain.Employee, error)
+	GetEmployeesByIDs(ctx context.Context, ids []int64) ([]*domain.Employee, error)
+	GetEmployeesPage(ctx context.Context, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error)
+	GetRecentEmployees(ctx context.Context, limit int) ([]*domain.Employee, error)
+	StreamAllEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	GetEmployeesCreatedBetween(ctx context.Context, from, to time.Time) ([]*domain.Employee, error)
+	CountEmployees(ctx context.Context, filter domain.EmployeeFilter) (int64, error)
 	UpdateEmployee(ctx context.Context, employee *domain.Employee) error
-	DeleteEmployee(ctx context.Context, id int) error
-	SearchEmployees(ctx context.Context, searchQuery string) ([]*domain.Employee, error)
+	UpdateEmployees(ctx context.Context, items []domain.BatchUpdateItem, strict bool) (*domain.BatchUpdateResponse, error)
+	SetEmployeeStatus(ctx context.Context, id int64, status string) (*domain.Employee, error)
+	DeleteEmployee(ctx context.Context, id int64) error
+	DeleteEmployeeIfExists(ctx context.Context, id int64) (bool, error)
+	DeleteEmployees(ctx context.Context, ids []int64, dryRun bool) (*domain.BatchDeleteResponse, error)
+	SearchEmployees(ctx context.Context, searchQuery string, filter domain.EmployeeFilter, limit, offset int) ([]*domain.Employee, int64, error)
+	GetEmployeesByCity(ctx context.Context, city string) ([]*domain.Employee, error)
+	GetCityCounts(ctx context.Context) ([]*domain.CityCount, error)
+	GetCityEmployees(ctx context.Context, city string, limit, offset int) ([]*domain.Employee, int64, error)
+	GetUpcomingAnniversaries(ctx context.Context, withinDays int) ([]*domain.Anniversary, error)
+	ValidateEmployee(ctx context.Context, employee *domain.Employee) ([]domain.FieldError, error)
+	CheckPhone(ctx context.Context, phone string, excludeID int64) (*domain.Employee, error)
+	ExportEmployees(ctx context.Context, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	ImportEmployees(ctx context.Context, reader EmployeeReader, opts ImportOptions) (*domain.ImportSummary, error)
+	GetEmployeeStats(ctx context.Context) (*repository.EmployeeStats, error)
+	MergeEmployees(ctx context.Context, targetID, sourceID int64, fillMissing bool) (*domain.MergeResult, error)
+	FindDuplicates(ctx context.Context, limit int) ([]*domain.DuplicatePair, error)
+	CheckIn(ctx context.Context, id int64, status, note string) (*domain.Attendance, error)
+	GetEmployeeAttendance(ctx context.Context, id int64, from, to time.Time) ([]*domain.Attendance, error)
+	GetAttendanceSummary(ctx context.Context, date time.Time) (*domain.AttendanceSummary, error)
+
+	// WithTx выполняет fn в одной транзакции: все вызовы svc внутри fn идут через
+	// нее и откатываются, если fn вернула ошибку
+	WithTx(ctx context.Context, fn func(svc EmployeeService) error) error
+}
+
+// Options настройки сервисов
+type Options struct {
+	// NormalizeCity приводит названия городов к единому виду ("алматы" -> "Алматы")
+	NormalizeCity bool
+	// PhoneValidator проверка формата телефона; nil — формат не проверяется
+	PhoneValidator PhoneValidator
+	// StatsCacheTTL сколько отдавать статистику из кэша; 0 — без кэша
+	StatsCacheTTL time.Duration
+	// SearchCacheTTL сколько отдавать результаты поиска из кэша (см. NewSearchCacheService); 0 — без кэша
+	SearchCacheTTL time.Duration
+	// SearchCacheRequests счетчик обращений к кэшу поиска (NewSearchCacheCounter); nil — без метрик
+	SearchCacheRequests *prometheus.CounterVec
+	// IdempotencyTTL сколько действует ключ Idempotency-Key; 0 — DefaultIdempotencyTTL
+	IdempotencyTTL time.Duration
+	// PageDefaultLimit размер страницы без limit; 0 — DefaultPageLimit
+	PageDefaultLimit int
+	// PageMaxLimit наибольший размер страницы; 0 — MaxPageLimit
+	PageMaxLimit int
+	// SearchMaxResults наибольшее количество результатов поиска за запрос; 0 — MaxSearchLimit
+	SearchMaxResults int
+	// MaxEmployees наибольшее количество сотрудников (см. QuotaError); 0 — без ограничения
+	MaxEmployees int
+	// ListMaxRows наибольшее количество сотрудников в списке без постраничной выдачи; 0 — DefaultListMaxRows
+	ListMaxRows int
+}
+
+// DefaultOptions настройки сервисов по умолчанию
+func DefaultOptions() Options {
+	return Options{
+		NormalizeCity:    true,
+		PhoneValidator:   kzPhoneValidator{},
+		StatsCacheTTL:    DefaultStatsCacheTTL,
+		SearchCacheTTL:   DefaultSearchCacheTTL,
+		IdempotencyTTL:   DefaultIdempotencyTTL,
+		PageDefaultLimit: DefaultPageLimit,
+		PageMaxLimit:     MaxPageLimit,
+		SearchMaxResults: MaxSearchLimit,
+		ListMaxRows:      DefaultListMaxRows,
+	}
 }
 
 // Services объединяет все сервисы
@@ -23,8 +97,16 @@ type IServices struct {
 }
 
 // NewServices создает все сервисы
-func NewServices(repos *repository.IRepositories, logger *zap.Logger) *IServices {
+func NewServices(repos *repository.IRepositories, logger *zap.Logger, opts Options) *IServices {
+	employee := NewEmployeeServiceWithOptions(repos.Employee, logger, opts)
+	employee.uow = repos.UnitOfWork
+
+	var svc EmployeeService = employee
+	if opts.SearchCacheTTL > 0 {
+		svc = NewSearchCacheService(svc, opts.SearchCacheTTL, opts.SearchCacheRequests)
+	}
+
 	return &IServices{
-		Employee: NewEmployeeService(repos.Employee, logger),
+		Employee: svc,
 	}
 }
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"employer/internal/repository"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultStatsCacheTTL время жизни закэшированной статистики по умолчанию
+const DefaultStatsCacheTTL = 30 * time.Second
+
+// statsCache кэширует статистику сотрудников на ttl. Записи кэш не сбрасывают:
+// после создания, изменения или удаления сотрудника статистика может отставать
+// не больше чем на ttl. Одновременные запросы после истечения срока выполняют
+// один запрос к БД.
+type statsCache struct {
+	ttl   time.Duration
+	now   func() time.Time
+	group singleflight.Group
+
+	mu      sync.RWMutex
+	stats   *repository.EmployeeStats
+	expires time.Time
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{ttl: ttl, now: time.Now}
+}
+
+// get возвращает статистику из кэша или загружает ее через load
+func (c *statsCache) get(ctx context.Context, load func(ctx context.Context) (*repository.EmployeeStats, error)) (*repository.EmployeeStats, error) {
+	c.mu.RLock()
+	stats, expires := c.stats, c.expires
+	c.mu.RUnlock()
+	if stats != nil && c.now().Before(expires) {
+		return stats, nil
+	}
+
+	v, err, _ := c.group.Do("stats", func() (interface{}, error) {
+		// Запрос общий для всех ожидающих, поэтому отмена одного из них его не прерывает
+		stats, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		c.stats, c.expires = stats, c.now().Add(c.ttl)
+		c.mu.Unlock()
+		return stats, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*repository.EmployeeStats), nil
+}
@@ -0,0 +1,12 @@
+// Package static содержит веб-интерфейс, встроенный в бинарный файл: приложению
+// не нужен отдельный каталог со статическими файлами
+package static
+
+import "embed"
+
+// Files страница и файлы веб-интерфейса. Новые типы файлов (CSS, JS, изображения)
+// нужно добавить в шаблон go:embed, иначе они не попадут в сборку; исходники
+// пакета не встраиваются
+//
+//go:embed *.html
+var Files embed.FS
@@ -0,0 +1,37 @@
+// Package build сведения о сборке. Значения задаются при компиляции через -ldflags:
+//
+//	go build -ldflags "-X employer/traits/build.Version=1.4.0 \
+//	  -X employer/traits/build.Commit=$(git rev-parse --short HEAD) \
+//	  -X employer/traits/build.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd
+//
+// Без -ldflags (go run, тесты) остаются значения по умолчанию.
+package build
+
+import "runtime"
+
+var (
+	// Version версия приложения
+	Version = "dev"
+	// Commit хэш коммита, из которого собран бинарник
+	Commit = "unknown"
+	// Date время сборки в UTC (RFC 3339)
+	Date = "unknown"
+)
+
+// Info сведения о сборке для логов и GET /version
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает сведения о текущей сборке
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: Date,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,88 @@
+//go:build integration
+
+package database_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"employer/traits/database"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+func TestIntegration_NotifyEmployeeChanges(t *testing.T) {
+	dsn := os.Getenv("EMPLOYER_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("EMPLOYER_TEST_DATABASE_URL не задан")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	// search_path задается на соединение, поэтому пул ограничен одним соединением
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("employer_test_%d", time.Now().UnixNano())
+	if _, err := db.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	if _, err := db.Exec(`SET search_path TO ` + schema + `, public`); err != nil {
+		t.Fatalf("set search_path: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		_ = db.Close()
+	})
+	if err := database.CreateTables(db, zap.NewNop()); err != nil {
+		t.Fatalf("CreateTables: %v", err)
+	}
+
+	listener := pq.NewListener(dsn, time.Second, time.Second, nil)
+	defer listener.Close()
+	if err := listener.Listen(database.EmployeeChangesChannel); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+
+	var id int
+	if err := db.QueryRow(`INSERT INTO employees (name, phone, city) VALUES ('Alice', '+77010000001', 'Almaty') RETURNING id`).Scan(&id); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE employees SET deleted_at = now() WHERE id = $1`, id); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM employees WHERE id = $1`, id); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	for _, wantOp := range []string{"insert", "delete"} {
+		select {
+		case n := <-listener.Notify:
+			var event struct {
+				Op string `json:"op"`
+				ID int    `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				t.Fatalf("payload %q: %v", n.Extra, err)
+			}
+			if event.Op != wantOp || event.ID != id {
+				t.Fatalf("expected %s of %d, got %+v", wantOp, id, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s notification", wantOp)
+		}
+	}
+
+	// окончательное удаление мягко удаленной записи повторно не отправляется
+	select {
+	case n := <-listener.Notify:
+		t.Fatalf("unexpected notification %q", n.Extra)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sync"
+)
+
+// MaxTablePrefixLength наибольшая длина префикса: самые длинные имена объектов схемы
+// (idx_employees_name_sort_und_x_icu) с префиксом не должны превышать 63 байта,
+// предел длины идентификатора PostgreSQL
+const MaxTablePrefixLength = 24
+
+// tablePrefixPattern допустимый префикс: идентификатор без кавычек, который
+// безопасно подставлять в SQL
+var tablePrefixPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// schemaNames имена объектов схемы, к которым добавляется префикс: таблицы, индексы,
+// ограничение уникальности телефона, функция триггера и канал NOTIFY. Колонки (employee_id) и имя триггера не меняются:
+// триггер принадлежит таблице
+var schemaNames = regexp.MustCompile(`\b(employees_history|employees_phone_key|employees|employee_merges|employee_attendance|idempotency_keys|notify_employee_change|employee_changes|idx_\w+)\b`)
+
+// ValidateTablePrefix проверяет префикс имен таблиц (DB_TABLE_PREFIX); пустой допустим
+func ValidateTablePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if len(prefix) > MaxTablePrefixLength || !tablePrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("префикс таблиц должен состоять из строчных латинских букв, цифр и _, начинаться не с цифры и быть не длиннее %d символов, получено %q",
+			MaxTablePrefixLength, prefix)
+	}
+	return nil
+}
+
+// Prefixed возвращает query, в котором имена таблиц, индексов, функции триггера
+// и канала уведомлений начинаются с prefix: "employees" -> "tenant1_employees".
+// prefix должен пройти ValidateTablePrefix
+func Prefixed(query, prefix string) string {
+	if prefix == "" {
+		return query
+	}
+	return schemaNames.ReplaceAllString(query, prefix+"$0")
+}
+
+// TablePrefixer подставляет префикс в запросы так же, как Prefixed, но переписывает
+// каждый текст запроса регулярным выражением только один раз. Запросы репозитория
+// собираются из конечного набора фрагментов (фильтры, слова поиска до предела),
+// поэтому кэш не растет неограниченно. Безопасен для одновременного использования
+type TablePrefixer struct {
+	prefix  string
+	queries sync.Map
+}
+
+// NewTablePrefixer создает подстановку префикса prefix; prefix должен пройти ValidateTablePrefix
+func NewTablePrefixer(prefix string) *TablePrefixer {
+	return &TablePrefixer{prefix: prefix}
+}
+
+// Prefix префикс имен таблиц
+func (p *TablePrefixer) Prefix() string {
+	return p.prefix
+}
+
+// Query возвращает query с префиксом имен (см. Prefixed)
+func (p *TablePrefixer) Query(query string) string {
+	if prefixed, ok := p.queries.Load(query); ok {
+		return prefixed.(string)
+	}
+	prefixed := Prefixed(query, p.prefix)
+	p.queries.Store(query, prefixed)
+	return prefixed
+}
+
+// PrefixedLockKey возвращает ключ advisory lock key для таблиц с префиксом prefix:
+// экземпляры с разными префиксами в одной БД не блокируют друг друга.
+// Без префикса ключ не меняется
+func PrefixedLockKey(key int64, prefix string) int64 {
+	if prefix == "" {
+		return key
+	}
+	h := fnv.New64a()
+	h.Write([]byte(prefix))
+	return key ^ int64(h.Sum64())
+}
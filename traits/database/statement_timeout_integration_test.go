@@ -0,0 +1,53 @@
+//go:build integration
+
+package database_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"employer/traits/database"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// timeoutConfig подключение по URL с ограничением времени запроса
+type timeoutConfig struct {
+	url     string
+	timeout time.Duration
+}
+
+func (c timeoutConfig) GetDBHost() string                    { return "" }
+func (c timeoutConfig) GetDBPort() string                    { return "" }
+func (c timeoutConfig) GetDBUser() string                    { return "" }
+func (c timeoutConfig) GetDBPassword() string                { return "" }
+func (c timeoutConfig) GetDBName() string                    { return "" }
+func (c timeoutConfig) GetDBSSLMode() string                 { return "" }
+func (c timeoutConfig) GetDatabaseURL() string               { return c.url }
+func (c timeoutConfig) GetDBStatementTimeout() time.Duration { return c.timeout }
+
+func TestIntegration_StatementTimeout(t *testing.T) {
+	dsn := os.Getenv("EMPLOYER_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("EMPLOYER_TEST_DATABASE_URL не задан")
+	}
+
+	db, err := database.InitDatabase(timeoutConfig{url: dsn, timeout: 100 * time.Millisecond}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	_, err = db.Exec(`SELECT pg_sleep(5)`)
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "57014" {
+		t.Fatalf("expected query_canceled (57014), got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("query was not cancelled by statement_timeout: %v", elapsed)
+	}
+}
@@ -1,32 +1,169 @@
 package logger
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// NewLogger создает новый logger
+// Config интерфейс для конфигурации логгера
+type Config interface {
+	GetEnvironment() string
+	GetLogLevel() string
+	GetLogFile() string
+	GetLogMaxSizeMB() int
+	GetLogMaxBackups() int
+	GetLogMaxAgeDays() int
+}
+
+// Options параметры логгера
+type Options struct {
+	Environment string
+	// Level минимальный уровень: debug, info, warn, error.
+	// Пустое значение — debug в development и info в остальных окружениях.
+	Level string
+	// File путь к JSON файлу логов с ротацией; пусто — только консоль
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// NewLogger создает logger по переменным окружения ENVIRONMENT, LOG_LEVEL,
+// LOG_FILE, LOG_MAX_SIZE_MB, LOG_MAX_BACKUPS и LOG_MAX_AGE_DAYS
 func NewLogger() (*zap.Logger, error) {
-	env := os.Getenv("ENVIRONMENT")
+	opts := Options{
+		Environment: os.Getenv("ENVIRONMENT"),
+		Level:       os.Getenv("LOG_LEVEL"),
+		File:        os.Getenv("LOG_FILE"),
+	}
+
+	for key, dst := range map[string]*int{
+		"LOG_MAX_SIZE_MB":  &opts.MaxSizeMB,
+		"LOG_MAX_BACKUPS":  &opts.MaxBackups,
+		"LOG_MAX_AGE_DAYS": &opts.MaxAgeDays,
+	} {
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s должен быть целым числом, получено %q", key, raw)
+		}
+		*dst = value
+	}
+
+	return New(opts)
+}
+
+// NewLoggerFromConfig создает logger по уже загруженной конфигурации
+func NewLoggerFromConfig(cfg Config) (*zap.Logger, error) {
+	logger, _, err := NewLoggerWithLevel(cfg)
+	return logger, err
+}
+
+// NewLoggerWithLevel создает logger по конфигурации и возвращает его уровень:
+// SetLevel меняет уровень работающего логгера (перезагрузка конфигурации)
+func NewLoggerWithLevel(cfg Config) (*zap.Logger, zap.AtomicLevel, error) {
+	return newLogger(Options{
+		Environment: cfg.GetEnvironment(),
+		Level:       cfg.GetLogLevel(),
+		File:        cfg.GetLogFile(),
+		MaxSizeMB:   cfg.GetLogMaxSizeMB(),
+		MaxBackups:  cfg.GetLogMaxBackups(),
+		MaxAgeDays:  cfg.GetLogMaxAgeDays(),
+	})
+}
+
+// New создает logger: вывод в консоль и, если задан opts.File, дополнительно
+// в JSON файл с ротацией
+func New(opts Options) (*zap.Logger, error) {
+	logger, _, err := newLogger(opts)
+	return logger, err
+}
 
+func newLogger(opts Options) (*zap.Logger, zap.AtomicLevel, error) {
 	var config zap.Config
 
-	if env == "production" {
+	if opts.Environment == "production" {
 		config = zap.NewProductionConfig()
 	} else {
 		config = zap.NewDevelopmentConfig()
 	}
 
+	level, err := EffectiveLevel(opts.Environment, opts.Level)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+	config.Level = zap.NewAtomicLevelAt(level)
+
 	// Настройка кодировщика для более читаемого вывода
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.LevelKey = "level"
 	config.EncoderConfig.MessageKey = "message"
 
-	logger, err := config.Build()
+	var buildOpts []zap.Option
+	if opts.File != "" {
+		fileCore := newFileCore(opts, config.Level)
+		buildOpts = append(buildOpts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+
+	logger, err := config.Build(buildOpts...)
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
+	}
+
+	return logger, config.Level, nil
+}
+
+// EffectiveLevel уровень логгера для окружения: level, а если он пуст —
+// debug в development и info в остальных окружениях
+func EffectiveLevel(environment, level string) (zapcore.Level, error) {
+	if level != "" {
+		return ParseLevel(level)
+	}
+	if environment == "production" {
+		return zapcore.InfoLevel, nil
+	}
+	return zapcore.DebugLevel, nil
+}
+
+// ParseLevel разбирает минимальный уровень логирования
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch level {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("LOG_LEVEL должен быть одним из debug, info, warn, error, получено %q", level)
+	}
+}
+
+// newFileCore создает JSON core, пишущий в файл с ротацией по размеру и возрасту
+func newFileCore(opts Options, level zapcore.LevelEnabler) zapcore.Core {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	writer := &lumberjack.Logger{
+		Filename:   opts.File,
+		MaxSize:    opts.MaxSizeMB,
+		MaxBackups: opts.MaxBackups,
+		MaxAge:     opts.MaxAgeDays,
+		Compress:   true,
 	}
 
-	return logger, nil
+	return zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(writer), level)
 }
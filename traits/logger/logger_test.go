@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew_FileSinkFiltersByLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	log, err := New(Options{Environment: "production", Level: "warn", File: path, MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	log.Debug("debug message")
+	log.Info("info message")
+	log.Warn("warn message")
+	log.Error("error message")
+	_ = log.Sync()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log file: %v", err)
+	}
+	defer f.Close()
+
+	var messages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("file entry is not JSON: %q", scanner.Text())
+		}
+		messages = append(messages, entry["msg"].(string))
+	}
+
+	if len(messages) != 2 || messages[0] != "warn message" || messages[1] != "error message" {
+		t.Fatalf("expected only warn and error entries, got %v", messages)
+	}
+}
+
+func TestNew_UnknownLevel(t *testing.T) {
+	if _, err := New(Options{Level: "verbose"}); err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+}
+
+func TestNewLogger_InvalidRotationValue(t *testing.T) {
+	t.Setenv("LOG_MAX_SIZE_MB", "big")
+	if _, err := NewLogger(); err == nil {
+		t.Fatalf("expected error for non-numeric LOG_MAX_SIZE_MB")
+	}
+}